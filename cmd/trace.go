@@ -0,0 +1,182 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dadrus/heimdall/internal/handler/management"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// nolint: gochecknoglobals
+var traceCmd = &cobra.Command{
+	Use:   "trace [url]",
+	Short: "Shows which rule matches the given request and optionally traces the execution of its pipeline",
+	Example: `heimdall trace -e https://heimdall.local https://my-service.local/foo
+heimdall trace -e https://heimdall.local -m POST -H "Authorization: Bearer foo" -x https://my-service.local/foo`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		endpointURL, _ := cmd.Flags().GetString("endpoint")
+		outputFormat, _ := cmd.Flags().GetString("output")
+
+		traceReq, err := newTraceRequest(cmd, args[0])
+		if err != nil {
+			cmd.PrintErrf("Invalid arguments: %v", err)
+			os.Exit(-1)
+		}
+
+		rawReq, err := json.Marshal(traceReq)
+		if err != nil {
+			cmd.PrintErrf("Failed to marshal request: %v", err)
+			os.Exit(-1)
+		}
+
+		resp, err := http.DefaultClient.Post(fmt.Sprintf("%s%s", endpointURL, management.EndpointTrace),
+			"application/json", bytes.NewReader(rawReq))
+		if err != nil {
+			cmd.PrintErrf("Failed to send request: %v", err)
+			os.Exit(-1)
+		}
+
+		defer resp.Body.Close()
+
+		rawResp, err := io.ReadAll(resp.Body)
+		if err != nil {
+			cmd.PrintErrf("Failed to read response: %v", err)
+			os.Exit(-1)
+		}
+
+		var traceResp management.TraceResponse
+		if err := json.Unmarshal(rawResp, &traceResp); err != nil {
+			cmd.PrintErrf("Unexpected response (HTTP status code: %s): %v", resp.Status, err)
+			os.Exit(-1)
+		}
+
+		switch outputFormat {
+		case "json":
+			cmd.Println(stringx.ToString(rawResp))
+		case "yaml":
+			var structuredResponse map[string]any
+			_ = json.Unmarshal(rawResp, &structuredResponse)
+
+			rawYaml, err := yaml.Marshal(structuredResponse)
+			if err != nil {
+				cmd.PrintErrf("Failed to convert response to yaml: %v", err)
+				os.Exit(-1)
+			}
+			cmd.Println(stringx.ToString(rawYaml))
+		default:
+			printTrace(cmd, &traceResp)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			os.Exit(-1)
+		}
+	},
+}
+
+func newTraceRequest(cmd *cobra.Command, reqURL string) (*management.TraceRequest, error) {
+	method, _ := cmd.Flags().GetString("method")
+	headers, _ := cmd.Flags().GetStringArray("header")
+	cookies, _ := cmd.Flags().GetStringArray("cookie")
+	execute, _ := cmd.Flags().GetBool("execute")
+
+	req := &management.TraceRequest{
+		Method:  strings.ToUpper(method),
+		URL:     reqURL,
+		Headers: make(map[string]string, len(headers)),
+		Cookies: make(map[string]string, len(cookies)),
+		Execute: execute,
+	}
+
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return nil, fmt.Errorf("header %q is not in the 'Name: value' format", header) // nolint: goerr113
+		}
+
+		req.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	for _, cookie := range cookies {
+		name, value, ok := strings.Cut(cookie, "=")
+		if !ok {
+			return nil, fmt.Errorf("cookie %q is not in the 'name=value' format", cookie) // nolint: goerr113
+		}
+
+		req.Cookies[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return req, nil
+}
+
+func printTrace(cmd *cobra.Command, resp *management.TraceResponse) {
+	if resp.Rule == nil {
+		cmd.Printf("Trace failed: %s\n", resp.Error)
+
+		return
+	}
+
+	cmd.Printf("Matched rule: %s (src: %s)\n", resp.Rule.ID, resp.Rule.SrcID)
+
+	if resp.Result == nil {
+		return
+	}
+
+	for _, step := range resp.Trace {
+		cmd.Printf("  %s %s: %s", step.Type, step.ID, step.Outcome)
+
+		if len(step.Error) != 0 {
+			cmd.Printf(" (%s)", step.Error)
+		}
+
+		cmd.Println()
+	}
+
+	if resp.Result.Allowed {
+		cmd.Println("Result: allowed")
+	} else {
+		cmd.Printf("Result: denied (%s)\n", resp.Result.Error)
+	}
+}
+
+// nolint: gochecknoinits
+func init() {
+	RootCmd.AddCommand(traceCmd)
+
+	traceCmd.PersistentFlags().StringP("endpoint", "e", "", `The base URL of Heimdall's management service. 
+Note: The endpoint URL should point to a single Heimdall deployment.`)
+	traceCmd.PersistentFlags().StringP("method", "m", http.MethodGet, "The HTTP method of the request to trace.")
+	traceCmd.PersistentFlags().StringArrayP("header", "H", nil,
+		`A header of the request to trace in the "Name: value" format. Can be specified multiple times.`)
+	traceCmd.PersistentFlags().StringArrayP("cookie", "c", nil,
+		`A cookie of the request to trace in the "name=value" format. Can be specified multiple times.`)
+	traceCmd.PersistentFlags().BoolP("execute", "x", false,
+		"Execute the pipeline of the matched rule and trace the outcome of each mechanism.")
+	traceCmd.PersistentFlags().StringP("output", "o", "text", `The format for the result output.
+Can be "json", "text", or "yaml".`)
+}
@@ -25,6 +25,7 @@ type ctxKey struct{}
 type accessContext struct {
 	err     error
 	subject string
+	ruleID  string
 }
 
 func New(ctx context.Context) context.Context {
@@ -58,3 +59,17 @@ func SetSubject(ctx context.Context, subject string) {
 		c.subject = subject
 	}
 }
+
+func RuleID(ctx context.Context) string {
+	if c, ok := ctx.Value(ctxKey{}).(*accessContext); ok {
+		return c.ruleID
+	}
+
+	return ""
+}
+
+func SetRuleID(ctx context.Context, ruleID string) {
+	if c, ok := ctx.Value(ctxKey{}).(*accessContext); ok {
+		c.ruleID = ruleID
+	}
+}
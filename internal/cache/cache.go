@@ -17,6 +17,7 @@
 package cache
 
 import (
+	"context"
 	"time"
 )
 
@@ -29,3 +30,9 @@ type Cache interface {
 	Set(key string, value any, ttl time.Duration)
 	Delete(key string)
 }
+
+// ScriptEvaluator is implemented by caches, which can evaluate lua scripts atomically on the
+// server side. This allows read-modify-write cycles on entries shared between heimdall instances.
+type ScriptEvaluator interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
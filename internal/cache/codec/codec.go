@@ -0,0 +1,132 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/goccy/go-json"
+)
+
+var (
+	ErrUnknownType = errors.New("unknown type")
+
+	typesMutex sync.RWMutex           //nolint:gochecknoglobals
+	types      = registeredBuiltins() //nolint:gochecknoglobals
+)
+
+type envelope struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v"`
+}
+
+// Register makes the type of the given value known to the codec. Caches, which cannot hold
+// values as is, like caches using remote backends, can only store values of registered types.
+// The type must be serializable to and from JSON. Similar to gob.Register, this function is
+// expected to be called from init functions.
+func Register(value any) {
+	typ := reflect.TypeOf(value)
+
+	typesMutex.Lock()
+	defer typesMutex.Unlock()
+
+	types[typeName(typ)] = typ
+}
+
+// Encode serializes the given value together with the name of its type.
+func Encode(value any) ([]byte, error) {
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		return nil, fmt.Errorf("%w: nil", ErrUnknownType)
+	}
+
+	name := typeName(typ)
+
+	typesMutex.RLock()
+	_, known := types[name]
+	typesMutex.RUnlock()
+
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, name)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(envelope{Type: name, Value: raw})
+}
+
+// Decode deserializes the value encoded with Encode into an instance of its original type.
+func Decode(data []byte) (any, error) {
+	var env envelope
+
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+
+	typesMutex.RLock()
+	typ, known := types[env.Type]
+	typesMutex.RUnlock()
+
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, env.Type)
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		value := reflect.New(typ.Elem())
+		if err := json.Unmarshal(env.Value, value.Interface()); err != nil {
+			return nil, err
+		}
+
+		return value.Interface(), nil
+	}
+
+	value := reflect.New(typ)
+	if err := json.Unmarshal(env.Value, value.Interface()); err != nil {
+		return nil, err
+	}
+
+	return value.Elem().Interface(), nil
+}
+
+func typeName(typ reflect.Type) string {
+	if typ.Kind() == reflect.Pointer {
+		return "*" + typeName(typ.Elem())
+	}
+
+	if len(typ.PkgPath()) == 0 {
+		return typ.String()
+	}
+
+	return typ.PkgPath() + "." + typ.Name()
+}
+
+func registeredBuiltins() map[string]reflect.Type {
+	registered := make(map[string]reflect.Type)
+
+	for _, value := range []any{"", []byte{}} {
+		typ := reflect.TypeOf(value)
+		registered[typeName(typ)] = typ
+	}
+
+	return registered
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValue struct {
+	Foo string `json:"foo"`
+	Bar int    `json:"bar"`
+}
+
+type unregisteredValue struct{}
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	Register(&testValue{})
+
+	for _, tc := range []struct {
+		uc    string
+		value any
+	}{
+		{uc: "string", value: "foo"},
+		{uc: "byte slice", value: []byte("bar")},
+		{uc: "registered struct pointer", value: &testValue{Foo: "baz", Bar: 42}},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			data, err := Encode(tc.value)
+			require.NoError(t, err)
+
+			decoded, err := Decode(data)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.value, decoded)
+		})
+	}
+}
+
+func TestEncodeUnregisteredType(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []any{nil, &unregisteredValue{}, 10} {
+		_, err := Encode(value)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrUnknownType)
+	}
+}
+
+func TestDecodeUnknownType(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	_, err := Decode([]byte(`{"t": "foo.bar", "v": {}}`))
+
+	// THEN
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
+
+func TestDecodeMalformedData(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	_, err := Decode([]byte(`foo`))
+
+	// THEN
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnknownType)
+}
@@ -17,41 +17,293 @@
 package memory
 
 import (
+	"container/list"
 	"context"
+	"sync"
 	"time"
 
-	"github.com/jellydator/ttlcache/v3"
+	"github.com/goccy/go-json"
+	"github.com/inhies/go-bytesize"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
+const (
+	cleanupInterval = 10 * time.Second
+
+	evictionReasonExpired  = "expired"
+	evictionReasonCapacity = "capacity"
+)
+
+type entry struct {
+	key       string
+	value     any
+	size      uint64
+	expiresAt time.Time
+}
+
+// isExpired reports whether the entry is expired. Entries without expiration time never expire.
+func (e *entry) isExpired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// InMemoryCache is a cache holding the values in the process memory. If configured, the number of
+// entries and the overall size of the cached values is bounded. If a limit is reached, the least
+// recently used entries are evicted.
 type InMemoryCache struct {
-	c *ttlcache.Cache[string, any]
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	size       uint64
+	maxEntries uint64
+	maxSize    uint64
+
+	hits      uint64
+	misses    uint64
+	evictions map[string]uint64
+
+	stopOnce sync.Once
+	done     chan struct{}
+
+	hitsDesc      *prometheus.Desc
+	missesDesc    *prometheus.Desc
+	evictionsDesc *prometheus.Desc
+	entriesDesc   *prometheus.Desc
+	sizeDesc      *prometheus.Desc
 }
 
-func New() *InMemoryCache {
-	return &InMemoryCache{c: ttlcache.New[string, any](ttlcache.WithDisableTouchOnHit[string, any]())}
+type Option func(cache *InMemoryCache)
+
+// WithMaxEntries limits the number of entries in the cache. 0 means no limit.
+func WithMaxEntries(count uint64) Option {
+	return func(cache *InMemoryCache) {
+		cache.maxEntries = count
+	}
+}
+
+// WithMaxSize limits the overall size of the cached entries in bytes. 0 means no limit.
+func WithMaxSize(bytes uint64) Option {
+	return func(cache *InMemoryCache) {
+		cache.maxSize = bytes
+	}
+}
+
+func New(opts ...Option) *InMemoryCache {
+	cache := &InMemoryCache{
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+		evictions: make(map[string]uint64),
+		done:      make(chan struct{}),
+		hitsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("cache", "", "hits_total"),
+			"Number of cache lookups, which found a value", nil, nil),
+		missesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("cache", "", "misses_total"),
+			"Number of cache lookups, which did not find a value", nil, nil),
+		evictionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("cache", "", "evictions_total"),
+			"Number of entries removed from the cache because they expired or a limit has been reached",
+			[]string{"reason"}, nil),
+		entriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("cache", "", "entries"),
+			"Number of entries currently held in the cache", nil, nil),
+		sizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("cache", "", "size_bytes"),
+			"Approximate size of the entries currently held in the cache", nil, nil),
+	}
+
+	for _, opt := range opts {
+		opt(cache)
+	}
+
+	return cache
+}
+
+// NewCache creates an in memory cache from the given configuration. Without configuration,
+// the cache is not bounded.
+func NewCache(rawConf map[string]any) (*InMemoryCache, error) {
+	type Config struct {
+		MaxEntries uint64            `mapstructure:"max_entries"`
+		MaxSize    bytesize.ByteSize `mapstructure:"max_size"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConf, &conf); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to decode memory cache config").
+			CausedBy(err)
+	}
+
+	return New(WithMaxEntries(conf.MaxEntries), WithMaxSize(uint64(conf.MaxSize))), nil
 }
 
 func (c *InMemoryCache) Start(_ context.Context) error {
-	go c.c.Start()
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.deleteExpired()
+			case <-c.done:
+				return
+			}
+		}
+	}()
 
 	return nil
 }
 
 func (c *InMemoryCache) Stop(_ context.Context) error {
-	c.c.Stop()
+	c.stopOnce.Do(func() { close(c.done) })
 
 	return nil
 }
 
 func (c *InMemoryCache) Get(key string) any {
-	item := c.c.Get(key)
-	if item != nil && !item.IsExpired() {
-		return item.Value()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.entries[key]
+	if !found {
+		c.misses++
+
+		return nil
 	}
 
-	return nil
+	ent := elem.Value.(*entry) // nolint: forcetypeassert
+	if ent.isExpired(time.Now()) {
+		c.misses++
+		c.remove(elem, evictionReasonExpired)
+
+		return nil
+	}
+
+	c.hits++
+	c.lru.MoveToFront(elem)
+
+	return ent.value
 }
 
-func (c *InMemoryCache) Set(key string, value any, ttl time.Duration) { c.c.Set(key, value, ttl) }
+// Set stores the given value. A ttl <= 0 results in an entry, which does not expire. It is
+// however still subject to eviction if the configured limits are reached.
+func (c *InMemoryCache) Set(key string, value any, ttl time.Duration) {
+	var (
+		size      uint64
+		expiresAt time.Time
+	)
 
-func (c *InMemoryCache) Delete(key string) { c.c.Delete(key) }
+	// calculating the size can be expensive, so it is only done if the size is bounded
+	if c.maxSize != 0 {
+		size = uint64(len(key)) + sizeOf(value)
+	}
+
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.entries[key]; found {
+		c.remove(elem, "")
+	}
+
+	// values, which could never fit, would just empty the cache
+	if c.maxSize != 0 && size > c.maxSize {
+		return
+	}
+
+	for c.lru.Len() != 0 &&
+		((c.maxEntries != 0 && uint64(c.lru.Len()) >= c.maxEntries) ||
+			(c.maxSize != 0 && c.size+size > c.maxSize)) {
+		c.remove(c.lru.Back(), evictionReasonCapacity)
+	}
+
+	c.entries[key] = c.lru.PushFront(&entry{
+		key:       key,
+		value:     value,
+		size:      size,
+		expiresAt: expiresAt,
+	})
+	c.size += size
+}
+
+func (c *InMemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.entries[key]; found {
+		c.remove(elem, "")
+	}
+}
+
+func (c *InMemoryCache) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hitsDesc
+	ch <- c.missesDesc
+	ch <- c.evictionsDesc
+	ch <- c.entriesDesc
+	ch <- c.sizeDesc
+}
+
+func (c *InMemoryCache) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(c.hitsDesc, prometheus.CounterValue, float64(c.hits))
+	ch <- prometheus.MustNewConstMetric(c.missesDesc, prometheus.CounterValue, float64(c.misses))
+	ch <- prometheus.MustNewConstMetric(c.entriesDesc, prometheus.GaugeValue, float64(c.lru.Len()))
+	ch <- prometheus.MustNewConstMetric(c.sizeDesc, prometheus.GaugeValue, float64(c.size))
+
+	for _, reason := range []string{evictionReasonExpired, evictionReasonCapacity} {
+		ch <- prometheus.MustNewConstMetric(c.evictionsDesc, prometheus.CounterValue,
+			float64(c.evictions[reason]), reason)
+	}
+}
+
+func (c *InMemoryCache) deleteExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	for _, elem := range c.entries {
+		if elem.Value.(*entry).isExpired(now) { // nolint: forcetypeassert
+			c.remove(elem, evictionReasonExpired)
+		}
+	}
+}
+
+// remove deletes the given element. If a reason is given, the removal is counted as eviction.
+// Not concurrently safe.
+func (c *InMemoryCache) remove(elem *list.Element, reason string) {
+	ent := elem.Value.(*entry) // nolint: forcetypeassert
+
+	c.lru.Remove(elem)
+	delete(c.entries, ent.key)
+	c.size -= ent.size
+
+	if len(reason) != 0 {
+		c.evictions[reason]++
+	}
+}
+
+// sizeOf returns the approximate size of the given value in bytes. For values other than strings
+// and byte slices, the size of their JSON representation is used.
+func sizeOf(value any) uint64 {
+	switch val := value.(type) {
+	case string:
+		return uint64(len(val))
+	case []byte:
+		return uint64(len(val))
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return 0
+		}
+
+		return uint64(len(data))
+	}
+}
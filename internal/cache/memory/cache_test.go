@@ -17,10 +17,16 @@
 package memory
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
 func TestCacheUsage(t *testing.T) {
@@ -77,6 +83,36 @@ func TestCacheUsage(t *testing.T) {
 				assert.Nil(t, data)
 			},
 		},
+		{
+			uc:  "value without ttl does not expire",
+			key: "foo",
+			configureCache: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("foo", "bar", 0)
+				cache.deleteExpired()
+			},
+			assert: func(t *testing.T, data any) {
+				t.Helper()
+
+				assert.Equal(t, "bar", data)
+			},
+		},
+		{
+			uc:  "can retrieve overwritten value",
+			key: "foo",
+			configureCache: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("foo", "bar", 1*time.Minute)
+				cache.Set("foo", "baz", 1*time.Minute)
+			},
+			assert: func(t *testing.T, data any) {
+				t.Helper()
+
+				assert.Equal(t, "baz", data)
+			},
+		},
 		{
 			uc:  "cannot retrieve not existing value",
 			key: "baz",
@@ -124,3 +160,261 @@ func TestCacheExpiration(t *testing.T) {
 
 	assert.LessOrEqual(t, hits, 4)
 }
+
+func TestNewCache(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config map[string]any
+		assert func(t *testing.T, err error, cache *InMemoryCache)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, cache *InMemoryCache) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Zero(t, cache.maxEntries)
+				assert.Zero(t, cache.maxSize)
+			},
+		},
+		{
+			uc:     "with limits",
+			config: map[string]any{"max_entries": 100, "max_size": "1MB"},
+			assert: func(t *testing.T, err error, cache *InMemoryCache) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, uint64(100), cache.maxEntries)
+				assert.Equal(t, uint64(1024*1024), cache.maxSize)
+			},
+		},
+		{
+			uc:     "with malformed size",
+			config: map[string]any{"max_size": "foo"},
+			assert: func(t *testing.T, err error, cache *InMemoryCache) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc:     "with unsupported properties",
+			config: map[string]any{"foo": "bar"},
+			assert: func(t *testing.T, err error, cache *InMemoryCache) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			cache, err := NewCache(tc.config)
+
+			// THEN
+			tc.assert(t, err, cache)
+		})
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		opts   []Option
+		fill   func(t *testing.T, cache *InMemoryCache)
+		assert func(t *testing.T, cache *InMemoryCache)
+	}{
+		{
+			uc:   "least recently used entry is evicted if max entries is reached",
+			opts: []Option{WithMaxEntries(2)},
+			fill: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("foo", "1", 1*time.Minute)
+				cache.Set("bar", "2", 1*time.Minute)
+				// makes foo the most recently used one
+				cache.Get("foo")
+				cache.Set("baz", "3", 1*time.Minute)
+			},
+			assert: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				assert.Equal(t, "1", cache.Get("foo"))
+				assert.Nil(t, cache.Get("bar"))
+				assert.Equal(t, "3", cache.Get("baz"))
+				assert.Equal(t, 2, cache.lru.Len())
+				assert.Equal(t, uint64(1), cache.evictions[evictionReasonCapacity])
+			},
+		},
+		{
+			uc:   "least recently used entries are evicted if max size is reached",
+			opts: []Option{WithMaxSize(20)},
+			fill: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("a", []byte("123456789"), 1*time.Minute)
+				cache.Set("b", "123456789", 1*time.Minute)
+				cache.Set("c", "123456789", 1*time.Minute)
+			},
+			assert: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				assert.Nil(t, cache.Get("a"))
+				assert.Equal(t, "123456789", cache.Get("b"))
+				assert.Equal(t, "123456789", cache.Get("c"))
+				assert.Equal(t, uint64(20), cache.size)
+				assert.Equal(t, uint64(1), cache.evictions[evictionReasonCapacity])
+			},
+		},
+		{
+			uc:   "value exceeding max size is not stored",
+			opts: []Option{WithMaxSize(10)},
+			fill: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("a", "1", 1*time.Minute)
+				cache.Set("b", "this value is too big", 1*time.Minute)
+			},
+			assert: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				assert.Equal(t, "1", cache.Get("a"))
+				assert.Nil(t, cache.Get("b"))
+				assert.Equal(t, uint64(2), cache.size)
+				assert.Zero(t, cache.evictions[evictionReasonCapacity])
+			},
+		},
+		{
+			uc:   "size of structured values is taken into account",
+			opts: []Option{WithMaxSize(100)},
+			fill: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("a", map[string]any{"foo": "bar"}, 1*time.Minute)
+			},
+			assert: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				assert.NotNil(t, cache.Get("a"))
+				// key + {"foo":"bar"}
+				assert.Equal(t, uint64(14), cache.size)
+			},
+		},
+		{
+			uc: "size is not calculated if not bounded",
+			fill: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("a", map[string]string{"foo": "bar"}, 1*time.Minute)
+			},
+			assert: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				assert.NotNil(t, cache.Get("a"))
+				assert.Zero(t, cache.size)
+			},
+		},
+		{
+			uc:   "expired entries are removed",
+			opts: []Option{WithMaxSize(100)},
+			fill: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("a", "1", 1*time.Microsecond)
+				cache.Set("b", "2", 1*time.Minute)
+
+				time.Sleep(10 * time.Millisecond)
+				cache.deleteExpired()
+			},
+			assert: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				assert.Equal(t, 1, cache.lru.Len())
+				assert.Equal(t, uint64(2), cache.size)
+				assert.Equal(t, uint64(1), cache.evictions[evictionReasonExpired])
+			},
+		},
+		{
+			uc: "deleted entries are not counted as evictions",
+			fill: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				cache.Set("a", "1", 1*time.Minute)
+				cache.Delete("a")
+			},
+			assert: func(t *testing.T, cache *InMemoryCache) {
+				t.Helper()
+
+				assert.Zero(t, cache.lru.Len())
+				assert.Zero(t, cache.size)
+				assert.Empty(t, cache.evictions)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			cache := New(tc.opts...)
+
+			// WHEN
+			tc.fill(t, cache)
+
+			// THEN
+			tc.assert(t, cache)
+		})
+	}
+}
+
+func TestCacheMetrics(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cache := New(WithMaxEntries(1), WithMaxSize(100))
+	require.NoError(t, cache.Start(context.Background()))
+
+	defer cache.Stop(context.Background())
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(cache)
+
+	cache.Set("foo", "bar", 1*time.Minute)
+	cache.Get("foo")
+	cache.Get("bar")
+	cache.Set("bar", "baz", 1*time.Minute)
+
+	// WHEN
+	families, err := reg.Gather()
+
+	// THEN
+	require.NoError(t, err)
+
+	metrics := make(map[string]*dto.MetricFamily, len(families))
+	for _, family := range families {
+		metrics[family.GetName()] = family
+	}
+
+	require.Len(t, metrics, 5)
+	assert.Equal(t, 1.0, metrics["cache_hits_total"].GetMetric()[0].GetCounter().GetValue())
+	assert.Equal(t, 1.0, metrics["cache_misses_total"].GetMetric()[0].GetCounter().GetValue())
+	assert.Equal(t, 1.0, metrics["cache_entries"].GetMetric()[0].GetGauge().GetValue())
+	assert.Equal(t, 6.0, metrics["cache_size_bytes"].GetMetric()[0].GetGauge().GetValue())
+
+	evictions := metrics["cache_evictions_total"].GetMetric()
+	require.Len(t, evictions, 2)
+
+	for _, metric := range evictions {
+		switch metric.GetLabel()[0].GetValue() {
+		case evictionReasonCapacity:
+			assert.Equal(t, 1.0, metric.GetCounter().GetValue())
+		case evictionReasonExpired:
+			assert.Equal(t, 0.0, metric.GetCounter().GetValue())
+		default:
+			t.Errorf("unexpected eviction reason %s", metric.GetLabel()[0].GetValue())
+		}
+	}
+}
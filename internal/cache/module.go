@@ -19,30 +19,63 @@ package cache
 import (
 	"context"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 
 	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/cache/redis"
 	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/readiness"
 )
 
 //nolint:gochecknoglobals
-var Module = fx.Provide(
-	fx.Annotate(
-		newCache,
-		fx.OnStart(func(ctx context.Context, cch Cache) error { return cch.Start(ctx) }),
-		fx.OnStop(func(ctx context.Context, cch Cache) error { return cch.Stop(ctx) }),
+var Module = fx.Options(
+	fx.Provide(
+		fx.Annotate(
+			newCache,
+			fx.OnStart(func(ctx context.Context, cch Cache) error { return cch.Start(ctx) }),
+			fx.OnStop(func(ctx context.Context, cch Cache) error { return cch.Stop(ctx) }),
+		),
 	),
+	// only caches, which depend on external backends, report their readiness
+	fx.Invoke(func(registry readiness.Registry, cch Cache) {
+		if checker, ok := cch.(readiness.Checker); ok {
+			registry.Register(checker)
+		}
+	}),
+	// only caches, which are not backed by external systems, expose their own metrics
+	fx.Invoke(func(reg prometheus.Registerer, cch Cache) {
+		if collector, ok := cch.(prometheus.Collector); ok {
+			reg.MustRegister(collector)
+		}
+	}),
 )
 
-func newCache(conf *config.Configuration, logger zerolog.Logger) Cache {
-	if len(conf.Cache.Type) == 0 {
+func newCache(conf *config.Configuration, logger zerolog.Logger) (Cache, error) {
+	switch conf.Cache.Type {
+	case "", "memory":
 		logger.Info().Msg("Instantiating in memory cache")
 
-		return memory.New()
-	}
+		return memory.NewCache(conf.Cache.Config)
+	case "redis":
+		logger.Info().Msg("Instantiating redis cache")
 
-	logger.Info().Msg("Cache is disabled")
+		return redis.NewCache(conf.Cache.Config, logger)
+	default:
+		logger.Info().Msg("Cache is disabled")
 
-	return noopCache{}
+		return noopCache{}, nil
+	}
+}
+
+// Enabled reports whether the given configuration results in a cache, which actually stores
+// entries, and not in a disabled one.
+func Enabled(conf *config.Configuration) bool {
+	switch conf.Cache.Type {
+	case "", "memory", "redis":
+		return true
+	default:
+		return false
+	}
 }
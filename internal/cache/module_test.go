@@ -21,9 +21,12 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/cache/redis"
 	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
 func TestNewCache(t *testing.T) {
@@ -32,33 +35,105 @@ func TestNewCache(t *testing.T) {
 	for _, tc := range []struct {
 		uc     string
 		conf   *config.Configuration
-		assert func(t *testing.T, cch Cache)
+		assert func(t *testing.T, err error, cch Cache)
 	}{
 		{
 			uc:   "in memory cache",
 			conf: &config.Configuration{},
-			assert: func(t *testing.T, cch Cache) {
+			assert: func(t *testing.T, err error, cch Cache) {
 				t.Helper()
 
+				require.NoError(t, err)
 				assert.IsType(t, &memory.InMemoryCache{}, cch)
 			},
 		},
+		{
+			uc:   "explicitly configured in memory cache",
+			conf: &config.Configuration{Cache: config.CacheConfig{Type: "memory"}},
+			assert: func(t *testing.T, err error, cch Cache) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.IsType(t, &memory.InMemoryCache{}, cch)
+			},
+		},
+		{
+			uc:   "in memory cache with invalid configuration",
+			conf: &config.Configuration{Cache: config.CacheConfig{Config: map[string]any{"foo": "bar"}}},
+			assert: func(t *testing.T, err error, cch Cache) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "redis cache",
+			conf: &config.Configuration{Cache: config.CacheConfig{
+				Type:   "redis",
+				Config: map[string]any{"address": "127.0.0.1:6379"},
+			}},
+			assert: func(t *testing.T, err error, cch Cache) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.IsType(t, &redis.Cache{}, cch)
+			},
+		},
+		{
+			uc:   "redis cache with invalid configuration",
+			conf: &config.Configuration{Cache: config.CacheConfig{Type: "redis"}},
+			assert: func(t *testing.T, err error, cch Cache) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
 		{
 			uc:   "disabled cache",
 			conf: &config.Configuration{Cache: config.CacheConfig{Type: "foo"}},
-			assert: func(t *testing.T, cch Cache) {
+			assert: func(t *testing.T, err error, cch Cache) {
 				t.Helper()
 
+				require.NoError(t, err)
 				assert.IsType(t, noopCache{}, cch)
 			},
 		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// WHEN
-			cch := newCache(tc.conf, log.Logger)
+			cch, err := newCache(tc.conf, log.Logger)
+
+			// THEN
+			tc.assert(t, err, cch)
+		})
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc      string
+		typ     string
+		enabled bool
+	}{
+		{uc: "default cache", enabled: true},
+		{uc: "in memory cache", typ: "memory", enabled: true},
+		{uc: "redis cache", typ: "redis", enabled: true},
+		{uc: "disabled cache", typ: "noop", enabled: false},
+	} {
+		tc := tc
+
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			enabled := Enabled(&config.Configuration{Cache: config.CacheConfig{Type: tc.typ}})
 
 			// THEN
-			tc.assert(t, cch)
+			assert.Equal(t, tc.enabled, enabled)
 		})
 	}
 }
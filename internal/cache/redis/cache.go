@@ -0,0 +1,224 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache/codec"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/readiness"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+type credentials struct {
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+type keyStore struct {
+	Path     string `mapstructure:"path"`
+	Password string `mapstructure:"password"`
+}
+
+type tlsConfig struct {
+	Disabled   bool                  `mapstructure:"disabled"`
+	TrustStore truststore.TrustStore `mapstructure:"trust_store"`
+	KeyStore   *keyStore             `mapstructure:"key_store"`
+	KeyID      string                `mapstructure:"key_id"`
+}
+
+type Cache struct {
+	c      *redis.Client
+	prefix string
+	l      zerolog.Logger
+}
+
+func NewCache(rawConf map[string]any, logger zerolog.Logger) (*Cache, error) {
+	type Config struct {
+		Address     string        `mapstructure:"address"`
+		DB          int           `mapstructure:"db"`
+		KeyPrefix   string        `mapstructure:"key_prefix"`
+		Credentials *credentials  `mapstructure:"credentials"`
+		TLS         tlsConfig     `mapstructure:"tls"`
+		Timeout     time.Duration `mapstructure:"timeout"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConf, &conf); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to decode redis cache config").
+			CausedBy(err)
+	}
+
+	if len(conf.Address) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "no address configured for redis cache")
+	}
+
+	opts := &redis.Options{
+		Addr:         conf.Address,
+		DB:           conf.DB,
+		DialTimeout:  conf.Timeout,
+		ReadTimeout:  conf.Timeout,
+		WriteTimeout: conf.Timeout,
+	}
+
+	if conf.Credentials != nil {
+		opts.Username = conf.Credentials.Username
+		opts.Password = conf.Credentials.Password
+	}
+
+	if !conf.TLS.Disabled {
+		tlsConf, err := newTLSConfig(&conf.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.TLSConfig = tlsConf
+	} else {
+		logger.Warn().Msg("TLS is disabled for the redis cache. NEVER DO IT IN PRODUCTION!!!!")
+	}
+
+	return &Cache{c: redis.NewClient(opts), prefix: conf.KeyPrefix, l: logger}, nil
+}
+
+func newTLSConfig(conf *tlsConfig) (*tls.Config, error) {
+	tlsConf := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(conf.TrustStore) != 0 {
+		pool := x509.NewCertPool()
+		for _, cert := range conf.TrustStore {
+			pool.AddCert(cert)
+		}
+
+		tlsConf.RootCAs = pool
+	}
+
+	if conf.KeyStore == nil {
+		return tlsConf, nil
+	}
+
+	ks, err := keystore.NewKeyStoreFromPEMFile(conf.KeyStore.Path, conf.KeyStore.Password)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed loading key store for the redis cache").CausedBy(err)
+	}
+
+	var entry *keystore.Entry
+
+	if len(conf.KeyID) != 0 {
+		entry, err = ks.GetKey(conf.KeyID)
+	} else {
+		entry, err = ks.Entries()[0], nil
+	}
+
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed retrieving key for the redis cache client certificate").CausedBy(err)
+	}
+
+	cert, err := keystore.ToTLSCertificate(entry)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"key store entry for the redis cache has no certificate").CausedBy(err)
+	}
+
+	tlsConf.Certificates = []tls.Certificate{cert}
+
+	return tlsConf, nil
+}
+
+func (c *Cache) Start(_ context.Context) error { return nil }
+
+func (c *Cache) Stop(_ context.Context) error { return c.c.Close() }
+
+func (c *Cache) Get(key string) any {
+	data, err := c.c.Get(context.Background(), c.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.l.Warn().Err(err).Msg("Failed to retrieve value from redis cache")
+		}
+
+		return nil
+	}
+
+	value, err := codec.Decode(data)
+	if err != nil {
+		c.l.Warn().Err(err).Msg("Failed to decode value from redis cache")
+
+		return nil
+	}
+
+	return value
+}
+
+func (c *Cache) Set(key string, value any, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := codec.Encode(value)
+	if err != nil {
+		c.l.Warn().Err(err).Msg("Failed to encode value for redis cache")
+
+		return
+	}
+
+	if err = c.c.Set(context.Background(), c.prefix+key, data, ttl).Err(); err != nil {
+		c.l.Warn().Err(err).Msg("Failed to store value in redis cache")
+	}
+}
+
+func (c *Cache) Delete(key string) {
+	if err := c.c.Del(context.Background(), c.prefix+key).Err(); err != nil {
+		c.l.Warn().Err(err).Msg("Failed to delete value from redis cache")
+	}
+}
+
+func (c *Cache) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	prefixed := make([]string, len(keys))
+	for idx, key := range keys {
+		prefixed[idx] = c.prefix + key
+	}
+
+	// uses EVALSHA and falls back to EVAL if the script is not yet known to the server
+	return redis.NewScript(script).Run(ctx, c.c, prefixed, args...).Result()
+}
+
+func (c *Cache) Name() string { return "cache" }
+
+func (c *Cache) Status(ctx context.Context) readiness.Status {
+	status := readiness.Status{
+		Ready:   true,
+		Details: map[string]any{"type": "redis", "address": c.c.Options().Addr},
+	}
+
+	if err := c.c.Ping(ctx).Err(); err != nil {
+		status.Ready = false
+		status.Error = err.Error()
+	}
+
+	return status
+}
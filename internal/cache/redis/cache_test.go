@@ -0,0 +1,336 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+type unregistered struct {
+	Value string
+}
+
+func TestNewCache(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config map[string]any
+		assert func(t *testing.T, err error, cch *Cache)
+	}{
+		{
+			uc: "without address",
+			config: map[string]any{
+				"tls": map[string]any{"disabled": true},
+			},
+			assert: func(t *testing.T, err error, cch *Cache) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no address")
+			},
+		},
+		{
+			uc: "with unsupported properties",
+			config: map[string]any{
+				"address": "127.0.0.1:6379",
+				"foo":     "bar",
+			},
+			assert: func(t *testing.T, err error, cch *Cache) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to decode")
+			},
+		},
+		{
+			uc: "with not existing key store",
+			config: map[string]any{
+				"address": "127.0.0.1:6379",
+				"tls": map[string]any{
+					"key_store": map[string]any{"path": "/no/such/file.pem"},
+				},
+			},
+			assert: func(t *testing.T, err error, cch *Cache) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "key store")
+			},
+		},
+		{
+			uc: "with full configuration without TLS",
+			config: map[string]any{
+				"address":     "127.0.0.1:6379",
+				"db":          2,
+				"key_prefix":  "heimdall:",
+				"timeout":     "2s",
+				"credentials": map[string]any{"username": "foo", "password": "bar"},
+				"tls":         map[string]any{"disabled": true},
+			},
+			assert: func(t *testing.T, err error, cch *Cache) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				opts := cch.c.Options()
+				assert.Equal(t, "127.0.0.1:6379", opts.Addr)
+				assert.Equal(t, 2, opts.DB)
+				assert.Equal(t, "foo", opts.Username)
+				assert.Equal(t, "bar", opts.Password)
+				assert.Equal(t, 2*time.Second, opts.DialTimeout)
+				assert.Nil(t, opts.TLSConfig)
+				assert.Equal(t, "heimdall:", cch.prefix)
+			},
+		},
+		{
+			uc:     "with TLS enabled by default",
+			config: map[string]any{"address": "127.0.0.1:6379"},
+			assert: func(t *testing.T, err error, cch *Cache) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, cch.c.Options().TLSConfig)
+				assert.Nil(t, cch.c.Options().TLSConfig.RootCAs)
+				assert.Empty(t, cch.c.Options().TLSConfig.Certificates)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			cch, err := NewCache(tc.config, log.Logger)
+
+			// THEN
+			tc.assert(t, err, cch)
+		})
+	}
+}
+
+func TestCacheUsage(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc             string
+		key            string
+		configureCache func(t *testing.T, srv *miniredis.Miniredis, cch *Cache)
+		assert         func(t *testing.T, srv *miniredis.Miniredis, data any)
+	}{
+		{
+			uc:  "can retrieve not expired value",
+			key: "foo",
+			configureCache: func(t *testing.T, _ *miniredis.Miniredis, cch *Cache) {
+				t.Helper()
+
+				cch.Set("foo", "bar", 10*time.Minute)
+			},
+			assert: func(t *testing.T, srv *miniredis.Miniredis, data any) {
+				t.Helper()
+
+				assert.Equal(t, "bar", data)
+				assert.True(t, srv.Exists("test:foo"))
+			},
+		},
+		{
+			uc:  "can retrieve byte slices",
+			key: "foo",
+			configureCache: func(t *testing.T, _ *miniredis.Miniredis, cch *Cache) {
+				t.Helper()
+
+				cch.Set("foo", []byte("bar"), 10*time.Minute)
+			},
+			assert: func(t *testing.T, _ *miniredis.Miniredis, data any) {
+				t.Helper()
+
+				assert.Equal(t, []byte("bar"), data)
+			},
+		},
+		{
+			uc:  "cannot retrieve expired value",
+			key: "bar",
+			configureCache: func(t *testing.T, srv *miniredis.Miniredis, cch *Cache) {
+				t.Helper()
+
+				cch.Set("bar", "baz", 1*time.Second)
+				srv.FastForward(2 * time.Second)
+			},
+			assert: func(t *testing.T, _ *miniredis.Miniredis, data any) {
+				t.Helper()
+
+				assert.Nil(t, data)
+			},
+		},
+		{
+			uc:  "cannot retrieve deleted value",
+			key: "baz",
+			configureCache: func(t *testing.T, _ *miniredis.Miniredis, cch *Cache) {
+				t.Helper()
+
+				cch.Set("baz", "bar", 1*time.Minute)
+				cch.Delete("baz")
+			},
+			assert: func(t *testing.T, _ *miniredis.Miniredis, data any) {
+				t.Helper()
+
+				assert.Nil(t, data)
+			},
+		},
+		{
+			uc:  "value without ttl is not stored",
+			key: "foo",
+			configureCache: func(t *testing.T, _ *miniredis.Miniredis, cch *Cache) {
+				t.Helper()
+
+				cch.Set("foo", "bar", 0)
+			},
+			assert: func(t *testing.T, srv *miniredis.Miniredis, data any) {
+				t.Helper()
+
+				assert.Nil(t, data)
+				assert.False(t, srv.Exists("test:foo"))
+			},
+		},
+		{
+			uc:  "value of unregistered type is not stored",
+			key: "foo",
+			configureCache: func(t *testing.T, _ *miniredis.Miniredis, cch *Cache) {
+				t.Helper()
+
+				cch.Set("foo", &unregistered{Value: "bar"}, 1*time.Minute)
+			},
+			assert: func(t *testing.T, srv *miniredis.Miniredis, data any) {
+				t.Helper()
+
+				assert.Nil(t, data)
+				assert.False(t, srv.Exists("test:foo"))
+			},
+		},
+		{
+			uc:  "value which cannot be decoded is ignored",
+			key: "foo",
+			configureCache: func(t *testing.T, srv *miniredis.Miniredis, _ *Cache) {
+				t.Helper()
+
+				require.NoError(t, srv.Set("test:foo", "not json"))
+			},
+			assert: func(t *testing.T, _ *miniredis.Miniredis, data any) {
+				t.Helper()
+
+				assert.Nil(t, data)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			srv := miniredis.RunT(t)
+
+			cch, err := NewCache(map[string]any{
+				"address":    srv.Addr(),
+				"key_prefix": "test:",
+				"tls":        map[string]any{"disabled": true},
+			}, log.Logger)
+			require.NoError(t, err)
+
+			defer cch.Stop(context.Background())
+
+			tc.configureCache(t, srv, cch)
+
+			// WHEN
+			data := cch.Get(tc.key)
+
+			// THEN
+			tc.assert(t, srv, data)
+		})
+	}
+}
+
+func TestCacheStatus(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := miniredis.RunT(t)
+
+	cch, err := NewCache(map[string]any{
+		"address": srv.Addr(),
+		"tls":     map[string]any{"disabled": true},
+	}, log.Logger)
+	require.NoError(t, err)
+
+	defer cch.Stop(context.Background())
+
+	// WHEN
+	status := cch.Status(context.Background())
+
+	// THEN
+	assert.Equal(t, "cache", cch.Name())
+	assert.True(t, status.Ready)
+	assert.Empty(t, status.Error)
+	assert.Equal(t, "redis", status.Details["type"])
+	assert.Equal(t, srv.Addr(), status.Details["address"])
+
+	// WHEN
+	srv.Close()
+	status = cch.Status(context.Background())
+
+	// THEN
+	assert.False(t, status.Ready)
+	assert.NotEmpty(t, status.Error)
+}
+
+func TestCacheEval(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := miniredis.RunT(t)
+
+	cch, err := NewCache(map[string]any{
+		"address":    srv.Addr(),
+		"key_prefix": "test:",
+		"tls":        map[string]any{"disabled": true},
+	}, log.Logger)
+	require.NoError(t, err)
+
+	defer cch.Stop(context.Background())
+
+	script := `return redis.call('INCRBY', KEYS[1], ARGV[1])`
+
+	// WHEN
+	first, err := cch.Eval(context.Background(), script, []string{"foo"}, 2)
+	require.NoError(t, err)
+
+	second, err := cch.Eval(context.Background(), script, []string{"foo"}, 3)
+	require.NoError(t, err)
+
+	// THEN
+	assert.Equal(t, int64(2), first)
+	assert.Equal(t, int64(5), second)
+
+	value, err := srv.Get("test:foo")
+	require.NoError(t, err)
+	assert.Equal(t, "5", value)
+}
@@ -36,6 +36,8 @@ const (
 
 	defaultBufferSize = 4 * bytesize.KB
 
+	defaultKeyRotationOverlap = 15 * time.Minute
+
 	loopbackIP = "127.0.0.1"
 )
 
@@ -66,16 +68,18 @@ func defaultConfig() Configuration {
 					Write: defaultBufferSize,
 				},
 			},
-			Management: ServiceConfig{
-				Port: defaultManagementServicePort,
-				Timeout: Timeout{
-					Read:  defaultReadTimeout,
-					Write: defaultWriteTimeout,
-					Idle:  defaultIdleTimeout,
-				},
-				BufferLimit: BufferLimit{
-					Read:  defaultBufferSize,
-					Write: defaultBufferSize,
+			Management: ManagementServiceConfig{
+				ServiceConfig: ServiceConfig{
+					Port: defaultManagementServicePort,
+					Timeout: Timeout{
+						Read:  defaultReadTimeout,
+						Write: defaultWriteTimeout,
+						Idle:  defaultIdleTimeout,
+					},
+					BufferLimit: BufferLimit{
+						Read:  defaultBufferSize,
+						Write: defaultBufferSize,
+					},
 				},
 			},
 		},
@@ -100,6 +104,9 @@ func defaultConfig() Configuration {
 		},
 		Signer: SignerConfig{
 			Name: "heimdall",
+			KeyRotation: KeyRotation{
+				Overlap: defaultKeyRotationOverlap,
+			},
 		},
 		Rules: Rules{
 			Prototypes: &MechanismPrototypes{},
@@ -37,6 +37,11 @@ func merge(dest, src any) any {
 			panic(fmt.Sprintf("Cannot merge %s and %s. Types are different: %s - %s", dest, src, vDst.Type(), vSrc.Type()))
 		}
 
+		// a nil map, like a not set map in the default configuration, cannot be merged into
+		if vDst.IsNil() {
+			return cleanSuffix(src)
+		}
+
 		// nolint: forcetypeassert
 		return mergeMaps(dest.(map[string]any), src.(map[string]any))
 	case reflect.Slice:
@@ -76,6 +76,9 @@ type TLS struct {
 	KeyID        string          `koanf:"key_id"`
 	CipherSuites TLSCipherSuites `koanf:"cipher_suites"`
 	MinVersion   TLSMinVersion   `koanf:"min_version"`
+	// RequestClientCertificate lets the server ask the client for a certificate during the handshake.
+	// The certificate is not verified by the server. That is done by the mtls authenticator.
+	RequestClientCertificate bool `koanf:"request_client_certificate"`
 }
 
 type ServiceConfig struct {
@@ -91,10 +94,22 @@ type ServiceConfig struct {
 
 func (c ServiceConfig) Address() string { return fmt.Sprintf("%s:%d", c.Host, c.Port) }
 
+type TraceConfig struct {
+	// AllowExecution enables the execution of the matched rule pipeline by the trace endpoint.
+	// As this results in calls to the configured upstream services, it is disabled by default.
+	AllowExecution bool `koanf:"allow_execution"`
+}
+
+type ManagementServiceConfig struct {
+	ServiceConfig `koanf:",squash,flatten"`
+
+	Trace TraceConfig `koanf:"trace"`
+}
+
 type ServeConfig struct {
-	Proxy      ServiceConfig `koanf:"proxy"`
-	Decision   ServiceConfig `koanf:"decision"`
-	Management ServiceConfig `koanf:"management"`
+	Proxy      ServiceConfig           `koanf:"proxy"`
+	Decision   ServiceConfig           `koanf:"decision"`
+	Management ManagementServiceConfig `koanf:"management"`
 }
 
 type ResponseOverride struct {
@@ -104,13 +119,14 @@ type ResponseOverride struct {
 type RespondConfig struct {
 	Verbose bool `koanf:"verbose"`
 	With    struct {
-		Accepted            ResponseOverride `koanf:"accepted"`
-		ArgumentError       ResponseOverride `koanf:"argument_error"`
-		AuthenticationError ResponseOverride `koanf:"authentication_error"`
-		AuthorizationError  ResponseOverride `koanf:"authorization_error"`
-		BadMethodError      ResponseOverride `koanf:"method_error"`
-		CommunicationError  ResponseOverride `koanf:"communication_error"`
-		InternalError       ResponseOverride `koanf:"internal_error"`
-		NoRuleError         ResponseOverride `koanf:"no_rule_error"`
+		Accepted             ResponseOverride `koanf:"accepted"`
+		ArgumentError        ResponseOverride `koanf:"argument_error"`
+		AuthenticationError  ResponseOverride `koanf:"authentication_error"`
+		AuthorizationError   ResponseOverride `koanf:"authorization_error"`
+		BadMethodError       ResponseOverride `koanf:"method_error"`
+		CommunicationError   ResponseOverride `koanf:"communication_error"`
+		InternalError        ResponseOverride `koanf:"internal_error"`
+		NoRuleError          ResponseOverride `koanf:"no_rule_error"`
+		TooManyRequestsError ResponseOverride `koanf:"too_many_requests_error"`
 	} `koanf:"with"`
 }
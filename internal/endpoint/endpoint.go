@@ -26,11 +26,13 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/ybbus/httpretry"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/exp/maps"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/httpcache"
@@ -195,10 +197,14 @@ func (e Endpoint) Hash() []byte {
 		hash.Write(giveUpAfterBytes)
 	}
 
+	// map iteration order is random, but the hash must be stable
+	headerNames := maps.Keys(e.Headers)
+	sort.Strings(headerNames)
+
 	buf := bytes.NewBufferString("")
-	for k, v := range e.Headers {
+	for _, k := range headerNames {
 		buf.Write(stringx.ToBytes(k))
-		buf.Write(stringx.ToBytes(v))
+		buf.Write(stringx.ToBytes(e.Headers[k]))
 	}
 
 	hash.Write(buf.Bytes())
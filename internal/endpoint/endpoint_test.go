@@ -19,6 +19,7 @@ package endpoint
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -548,3 +549,24 @@ func TestEndpointHash(t *testing.T) {
 	assert.NotEqual(t, hash2, hash4)
 	assert.NotEqual(t, hash3, hash4)
 }
+
+func TestEndpointHashIsStable(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	headers := make(map[string]string, 20)
+	for i := 0; i < 20; i++ {
+		headers[fmt.Sprintf("X-Header-%d", i)] = fmt.Sprintf("value-%d", i)
+	}
+
+	ep := Endpoint{URL: "foo.bar", Headers: headers}
+	expected := ep.Hash()
+
+	for i := 0; i < 10; i++ {
+		// WHEN
+		hash := ep.Hash()
+
+		// THEN
+		assert.Equal(t, expected, hash)
+	}
+}
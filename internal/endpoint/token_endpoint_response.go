@@ -16,6 +16,15 @@
 
 package endpoint
 
+import "github.com/dadrus/heimdall/internal/cache/codec"
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	codec.Register(&tokenEndpointResponse{})
+}
+
 type tokenEndpointResponse struct {
 	AccessToken string `json:"access_token"`
 	TokenType   string `json:"token_type"`
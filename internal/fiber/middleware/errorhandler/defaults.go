@@ -25,5 +25,6 @@ var defaultOptions = opts{ //nolint:gochecknoglobals
 	onPreconditionError:   func(ctx *fiber.Ctx) { ctx.Status(fiber.StatusBadRequest) },
 	onBadMethodError:      func(ctx *fiber.Ctx) { ctx.Status(fiber.StatusMethodNotAllowed) },
 	onNoRuleError:         func(ctx *fiber.Ctx) { ctx.Status(fiber.StatusNotFound) },
+	onTooManyRequests:     func(ctx *fiber.Ctx) { ctx.Status(fiber.StatusTooManyRequests) },
 	onInternalError:       func(ctx *fiber.Ctx) { ctx.Status(fiber.StatusInternalServerError) },
 }
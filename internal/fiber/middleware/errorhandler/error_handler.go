@@ -18,7 +18,9 @@ package errorhandler
 
 import (
 	"errors"
+	"strconv"
 
+	"github.com/goccy/go-json"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
@@ -50,6 +52,17 @@ func (h *handler) handle(ctx *fiber.Ctx) error { //nolint:cyclop
 
 	accesscontext.SetError(ctx.UserContext(), err)
 
+	// headers, an error handler decided to expose, are set upfront, as redirects end the processing.
+	// Problem details are rendered after the status code has been set
+	var respErr *heimdall.ResponseError
+	if errors.As(err, &respErr) {
+		err = respErr.Err
+
+		for name, value := range respErr.Headers {
+			ctx.Set(name, value)
+		}
+	}
+
 	switch {
 	case errors.Is(err, heimdall.ErrAuthentication):
 		h.onAuthenticationError(ctx)
@@ -63,6 +76,14 @@ func (h *handler) handle(ctx *fiber.Ctx) error { //nolint:cyclop
 		h.onBadMethodError(ctx)
 	case errors.Is(err, heimdall.ErrNoRuleFound):
 		h.onNoRuleError(ctx)
+	case errors.Is(err, heimdall.ErrTooManyRequests):
+		var tmrErr *heimdall.TooManyRequestsError
+
+		if errors.As(err, &tmrErr) {
+			ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(tmrErr.RetryAfterSeconds()))
+		}
+
+		h.onTooManyRequests(ctx)
 	case errors.Is(err, &heimdall.RedirectError{}):
 		var redirectError *heimdall.RedirectError
 
@@ -76,9 +97,22 @@ func (h *handler) handle(ctx *fiber.Ctx) error { //nolint:cyclop
 		h.onInternalError(ctx)
 	}
 
+	if respErr != nil && respErr.ProblemDetails {
+		ctx.Set(fiber.HeaderContentType, "application/problem+json")
+
+		return ctx.Send(problemDetails(respErr, ctx.Response().StatusCode()))
+	}
+
 	if h.verboseErrors {
 		return ctx.Format(err)
 	}
 
 	return nil
 }
+
+func problemDetails(err *heimdall.ResponseError, code int) []byte {
+	// marshalling a map with values of basic types only does not fail
+	body, _ := json.Marshal(err.ProblemDetailsFor(code))
+
+	return body
+}
@@ -21,23 +21,27 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
 func TestHandlerHandle(t *testing.T) {
 	t.Parallel()
 
 	for _, tc := range []struct {
-		uc      string
-		handler fiber.Handler
-		err     error
-		expCode int
-		expBody string
+		uc            string
+		handler       fiber.Handler
+		err           error
+		expCode       int
+		expBody       string
+		expRetryAfter string
+		expHeaders    map[string]string
 	}{
 		{
 			uc:      "no error",
@@ -177,6 +181,35 @@ func TestHandlerHandle(t *testing.T) {
 			expCode: http.StatusNotFound,
 			expBody: "<p>no rule found</p>",
 		},
+		{
+			uc:      "too many requests error default",
+			handler: New(),
+			err:     heimdall.ErrTooManyRequests,
+			expCode: http.StatusTooManyRequests,
+		},
+		{
+			uc:      "too many requests error with retry after",
+			handler: New(),
+			err: errorchain.NewWithMessage(heimdall.ErrTooManyRequests, "rate limit exceeded").
+				CausedBy(&heimdall.TooManyRequestsError{RetryAfter: 1500 * time.Millisecond}),
+			expCode:       http.StatusTooManyRequests,
+			expRetryAfter: "2",
+		},
+		{
+			uc:      "too many requests error overridden",
+			handler: New(WithTooManyRequestsErrorCode(http.StatusServiceUnavailable)),
+			err: errorchain.NewWithMessage(heimdall.ErrTooManyRequests, "rate limit exceeded").
+				CausedBy(&heimdall.TooManyRequestsError{RetryAfter: 10 * time.Second}),
+			expCode:       http.StatusServiceUnavailable,
+			expRetryAfter: "10",
+		},
+		{
+			uc:      "too many requests error verbose",
+			handler: New(WithVerboseErrors(true)),
+			err:     heimdall.ErrTooManyRequests,
+			expCode: http.StatusTooManyRequests,
+			expBody: "<p>too many requests</p>",
+		},
 		{
 			uc:      "redirect error",
 			handler: New(),
@@ -189,6 +222,54 @@ func TestHandlerHandle(t *testing.T) {
 			err:     &heimdall.RedirectError{RedirectTo: "http://foo.local", Code: http.StatusFound},
 			expCode: http.StatusFound,
 		},
+		{
+			uc:      "authorization error with problem details",
+			handler: New(),
+			err: &heimdall.ResponseError{
+				Err: errorchain.NewWithMessage(heimdall.ErrAuthorization, "admin scope required").
+					CausedBy(&heimdall.DenialError{
+						ExpressionID:   "is_admin",
+						Message:        "admin scope required",
+						RequiredScopes: []string{"admin"},
+					}),
+				ProblemDetails: true,
+			},
+			expCode: http.StatusForbidden,
+			expBody: `{"detail":"admin scope required","expression_id":"is_admin",` +
+				`"required_scopes":["admin"],"status":403,"title":"Forbidden","type":"about:blank"}`,
+			expHeaders: map[string]string{"Content-Type": "application/problem+json"},
+		},
+		{
+			uc:         "authentication error with problem details overridden and verbose",
+			handler:    New(WithVerboseErrors(true), WithAuthenticationErrorCode(http.StatusNotFound)),
+			err:        &heimdall.ResponseError{Err: heimdall.ErrAuthentication, ProblemDetails: true},
+			expCode:    http.StatusNotFound,
+			expBody:    `{"status":404,"title":"Not Found","type":"about:blank"}`,
+			expHeaders: map[string]string{"Content-Type": "application/problem+json"},
+		},
+		{
+			uc:      "authorization error with headers",
+			handler: New(),
+			err: &heimdall.ResponseError{
+				Err:     heimdall.ErrAuthorization,
+				Headers: map[string]string{"WWW-Authenticate": `Bearer error="insufficient_scope", scope="admin"`},
+			},
+			expCode:    http.StatusForbidden,
+			expHeaders: map[string]string{"WWW-Authenticate": `Bearer error="insufficient_scope", scope="admin"`},
+		},
+		{
+			uc:      "redirect error with headers",
+			handler: New(),
+			err: &heimdall.ResponseError{
+				Err:     &heimdall.RedirectError{RedirectTo: "http://foo.local", Code: http.StatusFound},
+				Headers: map[string]string{"Set-Cookie": "foo=bar"},
+			},
+			expCode: http.StatusFound,
+			expHeaders: map[string]string{
+				"Location":   "http://foo.local",
+				"Set-Cookie": "foo=bar",
+			},
+		},
 		{
 			uc:      "internal error default",
 			handler: New(),
@@ -230,6 +311,11 @@ func TestHandlerHandle(t *testing.T) {
 
 			assert.Equal(t, tc.expCode, resp.StatusCode)
 			assert.Equal(t, tc.expBody, string(data))
+			assert.Equal(t, tc.expRetryAfter, resp.Header.Get("Retry-After"))
+
+			for name, value := range tc.expHeaders {
+				assert.Equal(t, value, resp.Header.Get(name))
+			}
 		})
 	}
 }
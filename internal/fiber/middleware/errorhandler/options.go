@@ -26,6 +26,7 @@ type opts struct {
 	onPreconditionError   func(ctx *fiber.Ctx)
 	onBadMethodError      func(ctx *fiber.Ctx)
 	onNoRuleError         func(ctx *fiber.Ctx)
+	onTooManyRequests     func(ctx *fiber.Ctx)
 	onInternalError       func(ctx *fiber.Ctx)
 }
 
@@ -87,6 +88,14 @@ func WithNoRuleErrorCode(code int) Option {
 	}
 }
 
+func WithTooManyRequestsErrorCode(code int) Option {
+	return func(o *opts) {
+		if code != 0 {
+			o.onTooManyRequests = func(ctx *fiber.Ctx) { ctx.Status(code) }
+		}
+	}
+}
+
 func WithVerboseErrors(flag bool) Option {
 	return func(o *opts) {
 		o.verboseErrors = flag
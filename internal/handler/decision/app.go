@@ -89,6 +89,7 @@ func newApp(args appArgs) *fiber.App {
 			errormiddleware.WithCommunicationErrorCode(service.Respond.With.CommunicationError.Code),
 			errormiddleware.WithMethodErrorCode(service.Respond.With.BadMethodError.Code),
 			errormiddleware.WithNoRuleErrorCode(service.Respond.With.NoRuleError.Code),
+			errormiddleware.WithTooManyRequestsErrorCode(service.Respond.With.TooManyRequestsError.Code),
 			errormiddleware.WithInternalServerErrorCode(service.Respond.With.InternalError.Code),
 		),
 		cachemiddleware.New(args.Cache),
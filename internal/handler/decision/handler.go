@@ -27,7 +27,6 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
-	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
 type Handler struct {
@@ -77,16 +76,11 @@ func (h *Handler) decisions(c *fiber.Ctx) error {
 		Str("_url", reqURL.String()).
 		Msg("Decision endpoint called")
 
-	rul, err := h.r.FindRule(reqURL)
+	rul, err := h.r.FindRule(method, reqURL)
 	if err != nil {
 		return err
 	}
 
-	if !rul.MatchesMethod(method) {
-		return errorchain.NewWithMessagef(heimdall.ErrMethodNotAllowed,
-			"rule doesn't match %s method", method)
-	}
-
 	reqCtx := requestcontext.New(c, method, reqURL, h.s)
 
 	_, err = rul.Execute(reqCtx)
@@ -55,7 +55,7 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				repository.EXPECT().FindRule(mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
+				repository.EXPECT().FindRule(mock.Anything, mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -78,8 +78,7 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(false)
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(nil, heimdall.ErrMethodNotAllowed)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -102,10 +101,9 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).Return(nil, heimdall.ErrAuthentication)
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -128,14 +126,13 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx heimdall.Context) bool {
 					ctx.SetPipelineError(heimdall.ErrAuthorization)
 
 					return true
 				})).Return(nil, nil)
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -169,7 +166,6 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx heimdall.Context) bool {
 					ctx.AddHeaderForUpstream("X-Foo-Bar", "baz")
 					ctx.AddCookieForUpstream("X-Bar-Foo", "zab")
@@ -177,7 +173,7 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 					return true
 				})).Return(mocks4.NewURIMutatorMock(t), nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPost, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.Scheme == "http" && reqURL.Host == "heimdall.test.local" && reqURL.Path == "/foobar"
 				})).Return(rule, nil)
 			},
@@ -221,7 +217,6 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx heimdall.Context) bool {
 					ctx.AddHeaderForUpstream("X-Foo-Bar", "baz")
 					ctx.AddCookieForUpstream("X-Bar-Foo", "zab")
@@ -229,7 +224,7 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 					return true
 				})).Return(mocks4.NewURIMutatorMock(t), nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPost, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.Scheme == "http" && reqURL.Host == "heimdall.test.local" && reqURL.Path == "/foobar"
 				})).Return(rule, nil)
 			},
@@ -274,7 +269,6 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx heimdall.Context) bool {
 					ctx.AddHeaderForUpstream("X-Foo-Bar", "baz")
 					ctx.AddCookieForUpstream("X-Bar-Foo", "zab")
@@ -282,7 +276,7 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 					return true
 				})).Return(mocks4.NewURIMutatorMock(t), nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPost, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.Scheme == "http" && reqURL.Host == "heimdall.test.local" && reqURL.Path == "/foobar"
 				})).Return(rule, nil)
 			},
@@ -324,11 +318,10 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodGet).Return(true)
 				rule.EXPECT().Execute(mock.Anything).
 					Return(mocks4.NewURIMutatorMock(t), nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodGet, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.Scheme == "http" && reqURL.Host == "heimdall.test.local" && reqURL.Path == "/foobar"
 				})).Return(rule, nil)
 			},
@@ -358,11 +351,10 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).
 					Return(mocks4.NewURIMutatorMock(t), nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPost, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.Scheme == "http" && reqURL.Host == "test.com" && reqURL.Path == "/foobar"
 				})).Return(rule, nil)
 			},
@@ -392,11 +384,10 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).
 					Return(mocks4.NewURIMutatorMock(t), nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPost, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.Scheme == "http" && reqURL.Host == "heimdall.test.local" && reqURL.Path == "bar"
 				})).Return(rule, nil)
 			},
@@ -426,11 +417,10 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).
 					Return(mocks4.NewURIMutatorMock(t), nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPost, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.Scheme == "https" && reqURL.Host == "heimdall.test.local" && reqURL.Path == "/foobar"
 				})).Return(rule, nil)
 			},
@@ -463,11 +453,10 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPatch).Return(true)
 				rule.EXPECT().Execute(mock.Anything).
 					Return(mocks4.NewURIMutatorMock(t), nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPatch, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.Scheme == "https" && reqURL.Host == "test.com" && reqURL.Path == "bar"
 				})).Return(rule, nil)
 			},
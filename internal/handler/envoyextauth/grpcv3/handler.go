@@ -24,7 +24,6 @@ import (
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/rule"
-	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
 type Handler struct {
@@ -43,16 +42,11 @@ func (h *Handler) Check(ctx context.Context, creq *envoy_auth.CheckRequest) (*en
 		Str("_url", req.URL.String()).
 		Msg("Decision Envoy ExtAuth called")
 
-	rul, err := h.r.FindRule(req.URL)
+	rul, err := h.r.FindRule(req.Method, req.URL)
 	if err != nil {
 		return nil, err
 	}
 
-	if !rul.MatchesMethod(req.Method) {
-		return nil, errorchain.NewWithMessagef(heimdall.ErrMethodNotAllowed,
-			"rule doesn't match %s method", req.Method)
-	}
-
 	_, err = rul.Execute(reqCtx)
 	if err != nil {
 		return nil, err
@@ -35,7 +35,7 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks2.RepositoryMock, rule *mocks2.RuleMock) {
 				t.Helper()
 
-				repository.EXPECT().FindRule(mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
+				repository.EXPECT().FindRule(mock.Anything, mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
 			},
 			assertResponse: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
 				t.Helper()
@@ -55,9 +55,7 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks2.RepositoryMock, rule *mocks2.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(false)
-
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(nil, heimdall.ErrMethodNotAllowed)
 			},
 			assertResponse: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
 				t.Helper()
@@ -77,10 +75,9 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks2.RepositoryMock, rule *mocks2.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).Return(nil, heimdall.ErrAuthentication)
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
 				t.Helper()
@@ -100,14 +97,13 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks2.RepositoryMock, rule *mocks2.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx heimdall.Context) bool {
 					ctx.SetPipelineError(heimdall.ErrAuthorization)
 
 					return true
 				})).Return(nil, nil)
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
 				t.Helper()
@@ -127,14 +123,13 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks2.RepositoryMock, rule *mocks2.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).Return(nil, &heimdall.RedirectError{
 					Message:    "test redirect",
 					Code:       http.StatusFound,
 					RedirectTo: "http://foo.bar",
 				})
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
 				t.Helper()
@@ -156,10 +151,9 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks2.RepositoryMock, rule *mocks2.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).Return(nil, nil)
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
 				t.Helper()
@@ -177,7 +171,7 @@ func TestHandleDecisionEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks2.RepositoryMock, rule *mocks2.RuleMock) {
 				t.Helper()
 
-				repository.EXPECT().FindRule(mock.Anything).Panic("wuff")
+				repository.EXPECT().FindRule(mock.Anything, mock.Anything).Panic("wuff")
 			},
 			assertResponse: func(t *testing.T, err error, response *envoy_auth.CheckResponse) {
 				t.Helper()
@@ -29,5 +29,6 @@ var defaultOptions = opts{ //nolint:gochecknoglobals
 	preconditionError:   responseWith(codes.InvalidArgument, http.StatusBadRequest),
 	badMethodError:      responseWith(codes.InvalidArgument, http.StatusMethodNotAllowed),
 	noRuleError:         responseWith(codes.NotFound, http.StatusNotFound),
+	tooManyRequests:     responseWith(codes.ResourceExhausted, http.StatusTooManyRequests),
 	internalError:       responseWith(codes.Internal, http.StatusInternalServerError),
 }
@@ -19,17 +19,21 @@ package errorhandler
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	envoy_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/goccy/go-json"
 	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
 	"google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 
 	"github.com/dadrus/heimdall/internal/accesscontext"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/stringx"
 )
 
 func New(opts ...Option) grpc.UnaryServerInterceptor {
@@ -58,19 +62,35 @@ func (h *interceptor) intercept(
 
 	accesscontext.SetError(ctx, err)
 
+	var respErr *heimdall.ResponseError
+	if !errors.As(err, &respErr) {
+		return h.handleError(ctx, err, acceptType(req))
+	}
+
+	resp, err := h.handleError(ctx, respErr.Err, acceptType(req))
+	if err == nil {
+		withResponseErrorDetails(resp, respErr)
+	}
+
+	return resp, err
+}
+
+func (h *interceptor) handleError(ctx context.Context, err error, mimeType string) (any, error) { //nolint:cyclop
 	switch {
 	case errors.Is(err, heimdall.ErrAuthentication):
-		return h.authenticationError(err, h.verboseErrors, acceptType(req))
+		return h.authenticationError(err, h.verboseErrors, mimeType)
 	case errors.Is(err, heimdall.ErrAuthorization):
-		return h.authorizationError(err, h.verboseErrors, acceptType(req))
+		return h.authorizationError(err, h.verboseErrors, mimeType)
 	case errors.Is(err, heimdall.ErrCommunicationTimeout) || errors.Is(err, heimdall.ErrCommunication):
-		return h.communicationError(err, h.verboseErrors, acceptType(req))
+		return h.communicationError(err, h.verboseErrors, mimeType)
 	case errors.Is(err, heimdall.ErrArgument):
-		return h.preconditionError(err, h.verboseErrors, acceptType(req))
+		return h.preconditionError(err, h.verboseErrors, mimeType)
 	case errors.Is(err, heimdall.ErrMethodNotAllowed):
-		return h.badMethodError(err, h.verboseErrors, acceptType(req))
+		return h.badMethodError(err, h.verboseErrors, mimeType)
 	case errors.Is(err, heimdall.ErrNoRuleFound):
-		return h.noRuleError(err, h.verboseErrors, acceptType(req))
+		return h.noRuleError(err, h.verboseErrors, mimeType)
+	case errors.Is(err, heimdall.ErrTooManyRequests):
+		return h.tooManyRequestsError(err, mimeType)
 	case errors.Is(err, &heimdall.RedirectError{}):
 		var redirectError *heimdall.RedirectError
 
@@ -97,8 +117,57 @@ func (h *interceptor) intercept(
 		logger := zerolog.Ctx(ctx)
 		logger.Error().Err(err).Msg("Internal error occurred")
 
-		return h.internalError(err, h.verboseErrors, acceptType(req))
+		return h.internalError(err, h.verboseErrors, mimeType)
+	}
+}
+
+func (h *interceptor) tooManyRequestsError(err error, mimeType string) (any, error) {
+	resp, respErr := h.tooManyRequests(err, h.verboseErrors, mimeType)
+
+	var tmrErr *heimdall.TooManyRequestsError
+
+	checkResp, ok := resp.(*envoy_auth.CheckResponse)
+	if !ok || !errors.As(err, &tmrErr) {
+		return resp, respErr
 	}
+
+	deniedResponse := checkResp.GetDeniedResponse()
+	deniedResponse.Headers = append(deniedResponse.Headers, &envoy_core.HeaderValueOption{
+		Header: &envoy_core.HeaderValue{Key: "Retry-After", Value: strconv.Itoa(tmrErr.RetryAfterSeconds())},
+	})
+
+	return resp, respErr
+}
+
+func withResponseErrorDetails(resp any, err *heimdall.ResponseError) {
+	checkResp, ok := resp.(*envoy_auth.CheckResponse)
+	if !ok {
+		return
+	}
+
+	deniedResponse := checkResp.GetDeniedResponse()
+
+	for name, value := range err.Headers {
+		deniedResponse.Headers = append(deniedResponse.Headers, &envoy_core.HeaderValueOption{
+			Header: &envoy_core.HeaderValue{Key: name, Value: value},
+		})
+	}
+
+	if !err.ProblemDetails {
+		return
+	}
+
+	// marshalling a map with values of basic types only does not fail
+	body, _ := json.Marshal(err.ProblemDetailsFor(int(deniedResponse.GetStatus().GetCode())))
+
+	// problem details replace the body and the content type of a verbose error response
+	deniedResponse.Headers = slices.DeleteFunc(deniedResponse.Headers, func(hvo *envoy_core.HeaderValueOption) bool {
+		return hvo.GetHeader().GetKey() == "Content-Type"
+	})
+	deniedResponse.Headers = append(deniedResponse.Headers, &envoy_core.HeaderValueOption{
+		Header: &envoy_core.HeaderValue{Key: "Content-Type", Value: "application/problem+json"},
+	})
+	deniedResponse.Body = stringx.ToString(body)
 }
 
 func acceptType(req any) string {
@@ -21,6 +21,7 @@ import (
 	"net"
 	"net/http"
 	"testing"
+	"time"
 
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
@@ -35,18 +36,21 @@ import (
 
 	"github.com/dadrus/heimdall/internal/handler/envoyextauth/grpcv3/middleware/mocks"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
 func TestErrorInterceptor(t *testing.T) {
 	t.Parallel()
 
 	for _, tc := range []struct {
-		uc          string
-		interceptor grpc.UnaryServerInterceptor
-		err         error
-		expGRPCCode codes.Code
-		expHTTPCode envoy_type.StatusCode
-		expBody     string
+		uc            string
+		interceptor   grpc.UnaryServerInterceptor
+		err           error
+		expGRPCCode   codes.Code
+		expHTTPCode   envoy_type.StatusCode
+		expBody       string
+		expRetryAfter string
+		expHeaders    map[string]string
 	}{
 		{
 			uc:          "no error",
@@ -208,6 +212,39 @@ func TestErrorInterceptor(t *testing.T) {
 			expHTTPCode: http.StatusNotFound,
 			expBody:     "<p>no rule found</p>",
 		},
+		{
+			uc:          "too many requests error default",
+			interceptor: New(),
+			err:         heimdall.ErrTooManyRequests,
+			expGRPCCode: codes.ResourceExhausted,
+			expHTTPCode: http.StatusTooManyRequests,
+		},
+		{
+			uc:          "too many requests error with retry after",
+			interceptor: New(),
+			err: errorchain.NewWithMessage(heimdall.ErrTooManyRequests, "rate limit exceeded").
+				CausedBy(&heimdall.TooManyRequestsError{RetryAfter: 30 * time.Second}),
+			expGRPCCode:   codes.ResourceExhausted,
+			expHTTPCode:   http.StatusTooManyRequests,
+			expRetryAfter: "30",
+		},
+		{
+			uc:          "too many requests error overridden",
+			interceptor: New(WithTooManyRequestsErrorCode(http.StatusServiceUnavailable)),
+			err: errorchain.NewWithMessage(heimdall.ErrTooManyRequests, "rate limit exceeded").
+				CausedBy(&heimdall.TooManyRequestsError{RetryAfter: 100 * time.Millisecond}),
+			expGRPCCode:   codes.ResourceExhausted,
+			expHTTPCode:   http.StatusServiceUnavailable,
+			expRetryAfter: "1",
+		},
+		{
+			uc:          "too many requests error verbose",
+			interceptor: New(WithVerboseErrors(true)),
+			err:         heimdall.ErrTooManyRequests,
+			expGRPCCode: codes.ResourceExhausted,
+			expHTTPCode: http.StatusTooManyRequests,
+			expBody:     "<p>too many requests</p>",
+		},
 		{
 			uc:          "redirect error",
 			interceptor: New(),
@@ -222,6 +259,48 @@ func TestErrorInterceptor(t *testing.T) {
 			expGRPCCode: codes.FailedPrecondition,
 			expHTTPCode: http.StatusFound,
 		},
+		{
+			uc:          "authorization error with problem details",
+			interceptor: New(),
+			err: &heimdall.ResponseError{
+				Err: errorchain.NewWithMessage(heimdall.ErrAuthorization, "admin scope required").
+					CausedBy(&heimdall.DenialError{
+						ExpressionID:   "is_admin",
+						Message:        "admin scope required",
+						RequiredScopes: []string{"admin"},
+					}),
+				ProblemDetails: true,
+			},
+			expGRPCCode: codes.PermissionDenied,
+			expHTTPCode: http.StatusForbidden,
+			expBody: `{"detail":"admin scope required","expression_id":"is_admin",` +
+				`"required_scopes":["admin"],"status":403,"title":"Forbidden","type":"about:blank"}`,
+			expHeaders: map[string]string{"Content-Type": "application/problem+json"},
+		},
+		{
+			uc:          "authentication error with problem details overridden and verbose",
+			interceptor: New(WithVerboseErrors(true), WithAuthenticationErrorCode(http.StatusNotFound)),
+			err:         &heimdall.ResponseError{Err: heimdall.ErrAuthentication, ProblemDetails: true},
+			expGRPCCode: codes.Unauthenticated,
+			expHTTPCode: http.StatusNotFound,
+			expBody:     `{"status":404,"title":"Not Found","type":"about:blank"}`,
+			expHeaders:  map[string]string{"Content-Type": "application/problem+json"},
+		},
+		{
+			uc:          "authorization error with headers and verbose",
+			interceptor: New(WithVerboseErrors(true)),
+			err: &heimdall.ResponseError{
+				Err:     heimdall.ErrAuthorization,
+				Headers: map[string]string{"WWW-Authenticate": `Bearer error="insufficient_scope", scope="admin"`},
+			},
+			expGRPCCode: codes.PermissionDenied,
+			expHTTPCode: http.StatusForbidden,
+			expBody:     "<p>authorization error</p>",
+			expHeaders: map[string]string{
+				"Content-Type":     "text/html",
+				"WWW-Authenticate": `Bearer error="insufficient_scope", scope="admin"`,
+			},
+		},
 		{
 			uc:          "internal error default",
 			interceptor: New(),
@@ -302,6 +381,20 @@ func TestErrorInterceptor(t *testing.T) {
 				require.NotNil(t, deniedResp)
 				assert.Equal(t, tc.expHTTPCode, deniedResp.Status.Code)
 				assert.Equal(t, tc.expBody, deniedResp.Body)
+
+				headers := make(map[string]string, len(deniedResp.Headers))
+
+				for _, hdr := range deniedResp.Headers {
+					assert.NotContains(t, headers, hdr.Header.Key)
+
+					headers[hdr.Header.Key] = hdr.Header.Value
+				}
+
+				assert.Equal(t, tc.expRetryAfter, headers["Retry-After"])
+
+				for name, value := range tc.expHeaders {
+					assert.Equal(t, value, headers[name])
+				}
 			}
 		})
 	}
@@ -26,6 +26,7 @@ type opts struct {
 	preconditionError   func(err error, verbose bool, mimeType string) (any, error)
 	badMethodError      func(err error, verbose bool, mimeType string) (any, error)
 	noRuleError         func(err error, verbose bool, mimeType string) (any, error)
+	tooManyRequests     func(err error, verbose bool, mimeType string) (any, error)
 	internalError       func(err error, verbose bool, mimeType string) (any, error)
 }
 
@@ -87,6 +88,14 @@ func WithNoRuleErrorCode(code int) Option {
 	}
 }
 
+func WithTooManyRequestsErrorCode(code int) Option {
+	return func(o *opts) {
+		if code > 0 {
+			o.tooManyRequests = responseWith(codes.ResourceExhausted, code)
+		}
+	}
+}
+
 func WithVerboseErrors(flag bool) Option {
 	return func(o *opts) {
 		o.verboseErrors = flag
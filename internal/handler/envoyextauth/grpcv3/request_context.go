@@ -18,6 +18,8 @@ package grpcv3
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -36,6 +38,7 @@ import (
 type RequestContext struct {
 	ctx             context.Context // nolint: containedctx
 	ips             []string
+	clientCerts     []*x509.Certificate
 	reqMethod       string
 	reqHeaders      map[string]string
 	reqURL          *url.URL
@@ -58,10 +61,11 @@ func NewRequestContext(ctx context.Context, req *envoy_auth.CheckRequest, signer
 	}
 
 	return &RequestContext{
-		ctx:        ctx,
-		ips:        clientIPs,
-		reqMethod:  req.Attributes.Request.Http.Method,
-		reqHeaders: canonicalizeHeaders(req.Attributes.Request.Http.Headers),
+		ctx:         ctx,
+		ips:         clientIPs,
+		clientCerts: peerCertificates(req.Attributes.Source),
+		reqMethod:   req.Attributes.Request.Http.Method,
+		reqHeaders:  canonicalizeHeaders(req.Attributes.Request.Http.Headers),
 		reqURL: &url.URL{
 			Scheme:   req.Attributes.Request.Http.Scheme,
 			Host:     req.Attributes.Request.Http.Host,
@@ -77,6 +81,40 @@ func NewRequestContext(ctx context.Context, req *envoy_auth.CheckRequest, signer
 	}
 }
 
+// peerCertificates returns the certificate of the downstream peer, which envoy forwards
+// in url encoded PEM format if include_peer_certificate is enabled.
+func peerCertificates(peer *envoy_auth.AttributeContext_Peer) []*x509.Certificate {
+	if peer == nil || len(peer.Certificate) == 0 {
+		return nil
+	}
+
+	value, err := url.PathUnescape(peer.Certificate)
+	if err != nil {
+		return nil
+	}
+
+	var certs []*x509.Certificate
+
+	rest := []byte(value)
+
+	for {
+		var block *pem.Block
+
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil
+		}
+
+		certs = append(certs, cert)
+	}
+
+	return certs
+}
+
 func canonicalizeHeaders(headers map[string]string) map[string]string {
 	result := make(map[string]string, len(headers))
 
@@ -89,10 +127,11 @@ func canonicalizeHeaders(headers map[string]string) map[string]string {
 
 func (s *RequestContext) Request() *heimdall.Request {
 	return &heimdall.Request{
-		RequestFunctions: s,
-		Method:           s.reqMethod,
-		URL:              s.reqURL,
-		ClientIP:         s.ips,
+		RequestFunctions:   s,
+		Method:             s.reqMethod,
+		URL:                s.reqURL,
+		ClientIP:           s.ips,
+		ClientCertificates: s.clientCerts,
 	}
 }
 
@@ -80,6 +80,7 @@ func newService(
 			errormiddleware.WithCommunicationErrorCode(service.Respond.With.CommunicationError.Code),
 			errormiddleware.WithMethodErrorCode(service.Respond.With.BadMethodError.Code),
 			errormiddleware.WithNoRuleErrorCode(service.Respond.With.NoRuleError.Code),
+			errormiddleware.WithTooManyRequestsErrorCode(service.Respond.With.TooManyRequestsError.Code),
 			errormiddleware.WithInternalServerErrorCode(service.Respond.With.InternalError.Code),
 		),
 		// the accesslogger is used here to have access to the error object
@@ -79,6 +79,10 @@ func newTLSListener(tlsConf *config.TLS, listener net.Listener) (net.Listener, e
 		GetCertificate: tlsHandler.GetClientInfo,
 	}
 
+	if tlsConf.RequestClientCertificate {
+		cfg.ClientAuth = tls.RequestClientCert
+	}
+
 	if cfg.MinVersion != tls.VersionTLS13 {
 		cfg.CipherSuites = tlsConf.CipherSuites.OrDefault()
 	}
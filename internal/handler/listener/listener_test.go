@@ -212,6 +212,60 @@ func TestNewListener(t *testing.T) {
 				assert.Contains(t, ln.Addr().String(), port)
 			},
 		},
+		{
+			uc:      "successful with client certificate requested",
+			network: "tcp",
+			serviceConf: config.ServiceConfig{
+				TLS: &config.TLS{
+					KeyStore:                 config.KeyStore{Path: pemFile.Name()},
+					KeyID:                    "key1",
+					RequestClientCertificate: true,
+				},
+			},
+			assert: func(t *testing.T, err error, ln net.Listener, port string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, ln)
+
+				peerCerts := make(chan []*x509.Certificate, 1)
+
+				go func() {
+					conn, err := ln.Accept()
+					if err != nil {
+						peerCerts <- nil
+
+						return
+					}
+
+					defer conn.Close()
+
+					tlsConn := conn.(*tls.Conn) // nolint: forcetypeassert
+					if err = tlsConn.Handshake(); err != nil {
+						peerCerts <- nil
+
+						return
+					}
+
+					peerCerts <- tlsConn.ConnectionState().PeerCertificates
+				}()
+
+				// nolint: gosec
+				conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+					InsecureSkipVerify: true,
+					Certificates: []tls.Certificate{
+						{Certificate: [][]byte{cert.Raw}, PrivateKey: privKey1, Leaf: cert},
+					},
+				})
+				require.NoError(t, err)
+
+				defer conn.Close()
+
+				received := <-peerCerts
+				require.Len(t, received, 1)
+				assert.Equal(t, cert.Raw, received[0].Raw)
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
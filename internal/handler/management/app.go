@@ -46,7 +46,9 @@ type appArgs struct {
 func newApp(args appArgs) *fiber.App {
 	service := args.Config.Serve.Management
 
-	filterHealthEndpoint := func(ctx *fiber.Ctx) bool { return ctx.Path() == EndpointHealth }
+	filterHealthEndpoint := func(ctx *fiber.Ctx) bool {
+		return ctx.Path() == EndpointHealth || ctx.Path() == EndpointReadiness
+	}
 
 	app := fiber.New(fiber.Config{
 		AppName:                 "Heimdall Management Service",
@@ -17,6 +17,10 @@
 package management
 
 const (
-	EndpointHealth = "/.well-known/health"
-	EndpointJWKS   = "/.well-known/jwks"
+	EndpointHealth      = "/.well-known/health"
+	EndpointJWKS        = "/.well-known/jwks"
+	EndpointReadiness   = "/.well-known/ready"
+	EndpointRuleSets    = "/rulesets"
+	EndpointDefaultRule = "/rules/default"
+	EndpointTrace       = "/rules/trace"
 )
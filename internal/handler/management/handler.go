@@ -22,7 +22,10 @@ import (
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 
+	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
+	"github.com/dadrus/heimdall/internal/rules/rule"
 )
 
 type Handler struct{}
@@ -30,22 +33,39 @@ type Handler struct{}
 type handlerArgs struct {
 	fx.In
 
-	App    *fiber.App `name:"management"`
-	Signer heimdall.JWTSigner
-	Logger zerolog.Logger
+	App        *fiber.App `name:"management"`
+	Config     *config.Configuration
+	Signer     heimdall.JWTSigner
+	Inspector  rule.Inspector
+	Repository rule.Repository
+	Registry   readiness.Registry
+	Logger     zerolog.Logger
 }
 
 func newHandler(args handlerArgs) (*Handler, error) {
 	handler := &Handler{}
 
-	handler.registerRoutes(args.App.Group("/"), args.Logger, args.Signer)
+	handler.registerRoutes(args.App.Group("/"), args.Logger, args.Config.Serve.Management.Trace, args.Signer,
+		args.Inspector, args.Repository, args.Registry)
 
 	return handler, nil
 }
 
-func (h *Handler) registerRoutes(router fiber.Router, logger zerolog.Logger, signer heimdall.JWTSigner) {
+func (h *Handler) registerRoutes(
+	router fiber.Router,
+	logger zerolog.Logger,
+	traceConf config.TraceConfig,
+	signer heimdall.JWTSigner,
+	inspector rule.Inspector,
+	repository rule.Repository,
+	registry readiness.Registry,
+) {
 	logger.Debug().Msg("Registering Management service routes")
 
 	router.Get(EndpointHealth, health)
+	router.Get(EndpointReadiness, ready(registry))
 	router.Get(EndpointJWKS, etag.New(), jwks(signer))
+	router.Get(EndpointRuleSets, ruleSets(inspector))
+	router.Get(EndpointDefaultRule, defaultRule(inspector))
+	router.Post(EndpointTrace, trace(traceConf, repository, inspector, signer))
 }
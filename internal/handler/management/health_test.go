@@ -34,13 +34,16 @@ func TestHealthRequest(t *testing.T) {
 	t.Parallel()
 
 	// GIVEN
+	conf := &config.Configuration{Serve: config.ServeConfig{Management: config.ManagementServiceConfig{}}}
+
 	app := newApp(appArgs{
-		Config:     &config.Configuration{Serve: config.ServeConfig{Management: config.ServiceConfig{}}},
+		Config:     conf,
 		Registerer: prometheus.NewRegistry(),
 		Logger:     log.Logger,
 	})
 
 	_, err := newHandler(handlerArgs{
+		Config: conf,
 		App:    app,
 		Logger: log.Logger,
 	})
@@ -107,8 +107,10 @@ func (suite *JWKSTestSuite) SetupSuite() {
 	suite.ks, err = keystore.NewKeyStoreFromPEMBytes(pemBytes, "")
 	suite.NoError(err)
 
+	conf := &config.Configuration{Serve: config.ServeConfig{Management: config.ManagementServiceConfig{}}}
+
 	suite.app = newApp(appArgs{
-		Config:     &config.Configuration{Serve: config.ServeConfig{Management: config.ServiceConfig{}}},
+		Config:     conf,
 		Registerer: prometheus.NewRegistry(),
 		Logger:     log.Logger,
 	})
@@ -123,6 +125,7 @@ func (suite *JWKSTestSuite) SetupSuite() {
 	signer.EXPECT().Keys().Return(keys)
 
 	_, err = newHandler(handlerArgs{
+		Config: conf,
 		App:    suite.app,
 		Logger: log.Logger,
 		Signer: signer,
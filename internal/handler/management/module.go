@@ -45,7 +45,7 @@ type hooksArgs struct {
 }
 
 func registerHooks(args hooksArgs) {
-	ln, err := listener.New(args.App.Config().Network, args.Config.Serve.Management)
+	ln, err := listener.New(args.App.Config().Network, args.Config.Serve.Management.ServiceConfig)
 	if err != nil {
 		args.Logger.Fatal().Err(err).Msg("Could not create listener for the Management service")
 
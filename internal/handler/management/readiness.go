@@ -0,0 +1,40 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/dadrus/heimdall/internal/readiness"
+)
+
+func ready(registry readiness.Registry) fiber.Handler {
+	type status struct {
+		Status     string                      `json:"status"`
+		Components map[string]readiness.Status `json:"components"`
+	}
+
+	return func(c *fiber.Ctx) error {
+		isReady, components := registry.Check(c.UserContext())
+		if !isReady {
+			return c.Status(fiber.StatusServiceUnavailable).
+				JSON(status{Status: "not ready", Components: components})
+		}
+
+		return c.JSON(status{Status: "ready", Components: components})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/readiness"
+)
+
+type testChecker struct {
+	name   string
+	status readiness.Status
+}
+
+func (c testChecker) Name() string { return c.name }
+
+func (c testChecker) Status(_ context.Context) readiness.Status { return c.status }
+
+func TestReadinessRequest(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc               string
+		checkers         []readiness.Checker
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			uc:               "no components registered",
+			expectedCode:     http.StatusOK,
+			expectedResponse: `{"status": "ready", "components": {}}`,
+		},
+		{
+			uc: "all components ready",
+			checkers: []readiness.Checker{
+				testChecker{name: "foo", status: readiness.Status{Ready: true}},
+				testChecker{name: "bar", status: readiness.Status{Ready: true, Details: map[string]any{"rules": 1}}},
+			},
+			expectedCode: http.StatusOK,
+			expectedResponse: `{
+				"status": "ready",
+				"components": {
+					"foo": {"ready": true},
+					"bar": {"ready": true, "details": {"rules": 1}}
+				}
+			}`,
+		},
+		{
+			uc: "one component not ready",
+			checkers: []readiness.Checker{
+				testChecker{name: "foo", status: readiness.Status{Ready: true}},
+				testChecker{name: "bar", status: readiness.Status{Ready: false, Error: "test error"}},
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedResponse: `{
+				"status": "not ready",
+				"components": {
+					"foo": {"ready": true},
+					"bar": {"ready": false, "error": "test error"}
+				}
+			}`,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			registry := readiness.NewRegistry()
+			for _, checker := range tc.checkers {
+				registry.Register(checker)
+			}
+
+			conf := &config.Configuration{Serve: config.ServeConfig{Management: config.ManagementServiceConfig{}}}
+
+			app := newApp(appArgs{
+				Config:     conf,
+				Registerer: prometheus.NewRegistry(),
+				Logger:     log.Logger,
+			})
+
+			_, err := newHandler(handlerArgs{
+				Config:   conf,
+				App:      app,
+				Registry: registry,
+				Logger:   log.Logger,
+			})
+			require.NoError(t, err)
+
+			// WHEN
+			resp, err := app.Test(
+				httptest.NewRequest(http.MethodGet, "http://heimdall.test.local"+EndpointReadiness, nil),
+				-1)
+
+			// THEN
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			defer resp.Body.Close()
+
+			rawResp, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tc.expectedResponse, string(rawResp))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+func ruleSets(inspector rule.Inspector) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(inspector.RuleSets())
+	}
+}
+
+func defaultRule(inspector rule.Inspector) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		info, ok := inspector.DefaultRule()
+		if !ok {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+
+		return c.JSON(info)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+)
+
+func TestRuleInspectionRequests(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc               string
+		endpoint         string
+		configureMock    func(t *testing.T, inspector *mocks.InspectorMock)
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			uc:       "no rule sets loaded",
+			endpoint: EndpointRuleSets,
+			configureMock: func(t *testing.T, inspector *mocks.InspectorMock) {
+				t.Helper()
+
+				inspector.EXPECT().RuleSets().Return([]rule.SetInfo{})
+			},
+			expectedCode:     http.StatusOK,
+			expectedResponse: `[]`,
+		},
+		{
+			uc:       "rule sets loaded",
+			endpoint: EndpointRuleSets,
+			configureMock: func(t *testing.T, inspector *mocks.InspectorMock) {
+				t.Helper()
+
+				inspector.EXPECT().RuleSets().Return([]rule.SetInfo{
+					{
+						Source: "file_system:/etc/heimdall/rules.yaml",
+						Name:   "test",
+						Rules: []rule.Info{
+							{
+								ID:      "rule:foo",
+								SrcID:   "file_system:/etc/heimdall/rules.yaml",
+								Match:   rule.MatchInfo{URL: "http://foo.bar/<**>", Strategy: "glob"},
+								Methods: []string{http.MethodGet},
+								Execute: []rule.MechanismInfo{
+									{Type: "authenticator", ID: "anon"},
+									{Type: "unifier", ID: "noop"},
+								},
+								OnError: []rule.MechanismInfo{},
+								Hash:    "0102",
+							},
+						},
+					},
+				})
+			},
+			expectedCode: http.StatusOK,
+			expectedResponse: `[{
+				"src": "file_system:/etc/heimdall/rules.yaml",
+				"name": "test",
+				"rules": [{
+					"id": "rule:foo",
+					"src": "file_system:/etc/heimdall/rules.yaml",
+					"match": { "url": "http://foo.bar/<**>", "strategy": "glob" },
+					"methods": ["GET"],
+					"priority": 0,
+					"execute": [
+						{ "type": "authenticator", "id": "anon" },
+						{ "type": "unifier", "id": "noop" }
+					],
+					"on_error": [],
+					"hash": "0102"
+				}]
+			}]`,
+		},
+		{
+			uc:       "no default rule configured",
+			endpoint: EndpointDefaultRule,
+			configureMock: func(t *testing.T, inspector *mocks.InspectorMock) {
+				t.Helper()
+
+				inspector.EXPECT().DefaultRule().Return(rule.Info{}, false)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			uc:       "default rule configured",
+			endpoint: EndpointDefaultRule,
+			configureMock: func(t *testing.T, inspector *mocks.InspectorMock) {
+				t.Helper()
+
+				inspector.EXPECT().DefaultRule().Return(rule.Info{
+					ID:      "default",
+					SrcID:   "config",
+					Methods: []string{http.MethodGet},
+					Execute: []rule.MechanismInfo{{Type: "authenticator", ID: "anon"}},
+				}, true)
+			},
+			expectedCode: http.StatusOK,
+			expectedResponse: `{
+				"id": "default",
+				"src": "config",
+				"match": {},
+				"methods": ["GET"],
+				"priority": 0,
+				"execute": [{ "type": "authenticator", "id": "anon" }],
+				"on_error": null
+			}`,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			inspector := mocks.NewInspectorMock(t)
+			tc.configureMock(t, inspector)
+
+			conf := &config.Configuration{Serve: config.ServeConfig{Management: config.ManagementServiceConfig{}}}
+
+			app := newApp(appArgs{
+				Config:     conf,
+				Registerer: prometheus.NewRegistry(),
+				Logger:     log.Logger,
+			})
+
+			_, err := newHandler(handlerArgs{
+				Config:    conf,
+				App:       app,
+				Inspector: inspector,
+				Logger:    log.Logger,
+			})
+			require.NoError(t, err)
+
+			// WHEN
+			resp, err := app.Test(
+				httptest.NewRequest(http.MethodGet, "http://heimdall.test.local"+tc.endpoint, nil),
+				-1)
+
+			// THEN
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			defer resp.Body.Close()
+
+			if len(tc.expectedResponse) != 0 {
+				rawResp, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+
+				assert.JSONEq(t, tc.expectedResponse, string(rawResp))
+			}
+		})
+	}
+}
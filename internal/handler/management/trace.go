@@ -0,0 +1,207 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/exp/slices"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/pipelinetrace"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/x"
+)
+
+// TraceRequest describes the request, a rule should be looked up, and optionally executed for.
+type TraceRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
+	Execute bool              `json:"execute"`
+}
+
+type TraceResponse struct {
+	Rule   *rule.Info           `json:"rule,omitempty"`
+	Error  string               `json:"error,omitempty"`
+	Result *TraceResult         `json:"result,omitempty"`
+	Trace  []pipelinetrace.Step `json:"trace,omitempty"`
+}
+
+type TraceResult struct {
+	Allowed     bool              `json:"allowed"`
+	Error       string            `json:"error,omitempty"`
+	UpstreamURL string            `json:"upstream_url,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Cookies     map[string]string `json:"cookies,omitempty"`
+}
+
+const redactedValue = "[REDACTED]"
+
+// credentialHeaders lists the headers, which values are not revealed in a trace result.
+var credentialHeaders = []string{ //nolint:gochecknoglobals
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// trace implements a dry-run endpoint, which resolves the rule responsible for the
+// given request and, if requested and allowed by the configuration, executes its
+// pipeline against a synthetic context.
+func trace(
+	conf config.TraceConfig,
+	repository rule.Repository,
+	inspector rule.Inspector,
+	signer heimdall.JWTSigner,
+) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req TraceRequest
+
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(TraceResponse{Error: err.Error()})
+		}
+
+		reqURL, err := url.Parse(req.URL)
+		if err != nil || len(req.Method) == 0 || !reqURL.IsAbs() {
+			return c.Status(fiber.StatusBadRequest).
+				JSON(TraceResponse{Error: "method and an absolute url are required"})
+		}
+
+		if req.Execute && !conf.AllowExecution {
+			return c.Status(fiber.StatusForbidden).
+				JSON(TraceResponse{Error: "pipeline execution is not enabled"})
+		}
+
+		rul, err := repository.FindRule(req.Method, reqURL)
+		if err != nil {
+			code := fiber.StatusNotFound
+			if errors.Is(err, heimdall.ErrMethodNotAllowed) {
+				code = fiber.StatusMethodNotAllowed
+			}
+
+			return c.Status(code).JSON(TraceResponse{Error: err.Error()})
+		}
+
+		info := inspector.Describe(rul)
+		resp := TraceResponse{Rule: &info}
+
+		if !req.Execute {
+			return c.JSON(resp)
+		}
+
+		ctx := newTraceContext(pipelinetrace.New(c.UserContext()), &req, reqURL, signer)
+
+		resp.Result = ctx.execute(rul)
+		resp.Trace = pipelinetrace.Steps(ctx.AppContext())
+
+		return c.JSON(resp)
+	}
+}
+
+type traceContext struct {
+	ctx             context.Context //nolint:containedctx
+	req             *TraceRequest
+	reqURL          *url.URL
+	headers         map[string]string
+	upstreamHeaders http.Header
+	upstreamCookies map[string]string
+	signer          heimdall.JWTSigner
+	err             error
+}
+
+func newTraceContext(
+	ctx context.Context,
+	req *TraceRequest,
+	reqURL *url.URL,
+	signer heimdall.JWTSigner,
+) *traceContext {
+	headers := make(map[string]string, len(req.Headers))
+	for k, v := range req.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+
+	return &traceContext{
+		ctx:             ctx,
+		req:             req,
+		reqURL:          reqURL,
+		headers:         headers,
+		upstreamHeaders: make(http.Header),
+		upstreamCookies: make(map[string]string),
+		signer:          signer,
+	}
+}
+
+func (t *traceContext) Request() *heimdall.Request {
+	return &heimdall.Request{
+		RequestFunctions: t,
+		Method:           t.req.Method,
+		URL:              t.reqURL,
+		ClientIP:         []string{},
+	}
+}
+
+func (t *traceContext) Headers() map[string]string              { return t.headers }
+func (t *traceContext) Header(name string) string               { return t.headers[http.CanonicalHeaderKey(name)] }
+func (t *traceContext) Cookie(name string) string               { return t.req.Cookies[name] }
+func (t *traceContext) Body() []byte                            { return nil }
+func (t *traceContext) AppContext() context.Context             { return t.ctx }
+func (t *traceContext) SetPipelineError(err error)              { t.err = err }
+func (t *traceContext) AddHeaderForUpstream(name, value string) { t.upstreamHeaders.Add(name, value) }
+func (t *traceContext) AddCookieForUpstream(name, value string) { t.upstreamCookies[name] = value }
+func (t *traceContext) Signer() heimdall.JWTSigner              { return t.signer }
+
+func (t *traceContext) execute(rul rule.Rule) *TraceResult {
+	mutator, err := rul.Execute(t)
+	if err == nil {
+		err = t.err
+	}
+
+	if err != nil {
+		return &TraceResult{Error: err.Error()}
+	}
+
+	result := &TraceResult{
+		Allowed: true,
+		Headers: make(map[string]string, len(t.upstreamHeaders)),
+		Cookies: make(map[string]string, len(t.upstreamCookies)),
+	}
+
+	for k := range t.upstreamHeaders {
+		result.Headers[k] = x.IfThenElse(slices.Contains(credentialHeaders, k), redactedValue, t.upstreamHeaders.Get(k))
+	}
+
+	// cookie values are usually session identifiers or tokens
+	for k := range t.upstreamCookies {
+		result.Cookies[k] = redactedValue
+	}
+
+	// rules used in decision mode might not have an upstream defined
+	if upstreamURL, err := mutator.Mutate(t.reqURL); err == nil {
+		result.UpstreamURL = upstreamURL.String()
+	}
+
+	return result
+}
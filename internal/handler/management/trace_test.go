@@ -0,0 +1,253 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/pipelinetrace"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+type testMechanism string
+
+func (m testMechanism) HandlerID() string { return string(m) }
+
+func TestTraceRequest(t *testing.T) {
+	t.Parallel()
+
+	ruleInfo := rule.Info{ID: "rule:foo", SrcID: "test", Methods: []string{http.MethodGet}}
+
+	for _, tc := range []struct {
+		uc               string
+		allowExecution   bool
+		request          string
+		configureMocks   func(t *testing.T, repo *mocks.RepositoryMock, inspector *mocks.InspectorMock)
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			uc:               "malformed request",
+			request:          `{"method": `,
+			configureMocks:   func(t *testing.T, _ *mocks.RepositoryMock, _ *mocks.InspectorMock) { t.Helper() },
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: "",
+		},
+		{
+			uc:               "relative url",
+			request:          `{"method": "GET", "url": "/foo"}`,
+			configureMocks:   func(t *testing.T, _ *mocks.RepositoryMock, _ *mocks.InspectorMock) { t.Helper() },
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"error": "method and an absolute url are required"}`,
+		},
+		{
+			uc:      "no rule found",
+			request: `{"method": "GET", "url": "http://foo.bar/baz"}`,
+			configureMocks: func(t *testing.T, repo *mocks.RepositoryMock, _ *mocks.InspectorMock) {
+				t.Helper()
+
+				repo.EXPECT().FindRule(http.MethodGet, mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
+			},
+			expectedCode:     http.StatusNotFound,
+			expectedResponse: `{"error": "no rule found"}`,
+		},
+		{
+			uc:      "method not allowed",
+			request: `{"method": "POST", "url": "http://foo.bar/baz"}`,
+			configureMocks: func(t *testing.T, repo *mocks.RepositoryMock, _ *mocks.InspectorMock) {
+				t.Helper()
+
+				repo.EXPECT().FindRule(http.MethodPost, mock.Anything).
+					Return(nil, errorchain.New(heimdall.ErrMethodNotAllowed))
+			},
+			expectedCode:     http.StatusMethodNotAllowed,
+			expectedResponse: `{"error": "method not allowed"}`,
+		},
+		{
+			uc:      "rule found without pipeline execution",
+			request: `{"method": "GET", "url": "http://foo.bar/baz"}`,
+			configureMocks: func(t *testing.T, repo *mocks.RepositoryMock, inspector *mocks.InspectorMock) {
+				t.Helper()
+
+				rul := mocks.NewRuleMock(t)
+
+				repo.EXPECT().FindRule(http.MethodGet, &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"}).
+					Return(rul, nil)
+				inspector.EXPECT().Describe(rul).Return(ruleInfo)
+			},
+			expectedCode: http.StatusOK,
+			expectedResponse: `{
+				"rule": {
+					"id": "rule:foo", "src": "test", "match": {}, "methods": ["GET"], "priority": 0,
+					"execute": null, "on_error": null
+				}
+			}`,
+		},
+		{
+			uc:               "pipeline execution requested, but not enabled",
+			request:          `{"method": "GET", "url": "http://foo.bar/baz", "execute": true}`,
+			configureMocks:   func(t *testing.T, _ *mocks.RepositoryMock, _ *mocks.InspectorMock) { t.Helper() },
+			expectedCode:     http.StatusForbidden,
+			expectedResponse: `{"error": "pipeline execution is not enabled"}`,
+		},
+		{
+			uc:             "rule found and pipeline execution succeeds",
+			allowExecution: true,
+			request: `{
+				"method": "GET", "url": "http://foo.bar/baz", "execute": true,
+				"headers": {"x-foo": "bar"}, "cookies": {"session": "baz"}
+			}`,
+			configureMocks: func(t *testing.T, repo *mocks.RepositoryMock, inspector *mocks.InspectorMock) {
+				t.Helper()
+
+				rul := mocks.NewRuleMock(t)
+				mutator := mocks.NewURIMutatorMock(t)
+
+				repo.EXPECT().FindRule(http.MethodGet, mock.Anything).Return(rul, nil)
+				inspector.EXPECT().Describe(rul).Return(ruleInfo)
+				rul.EXPECT().Execute(mock.Anything).
+					Run(func(ctx heimdall.Context) {
+						assert.Equal(t, "bar", ctx.Request().Header("X-Foo"))
+						assert.Equal(t, "baz", ctx.Request().Cookie("session"))
+
+						ctx.AddHeaderForUpstream("X-User", "foo")
+						ctx.AddHeaderForUpstream("Authorization", "Bearer secret")
+						ctx.AddCookieForUpstream("user", "foo")
+						pipelinetrace.Record(ctx.AppContext(), "authenticator", testMechanism("anon"),
+							pipelinetrace.OutcomeSuccess, nil)
+					}).
+					Return(mutator, nil)
+				mutator.EXPECT().Mutate(mock.Anything).Return(&url.URL{Scheme: "http", Host: "bar.foo", Path: "/baz"}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResponse: `{
+				"rule": {
+					"id": "rule:foo", "src": "test", "match": {}, "methods": ["GET"], "priority": 0,
+					"execute": null, "on_error": null
+				},
+				"result": {
+					"allowed": true,
+					"upstream_url": "http://bar.foo/baz",
+					"headers": {"X-User": "foo", "Authorization": "[REDACTED]"},
+					"cookies": {"user": "[REDACTED]"}
+				},
+				"trace": [{"type": "authenticator", "id": "anon", "outcome": "success"}]
+			}`,
+		},
+		{
+			uc:             "rule found and pipeline execution fails",
+			allowExecution: true,
+			request:        `{"method": "GET", "url": "http://foo.bar/baz", "execute": true}`,
+			configureMocks: func(t *testing.T, repo *mocks.RepositoryMock, inspector *mocks.InspectorMock) {
+				t.Helper()
+
+				rul := mocks.NewRuleMock(t)
+
+				repo.EXPECT().FindRule(http.MethodGet, mock.Anything).Return(rul, nil)
+				inspector.EXPECT().Describe(rul).Return(ruleInfo)
+				rul.EXPECT().Execute(mock.Anything).
+					Run(func(ctx heimdall.Context) {
+						err := errors.New("test error")
+
+						pipelinetrace.Record(ctx.AppContext(), "authenticator", testMechanism("jwt"),
+							pipelinetrace.OutcomeFailure, err)
+						pipelinetrace.Record(ctx.AppContext(), "error_handler", testMechanism("default"),
+							pipelinetrace.OutcomeSuccess, nil)
+						ctx.SetPipelineError(errorchain.New(heimdall.ErrAuthentication).CausedBy(err))
+					}).
+					Return(nil, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResponse: `{
+				"rule": {
+					"id": "rule:foo", "src": "test", "match": {}, "methods": ["GET"], "priority": 0,
+					"execute": null, "on_error": null
+				},
+				"result": {
+					"allowed": false,
+					"error": "authentication error: test error"
+				},
+				"trace": [
+					{"type": "authenticator", "id": "jwt", "outcome": "failure", "error": "test error"},
+					{"type": "error_handler", "id": "default", "outcome": "success"}
+				]
+			}`,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			repo := mocks.NewRepositoryMock(t)
+			inspector := mocks.NewInspectorMock(t)
+			tc.configureMocks(t, repo, inspector)
+
+			conf := &config.Configuration{Serve: config.ServeConfig{Management: config.ManagementServiceConfig{
+				Trace: config.TraceConfig{AllowExecution: tc.allowExecution},
+			}}}
+
+			app := newApp(appArgs{
+				Config:     conf,
+				Registerer: prometheus.NewRegistry(),
+				Logger:     log.Logger,
+			})
+
+			_, err := newHandler(handlerArgs{
+				Config:     conf,
+				App:        app,
+				Inspector:  inspector,
+				Repository: repo,
+				Logger:     log.Logger,
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://heimdall.test.local"+EndpointTrace,
+				strings.NewReader(tc.request))
+			req.Header.Set("Content-Type", "application/json")
+
+			// WHEN
+			resp, err := app.Test(req, -1)
+
+			// THEN
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			defer resp.Body.Close()
+
+			if len(tc.expectedResponse) != 0 {
+				rawResp, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+
+				assert.JSONEq(t, tc.expectedResponse, string(rawResp))
+			}
+		})
+	}
+}
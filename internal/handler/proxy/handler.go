@@ -17,22 +17,28 @@
 package proxy
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
+	"golang.org/x/exp/slices"
 
+	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/config"
 	fiberxforwarded "github.com/dadrus/heimdall/internal/fiber/middleware/xfmphu"
 	"github.com/dadrus/heimdall/internal/handler/requestcontext"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
 type Handler struct {
 	r rule.Repository
+	f mechanisms.Factory
 	s heimdall.JWTSigner
 	t time.Duration
 }
@@ -42,6 +48,7 @@ type handlerArgs struct {
 
 	App             *fiber.App `name:"proxy"`
 	RulesRepository rule.Repository
+	Factory         mechanisms.Factory
 	Config          *config.Configuration
 	Signer          heimdall.JWTSigner
 	Logger          zerolog.Logger
@@ -50,21 +57,129 @@ type handlerArgs struct {
 func newHandler(args handlerArgs) (*Handler, error) {
 	handler := &Handler{
 		r: args.RulesRepository,
+		f: args.Factory,
 		s: args.Signer,
 		t: args.Config.Serve.Proxy.Timeout.Read,
 	}
 
-	handler.registerRoutes(args.App.Group("/"), args.Logger)
+	handler.registerRoutes(args.App.Group("/"), args.Logger, loginFlowConfigured(args.Config))
 
 	return handler, nil
 }
 
-func (h *Handler) registerRoutes(router fiber.Router, logger zerolog.Logger) {
+func (h *Handler) registerRoutes(router fiber.Router, logger zerolog.Logger, withLoginFlow bool) {
 	logger.Debug().Msg("Registering Proxy service routes")
 
+	if withLoginFlow {
+		router.Get(errorhandlers.LoginCallbackPath, h.loginCallback)
+		router.Post(errorhandlers.LogoutPath, h.logout)
+	}
+
 	router.All("/*", fiberxforwarded.New(), h.proxy)
 }
 
+// loginFlowConfigured reports whether an oidc_login error handler is configured. Only then
+// the login callback and logout endpoints are exposed. Otherwise, these paths are proxied
+// to the upstream services like any other path.
+func loginFlowConfigured(conf *config.Configuration) bool {
+	if conf.Rules.Prototypes == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(conf.Rules.Prototypes.ErrorHandlers, func(mech config.Mechanism) bool {
+		return mech.Type == errorhandlers.ErrorHandlerOIDCLogin
+	})
+}
+
+func (h *Handler) loginCallback(c *fiber.Ctx) error {
+	logger := zerolog.Ctx(c.UserContext())
+	logger.Debug().Msg("Login callback endpoint called")
+
+	if errCode := c.Query("error"); len(errCode) != 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrAuthentication,
+			"login failed: %s %s", errCode, c.Query("error_description"))
+	}
+
+	state := c.Query("state")
+
+	if err := errorhandlers.VerifyLoginState(state, c.Cookies(errorhandlers.LoginStateCookieName)); err != nil {
+		return err
+	}
+
+	handlerID, err := errorhandlers.LoginFlowID(cache.Ctx(c.UserContext()), state)
+	if err != nil {
+		return err
+	}
+
+	eh, err := h.f.CreateErrorHandler("", handlerID, nil)
+	if err != nil {
+		return err
+	}
+
+	flow, ok := eh.(errorhandlers.LoginFlow)
+	if !ok {
+		return errorchain.NewWithMessagef(heimdall.ErrInternal,
+			"error handler %s does not support login flows", handlerID)
+	}
+
+	result, err := flow.CompleteLogin(c.UserContext(), state, c.Query("code"))
+	if err != nil {
+		return err
+	}
+
+	for _, cookie := range result.Cookies {
+		setCookie(c, cookie)
+	}
+
+	return c.Redirect(result.RedirectTo, http.StatusFound)
+}
+
+func (h *Handler) logout(c *fiber.Ctx) error {
+	logger := zerolog.Ctx(c.UserContext())
+	logger.Debug().Msg("Logout endpoint called")
+
+	handlerID := c.Query("handler")
+	if len(handlerID) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrArgument, "no handler present")
+	}
+
+	eh, err := h.f.CreateErrorHandler("", handlerID, nil)
+	if err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrArgument, "unknown error handler %s", handlerID).
+			CausedBy(err)
+	}
+
+	flow, ok := eh.(errorhandlers.LoginFlow)
+	if !ok {
+		return errorchain.NewWithMessagef(heimdall.ErrArgument,
+			"error handler %s does not support login flows", handlerID)
+	}
+
+	result, err := flow.Logout(c.UserContext(), func(name string) string { return c.Cookies(name) })
+	if err != nil {
+		return err
+	}
+
+	for _, cookie := range result.Cookies {
+		setCookie(c, cookie)
+	}
+
+	return c.Redirect(result.RedirectTo, http.StatusSeeOther)
+}
+
+func setCookie(c *fiber.Ctx, cookie *http.Cookie) {
+	c.Cookie(&fiber.Cookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Domain:   cookie.Domain,
+		Expires:  cookie.Expires,
+		Secure:   cookie.Secure,
+		HTTPOnly: cookie.HttpOnly,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
 func (h *Handler) proxy(c *fiber.Ctx) error {
 	logger := zerolog.Ctx(c.UserContext())
 
@@ -76,16 +191,11 @@ func (h *Handler) proxy(c *fiber.Ctx) error {
 		Str("_url", reqURL.String()).
 		Msg("Proxy endpoint called")
 
-	rul, err := h.r.FindRule(reqURL)
+	rul, err := h.r.FindRule(method, reqURL)
 	if err != nil {
 		return err
 	}
 
-	if !rul.MatchesMethod(method) {
-		return errorchain.NewWithMessagef(heimdall.ErrMethodNotAllowed,
-			"rule (id=%s, src=%s) doesn't match %s method", rul.ID(), rul.SrcID(), method)
-	}
-
 	reqCtx := requestcontext.New(c, method, reqURL, h.s)
 
 	mutator, err := rul.Execute(reqCtx)
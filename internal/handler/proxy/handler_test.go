@@ -17,6 +17,10 @@
 package proxy
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -36,6 +40,9 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/requestcontext"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers"
+	mocks3 "github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers/mocks"
+	mocks2 "github.com/dadrus/heimdall/internal/rules/mechanisms/mocks"
 	mocks4 "github.com/dadrus/heimdall/internal/rules/rule/mocks"
 	"github.com/dadrus/heimdall/internal/x"
 )
@@ -89,7 +96,7 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				repository.EXPECT().FindRule(mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
+				repository.EXPECT().FindRule(mock.Anything, mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -114,11 +121,7 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(false)
-				rule.EXPECT().ID().Return("test")
-				rule.EXPECT().SrcID().Return("test")
-
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(nil, heimdall.ErrMethodNotAllowed)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -146,10 +149,9 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 				mutator := mocks4.NewURIMutatorMock(t)
 				mutator.EXPECT().Mutate(mock.Anything).Return(nil, heimdall.ErrConfiguration)
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).Return(mutator, nil)
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -174,10 +176,9 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.Anything).Return(nil, heimdall.ErrAuthentication)
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -204,14 +205,13 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 
 				mutator := mocks4.NewURIMutatorMock(t)
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx *requestcontext.RequestContext) bool {
 					ctx.SetPipelineError(heimdall.ErrAuthorization)
 
 					return true
 				})).Return(mutator, nil)
 
-				repository.EXPECT().FindRule(mock.Anything).Return(rule, nil)
+				repository.EXPECT().FindRule(http.MethodPost, mock.Anything).Return(rule, nil)
 			},
 			assertResponse: func(t *testing.T, err error, response *http.Response) {
 				t.Helper()
@@ -254,7 +254,6 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 					Path:   "/foobar",
 				}, nil)
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx *requestcontext.RequestContext) bool {
 					ctx.AddHeaderForUpstream("X-Foo-Bar", "baz")
 					ctx.AddCookieForUpstream("X-Bar-Foo", "zab")
@@ -262,7 +261,7 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 					return true
 				})).Return(mutator, nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPost, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.String() == "http://heimdall.test.local/foobar"
 				})).Return(rule, nil)
 			},
@@ -335,7 +334,6 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 					Path:   "/[id]/foobar",
 				}, nil)
 
-				rule.EXPECT().MatchesMethod(http.MethodGet).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx *requestcontext.RequestContext) bool {
 					ctx.AddHeaderForUpstream("X-Foo-Bar", "baz")
 					ctx.AddCookieForUpstream("X-Bar-Foo", "zab")
@@ -343,7 +341,7 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 					return true
 				})).Return(mutator, nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodGet, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.String() == "http://heimdall.test.local/%5Bid%5D/foobar"
 				})).Return(rule, nil)
 			},
@@ -415,7 +413,6 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 					Path:   "/[barfoo]",
 				}, nil)
 
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(true)
 				rule.EXPECT().Execute(mock.MatchedBy(func(ctx *requestcontext.RequestContext) bool {
 					ctx.AddHeaderForUpstream("X-Foo-Bar", "baz")
 					ctx.AddCookieForUpstream("X-Bar-Foo", "zab")
@@ -423,7 +420,7 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 					return true
 				})).Return(mutator, nil)
 
-				repository.EXPECT().FindRule(mock.MatchedBy(func(reqURL *url.URL) bool {
+				repository.EXPECT().FindRule(http.MethodPost, mock.MatchedBy(func(reqURL *url.URL) bool {
 					return reqURL.String() == "http://heimdall.test.local/%5Bbarfoo%5D"
 				})).Return(rule, nil)
 			},
@@ -465,6 +462,51 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 				assert.JSONEq(t, `{ "foo": "bar" }`, string(data))
 			},
 		},
+		{
+			uc: "login callback path is proxied to the upstream if no oidc_login error handler is configured",
+			createRequest: func(t *testing.T) *http.Request {
+				t.Helper()
+
+				return httptest.NewRequest(http.MethodGet,
+					"http://heimdall.test.local"+errorhandlers.LoginCallbackPath+"?code=foo&state=bar", nil)
+			},
+			configureMocks: func(t *testing.T, repository *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
+				t.Helper()
+
+				mutator := mocks4.NewURIMutatorMock(t)
+				mutator.EXPECT().Mutate(mock.Anything).Return(&url.URL{
+					Scheme:   upstreamURL.Scheme,
+					Host:     upstreamURL.Host,
+					Path:     errorhandlers.LoginCallbackPath,
+					RawQuery: "code=foo&state=bar",
+				}, nil)
+
+				rule.EXPECT().Execute(mock.Anything).Return(mutator, nil)
+
+				repository.EXPECT().FindRule(http.MethodGet, mock.MatchedBy(func(reqURL *url.URL) bool {
+					return reqURL.Path == errorhandlers.LoginCallbackPath
+				})).Return(rule, nil)
+			},
+			instructUpstream: func(t *testing.T) {
+				t.Helper()
+
+				upstreamCheckRequest = func(req *http.Request) {
+					assert.Equal(t, http.MethodGet, req.Method)
+					assert.Equal(t, errorhandlers.LoginCallbackPath, req.URL.Path)
+					assert.Equal(t, "foo", req.URL.Query().Get("code"))
+				}
+
+				upstreamResponseCode = http.StatusOK
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.True(t, upstreamCalled)
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -515,3 +557,358 @@ func TestHandleProxyEndpointRequest(t *testing.T) {
 		})
 	}
 }
+
+type loginFlowMock struct {
+	*mocks3.ErrorHandlerMock
+
+	result *errorhandlers.LoginResult
+	err    error
+
+	sessionCookie string
+}
+
+func (m *loginFlowMock) CompleteLogin(_ context.Context, _, _ string) (*errorhandlers.LoginResult, error) {
+	return m.result, m.err
+}
+
+func (m *loginFlowMock) Logout(
+	_ context.Context, cookie func(name string) string,
+) (*errorhandlers.LoginResult, error) {
+	m.sessionCookie = cookie("heimdall_session")
+
+	return m.result, m.err
+}
+
+func TestHandleLoginCallbackRequest(t *testing.T) {
+	t.Parallel()
+
+	callbackURL := "http://heimdall.test.local" + errorhandlers.LoginCallbackPath
+	stateDigest := sha256.Sum256([]byte("foo"))
+	stateBinding := base64.RawURLEncoding.EncodeToString(stateDigest[:])
+
+	for _, tc := range []struct {
+		uc             string
+		query          string
+		stateCookie    string
+		configureMocks func(t *testing.T, cch *mocks.CacheMock, factory *mocks2.FactoryMock)
+		assertResponse func(t *testing.T, err error, response *http.Response)
+	}{
+		{
+			uc:    "authorization server responded with an error",
+			query: "?error=access_denied&state=foo",
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			uc:    "without login state cookie",
+			query: "?code=bar&state=foo",
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			uc:          "with login state cookie not matching the state",
+			query:       "?code=bar&state=foo",
+			stateCookie: "bar",
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			uc:          "unknown login transaction",
+			query:       "?code=bar&state=foo",
+			stateCookie: stateBinding,
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock, _ *mocks2.FactoryMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			uc:          "error handler does not support login flows",
+			query:       "?code=bar&state=foo",
+			stateCookie: stateBinding,
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock, factory *mocks2.FactoryMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return(`{"handler_id":"login"}`)
+				factory.EXPECT().CreateErrorHandler("", "login", mock.Anything).
+					Return(mocks3.NewErrorHandlerMock(t), nil)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+		{
+			uc:          "login completion fails",
+			query:       "?code=bar&state=foo",
+			stateCookie: stateBinding,
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock, factory *mocks2.FactoryMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return(`{"handler_id":"login"}`)
+				factory.EXPECT().CreateErrorHandler("", "login", mock.Anything).
+					Return(&loginFlowMock{err: heimdall.ErrAuthentication}, nil)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			uc:          "login completed successfully",
+			query:       "?code=bar&state=foo",
+			stateCookie: stateBinding,
+			configureMocks: func(t *testing.T, cch *mocks.CacheMock, factory *mocks2.FactoryMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return(`{"handler_id":"login"}`)
+				factory.EXPECT().CreateErrorHandler("", "login", mock.Anything).
+					Return(&loginFlowMock{result: &errorhandlers.LoginResult{
+						RedirectTo: "https://app.local/foo",
+						Cookies: []*http.Cookie{
+							{
+								Name:     "heimdall_session",
+								Value:    "session",
+								Path:     "/",
+								Secure:   true,
+								HttpOnly: true,
+								SameSite: http.SameSiteLaxMode,
+							},
+						},
+					}}, nil)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusFound, response.StatusCode)
+				assert.Equal(t, "https://app.local/foo", response.Header.Get("Location"))
+
+				cookies := response.Cookies()
+				require.Len(t, cookies, 1)
+				assert.Equal(t, "heimdall_session", cookies[0].Name)
+				assert.Equal(t, "session", cookies[0].Value)
+				assert.True(t, cookies[0].Secure)
+				assert.True(t, cookies[0].HttpOnly)
+				assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *mocks.CacheMock, _ *mocks2.FactoryMock) { t.Helper() })
+
+			conf := &config.Configuration{
+				Serve: config.ServeConfig{Proxy: config.ServiceConfig{}},
+				Rules: config.Rules{Prototypes: &config.MechanismPrototypes{
+					ErrorHandlers: []config.Mechanism{{ID: "login", Type: errorhandlers.ErrorHandlerOIDCLogin}},
+				}},
+			}
+			cch := mocks.NewCacheMock(t)
+			factory := mocks2.NewFactoryMock(t)
+			repo := mocks4.NewRepositoryMock(t)
+
+			configureMocks(t, cch, factory)
+
+			app := newApp(appArgs{
+				Config:     conf,
+				Registerer: prometheus.NewRegistry(),
+				Cache:      cch,
+				Logger:     log.Logger,
+			})
+
+			defer app.Shutdown()
+
+			_, err := newHandler(handlerArgs{
+				App:             app,
+				RulesRepository: repo,
+				Factory:         factory,
+				Config:          conf,
+				Logger:          log.Logger,
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, callbackURL+tc.query, nil)
+			if len(tc.stateCookie) != 0 {
+				req.AddCookie(&http.Cookie{Name: errorhandlers.LoginStateCookieName, Value: tc.stateCookie})
+			}
+
+			// WHEN
+			resp, err := app.Test(req, -1)
+
+			// THEN
+			if err == nil {
+				defer resp.Body.Close()
+			}
+
+			tc.assertResponse(t, err, resp)
+		})
+	}
+}
+
+func TestHandleLogoutRequest(t *testing.T) {
+	t.Parallel()
+
+	logoutURL := "http://heimdall.test.local" + errorhandlers.LogoutPath
+
+	for _, tc := range []struct {
+		uc             string
+		query          string
+		configureMocks func(t *testing.T, factory *mocks2.FactoryMock)
+		assertResponse func(t *testing.T, err error, response *http.Response)
+	}{
+		{
+			uc: "without handler",
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			uc:    "unknown error handler",
+			query: "?handler=foo",
+			configureMocks: func(t *testing.T, factory *mocks2.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().CreateErrorHandler("", "foo", mock.Anything).
+					Return(nil, errors.New("no such error handler"))
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			uc:    "error handler does not support login flows",
+			query: "?handler=login",
+			configureMocks: func(t *testing.T, factory *mocks2.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().CreateErrorHandler("", "login", mock.Anything).
+					Return(mocks3.NewErrorHandlerMock(t), nil)
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			uc:    "session ended successfully",
+			query: "?handler=login",
+			configureMocks: func(t *testing.T, factory *mocks2.FactoryMock) {
+				t.Helper()
+
+				flow := &loginFlowMock{result: &errorhandlers.LoginResult{
+					RedirectTo: "/",
+					Cookies: []*http.Cookie{
+						{
+							Name:     "heimdall_session",
+							Path:     "/",
+							Expires:  time.Unix(0, 0),
+							Secure:   true,
+							HttpOnly: true,
+							SameSite: http.SameSiteLaxMode,
+						},
+					},
+				}}
+
+				factory.EXPECT().CreateErrorHandler("", "login", mock.Anything).Return(flow, nil)
+
+				t.Cleanup(func() { assert.Equal(t, "session", flow.sessionCookie) })
+			},
+			assertResponse: func(t *testing.T, err error, response *http.Response) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusSeeOther, response.StatusCode)
+				assert.Equal(t, "/", response.Header.Get("Location"))
+
+				cookies := response.Cookies()
+				require.Len(t, cookies, 1)
+				assert.Equal(t, "heimdall_session", cookies[0].Name)
+				assert.Empty(t, cookies[0].Value)
+				assert.True(t, cookies[0].Expires.Before(time.Now()))
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *mocks2.FactoryMock) { t.Helper() })
+
+			conf := &config.Configuration{
+				Serve: config.ServeConfig{Proxy: config.ServiceConfig{}},
+				Rules: config.Rules{Prototypes: &config.MechanismPrototypes{
+					ErrorHandlers: []config.Mechanism{{ID: "login", Type: errorhandlers.ErrorHandlerOIDCLogin}},
+				}},
+			}
+			cch := mocks.NewCacheMock(t)
+			factory := mocks2.NewFactoryMock(t)
+			repo := mocks4.NewRepositoryMock(t)
+
+			configureMocks(t, factory)
+
+			app := newApp(appArgs{
+				Config:     conf,
+				Registerer: prometheus.NewRegistry(),
+				Cache:      cch,
+				Logger:     log.Logger,
+			})
+
+			defer app.Shutdown()
+
+			_, err := newHandler(handlerArgs{
+				App:             app,
+				RulesRepository: repo,
+				Factory:         factory,
+				Config:          conf,
+				Logger:          log.Logger,
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, logoutURL+tc.query, nil)
+			req.AddCookie(&http.Cookie{Name: "heimdall_session", Value: "session"})
+
+			// WHEN
+			resp, err := app.Test(req, -1)
+
+			// THEN
+			if err == nil {
+				defer resp.Body.Close()
+			}
+
+			tc.assertResponse(t, err, resp)
+		})
+	}
+}
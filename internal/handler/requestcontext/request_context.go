@@ -18,6 +18,7 @@ package requestcontext
 
 import (
 	"context"
+	"crypto/x509"
 	"net/http"
 	"net/url"
 	"time"
@@ -54,10 +55,11 @@ func New(c *fiber.Ctx, method string, reqURL *url.URL, signer heimdall.JWTSigner
 
 func (s *RequestContext) Request() *heimdall.Request {
 	return &heimdall.Request{
-		RequestFunctions: s,
-		Method:           s.reqMethod,
-		URL:              s.reqURL,
-		ClientIP:         s.RequestClientIPs(),
+		RequestFunctions:   s,
+		Method:             s.reqMethod,
+		URL:                s.reqURL,
+		ClientIP:           s.RequestClientIPs(),
+		ClientCertificates: s.clientCertificates(),
 	}
 }
 
@@ -76,6 +78,14 @@ func (s *RequestContext) RequestClientIPs() []string {
 	return x.IfThenElse(len(ips) != 0, ips, []string{s.c.IP()})
 }
 
+func (s *RequestContext) clientCertificates() []*x509.Certificate {
+	if state := s.c.Context().TLSConnectionState(); state != nil {
+		return state.PeerCertificates
+	}
+
+	return nil
+}
+
 func (s *RequestContext) Finalize(statusCode int) error {
 	if s.err != nil {
 		return s.err
@@ -18,6 +18,7 @@ package heimdall
 
 import (
 	"context"
+	"crypto/x509"
 	"net/url"
 )
 
@@ -51,4 +52,6 @@ type Request struct {
 	Method   string
 	URL      *url.URL
 	ClientIP []string
+	// ClientCertificates holds the certificate chain presented by the client in the TLS handshake
+	ClientCertificates []*x509.Certificate
 }
@@ -18,7 +18,10 @@ package heimdall
 
 import (
 	"errors"
+	"math"
+	"net/http"
 	"reflect"
+	"time"
 )
 
 var (
@@ -31,6 +34,7 @@ var (
 	ErrInternal             = errors.New("internal error")
 	ErrMethodNotAllowed     = errors.New("method not allowed")
 	ErrNoRuleFound          = errors.New("no rule found")
+	ErrTooManyRequests      = errors.New("too many requests")
 )
 
 type RedirectError struct {
@@ -42,3 +46,74 @@ type RedirectError struct {
 func (e *RedirectError) Error() string { return e.Message }
 
 func (e *RedirectError) Is(target error) bool { return reflect.TypeOf(e) == reflect.TypeOf(target) }
+
+// TooManyRequestsError carries the time a client should wait before sending the next request.
+// It is used as the cause of an ErrTooManyRequests error.
+type TooManyRequestsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string { return "retry after " + e.RetryAfter.String() }
+
+// RetryAfterSeconds returns the value for the Retry-After header, which is at least one second.
+func (e *TooManyRequestsError) RetryAfterSeconds() int {
+	return int(math.Max(1, math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// DenialError carries machine-readable details about the reason an authorizer denied access.
+// It is used as the cause of an ErrAuthorization error. These details are exposed to the client
+// only if an error handler explicitly opts in to that.
+type DenialError struct {
+	ExpressionID   string
+	Message        string
+	RequiredScopes []string
+}
+
+func (e *DenialError) Error() string {
+	if len(e.ExpressionID) == 0 {
+		return "access denied"
+	}
+
+	return "access denied by expression " + e.ExpressionID
+}
+
+// ResponseError wraps a pipeline error and carries the information an error handler decided to
+// expose to the client. The status code of the response is derived from the wrapped error.
+type ResponseError struct {
+	Err            error
+	Headers        map[string]string
+	ProblemDetails bool
+}
+
+func (e *ResponseError) Error() string { return e.Err.Error() }
+
+func (e *ResponseError) Unwrap() error { return e.Err }
+
+// ProblemDetailsFor returns an RFC 7807 problem details object for the given status code,
+// enriched with the details of a DenialError if the wrapped error has been caused by one.
+func (e *ResponseError) ProblemDetailsFor(code int) map[string]any {
+	details := map[string]any{
+		"type":   "about:blank",
+		"title":  http.StatusText(code),
+		"status": code,
+	}
+
+	var denial *DenialError
+	if !errors.As(e.Err, &denial) {
+		return details
+	}
+
+	if len(denial.Message) != 0 {
+		details["detail"] = denial.Message
+	}
+
+	if len(denial.ExpressionID) != 0 {
+		details["expression_id"] = denial.ExpressionID
+	}
+
+	if len(denial.RequiredScopes) != 0 {
+		details["required_scopes"] = denial.RequiredScopes
+	}
+
+	return details
+}
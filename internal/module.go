@@ -27,6 +27,7 @@ import (
 	"github.com/dadrus/heimdall/internal/handler/profiling"
 	"github.com/dadrus/heimdall/internal/logging"
 	"github.com/dadrus/heimdall/internal/prometheus"
+	"github.com/dadrus/heimdall/internal/readiness"
 	"github.com/dadrus/heimdall/internal/rules"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms"
 	"github.com/dadrus/heimdall/internal/signer"
@@ -41,6 +42,7 @@ var Module = fx.Options( //nolint:gochecknoglobals
 		logger.Info().Str("_version", version.Version).Msg("Starting heimdall")
 	}),
 	tracing.Module,
+	readiness.Module,
 	cache.Module,
 	signer.Module,
 	mechanisms.Module,
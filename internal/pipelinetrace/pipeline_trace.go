@@ -0,0 +1,74 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelinetrace
+
+import (
+	"context"
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+	OutcomeSkipped Outcome = "skipped"
+)
+
+type Step struct {
+	Type    string  `json:"type"`
+	ID      string  `json:"id"`
+	Outcome Outcome `json:"outcome"`
+	Error   string  `json:"error,omitempty"`
+}
+
+type Mechanism interface {
+	HandlerID() string
+}
+
+type ctxKey struct{}
+
+type pipelineTrace struct {
+	steps []Step
+}
+
+// New returns a context, which records the steps executed by a rule pipeline.
+// Without it, recording is a no-op.
+func New(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &pipelineTrace{})
+}
+
+func Steps(ctx context.Context) []Step {
+	if t, ok := ctx.Value(ctxKey{}).(*pipelineTrace); ok {
+		return t.steps
+	}
+
+	return nil
+}
+
+func Record(ctx context.Context, typ string, mechanism Mechanism, outcome Outcome, err error) {
+	t, ok := ctx.Value(ctxKey{}).(*pipelineTrace)
+	if !ok {
+		return
+	}
+
+	step := Step{Type: typ, ID: mechanism.HandlerID(), Outcome: outcome}
+	if err != nil {
+		step.Error = err.Error()
+	}
+
+	t.steps = append(t.steps, step)
+}
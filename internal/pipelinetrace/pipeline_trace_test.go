@@ -0,0 +1,61 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelinetrace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mechanism string
+
+func (m mechanism) HandlerID() string { return string(m) }
+
+func TestRecordWithoutTrace(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := context.Background()
+
+	// WHEN
+	Record(ctx, "authenticator", mechanism("foo"), OutcomeSuccess, nil)
+
+	// THEN
+	assert.Empty(t, Steps(ctx))
+}
+
+func TestRecordWithTrace(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := New(context.Background())
+
+	// WHEN
+	Record(ctx, "authenticator", mechanism("foo"), OutcomeFailure, errors.New("test error"))
+	Record(ctx, "authenticator", mechanism("bar"), OutcomeSuccess, nil)
+	Record(ctx, "authorizer", mechanism("baz"), OutcomeSkipped, nil)
+
+	// THEN
+	assert.Equal(t, []Step{
+		{Type: "authenticator", ID: "foo", Outcome: OutcomeFailure, Error: "test error"},
+		{Type: "authenticator", ID: "bar", Outcome: OutcomeSuccess},
+		{Type: "authorizer", ID: "baz", Outcome: OutcomeSkipped},
+	}, Steps(ctx))
+}
@@ -163,10 +163,12 @@ func TestRegisterCertificateExpiryCollector(t *testing.T) {
 						KeyID:    "decision",
 					},
 				},
-				Management: config.ServiceConfig{
-					TLS: &config.TLS{
-						KeyStore: config.KeyStore{Path: serveServicesPEMFile.Name()},
-						KeyID:    "management",
+				Management: config.ManagementServiceConfig{
+					ServiceConfig: config.ServiceConfig{
+						TLS: &config.TLS{
+							KeyStore: config.KeyStore{Path: serveServicesPEMFile.Name()},
+							KeyID:    "management",
+						},
 					},
 				},
 			},
@@ -0,0 +1,64 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package readiness
+
+import (
+	"context"
+)
+
+// Status describes the readiness of a single component.
+type Status struct {
+	Ready   bool           `json:"ready"`
+	Details map[string]any `json:"details,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// Checker is implemented by components, which need to be ready before heimdall can serve traffic.
+type Checker interface {
+	Name() string
+	Status(ctx context.Context) Status
+}
+
+// Registry aggregates the readiness of all registered components.
+type Registry interface {
+	Register(checker Checker)
+	Check(ctx context.Context) (bool, map[string]Status)
+}
+
+func NewRegistry() Registry { return &registry{} }
+
+type registry struct {
+	checkers []Checker
+}
+
+// Register is expected to be called during app bootstrapping only and is not safe for concurrent use.
+func (r *registry) Register(checker Checker) {
+	r.checkers = append(r.checkers, checker)
+}
+
+func (r *registry) Check(ctx context.Context) (bool, map[string]Status) {
+	ready := true
+	statuses := make(map[string]Status, len(r.checkers))
+
+	for _, checker := range r.checkers {
+		status := checker.Status(ctx)
+		ready = ready && status.Ready
+		statuses[checker.Name()] = status
+	}
+
+	return ready, statuses
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package readiness
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testChecker struct {
+	name   string
+	status Status
+}
+
+func (c testChecker) Name() string { return c.name }
+
+func (c testChecker) Status(_ context.Context) Status { return c.status }
+
+func TestRegistryCheck(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		checkers []Checker
+		ready    bool
+	}{
+		{uc: "nothing registered", ready: true},
+		{
+			uc: "all ready",
+			checkers: []Checker{
+				testChecker{name: "foo", status: Status{Ready: true}},
+				testChecker{name: "bar", status: Status{Ready: true}},
+			},
+			ready: true,
+		},
+		{
+			uc: "one not ready",
+			checkers: []Checker{
+				testChecker{name: "foo", status: Status{Ready: false}},
+				testChecker{name: "bar", status: Status{Ready: true}},
+			},
+			ready: false,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			registry := NewRegistry()
+			for _, checker := range tc.checkers {
+				registry.Register(checker)
+			}
+
+			// WHEN
+			ready, statuses := registry.Check(context.Background())
+
+			// THEN
+			assert.Equal(t, tc.ready, ready)
+			assert.Len(t, statuses, len(tc.checkers))
+
+			for _, checker := range tc.checkers {
+				assert.Equal(t, checker.Status(context.Background()), statuses[checker.Name()])
+			}
+		})
+	}
+}
+
+func TestSyncState(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	state := NewSyncState("foo")
+
+	// WHEN
+	initial := state.Status(context.Background())
+
+	state.Failed(errors.New("test error"))
+	failedInitially := state.Status(context.Background())
+
+	state.Succeeded()
+	synced := state.Status(context.Background())
+
+	state.Failed(errors.New("test error"))
+	failedLater := state.Status(context.Background())
+
+	// THEN
+	assert.Equal(t, "foo", state.Name())
+
+	assert.False(t, initial.Ready)
+	assert.Empty(t, initial.Error)
+
+	assert.False(t, failedInitially.Ready)
+	assert.Equal(t, "test error", failedInitially.Error)
+
+	assert.True(t, synced.Ready)
+	assert.Empty(t, synced.Error)
+	assert.Contains(t, synced.Details, "last_sync")
+
+	assert.True(t, failedLater.Ready)
+	assert.Equal(t, "test error", failedLater.Error)
+	assert.Equal(t, synced.Details, failedLater.Details)
+}
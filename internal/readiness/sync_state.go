@@ -0,0 +1,71 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package readiness
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SyncState tracks the synchronization state of a component, which loads its data
+// from somewhere else, like a rule provider. It is ready after the first successful
+// synchronization.
+type SyncState struct {
+	name     string
+	mutex    sync.RWMutex
+	lastSync time.Time
+	err      error
+}
+
+func NewSyncState(name string) *SyncState {
+	return &SyncState{name: name}
+}
+
+func (s *SyncState) Name() string { return s.name }
+
+func (s *SyncState) Succeeded() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastSync = time.Now()
+	s.err = nil
+}
+
+func (s *SyncState) Failed(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.err = err
+}
+
+func (s *SyncState) Status(_ context.Context) Status {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	status := Status{Ready: !s.lastSync.IsZero()}
+
+	if status.Ready {
+		status.Details = map[string]any{"last_sync": s.lastSync.UTC().Format(time.RFC3339)}
+	}
+
+	if s.err != nil {
+		status.Error = s.err.Error()
+	}
+
+	return status
+}
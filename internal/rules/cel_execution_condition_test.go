@@ -76,6 +76,13 @@ func TestCelExecutionConditionCanExecute(t *testing.T) {
 			expression: `Subject.ID == "foobar" && Request.Method == "GET"`,
 			expected:   true,
 		},
+		{
+			uc: "expression using network, time and url functions",
+			expression: `Request.ClientIP.all(i, ip(i).isLoopback() || ip(i).inCIDR("10.0.0.0/8")) &&
+							now() > timestamp("2023-01-01T00:00:00Z") &&
+							Request.URL.String() == "http://localhost/test?foo=bar&baz=zab"`,
+			expected: true,
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -20,6 +20,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/pipelinetrace"
 )
 
 type compositeErrorHandler []errorHandler
@@ -35,6 +36,9 @@ func (eh compositeErrorHandler) Execute(ctx heimdall.Context, exErr error) (bool
 
 	for _, eh := range eh {
 		ok, err = eh.Execute(ctx, exErr)
+
+		pipelinetrace.Record(ctx.AppContext(), "error_handler", eh, errorHandlerOutcome(ok, err), err)
+
 		if err != nil {
 			logger.Error().Err(err).
 				Msg("Failed to execute error handler. Falling back to the next or the default one")
@@ -47,3 +51,14 @@ func (eh compositeErrorHandler) Execute(ctx heimdall.Context, exErr error) (bool
 
 	return false, exErr
 }
+
+func errorHandlerOutcome(responsible bool, err error) pipelinetrace.Outcome {
+	switch {
+	case err != nil:
+		return pipelinetrace.OutcomeFailure
+	case responsible:
+		return pipelinetrace.OutcomeSuccess
+	default:
+		return pipelinetrace.OutcomeSkipped
+	}
+}
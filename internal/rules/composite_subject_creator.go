@@ -23,6 +23,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/accesscontext"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/pipelinetrace"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 )
 
@@ -39,6 +40,7 @@ func (ca compositeSubjectCreator) Execute(ctx heimdall.Context) (*subject.Subjec
 	for idx, a := range ca {
 		sub, err = a.Execute(ctx)
 		if err != nil {
+			pipelinetrace.Record(ctx.AppContext(), "authenticator", a, pipelinetrace.OutcomeFailure, err)
 			logger.Info().Err(err).Msg("Pipeline step execution failed")
 
 			if (errors.Is(err, heimdall.ErrArgument) || a.IsFallbackOnErrorAllowed()) && idx < len(ca) {
@@ -50,6 +52,7 @@ func (ca compositeSubjectCreator) Execute(ctx heimdall.Context) (*subject.Subjec
 			break
 		}
 
+		pipelinetrace.Record(ctx.AppContext(), "authenticator", a, pipelinetrace.OutcomeSuccess, nil)
 		accesscontext.SetSubject(ctx.AppContext(), sub.ID)
 
 		return sub, nil
@@ -2,22 +2,39 @@ package rules
 
 import (
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/pipelinetrace"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
 )
 
 type conditionalSubjectHandler struct {
-	h subjectHandler
-	c executionCondition
+	h   subjectHandler
+	c   executionCondition
+	typ string
 }
 
 func (h *conditionalSubjectHandler) Execute(ctx heimdall.Context, sub *subject.Subject) error {
-	if canExecute, err := h.c.CanExecute(ctx, sub); err != nil {
+	canExecute, err := h.c.CanExecute(ctx, sub)
+	if err != nil {
+		pipelinetrace.Record(ctx.AppContext(), h.typ, h, pipelinetrace.OutcomeFailure, err)
+
 		return err
-	} else if canExecute {
-		return h.h.Execute(ctx, sub)
 	}
 
-	return nil
+	if !canExecute {
+		pipelinetrace.Record(ctx.AppContext(), h.typ, h, pipelinetrace.OutcomeSkipped, nil)
+
+		return nil
+	}
+
+	err = h.h.Execute(ctx, sub)
+
+	pipelinetrace.Record(ctx.AppContext(), h.typ, h,
+		x.IfThenElse(err != nil, pipelinetrace.OutcomeFailure, pipelinetrace.OutcomeSuccess), err)
+
+	return err
 }
 
 func (h *conditionalSubjectHandler) ContinueOnError() bool { return h.h.ContinueOnError() }
+
+func (h *conditionalSubjectHandler) HandlerID() string { return h.h.HandlerID() }
@@ -1,12 +1,15 @@
 package rules
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/pipelinetrace"
 	rulemocks "github.com/dadrus/heimdall/internal/rules/mocks"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
@@ -17,7 +20,7 @@ func TestConditionalSubjectHandlerExecute(t *testing.T) {
 	for _, tc := range []struct {
 		uc             string
 		configureMocks func(t *testing.T, c *rulemocks.ExecutionConditionMock, h *rulemocks.SubjectHandlerMock)
-		assert         func(t *testing.T, err error)
+		assert         func(t *testing.T, err error, steps []pipelinetrace.Step)
 	}{
 		{
 			uc: "executes if can",
@@ -26,11 +29,15 @@ func TestConditionalSubjectHandlerExecute(t *testing.T) {
 
 				c.EXPECT().CanExecute(mock.Anything, mock.Anything).Return(true, nil)
 				h.EXPECT().Execute(mock.Anything, mock.Anything).Return(nil)
+				h.EXPECT().HandlerID().Return("foo")
 			},
-			assert: func(t *testing.T, err error) {
+			assert: func(t *testing.T, err error, steps []pipelinetrace.Step) {
 				t.Helper()
 
 				require.NoError(t, err)
+				assert.Equal(t, []pipelinetrace.Step{
+					{Type: "authorizer", ID: "foo", Outcome: pipelinetrace.OutcomeSuccess},
+				}, steps)
 			},
 		},
 		{
@@ -39,11 +46,15 @@ func TestConditionalSubjectHandlerExecute(t *testing.T) {
 				t.Helper()
 
 				c.EXPECT().CanExecute(mock.Anything, mock.Anything).Return(false, nil)
+				h.EXPECT().HandlerID().Return("foo")
 			},
-			assert: func(t *testing.T, err error) {
+			assert: func(t *testing.T, err error, steps []pipelinetrace.Step) {
 				t.Helper()
 
 				require.NoError(t, err)
+				assert.Equal(t, []pipelinetrace.Step{
+					{Type: "authorizer", ID: "foo", Outcome: pipelinetrace.OutcomeSkipped},
+				}, steps)
 			},
 		},
 		{
@@ -52,12 +63,19 @@ func TestConditionalSubjectHandlerExecute(t *testing.T) {
 				t.Helper()
 
 				c.EXPECT().CanExecute(mock.Anything, mock.Anything).Return(true, testsupport.ErrTestPurpose)
+				h.EXPECT().HandlerID().Return("foo")
 			},
-			assert: func(t *testing.T, err error) {
+			assert: func(t *testing.T, err error, steps []pipelinetrace.Step) {
 				t.Helper()
 
 				require.Error(t, err)
 				assert.ErrorIs(t, err, testsupport.ErrTestPurpose)
+				assert.Equal(t, []pipelinetrace.Step{
+					{
+						Type: "authorizer", ID: "foo", Outcome: pipelinetrace.OutcomeFailure,
+						Error: testsupport.ErrTestPurpose.Error(),
+					},
+				}, steps)
 			},
 		},
 	} {
@@ -65,15 +83,19 @@ func TestConditionalSubjectHandlerExecute(t *testing.T) {
 			// GIVEN
 			condition := rulemocks.NewExecutionConditionMock(t)
 			handler := rulemocks.NewSubjectHandlerMock(t)
-			decorator := conditionalSubjectHandler{c: condition, h: handler}
+			decorator := conditionalSubjectHandler{c: condition, h: handler, typ: "authorizer"}
+			appCtx := pipelinetrace.New(context.Background())
+
+			ctx := heimdallmocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(appCtx)
 
 			tc.configureMocks(t, condition, handler)
 
 			// WHEN
-			err := decorator.Execute(nil, nil)
+			err := decorator.Execute(ctx, nil)
 
 			// THEN
-			tc.assert(t, err)
+			tc.assert(t, err, pipelinetrace.Steps(appCtx))
 		})
 	}
 }
@@ -23,6 +23,7 @@ import (
 type Rule struct {
 	ID                 string                   `json:"id"         yaml:"id"`
 	RuleMatcher        Matcher                  `json:"match"      yaml:"match"`
+	Priority           int                      `json:"priority"   yaml:"priority"`
 	UpstreamURLFactory *UpstreamURLFactory      `json:"forward_to" yaml:"forward_to"`
 	Methods            []string                 `json:"methods"    yaml:"methods"`
 	Execute            []config.MechanismConfig `json:"execute"    yaml:"execute"`
@@ -37,6 +37,7 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 			URL:      "bar",
 			Strategy: "glob",
 		},
+		Priority: 10,
 		UpstreamURLFactory: &UpstreamURLFactory{
 			Host: "baz",
 			URLRewriter: &URLRewriter{
@@ -57,6 +58,7 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 	// THEN
 	assert.Equal(t, in.ID, out.ID)
 	assert.Equal(t, in.RuleMatcher.URL, out.RuleMatcher.URL)
+	assert.Equal(t, in.Priority, out.Priority)
 	assert.Equal(t, in.UpstreamURLFactory, out.UpstreamURLFactory)
 	assert.Equal(t, in.RuleMatcher.Strategy, out.RuleMatcher.Strategy)
 	assert.Equal(t, in.Methods, out.Methods)
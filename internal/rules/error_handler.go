@@ -22,4 +22,5 @@ import "github.com/dadrus/heimdall/internal/heimdall"
 
 type errorHandler interface {
 	Execute(ctx heimdall.Context, err error) (bool, error)
+	HandlerID() string
 }
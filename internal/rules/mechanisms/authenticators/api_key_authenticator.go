@@ -0,0 +1,169 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"github.com/rs/zerolog"
+	"golang.org/x/exp/maps"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthenticatorTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorAPIKey {
+				return false, nil, nil
+			}
+
+			auth, err := newAPIKeyAuthenticator(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type apiKeyAuthenticator struct {
+	id                   string
+	ads                  extractors.AuthDataExtractStrategy
+	keys                 *apiKeyStore
+	allowFallbackOnError bool
+}
+
+func newAPIKeyAuthenticator(id string, rawConfig map[string]any) (*apiKeyAuthenticator, error) {
+	type Config struct {
+		KeySource            extractors.CompositeExtractStrategy `mapstructure:"key_source"`
+		KeysFile             string                              `mapstructure:"keys_file"`
+		AllowFallbackOnError bool                                `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var (
+		conf      Config
+		apiKeys   []apiKeyConfig
+		otherConf = maps.Clone(rawConfig)
+	)
+
+	// keys carry arbitrary attributes, which must not be touched by the
+	// decode hooks used for the remaining configuration
+	delete(otherConf, "keys")
+
+	if err := decodeConfig(otherConf, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to decode api_key authenticator config").
+			CausedBy(err)
+	}
+
+	if err := decodeAPIKeys(rawConfig["keys"], &apiKeys); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to decode api_key authenticator keys").
+			CausedBy(err)
+	}
+
+	if len(apiKeys) == 0 && len(conf.KeysFile) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"api_key authenticator requires either keys or keys_file to be set")
+	}
+
+	keys, err := newAPIKeyStore(apiKeys, conf.KeysFile)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to load keys for api_key authenticator").
+			CausedBy(err)
+	}
+
+	return &apiKeyAuthenticator{
+		id: id,
+		ads: x.IfThenElseExec(conf.KeySource == nil,
+			func() extractors.CompositeExtractStrategy {
+				return extractors.CompositeExtractStrategy{
+					extractors.HeaderValueExtractStrategy{Name: "X-API-Key"},
+				}
+			},
+			func() extractors.CompositeExtractStrategy { return conf.KeySource },
+		),
+		keys:                 keys,
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}, nil
+}
+
+func (a *apiKeyAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using api_key authenticator")
+
+	key, err := a.ads.GetAuthData(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no api key present").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	entry := a.keys.lookup(key)
+	if entry == nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "invalid api key").
+			WithErrorContext(a)
+	}
+
+	return &subject.Subject{ID: entry.subject, Attributes: entry.attributesCopy()}, nil
+}
+
+func (a *apiKeyAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows only the fallback behavior to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		AllowFallbackOnError *bool `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to decode api_key authenticator config").
+			CausedBy(err)
+	}
+
+	return &apiKeyAuthenticator{
+		id:   a.id,
+		ads:  a.ads,
+		keys: a.keys,
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *apiKeyAuthenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *apiKeyAuthenticator) HandlerID() string {
+	return a.id
+}
+
+// Start starts watching the keys file, if configured.
+func (a *apiKeyAuthenticator) Start(logger zerolog.Logger) error { return a.keys.start(logger) }
+
+func (a *apiKeyAuthenticator) Stop() error { return a.keys.stop() }
@@ -0,0 +1,594 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func sha256APIKeyHash(key string) string {
+	md := sha256.Sum256([]byte(key))
+
+	return "sha256:" + hex.EncodeToString(md[:])
+}
+
+func bcryptAPIKeyHash(t *testing.T, key string) string {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(key), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	return string(hash)
+}
+
+func argon2idAPIKeyHash(key string) string {
+	salt := []byte("some-salt-value")
+	hash := argon2.IDKey([]byte(key), salt, 1, 1024, 1, 32)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=1024,t=1,p=1$%s$%s", argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash))
+}
+
+func TestCreateAPIKeyAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	keysFile := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keysFile, []byte(`
+- hash: `+sha256APIKeyHash("foo")+`
+  subject: foo
+`), 0o600))
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *apiKeyAuthenticator)
+	}{
+		{
+			uc:     "without keys",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires either keys or keys_file")
+			},
+		},
+		{
+			uc: "with unsupported properties",
+			config: []byte(`
+keys_file: ` + keysFile + `
+foo: bar`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to decode")
+			},
+		},
+		{
+			uc: "with key without subject",
+			config: []byte(`
+keys:
+  - hash: ` + sha256APIKeyHash("foo")),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no subject set")
+			},
+		},
+		{
+			uc: "with key without hash",
+			config: []byte(`
+keys:
+  - subject: foo`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no hash set")
+			},
+		},
+		{
+			uc: "with malformed sha256 hash",
+			config: []byte(`
+keys:
+  - hash: sha256:foo
+    subject: foo`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "malformed sha256 hash")
+			},
+		},
+		{
+			uc: "with malformed argon2 hash",
+			config: []byte(`
+keys:
+  - hash: $argon2id$v=19$m=1024,t=1$foo$bar
+    subject: foo`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "malformed argon2 hash")
+			},
+		},
+		{
+			uc: "with unsupported hash",
+			config: []byte(`
+keys:
+  - hash: foobar
+    subject: foo`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported hash")
+			},
+		},
+		{
+			uc: "with bcrypt hash without id",
+			config: []byte(`
+keys:
+  - hash: ` + bcryptAPIKeyHash(t, "foo") + `
+    subject: foo`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "require an id")
+			},
+		},
+		{
+			uc: "with argon2 hash and id containing a dot",
+			config: []byte(`
+keys:
+  - id: k.1
+    hash: ` + argon2idAPIKeyHash("k.1.foo") + `
+    subject: foo`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "require an id without dots")
+			},
+		},
+		{
+			uc: "with duplicate ids",
+			config: []byte(`
+keys:
+  - id: k1
+    hash: ` + bcryptAPIKeyHash(t, "k1.foo") + `
+    subject: foo
+  - id: k1
+    hash: ` + argon2idAPIKeyHash("k1.bar") + `
+    subject: bar`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "duplicate id k1")
+			},
+		},
+		{
+			uc:     "with not existing keys file",
+			config: []byte(`keys_file: /no/such/file.yaml`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to read the keys file")
+			},
+		},
+		{
+			uc: "with inline keys and default key source",
+			id: "auth1",
+			config: []byte(`
+keys:
+  - hash: ` + sha256APIKeyHash("foo") + `
+    subject: foo
+  - id: k1
+    hash: ` + bcryptAPIKeyHash(t, "k1.bar") + `
+    subject: bar
+  - id: k2
+    hash: ` + argon2idAPIKeyHash("k2.baz") + `
+    subject: baz
+    attributes:
+      team: a`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "auth1", auth.HandlerID())
+				assert.Equal(t, extractors.CompositeExtractStrategy{
+					extractors.HeaderValueExtractStrategy{Name: "X-API-Key"},
+				}, auth.ads)
+				assert.Len(t, auth.keys.keys.digests, 1)
+				assert.Len(t, auth.keys.keys.slow, 2)
+				assert.Nil(t, auth.keys.w)
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with keys file and custom key source",
+			id: "auth2",
+			config: []byte(`
+keys_file: ` + keysFile + `
+key_source:
+  - header: Authorization
+    schema: ApiKey
+  - query_parameter: api_key
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "auth2", auth.HandlerID())
+				assert.Len(t, auth.ads, 2)
+				assert.Contains(t, auth.ads, &extractors.HeaderValueExtractStrategy{Name: "Authorization", Schema: "ApiKey"})
+				assert.Contains(t, auth.ads, &extractors.QueryParameterExtractStrategy{Name: "api_key"})
+				assert.Len(t, auth.keys.keys.digests, 1)
+				assert.NotNil(t, auth.keys.w)
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newAPIKeyAuthenticator(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateAPIKeyAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *apiKeyAuthenticator, configured *apiKeyAuthenticator)
+	}{
+		{
+			uc: "without target config",
+			assert: func(t *testing.T, err error, prototype *apiKeyAuthenticator, configured *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc: "with not overridable keys",
+			config: []byte(`
+keys:
+  - hash: ` + sha256APIKeyHash("bar") + `
+    subject: bar`),
+			assert: func(t *testing.T, err error, prototype *apiKeyAuthenticator, configured *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "has invalid keys: keys")
+			},
+		},
+		{
+			uc:     "with fallback redefined",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *apiKeyAuthenticator, configured *apiKeyAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.id, configured.id)
+				assert.Equal(t, prototype.ads, configured.ads)
+				assert.Same(t, prototype.keys, configured.keys)
+				assert.False(t, prototype.IsFallbackOnErrorAllowed())
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+keys:
+  - hash: ` + sha256APIKeyHash("foo") + `
+    subject: foo`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newAPIKeyAuthenticator("auth", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				akAuth *apiKeyAuthenticator
+				ok     bool
+			)
+
+			if err == nil {
+				akAuth, ok = auth.(*apiKeyAuthenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, akAuth)
+		})
+	}
+}
+
+func TestAPIKeyAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	type HandlerIdentifier interface {
+		HandlerID() string
+	}
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+keys:
+  - hash: ` + sha256APIKeyHash("foo") + `
+    subject: foo
+  - id: k1
+    hash: ` + bcryptAPIKeyHash(t, "k1.bar") + `
+    subject: bar
+    attributes:
+      team: a
+  - id: k2
+    hash: ` + argon2idAPIKeyHash("k2.baz") + `
+    subject: baz
+    attributes:
+      team: b`))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc     string
+		key    string
+		assert func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc: "no api key present",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no api key present")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth1", identifier.HandlerID())
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:  "unknown api key",
+			key: "qux",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.NotErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "invalid api key")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth1", identifier.HandlerID())
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:  "api key with unknown id",
+			key: "k3.bar",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid api key")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:  "api key with id of another entry",
+			key: "k1.baz",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid api key")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:  "sha256 hashed api key",
+			key: "foo",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{ID: "foo", Attributes: map[string]any{}}, sub)
+			},
+		},
+		{
+			uc:  "bcrypt hashed api key",
+			key: "k1.bar",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{ID: "bar", Attributes: map[string]any{"team": "a"}}, sub)
+			},
+		},
+		{
+			uc:  "argon2id hashed api key",
+			key: "k2.baz",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{ID: "baz", Attributes: map[string]any{"team": "b"}}, sub)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			auth, err := newAPIKeyAuthenticator("auth1", conf)
+			require.NoError(t, err)
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header("X-API-Key").Return(tc.key)
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
+
+func TestAPIKeyAuthenticatorReloadsKeysFile(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	keysFile := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keysFile, []byte(`
+- id: k1
+  hash: `+bcryptAPIKeyHash(t, "k1.foo")+`
+  subject: foo
+`), 0o600))
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+keys_file: ` + keysFile + `
+keys:
+  - hash: ` + sha256APIKeyHash("bar") + `
+    subject: bar`))
+	require.NoError(t, err)
+
+	auth, err := newAPIKeyAuthenticator("auth1", conf)
+	require.NoError(t, err)
+
+	require.NoError(t, auth.Start(zerolog.Nop()))
+	t.Cleanup(func() { auth.Stop() })
+
+	execute := func(key string) (*subject.Subject, error) {
+		fnt := mocks.NewRequestFunctionsMock(t)
+		fnt.EXPECT().Header("X-API-Key").Return(key)
+
+		ctx := mocks.NewContextMock(t)
+		ctx.EXPECT().AppContext().Return(context.Background())
+		ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+
+		return auth.Execute(ctx)
+	}
+
+	sub, err := execute("k1.foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", sub.ID)
+
+	sub, err = execute("bar")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", sub.ID)
+
+	// WHEN
+	require.NoError(t, os.WriteFile(keysFile, []byte(`
+- hash: `+sha256APIKeyHash("baz")+`
+  subject: baz
+`), 0o600))
+
+	// THEN
+	assert.Eventually(t, func() bool {
+		sub, err := execute("baz")
+
+		return err == nil && sub.ID == "baz"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_, err = execute("k1.foo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid api key")
+
+	sub, err = execute("bar")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", sub.ID)
+
+	// WHEN
+	require.NoError(t, os.WriteFile(keysFile, []byte(`foo: bar`), 0o600))
+	time.Sleep(100 * time.Millisecond)
+
+	// THEN
+	sub, err = execute("baz")
+	require.NoError(t, err)
+	assert.Equal(t, "baz", sub.ID)
+}
@@ -0,0 +1,362 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	apiKeyHashPrefixSHA256   = "sha256:"
+	apiKeyHashPrefixArgon2i  = "$argon2i$"
+	apiKeyHashPrefixArgon2id = "$argon2id$"
+	argon2HashElements       = 6
+	apiKeyIDSeparator        = "."
+)
+
+type apiKeyConfig struct {
+	ID         string         `mapstructure:"id"`
+	Hash       string         `mapstructure:"hash"`
+	Subject    string         `mapstructure:"subject"`
+	Attributes map[string]any `mapstructure:"attributes"`
+}
+
+func decodeAPIKeys(input any, output *[]apiKeyConfig) error {
+	dec, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			Result:      output,
+			ErrorUnused: true,
+		})
+	if err != nil {
+		return err
+	}
+
+	return dec.Decode(input)
+}
+
+type apiKeyVerifier func(key []byte) bool
+
+type apiKeyEntry struct {
+	subject    string
+	attributes map[string]any
+	// digest is set for sha256 hashed keys only. All other keys have to be verified
+	// using the verifier, which is expensive by design.
+	digest   string
+	verifier apiKeyVerifier
+}
+
+type apiKeys struct {
+	digests map[string]*apiKeyEntry
+	// slow holds the entries, which have to be verified using the expensive verifier, indexed by
+	// their id. The id is the prefix of the api key, so that each key is verified against a single
+	// entry only.
+	slow map[string]*apiKeyEntry
+}
+
+func newAPIKeys(confs []apiKeyConfig) (*apiKeys, error) {
+	keys := &apiKeys{
+		digests: make(map[string]*apiKeyEntry),
+		slow:    make(map[string]*apiKeyEntry),
+	}
+
+	for idx, conf := range confs {
+		entry, err := newAPIKeyEntry(conf)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"invalid api key entry at index %d", idx).CausedBy(err)
+		}
+
+		if len(entry.digest) != 0 {
+			keys.digests[entry.digest] = entry
+
+			continue
+		}
+
+		if _, exists := keys.slow[conf.ID]; exists {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"invalid api key entry at index %d: duplicate id %s", idx, conf.ID)
+		}
+
+		keys.slow[conf.ID] = entry
+	}
+
+	return keys, nil
+}
+
+func newAPIKeyEntry(conf apiKeyConfig) (*apiKeyEntry, error) {
+	if len(conf.Subject) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "no subject set")
+	}
+
+	entry := &apiKeyEntry{
+		subject:    conf.Subject,
+		attributes: conf.Attributes,
+	}
+
+	switch {
+	case len(conf.Hash) == 0:
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "no hash set")
+	case strings.HasPrefix(conf.Hash, apiKeyHashPrefixSHA256):
+		digest, err := hex.DecodeString(strings.TrimPrefix(conf.Hash, apiKeyHashPrefixSHA256))
+		if err != nil || len(digest) != sha256.Size {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "malformed sha256 hash")
+		}
+
+		entry.digest = hex.EncodeToString(digest)
+	case strings.HasPrefix(conf.Hash, apiKeyHashPrefixArgon2i), strings.HasPrefix(conf.Hash, apiKeyHashPrefixArgon2id):
+		verifier, err := newArgon2Verifier(conf.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.verifier = verifier
+	default:
+		hash := stringx.ToBytes(conf.Hash)
+		if _, err := bcrypt.Cost(hash); err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "unsupported hash").CausedBy(err)
+		}
+
+		entry.verifier = func(key []byte) bool { return bcrypt.CompareHashAndPassword(hash, key) == nil }
+	}
+
+	if entry.verifier != nil && (len(conf.ID) == 0 || strings.Contains(conf.ID, apiKeyIDSeparator)) {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"bcrypt and argon2 hashed keys require an id without dots")
+	}
+
+	return entry, nil
+}
+
+// newArgon2Verifier creates a verifier for hashes encoded in the PHC string format, like
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>, with salt and hash being base64 encoded without padding.
+func newArgon2Verifier(encoded string) (apiKeyVerifier, error) {
+	var (
+		version            int
+		memory, iterations uint32
+		threads            uint8
+		deriveKey          = argon2.IDKey
+		errMalformed       = errorchain.NewWithMessage(heimdall.ErrConfiguration, "malformed argon2 hash")
+	)
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != argon2HashElements {
+		return nil, errMalformed
+	}
+
+	if parts[1] == "argon2i" {
+		deriveKey = argon2.Key
+	}
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, errMalformed
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return nil, errMalformed
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, errMalformed
+	}
+
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(hash) == 0 {
+		return nil, errMalformed
+	}
+
+	return func(key []byte) bool {
+		return subtle.ConstantTimeCompare(hash, deriveKey(key, salt, iterations, memory, threads, uint32(len(hash)))) == 1
+	}, nil
+}
+
+// apiKeyStore holds the configured api keys. Keys loaded from a file are reloaded
+// after the file has been changed.
+type apiKeyStore struct {
+	inline []apiKeyConfig
+	path   string
+	w      *watcher.Watcher
+
+	mut      sync.RWMutex
+	fileHash []byte
+	keys     *apiKeys
+	// verified holds the entries for keys, which have already been verified using the
+	// expensive verifier, indexed by the sha256 digest of the key. It is reset on reload.
+	verified map[string]*apiKeyEntry
+}
+
+func newAPIKeyStore(inline []apiKeyConfig, path string) (*apiKeyStore, error) {
+	store := &apiKeyStore{inline: inline}
+
+	if len(path) == 0 {
+		keys, err := newAPIKeys(inline)
+		if err != nil {
+			return nil, err
+		}
+
+		store.keys = keys
+		store.verified = make(map[string]*apiKeyEntry)
+
+		return store, nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to get the absolute path for the keys file").CausedBy(err)
+	}
+
+	store.path = absPath
+
+	if err = store.load(); err != nil {
+		return nil, err
+	}
+
+	store.w = watcher.New(absPath)
+
+	return store, nil
+}
+
+func (s *apiKeyStore) start(logger zerolog.Logger) error {
+	if s.w == nil {
+		return nil
+	}
+
+	return s.w.Start(logger, s.load)
+}
+
+func (s *apiKeyStore) stop() error {
+	if s.w == nil {
+		return nil
+	}
+
+	return s.w.Stop()
+}
+
+func (s *apiKeyStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to read the keys file").
+			CausedBy(err)
+	}
+
+	hash := sha256.Sum256(data)
+
+	s.mut.RLock()
+	unchanged := bytes.Equal(s.fileHash, hash[:])
+	s.mut.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	var (
+		rawKeys []map[string]any
+		confs   []apiKeyConfig
+	)
+
+	if err = yaml.Unmarshal(data, &rawKeys); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to parse the keys file").
+			CausedBy(err)
+	}
+
+	if err = decodeAPIKeys(rawKeys, &confs); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to decode the keys file").
+			CausedBy(err)
+	}
+
+	keys, err := newAPIKeys(append(confs, s.inline...))
+	if err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	s.fileHash = hash[:]
+	s.keys = keys
+	s.verified = make(map[string]*apiKeyEntry)
+	s.mut.Unlock()
+
+	return nil
+}
+
+func (s *apiKeyStore) lookup(key string) *apiKeyEntry {
+	rawKey := stringx.ToBytes(key)
+	md := sha256.Sum256(rawKey)
+	digest := hex.EncodeToString(md[:])
+
+	s.mut.RLock()
+	keys := s.keys
+	entry, found := keys.digests[digest]
+
+	if !found {
+		entry, found = s.verified[digest]
+	}
+	s.mut.RUnlock()
+
+	if found {
+		return entry
+	}
+
+	// keys verified using the expensive verifier must be prefixed with the id of their entry
+	id, _, ok := strings.Cut(key, apiKeyIDSeparator)
+	if !ok {
+		return nil
+	}
+
+	candidate, found := keys.slow[id]
+	if !found || !candidate.verifier(rawKey) {
+		return nil
+	}
+
+	s.mut.Lock()
+	// the keys might have been reloaded in between
+	if s.keys == keys {
+		s.verified[digest] = candidate
+	}
+	s.mut.Unlock()
+
+	return candidate
+}
+
+func (e *apiKeyEntry) attributesCopy() map[string]any {
+	if e.attributes == nil {
+		return make(map[string]any)
+	}
+
+	return maps.Clone(e.attributes)
+}
@@ -27,4 +27,5 @@ type Authenticator interface {
 	Execute(heimdall.Context) (*subject.Subject, error)
 	WithConfig(config map[string]any) (Authenticator, error)
 	IsFallbackOnErrorAllowed() bool
+	HandlerID() string
 }
@@ -0,0 +1,94 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+)
+
+type distinguishedName struct {
+	DN                 string   `json:"dn"`
+	CommonName         string   `json:"common_name"`
+	SerialNumber       string   `json:"serial_number,omitempty"`
+	Organization       []string `json:"organization,omitempty"`
+	OrganizationalUnit []string `json:"organizational_unit,omitempty"`
+	Country            []string `json:"country,omitempty"`
+	Province           []string `json:"province,omitempty"`
+	Locality           []string `json:"locality,omitempty"`
+}
+
+type subjectAltNames struct {
+	DNS   []string `json:"dns,omitempty"`
+	Email []string `json:"email,omitempty"`
+	URI   []string `json:"uri,omitempty"`
+	IP    []string `json:"ip,omitempty"`
+}
+
+// certificateInfo is the JSON representation of a client certificate, the subject
+// information is extracted from.
+type certificateInfo struct {
+	Subject      distinguishedName `json:"subject"`
+	Issuer       distinguishedName `json:"issuer"`
+	SAN          subjectAltNames   `json:"san"`
+	SerialNumber string            `json:"serial_number"`
+	Fingerprint  string            `json:"fingerprint"`
+	NotBefore    int64             `json:"not_before"`
+	NotAfter     int64             `json:"not_after"`
+}
+
+func newDistinguishedName(name pkix.Name) distinguishedName {
+	return distinguishedName{
+		DN:                 name.String(),
+		CommonName:         name.CommonName,
+		SerialNumber:       name.SerialNumber,
+		Organization:       name.Organization,
+		OrganizationalUnit: name.OrganizationalUnit,
+		Country:            name.Country,
+		Province:           name.Province,
+		Locality:           name.Locality,
+	}
+}
+
+func newCertificateInfo(cert *x509.Certificate) *certificateInfo {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	info := &certificateInfo{
+		Subject:      newDistinguishedName(cert.Subject),
+		Issuer:       newDistinguishedName(cert.Issuer),
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+		NotBefore:    cert.NotBefore.Unix(),
+		NotAfter:     cert.NotAfter.Unix(),
+		SAN: subjectAltNames{
+			DNS:   cert.DNSNames,
+			Email: cert.EmailAddresses,
+		},
+	}
+
+	for _, uri := range cert.URIs {
+		info.SAN.URI = append(info.SAN.URI, uri.String())
+	}
+
+	for _, ip := range cert.IPAddresses {
+		info.SAN.IP = append(info.SAN.IP, ip.String())
+	}
+
+	return info
+}
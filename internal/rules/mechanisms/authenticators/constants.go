@@ -24,4 +24,7 @@ const (
 	AuthenticatorOAuth2Introspection = "oauth2_introspection"
 	AuthenticatorJwt                 = "jwt"
 	AuthenticatorGeneric             = "generic"
+	AuthenticatorMTLS                = "mtls"
+	AuthenticatorAPIKey              = "api_key"
+	AuthenticatorSession             = "session"
 )
@@ -16,7 +16,10 @@
 
 package authenticators
 
-import "gopkg.in/square/go-jose.v2"
+import (
+	"golang.org/x/exp/slices"
+	"gopkg.in/square/go-jose.v2"
+)
 
 func defaultAllowedAlgorithms() []string {
 	// RSA PKCS v1.5 is not allowed by intention
@@ -27,3 +30,36 @@ func defaultAllowedAlgorithms() []string {
 		string(jose.PS256), string(jose.PS384), string(jose.PS512),
 	}
 }
+
+// allowedAlgorithmsFrom returns those algorithms from the given list, like advertised by an
+// authorization server, which are allowed by default. Other algorithms, like RSA PKCS v1.5,
+// must be allowed explicitly via configuration. If none are present, the default ones are returned.
+func allowedAlgorithmsFrom(supported []string) []string {
+	defaults := defaultAllowedAlgorithms()
+
+	var algorithms []string
+
+	for _, alg := range supported {
+		if slices.Contains(defaults, alg) && !slices.Contains(algorithms, alg) {
+			algorithms = append(algorithms, alg)
+		}
+	}
+
+	if len(algorithms) == 0 {
+		return defaults
+	}
+
+	return algorithms
+}
+
+func isAsymmetricAlgorithm(alg string) bool {
+	switch jose.SignatureAlgorithm(alg) {
+	case jose.ES256, jose.ES384, jose.ES512,
+		jose.PS256, jose.PS384, jose.PS512,
+		jose.RS256, jose.RS384, jose.RS512,
+		jose.EdDSA:
+		return true
+	default:
+		return false
+	}
+}
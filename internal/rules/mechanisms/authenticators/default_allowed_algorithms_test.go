@@ -0,0 +1,53 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedAlgorithmsFrom(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc        string
+		supported []string
+		expected  []string
+	}{
+		{uc: "nothing advertised", expected: defaultAllowedAlgorithms()},
+		{
+			uc:        "only algorithms not allowed by default advertised",
+			supported: []string{"none", "HS256", "RS256", "EdDSA"},
+			expected:  defaultAllowedAlgorithms(),
+		},
+		{
+			uc:        "algorithms allowed and not allowed by default advertised",
+			supported: []string{"RS256", "ES384", "PS256", "HS512", "ES384"},
+			expected:  []string{"ES384", "PS256"},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			algorithms := allowedAlgorithmsFrom(tc.supported)
+
+			// THEN
+			assert.Equal(t, tc.expected, algorithms)
+		})
+	}
+}
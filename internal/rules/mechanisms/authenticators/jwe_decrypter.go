@@ -0,0 +1,176 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var errJWEDecryption = errors.New("failed to decrypt JWE")
+
+// nolint: gochecknoglobals
+var (
+	supportedKeyAlgorithms = []string{
+		string(jose.RSA_OAEP), string(jose.RSA_OAEP_256),
+		string(jose.ECDH_ES), string(jose.ECDH_ES_A128KW), string(jose.ECDH_ES_A192KW), string(jose.ECDH_ES_A256KW),
+	}
+
+	supportedContentAlgorithms = []string{
+		string(jose.A128GCM), string(jose.A192GCM), string(jose.A256GCM),
+		string(jose.A128CBC_HS256), string(jose.A192CBC_HS384), string(jose.A256CBC_HS512),
+	}
+)
+
+type jweDecryptionConfig struct {
+	KeyStore struct {
+		Path     string `mapstructure:"path"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"key_store"`
+	KeyAlgorithms     []string `mapstructure:"key_algorithms"`
+	ContentAlgorithms []string `mapstructure:"content_algorithms"`
+}
+
+type jweDecrypter struct {
+	ks                keystore.KeyStore
+	keyAlgorithms     []string
+	contentAlgorithms []string
+}
+
+func newJWEDecrypter(conf *jweDecryptionConfig) (*jweDecrypter, error) {
+	if conf == nil {
+		return nil, nil // nolint: nilnil
+	}
+
+	if len(conf.KeyStore.Path) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"decryption requires key_store path to be set")
+	}
+
+	ks, err := keystore.NewKeyStoreFromPEMFile(conf.KeyStore.Path, conf.KeyStore.Password)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed loading decryption key store").CausedBy(err)
+	}
+
+	keyAlgorithms := x.IfThenElse(len(conf.KeyAlgorithms) != 0, conf.KeyAlgorithms, supportedKeyAlgorithms)
+	for _, alg := range keyAlgorithms {
+		if !slices.Contains(supportedKeyAlgorithms, alg) {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"unsupported key management algorithm %s", alg)
+		}
+	}
+
+	contentAlgorithms := x.IfThenElse(len(conf.ContentAlgorithms) != 0,
+		conf.ContentAlgorithms, supportedContentAlgorithms)
+	for _, alg := range contentAlgorithms {
+		if !slices.Contains(supportedContentAlgorithms, alg) {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"unsupported content encryption algorithm %s", alg)
+		}
+	}
+
+	return &jweDecrypter{
+		ks:                ks,
+		keyAlgorithms:     keyAlgorithms,
+		contentAlgorithms: contentAlgorithms,
+	}, nil
+}
+
+// isEncrypted returns true if the given token is in JWE compact serialization format.
+func (d *jweDecrypter) isEncrypted(rawToken string) bool {
+	const jweCompactDots = 4
+
+	return d != nil && strings.Count(rawToken, ".") == jweCompactDots
+}
+
+// decrypt decrypts the given nested JWT and returns the signed inner one. If the JWE header references
+// a key id, only that key is used. Otherwise, all keys of a matching type are tried.
+func (d *jweDecrypter) decrypt(rawToken string) (*jwt.JSONWebToken, error) {
+	nested, err := jwt.ParseSignedAndEncrypted(rawToken)
+	if err != nil {
+		return nil, errorchain.New(errJWEDecryption).CausedBy(err)
+	}
+
+	header := nested.Headers[0]
+	contentAlg, _ := header.ExtraHeaders["enc"].(string)
+
+	if !slices.Contains(d.keyAlgorithms, header.Algorithm) {
+		return nil, errorchain.NewWithMessagef(errJWEDecryption,
+			"key management algorithm %s is not allowed", header.Algorithm)
+	}
+
+	if !slices.Contains(d.contentAlgorithms, contentAlg) {
+		return nil, errorchain.NewWithMessagef(errJWEDecryption,
+			"content encryption algorithm %s is not allowed", contentAlg)
+	}
+
+	entries, err := d.keysFor(header)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if token, err := nested.Decrypt(entry.PrivateKey); err == nil {
+			return token, nil
+		}
+	}
+
+	return nil, errorchain.NewWithMessage(errJWEDecryption, "none of the available keys could decrypt the token")
+}
+
+func (d *jweDecrypter) keysFor(header jose.Header) ([]*keystore.Entry, error) {
+	if len(header.KeyID) != 0 {
+		entry, err := d.ks.GetKey(header.KeyID)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(errJWEDecryption, "no key for referenced kid").
+				CausedBy(err)
+		}
+
+		return []*keystore.Entry{entry}, nil
+	}
+
+	isRSA := strings.HasPrefix(header.Algorithm, "RSA")
+
+	var entries []*keystore.Entry
+
+	for _, entry := range d.ks.Entries() {
+		switch entry.PrivateKey.(type) {
+		case *rsa.PrivateKey:
+			if isRSA {
+				entries = append(entries, entry)
+			}
+		case *ecdsa.PrivateKey:
+			if !isRSA {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries, nil
+}
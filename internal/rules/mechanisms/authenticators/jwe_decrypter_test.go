@@ -0,0 +1,328 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+)
+
+func createJWE(
+	t *testing.T, key crypto.PublicKey, kid string, alg jose.KeyAlgorithm, enc jose.ContentEncryption, payload string,
+) string {
+	t.Helper()
+
+	opts := (&jose.EncrypterOptions{}).WithContentType("JWT")
+	if len(kid) != 0 {
+		opts = opts.WithHeader("kid", kid)
+	}
+
+	encrypter, err := jose.NewEncrypter(enc, jose.Recipient{Algorithm: alg, Key: key}, opts)
+	require.NoError(t, err)
+
+	obj, err := encrypter.Encrypt([]byte(payload))
+	require.NoError(t, err)
+
+	raw, err := obj.CompactSerialize()
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestNewJWEDecrypter(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithECDSAPrivateKey(ecKey, pemx.WithHeader("X-Key-ID", "foo")))
+	require.NoError(t, err)
+
+	file, err := os.CreateTemp("", "test-jwe-decrypter-*")
+	require.NoError(t, err)
+
+	_, err = file.Write(pemBytes)
+	require.NoError(t, err)
+
+	defer os.Remove(file.Name())
+
+	for _, tc := range []struct {
+		uc     string
+		conf   *jweDecryptionConfig
+		assert func(t *testing.T, err error, dec *jweDecrypter)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, dec *jweDecrypter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Nil(t, dec)
+			},
+		},
+		{
+			uc:   "without key store path",
+			conf: &jweDecryptionConfig{},
+			assert: func(t *testing.T, err error, _ *jweDecrypter) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "key_store path")
+			},
+		},
+		{
+			uc: "with not existing key store",
+			conf: func() *jweDecryptionConfig {
+				conf := &jweDecryptionConfig{}
+				conf.KeyStore.Path = "/does/not/exist.pem"
+
+				return conf
+			}(),
+			assert: func(t *testing.T, err error, _ *jweDecrypter) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "key store")
+			},
+		},
+		{
+			uc: "with unsupported key management algorithm",
+			conf: func() *jweDecryptionConfig {
+				conf := &jweDecryptionConfig{KeyAlgorithms: []string{string(jose.RSA1_5)}}
+				conf.KeyStore.Path = file.Name()
+
+				return conf
+			}(),
+			assert: func(t *testing.T, err error, _ *jweDecrypter) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported key management algorithm RSA1_5")
+			},
+		},
+		{
+			uc: "with unsupported content encryption algorithm",
+			conf: func() *jweDecryptionConfig {
+				conf := &jweDecryptionConfig{ContentAlgorithms: []string{"foo"}}
+				conf.KeyStore.Path = file.Name()
+
+				return conf
+			}(),
+			assert: func(t *testing.T, err error, _ *jweDecrypter) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported content encryption algorithm foo")
+			},
+		},
+		{
+			uc: "with defaults",
+			conf: func() *jweDecryptionConfig {
+				conf := &jweDecryptionConfig{}
+				conf.KeyStore.Path = file.Name()
+
+				return conf
+			}(),
+			assert: func(t *testing.T, err error, dec *jweDecrypter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, dec)
+				assert.Len(t, dec.ks.Entries(), 1)
+				assert.Equal(t, supportedKeyAlgorithms, dec.keyAlgorithms)
+				assert.Equal(t, supportedContentAlgorithms, dec.contentAlgorithms)
+			},
+		},
+		{
+			uc: "with restricted algorithms",
+			conf: func() *jweDecryptionConfig {
+				conf := &jweDecryptionConfig{
+					KeyAlgorithms:     []string{"ECDH-ES"},
+					ContentAlgorithms: []string{"A256GCM"},
+				}
+				conf.KeyStore.Path = file.Name()
+
+				return conf
+			}(),
+			assert: func(t *testing.T, err error, dec *jweDecrypter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, dec)
+				assert.Equal(t, []string{"ECDH-ES"}, dec.keyAlgorithms)
+				assert.Equal(t, []string{"A256GCM"}, dec.contentAlgorithms)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			dec, err := newJWEDecrypter(tc.conf)
+
+			// THEN
+			tc.assert(t, err, dec)
+		})
+	}
+}
+
+func TestJWEDecrypterDecrypt(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(
+		pemx.WithECDSAPrivateKey(ecKey, pemx.WithHeader("X-Key-ID", "ec")),
+		pemx.WithRSAPrivateKey(rsaKey, pemx.WithHeader("X-Key-ID", "rsa")),
+	)
+	require.NoError(t, err)
+
+	ks, err := keystore.NewKeyStoreFromPEMBytes(pemBytes, "")
+	require.NoError(t, err)
+
+	signedJWT := createJWT(t, &keystore.Entry{
+		KeyID: "ec", Alg: keystore.AlgECDSA, KeySize: 384, PrivateKey: ecKey,
+	}, "foo", "bar", "baz", true)
+
+	dec := &jweDecrypter{
+		ks:                ks,
+		keyAlgorithms:     []string{string(jose.RSA_OAEP_256), string(jose.ECDH_ES_A256KW)},
+		contentAlgorithms: []string{string(jose.A256GCM)},
+	}
+
+	for _, tc := range []struct {
+		uc     string
+		token  string
+		assert func(t *testing.T, err error)
+	}{
+		{
+			uc:    "not a JWE",
+			token: "foo.bar.baz.bam.ban",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errJWEDecryption)
+			},
+		},
+		{
+			uc: "with not allowed key management algorithm",
+			token: createJWE(t, &rsaKey.PublicKey, "rsa",
+				jose.RSA_OAEP, jose.A256GCM, signedJWT),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errJWEDecryption)
+				assert.Contains(t, err.Error(), "key management algorithm RSA-OAEP is not allowed")
+			},
+		},
+		{
+			uc: "with not allowed content encryption algorithm",
+			token: createJWE(t, &rsaKey.PublicKey, "rsa",
+				jose.RSA_OAEP_256, jose.A128GCM, signedJWT),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errJWEDecryption)
+				assert.Contains(t, err.Error(), "content encryption algorithm A128GCM is not allowed")
+			},
+		},
+		{
+			uc: "with unknown kid",
+			token: createJWE(t, &rsaKey.PublicKey, "foo",
+				jose.RSA_OAEP_256, jose.A256GCM, signedJWT),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errJWEDecryption)
+				assert.ErrorIs(t, err, keystore.ErrNoSuchKey)
+			},
+		},
+		{
+			uc: "encrypted for an unknown key",
+			token: createJWE(t, &otherKey.PublicKey, "",
+				jose.ECDH_ES_A256KW, jose.A256GCM, signedJWT),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errJWEDecryption)
+				assert.Contains(t, err.Error(), "none of the available keys")
+			},
+		},
+		{
+			uc: "successful with referenced rsa key",
+			token: createJWE(t, &rsaKey.PublicKey, "rsa",
+				jose.RSA_OAEP_256, jose.A256GCM, signedJWT),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc: "successful without referenced ec key",
+			token: createJWE(t, &ecKey.PublicKey, "",
+				jose.ECDH_ES_A256KW, jose.A256GCM, signedJWT),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			token, err := dec.decrypt(tc.token)
+
+			// THEN
+			tc.assert(t, err)
+
+			if err == nil {
+				var claims map[string]any
+
+				require.NoError(t, token.Claims(&ecKey.PublicKey, &claims))
+				assert.Equal(t, "foo", claims["sub"])
+			}
+		})
+	}
+}
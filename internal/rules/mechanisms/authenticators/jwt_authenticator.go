@@ -32,6 +32,7 @@ import (
 	"gopkg.in/square/go-jose.v2/jwt"
 
 	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/codec"
 	"github.com/dadrus/heimdall/internal/endpoint"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
@@ -50,6 +51,8 @@ const defaultJWTAuthenticatorTTL = 10 * time.Minute
 //
 //nolint:gochecknoinits
 func init() {
+	codec.Register(&jose.JSONWebKey{})
+
 	registerAuthenticatorTypeFactory(
 		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
 			if typ != AuthenticatorJwt {
@@ -65,6 +68,7 @@ func init() {
 type jwtAuthenticator struct {
 	id                   string
 	e                    endpoint.Endpoint
+	md                   oauth2.ServerMetadataResolver
 	a                    oauth2.Expectation
 	ttl                  *time.Duration
 	sf                   SubjectFactory
@@ -72,11 +76,26 @@ type jwtAuthenticator struct {
 	allowFallbackOnError bool
 	trustStore           truststore.TrustStore
 	validateJWKCert      bool
+	dec                  *jweDecrypter
+	sc                   *senderConstraintVerifier
+	// issuers holds the issuer specific authenticators, if multiple issuers are configured.
+	// In that case the one to use is selected by the iss claim of the JWT.
+	issuers map[string]*jwtAuthenticator
+}
+
+type jwtIssuerConfig struct {
+	Issuer           string              `mapstructure:"issuer"`
+	Endpoint         endpoint.Endpoint   `mapstructure:"jwks_endpoint"`
+	MetadataEndpoint *endpoint.Endpoint  `mapstructure:"metadata_endpoint"`
+	Assertions       *oauth2.Expectation `mapstructure:"assertions"`
+	SubjectInfo      *SubjectInfo        `mapstructure:"subject"`
 }
 
 func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator, error) { // nolint: funlen
 	type Config struct {
 		Endpoint             endpoint.Endpoint                   `mapstructure:"jwks_endpoint"`
+		MetadataEndpoint     *endpoint.Endpoint                  `mapstructure:"metadata_endpoint"`
+		Issuers              []jwtIssuerConfig                   `mapstructure:"issuers"`
 		AuthDataSource       extractors.CompositeExtractStrategy `mapstructure:"jwt_source"`
 		Assertions           oauth2.Expectation                  `mapstructure:"assertions"`
 		SubjectInfo          SubjectInfo                         `mapstructure:"subject"`
@@ -84,6 +103,8 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 		AllowFallbackOnError bool                                `mapstructure:"allow_fallback_on_error"`
 		ValidateJWK          *bool                               `mapstructure:"validate_jwk"`
 		TrustStore           truststore.TrustStore               `mapstructure:"trust_store"`
+		Decryption           *jweDecryptionConfig                `mapstructure:"decryption"`
+		SenderConstraint     *senderConstraintConfig             `mapstructure:"sender_constraint"`
 	}
 
 	var (
@@ -97,37 +118,6 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 			CausedBy(err)
 	}
 
-	if err = conf.Endpoint.Validate(); err != nil {
-		return nil, errorchain.
-			NewWithMessage(heimdall.ErrConfiguration, "failed to validate endpoint configuration").
-			CausedBy(err)
-	}
-
-	if len(conf.Assertions.TrustedIssuers) == 0 {
-		return nil, errorchain.
-			NewWithMessage(heimdall.ErrConfiguration, "no trusted issuers configured")
-	}
-
-	if conf.Endpoint.Headers == nil {
-		conf.Endpoint.Headers = make(map[string]string)
-	}
-
-	if _, ok := conf.Endpoint.Headers["Accept-Type"]; !ok {
-		conf.Endpoint.Headers["Accept-Type"] = "application/json"
-	}
-
-	if len(conf.Endpoint.Method) == 0 {
-		conf.Endpoint.Method = "GET"
-	}
-
-	if len(conf.Assertions.AllowedAlgorithms) == 0 {
-		conf.Assertions.AllowedAlgorithms = defaultAllowedAlgorithms()
-	}
-
-	if conf.Assertions.ScopesMatcher == nil {
-		conf.Assertions.ScopesMatcher = oauth2.NoopMatcher{}
-	}
-
 	if len(conf.SubjectInfo.IDFrom) == 0 {
 		conf.SubjectInfo.IDFrom = "sub"
 	}
@@ -147,9 +137,20 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 		func() extractors.CompositeExtractStrategy { return conf.AuthDataSource },
 	)
 
-	return &jwtAuthenticator{
+	dec, err := newJWEDecrypter(conf.Decryption)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := newSenderConstraintVerifier(conf.SenderConstraint)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &jwtAuthenticator{
 		id:                   id,
-		e:                    conf.Endpoint,
+		dec:                  dec,
+		sc:                   sc,
 		a:                    conf.Assertions,
 		ttl:                  conf.CacheTTL,
 		sf:                   &conf.SubjectInfo,
@@ -157,7 +158,130 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 		allowFallbackOnError: conf.AllowFallbackOnError,
 		validateJWKCert:      validateJWKCert,
 		trustStore:           conf.TrustStore,
-	}, nil
+	}
+
+	if len(conf.Issuers) == 0 {
+		auth.e, auth.md, err = newJWKSSource(conf.Endpoint, conf.MetadataEndpoint, &auth.a)
+		if err != nil {
+			return nil, err
+		}
+
+		return auth, nil
+	}
+
+	if len(conf.Endpoint.URL) != 0 || conf.MetadataEndpoint != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"jwks_endpoint and metadata_endpoint cannot be used together with issuers")
+	}
+
+	auth.issuers = make(map[string]*jwtAuthenticator, len(conf.Issuers))
+
+	for idx, ic := range conf.Issuers {
+		if len(ic.Issuer) == 0 {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"no issuer configured for %d entry in issuers", idx)
+		}
+
+		if _, ok := auth.issuers[ic.Issuer]; ok {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"issuer %s is configured multiple times", ic.Issuer)
+		}
+
+		issuerAuth, err := auth.newIssuerAuthenticator(ic)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to configure issuer %s", ic.Issuer).CausedBy(err)
+		}
+
+		auth.issuers[ic.Issuer] = issuerAuth
+	}
+
+	return auth, nil
+}
+
+// newJWKSSource validates the given jwks and metadata endpoint configuration and applies
+// the defaults to the endpoint and the assertions.
+func newJWKSSource(
+	ep endpoint.Endpoint, mdEp *endpoint.Endpoint, assertions *oauth2.Expectation,
+) (endpoint.Endpoint, oauth2.ServerMetadataResolver, error) {
+	md, err := newServerMetadataResolver(mdEp)
+	if err != nil {
+		return ep, nil, err
+	}
+
+	// the url of the jwks endpoint can be taken from the metadata
+	if md == nil || len(ep.URL) != 0 {
+		if err = ep.Validate(); err != nil {
+			return ep, nil, errorchain.
+				NewWithMessage(heimdall.ErrConfiguration, "failed to validate endpoint configuration").
+				CausedBy(err)
+		}
+	}
+
+	// the issuer can be taken from the metadata
+	if md == nil && len(assertions.TrustedIssuers) == 0 {
+		return ep, nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "no trusted issuers configured")
+	}
+
+	if ep.Headers == nil {
+		ep.Headers = make(map[string]string)
+	}
+
+	if _, ok := ep.Headers["Accept-Type"]; !ok {
+		ep.Headers["Accept-Type"] = "application/json"
+	}
+
+	if len(ep.Method) == 0 {
+		ep.Method = "GET"
+	}
+
+	// if metadata is used, the allowed algorithms are derived from it
+	if md == nil && len(assertions.AllowedAlgorithms) == 0 {
+		assertions.AllowedAlgorithms = defaultAllowedAlgorithms()
+	}
+
+	if assertions.ScopesMatcher == nil {
+		assertions.ScopesMatcher = oauth2.NoopMatcher{}
+	}
+
+	return ep, md, nil
+}
+
+// newIssuerAuthenticator creates an authenticator for the given issuer. Assertions and subject
+// not configured for the issuer are taken from the given authenticator.
+func (a *jwtAuthenticator) newIssuerAuthenticator(conf jwtIssuerConfig) (*jwtAuthenticator, error) {
+	var err error
+
+	sf := a.sf
+
+	if conf.SubjectInfo != nil {
+		if len(conf.SubjectInfo.IDFrom) == 0 {
+			conf.SubjectInfo.IDFrom = "sub"
+		}
+
+		sf = conf.SubjectInfo
+	}
+
+	auth := &jwtAuthenticator{
+		id:                   a.id,
+		a:                    conf.Assertions.Merge(&a.a),
+		ttl:                  a.ttl,
+		sf:                   sf,
+		allowFallbackOnError: a.allowFallbackOnError,
+		validateJWKCert:      a.validateJWKCert,
+		trustStore:           a.trustStore,
+	}
+
+	// tokens are routed to this authenticator by their iss claim
+	auth.a.TrustedIssuers = []string{conf.Issuer}
+
+	auth.e, auth.md, err = newJWKSSource(conf.Endpoint, conf.MetadataEndpoint, &auth.a)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
 }
 
 func (a *jwtAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
@@ -172,21 +296,34 @@ func (a *jwtAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, erro
 			CausedBy(err)
 	}
 
-	token, err := jwt.ParseSigned(jwtAd)
+	token, err := a.parseToken(jwtAd)
 	if err != nil {
-		return nil, errorchain.
-			NewWithMessage(heimdall.ErrAuthentication, "failed to parse JWT").
-			WithErrorContext(a).
-			CausedBy(heimdall.ErrArgument).
-			CausedBy(err)
+		return nil, err
+	}
+
+	auth, err := a.issuerAuthenticator(token)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err = auth.withServerMetadata(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	rawClaims, err := a.verifyToken(ctx, token)
+	rawClaims, err := auth.verifyToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
 
-	sub, err := a.sf.CreateSubject(rawClaims)
+	if err = a.sc.verify(ctx, jwtAd, rawClaims); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "sender constraint verification failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	sub, err := auth.sf.CreateSubject(rawClaims)
 	if err != nil {
 		return nil, errorchain.
 			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from jwt").
@@ -216,19 +353,110 @@ func (a *jwtAuthenticator) WithConfig(config map[string]any) (Authenticator, err
 			CausedBy(err)
 	}
 
+	return a.withConfig(conf.Assertions, conf.CacheTTL, conf.AllowFallbackOnError), nil
+}
+
+func (a *jwtAuthenticator) withConfig(
+	assertions *oauth2.Expectation, ttl *time.Duration, allowFallbackOnError *bool,
+) *jwtAuthenticator {
+	var issuers map[string]*jwtAuthenticator
+
+	if len(a.issuers) != 0 {
+		issuers = make(map[string]*jwtAuthenticator, len(a.issuers))
+
+		for iss, issuerAuth := range a.issuers {
+			auth := issuerAuth.withConfig(copyExpectation(assertions), ttl, allowFallbackOnError)
+			// the issuer is bound to the entry and cannot be overridden
+			auth.a.TrustedIssuers = issuerAuth.a.TrustedIssuers
+
+			issuers[iss] = auth
+		}
+	}
+
 	return &jwtAuthenticator{
 		id:  a.id,
 		e:   a.e,
-		a:   conf.Assertions.Merge(&a.a),
-		ttl: x.IfThenElse(conf.CacheTTL != nil, conf.CacheTTL, a.ttl),
+		md:  a.md,
+		a:   copyExpectation(assertions).Merge(&a.a),
+		ttl: x.IfThenElse(ttl != nil, ttl, a.ttl),
 		sf:  a.sf,
 		ads: a.ads,
-		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
-			func() bool { return *conf.AllowFallbackOnError },
+		allowFallbackOnError: x.IfThenElseExec(allowFallbackOnError != nil,
+			func() bool { return *allowFallbackOnError },
 			func() bool { return a.allowFallbackOnError }),
 		validateJWKCert: a.validateJWKCert,
 		trustStore:      a.trustStore,
-	}, nil
+		dec:             a.dec,
+		sc:              a.sc,
+		issuers:         issuers,
+	}
+}
+
+// parseToken parses the given signed JWT. If decryption is configured and the token
+// is a JWE, it is decrypted first and the nested signed JWT is returned.
+func (a *jwtAuthenticator) parseToken(rawToken string) (*jwt.JSONWebToken, error) {
+	if a.dec.isEncrypted(rawToken) {
+		token, err := a.dec.decrypt(rawToken)
+		if err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "failed to decrypt JWT").
+				WithErrorContext(a).
+				CausedBy(heimdall.ErrArgument).
+				CausedBy(err)
+		}
+
+		return token, nil
+	}
+
+	token, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to parse JWT").
+			WithErrorContext(a).
+			CausedBy(heimdall.ErrArgument).
+			CausedBy(err)
+	}
+
+	return token, nil
+}
+
+// issuerAuthenticator returns the authenticator responsible for the issuer of the given token.
+// The iss claim is read without verifying the token. The verification happens afterwards by the
+// returned authenticator, which does only trust the issuer it is configured for.
+func (a *jwtAuthenticator) issuerAuthenticator(token *jwt.JSONWebToken) (*jwtAuthenticator, error) {
+	if len(a.issuers) == 0 {
+		return a, nil
+	}
+
+	var claims jwt.Claims
+	if err := token.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to read claims from JWT").
+			WithErrorContext(a).
+			CausedBy(heimdall.ErrArgument).
+			CausedBy(err)
+	}
+
+	auth, ok := a.issuers[claims.Issuer]
+	if !ok {
+		return nil, errorchain.
+			NewWithMessagef(heimdall.ErrAuthentication, "issuer %s is not trusted", claims.Issuer).
+			WithErrorContext(a)
+	}
+
+	return auth, nil
+}
+
+// copyExpectation returns a copy of the given expectation, as oauth2.Expectation.Merge
+// modifies the object it is called on.
+func copyExpectation(e *oauth2.Expectation) *oauth2.Expectation {
+	if e == nil {
+		return nil
+	}
+
+	cp := *e
+
+	return &cp
 }
 
 func (a *jwtAuthenticator) IsFallbackOnErrorAllowed() bool {
@@ -239,6 +467,50 @@ func (a *jwtAuthenticator) HandlerID() string {
 	return a.id
 }
 
+// RequiresCache reports whether DPoP bound tokens are accepted, as the detection of replayed
+// DPoP proofs relies on the cache.
+func (a *jwtAuthenticator) RequiresCache() bool {
+	return a.sc.requiresCache()
+}
+
+// withServerMetadata returns an authenticator with the jwks endpoint url, the trusted issuers and the
+// allowed algorithms taken from the authorization server metadata, if these are not configured explicitly.
+func (a *jwtAuthenticator) withServerMetadata(ctx heimdall.Context) (*jwtAuthenticator, error) {
+	if a.md == nil {
+		return a, nil
+	}
+
+	md, err := a.md.Get(ctx.AppContext())
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "failed to retrieve authorization server metadata").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	auth := *a
+
+	if len(auth.e.URL) == 0 {
+		if len(md.JWKSEndpointURL) == 0 {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrInternal, "authorization server metadata does not contain jwks_uri").
+				WithErrorContext(a)
+		}
+
+		auth.e.URL = md.JWKSEndpointURL
+	}
+
+	if len(auth.a.TrustedIssuers) == 0 {
+		auth.a.TrustedIssuers = []string{md.Issuer}
+	}
+
+	if len(auth.a.AllowedAlgorithms) == 0 {
+		auth.a.AllowedAlgorithms = allowedAlgorithmsFrom(md.IDTokenSigningAlgorithms)
+	}
+
+	return &auth, nil
+}
+
 func (a *jwtAuthenticator) isCacheEnabled() bool {
 	// cache is enabled if ttl is not configured (in that case the ttl value from either
 	// the jwk cert (if available) or the defaultTTL is used), or if ttl is configured and
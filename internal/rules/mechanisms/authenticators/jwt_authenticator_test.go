@@ -34,6 +34,7 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/square/go-jose.v2"
 	"gopkg.in/square/go-jose.v2/jwt"
@@ -341,6 +342,236 @@ trust_store: ` + trustStorePath),
 				assert.Equal(t, "auth1", auth.HandlerID())
 			},
 		},
+		{
+			uc: "with invalid metadata endpoint config",
+			config: []byte(`
+metadata_endpoint:
+  method: GET
+`),
+			assert: func(t *testing.T, err error, a *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "metadata endpoint configuration")
+			},
+		},
+		{
+			uc: "with metadata endpoint config only",
+			id: "auth1",
+			config: []byte(`
+metadata_endpoint:
+  url: http://test.com/.well-known/openid-configuration
+`),
+			assert: func(t *testing.T, err error, a *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.NotNil(t, a.md)
+				assert.Empty(t, a.e.URL)
+				assert.Equal(t, "GET", a.e.Method)
+				assert.Empty(t, a.a.TrustedIssuers)
+				assert.Empty(t, a.a.AllowedAlgorithms)
+				assert.Equal(t, "auth1", a.HandlerID())
+			},
+		},
+		{
+			uc: "with metadata endpoint and explicitly configured jwks endpoint and assertions",
+			id: "auth1",
+			config: []byte(`
+metadata_endpoint:
+  url: http://test.com/.well-known/openid-configuration
+jwks_endpoint:
+  url: http://test.com/jwks
+assertions:
+  issuers:
+    - foobar
+  allowed_algorithms:
+    - ES256
+`),
+			assert: func(t *testing.T, err error, a *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.NotNil(t, a.md)
+				assert.Equal(t, "http://test.com/jwks", a.e.URL)
+				assert.Equal(t, []string{"foobar"}, a.a.TrustedIssuers)
+				assert.Equal(t, []string{"ES256"}, a.a.AllowedAlgorithms)
+			},
+		},
+		{
+			uc: "with invalid sender constraint configuration",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+assertions:
+  issuers:
+    - foobar
+sender_constraint:
+  required: true
+`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "sender_constraint")
+			},
+		},
+		{
+			uc: "with sender constraint configuration",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+assertions:
+  issuers:
+    - foobar
+sender_constraint:
+  dpop:
+    proof_max_age: 30s
+  certificate_bound: true
+`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, auth.sc)
+				assert.True(t, auth.sc.dpopEnabled)
+				assert.True(t, auth.sc.certificateBound)
+				assert.False(t, auth.sc.required)
+				assert.Equal(t, 30*time.Second, auth.sc.proofMaxAge)
+			},
+		},
+		{
+			uc: "with issuers and jwks endpoint",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+issuers:
+  - issuer: foo
+    jwks_endpoint:
+      url: http://foo.com
+`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "cannot be used together with issuers")
+			},
+		},
+		{
+			uc: "with issuers entry without issuer",
+			config: []byte(`
+issuers:
+  - jwks_endpoint:
+      url: http://foo.com
+`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no issuer configured")
+			},
+		},
+		{
+			uc: "with issuers entry without jwks and metadata endpoint",
+			config: []byte(`
+issuers:
+  - issuer: foo
+`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "issuer foo")
+				assert.Contains(t, err.Error(), "endpoint configuration")
+			},
+		},
+		{
+			uc: "with duplicate issuers entries",
+			config: []byte(`
+issuers:
+  - issuer: foo
+    jwks_endpoint:
+      url: http://foo.com
+  - issuer: foo
+    jwks_endpoint:
+      url: http://bar.com
+`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "configured multiple times")
+			},
+		},
+		{
+			uc: "with multiple issuers",
+			id: "auth1",
+			config: []byte(`
+issuers:
+  - issuer: foo
+    jwks_endpoint:
+      url: http://foo.com
+    assertions:
+      audience:
+        - baz
+      allowed_algorithms:
+        - RS256
+    subject:
+      id: some_claim
+  - issuer: bar
+    metadata_endpoint:
+      url: http://bar.com/.well-known/openid-configuration
+assertions:
+  issuers:
+    - zab
+  audience:
+    - zab
+  validity_leeway: 5s
+cache_ttl: 5s
+allow_fallback_on_error: true
+`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, "auth1", auth.HandlerID())
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+				require.Len(t, auth.issuers, 2)
+
+				foo := auth.issuers["foo"]
+				require.NotNil(t, foo)
+				assert.Equal(t, "auth1", foo.HandlerID())
+				assert.Equal(t, "http://foo.com", foo.e.URL)
+				assert.Nil(t, foo.md)
+				assert.Equal(t, []string{"foo"}, foo.a.TrustedIssuers)
+				assert.Equal(t, []string{"baz"}, foo.a.TargetAudiences)
+				assert.Equal(t, []string{"RS256"}, foo.a.AllowedAlgorithms)
+				assert.Equal(t, 5*time.Second, foo.a.ValidityLeeway)
+				assert.Equal(t, oauth2.NoopMatcher{}, foo.a.ScopesMatcher)
+				assert.Equal(t, "some_claim", foo.sf.(*SubjectInfo).IDFrom) // nolint: forcetypeassert
+				assert.Equal(t, 5*time.Second, *foo.ttl)
+				assert.True(t, foo.IsFallbackOnErrorAllowed())
+
+				bar := auth.issuers["bar"]
+				require.NotNil(t, bar)
+				assert.Empty(t, bar.e.URL)
+				assert.NotNil(t, bar.md)
+				assert.Equal(t, []string{"bar"}, bar.a.TrustedIssuers)
+				assert.Equal(t, []string{"zab"}, bar.a.TargetAudiences)
+				assert.Empty(t, bar.a.AllowedAlgorithms)
+				assert.Equal(t, auth.sf, bar.sf)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			conf, err := testsupport.DecodeTestConfig(tc.config)
@@ -683,6 +914,47 @@ cache_ttl: 5s`),
 				assert.Contains(t, err.Error(), "has invalid keys: validate_jwk")
 			},
 		},
+		{
+			uc: "prototype with multiple issuers and overridden assertions",
+			prototypeConfig: []byte(`
+issuers:
+  - issuer: foo
+    jwks_endpoint:
+      url: http://foo.com
+    assertions:
+      audience:
+        - baz
+  - issuer: bar
+    jwks_endpoint:
+      url: http://bar.com
+`),
+			config: []byte(`
+assertions:
+  issuers:
+    - zab
+  allowed_algorithms:
+    - RS512
+cache_ttl: 1s
+`),
+			assert: func(t *testing.T, err error, prototype *jwtAuthenticator, configured *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, configured.issuers, 2)
+
+				for iss, auth := range configured.issuers {
+					assert.NotEqual(t, prototype.issuers[iss], auth)
+					assert.Equal(t, []string{iss}, auth.a.TrustedIssuers)
+					assert.Equal(t, []string{"RS512"}, auth.a.AllowedAlgorithms)
+					assert.Equal(t, prototype.issuers[iss].a.TargetAudiences, auth.a.TargetAudiences)
+					assert.Equal(t, prototype.issuers[iss].e, auth.e)
+					assert.Equal(t, time.Second, *auth.ttl)
+				}
+
+				assert.Equal(t, []string{"ES256", "ES384", "ES512", "PS256", "PS384", "PS512"},
+					prototype.issuers["foo"].a.AllowedAlgorithms)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			pc, err := testsupport.DecodeTestConfig(tc.prototypeConfig)
@@ -789,6 +1061,29 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 	}))
 	defer srv.Close()
 
+	mdSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md := map[string]any{"issuer": issuer}
+
+		switch r.URL.Path {
+		case "/with-jwks":
+			md["jwks_uri"] = srv.URL
+			md["id_token_signing_alg_values_supported"] = []string{"none", "HS256", "ES384"}
+		case "/without-jwks":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		rawMD, err := json.Marshal(md)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write(rawMD)
+		assert.NoError(t, err)
+	}))
+	defer mdSrv.Close()
+
 	for _, tc := range []struct {
 		uc             string
 		authenticator  *jwtAuthenticator
@@ -1807,6 +2102,337 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 				assert.Equal(t, "auth3", identifier.HandlerID())
 			},
 		},
+		{
+			uc: "with failing metadata retrieval",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				md: oauth2.NewServerMetadataResolver(endpoint.Endpoint{URL: mdSrv.URL + "/foo"}),
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "authorization server metadata")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth3", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "with metadata not containing jwks_uri",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				md: oauth2.NewServerMetadataResolver(endpoint.Endpoint{URL: mdSrv.URL + "/without-jwks"}),
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "jwks_uri")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth3", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "successful with jwks endpoint, issuer and algorithms from metadata",
+			authenticator: &jwtAuthenticator{
+				e: endpoint.Endpoint{
+					Headers: map[string]string{"Accept": "application/json"},
+				},
+				md: oauth2.NewServerMetadataResolver(endpoint.Endpoint{URL: mdSrv.URL + "/with-jwks"}),
+				a: oauth2.Expectation{
+					ScopesMatcher: oauth2.ExactScopeStrategyMatcher{},
+				},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &disabledTTL,
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkRequest = func(req *http.Request) {
+					assert.Equal(t, "application/json", req.Header.Get("Accept"))
+				}
+
+				responseCode = http.StatusOK
+				responseContent = jwksWithOneKeyOnlyEntry
+				responseContentType = "application/json"
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, subjectID, sub.ID)
+				assert.Equal(t, issuer, sub.Attributes["iss"])
+			},
+		},
+		{
+			uc: "with multiple issuers and untrusted issuer",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				issuers: map[string]*jwtAuthenticator{
+					"barfoo": {id: "auth3", e: endpoint.Endpoint{URL: srv.URL}},
+				},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "issuer foobar is not trusted")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth3", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "successful with multiple issuers",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				issuers: map[string]*jwtAuthenticator{
+					"barfoo": {
+						id: "auth3",
+						e:  endpoint.Endpoint{URL: srv.URL + "/barfoo"},
+						a: oauth2.Expectation{
+							AllowedAlgorithms: []string{"ES384"},
+							TrustedIssuers:    []string{"barfoo"},
+							ScopesMatcher:     oauth2.NoopMatcher{},
+						},
+						sf:  &SubjectInfo{IDFrom: "sub"},
+						ttl: &disabledTTL,
+					},
+					issuer: {
+						id: "auth3",
+						e:  endpoint.Endpoint{URL: srv.URL + "/" + issuer},
+						a: oauth2.Expectation{
+							AllowedAlgorithms: []string{"ES384"},
+							TrustedIssuers:    []string{issuer},
+							ScopesMatcher:     oauth2.NoopMatcher{},
+						},
+						sf:  &SubjectInfo{IDFrom: "iss"},
+						ttl: &disabledTTL,
+					},
+				},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkRequest = func(req *http.Request) {
+					assert.Equal(t, "/"+issuer, req.URL.Path)
+				}
+
+				responseCode = http.StatusOK
+				responseContent = jwksWithOneKeyOnlyEntry
+				responseContentType = "application/json"
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, issuer, sub.ID)
+			},
+		},
+		{
+			uc: "with JWE, which cannot be decrypted",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				dec: &jweDecrypter{
+					ks:                ks,
+					keyAlgorithms:     supportedKeyAlgorithms,
+					contentAlgorithms: supportedContentAlgorithms,
+				},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("foo.bar.baz.bam.ban", nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "failed to decrypt JWT")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth3", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "with token not being sender constrained, although required",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				e:  endpoint.Endpoint{URL: srv.URL},
+				a: oauth2.Expectation{
+					AllowedAlgorithms: []string{"ES384"},
+					TrustedIssuers:    []string{issuer},
+					ScopesMatcher:     oauth2.NoopMatcher{},
+				},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &disabledTTL,
+				sc:  &senderConstraintVerifier{required: true, certificateBound: true},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusOK
+				responseContent = jwksWithOneKeyOnlyEntry
+				responseContentType = "application/json"
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "not sender constrained")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth3", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "successful with JWE",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				e:  endpoint.Endpoint{URL: srv.URL},
+				a: oauth2.Expectation{
+					AllowedAlgorithms: []string{"ES384"},
+					TrustedIssuers:    []string{issuer},
+					ScopesMatcher:     oauth2.NoopMatcher{},
+				},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &disabledTTL,
+				dec: &jweDecrypter{
+					ks:                ks,
+					keyAlgorithms:     supportedKeyAlgorithms,
+					contentAlgorithms: supportedContentAlgorithms,
+				},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(
+					createJWE(t, keyRSAEntry.PrivateKey.Public(), kidRSAKey,
+						jose.RSA_OAEP_256, jose.A256GCM, jwtSignedWithKeyOnlyJWK), nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusOK
+				responseContent = jwksWithOneKeyOnlyEntry
+				responseContentType = "application/json"
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, subjectID, sub.ID)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
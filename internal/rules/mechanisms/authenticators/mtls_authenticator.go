@@ -0,0 +1,361 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/pkix"
+)
+
+const (
+	certificateFormatPEM  = "pem"
+	certificateFormatXFCC = "xfcc"
+
+	defaultMTLSSubjectIDFrom = "subject.common_name"
+)
+
+var errNoPEMCertificate = errors.New("no PEM encoded certificate found")
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthenticatorTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorMTLS {
+				return false, nil, nil
+			}
+
+			auth, err := newMTLSAuthenticator(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type certificateSource struct {
+	Header string `mapstructure:"header"`
+	Format string `mapstructure:"format"`
+}
+
+type mtlsAuthenticator struct {
+	id                   string
+	trustStore           truststore.TrustStore
+	source               *certificateSource
+	si                   SubjectInfo
+	allowFallbackOnError bool
+}
+
+func newMTLSAuthenticator(id string, rawConfig map[string]any) (*mtlsAuthenticator, error) {
+	type Config struct {
+		TrustStore           truststore.TrustStore `mapstructure:"trust_store"`
+		CertificateFrom      *certificateSource    `mapstructure:"certificate_from"`
+		SubjectInfo          SubjectInfo           `mapstructure:"subject"`
+		AllowFallbackOnError bool                  `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to decode mtls authenticator config").
+			CausedBy(err)
+	}
+
+	if len(conf.TrustStore) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "mtls authenticator requires trust_store to be set")
+	}
+
+	if conf.CertificateFrom != nil {
+		if len(conf.CertificateFrom.Header) == 0 {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"mtls authenticator requires certificate_from.header to be set")
+		}
+
+		switch conf.CertificateFrom.Format {
+		case "":
+			conf.CertificateFrom.Format = certificateFormatPEM
+		case certificateFormatPEM, certificateFormatXFCC:
+		default:
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"unsupported certificate format '%s' configured for mtls authenticator",
+				conf.CertificateFrom.Format)
+		}
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = defaultMTLSSubjectIDFrom
+	}
+
+	return &mtlsAuthenticator{
+		id:                   id,
+		trustStore:           conf.TrustStore,
+		source:               conf.CertificateFrom,
+		si:                   conf.SubjectInfo,
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}, nil
+}
+
+func (a *mtlsAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using mtls authenticator")
+
+	chain, err := a.clientCertificates(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to read client certificate").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(chain) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no client certificate present").
+			WithErrorContext(a).
+			CausedBy(heimdall.ErrArgument)
+	}
+
+	if err = pkix.ValidateCertificate(chain[0],
+		pkix.WithRootCACertificates(a.trustStore),
+		pkix.WithIntermediateCACertificates(chain[1:]),
+		pkix.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth),
+	); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "client certificate validation failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	rawData, err := json.Marshal(newCertificateInfo(chain[0]))
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to marshal client certificate information").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	sub, err := a.si.CreateSubject(rawData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from client certificate").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
+
+func (a *mtlsAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows subject and fallback settings to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		SubjectInfo          *SubjectInfo `mapstructure:"subject"`
+		AllowFallbackOnError *bool        `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to decode mtls authenticator config").
+			CausedBy(err)
+	}
+
+	si := a.si
+	if conf.SubjectInfo != nil {
+		si = *conf.SubjectInfo
+		if len(si.IDFrom) == 0 {
+			si.IDFrom = defaultMTLSSubjectIDFrom
+		}
+	}
+
+	return &mtlsAuthenticator{
+		id:         a.id,
+		trustStore: a.trustStore,
+		source:     a.source,
+		si:         si,
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *mtlsAuthenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *mtlsAuthenticator) HandlerID() string {
+	return a.id
+}
+
+func (a *mtlsAuthenticator) clientCertificates(ctx heimdall.Context) ([]*x509.Certificate, error) {
+	req := ctx.Request()
+
+	if a.source == nil {
+		return req.ClientCertificates, nil
+	}
+
+	value := req.Header(a.source.Header)
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	if a.source.Format == certificateFormatXFCC {
+		return certificatesFromXFCC(value)
+	}
+
+	return certificatesFromPEM(value)
+}
+
+// certificatesFromPEM parses the given, possibly url encoded, PEM value. The first
+// certificate is expected to be the leaf certificate, all others are treated as
+// intermediate certificates.
+func certificatesFromPEM(value string) ([]*x509.Certificate, error) {
+	decoded, err := url.PathUnescape(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+
+	rest := []byte(decoded)
+
+	for {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, errNoPEMCertificate
+	}
+
+	return certs, nil
+}
+
+// certificatesFromXFCC extracts the client certificate chain from the value of the
+// x-forwarded-client-cert header, as set by envoy. Only the first element is taken
+// into account, as it describes the original client. The Chain key is preferred over
+// the Cert key, as it contains the leaf certificate as well.
+func certificatesFromXFCC(value string) ([]*x509.Certificate, error) {
+	elements := parseXFCC(value)
+	if len(elements) == 0 {
+		return nil, nil
+	}
+
+	if chain := elements[0]["chain"]; len(chain) != 0 {
+		return certificatesFromPEM(chain)
+	}
+
+	if cert := elements[0]["cert"]; len(cert) != 0 {
+		return certificatesFromPEM(cert)
+	}
+
+	return nil, nil
+}
+
+// parseXFCC splits the value of the x-forwarded-client-cert header into its
+// comma separated elements, each holding semicolon separated key=value pairs.
+// Values can be quoted and contain escaped quotes. Keys are returned in lower case.
+func parseXFCC(value string) []map[string]string {
+	var (
+		elements []map[string]string
+		buf      strings.Builder
+		key      string
+		quoted   bool
+		escaped  bool
+	)
+
+	element := make(map[string]string)
+
+	addPair := func() {
+		if len(key) != 0 {
+			element[strings.ToLower(key)] = strings.TrimSpace(buf.String())
+		}
+
+		key = ""
+
+		buf.Reset()
+	}
+
+	addElement := func() {
+		addPair()
+
+		if len(element) != 0 {
+			elements = append(elements, element)
+		}
+
+		element = make(map[string]string)
+	}
+
+	for _, char := range value {
+		switch {
+		case escaped:
+			buf.WriteRune(char)
+
+			escaped = false
+		case quoted && char == '\\':
+			escaped = true
+		case char == '"':
+			quoted = !quoted
+		case quoted:
+			buf.WriteRune(char)
+		case char == '=' && len(key) == 0:
+			key = strings.TrimSpace(buf.String())
+
+			buf.Reset()
+		case char == ';':
+			addPair()
+		case char == ',':
+			addElement()
+		default:
+			buf.WriteRune(char)
+		}
+	}
+
+	addElement()
+
+	return elements
+}
@@ -0,0 +1,701 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+type mtlsTestPKI struct {
+	trustStorePath string
+	intCACert      *x509.Certificate
+	clientCert     *x509.Certificate
+	serverCert     *x509.Certificate
+	untrustedCert  *x509.Certificate
+}
+
+func newMTLSTestPKI(t *testing.T) *mtlsTestPKI {
+	t.Helper()
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA 1", time.Hour*24)
+	require.NoError(t, err)
+
+	otherRootCA, err := testsupport.NewRootCA("Test Root CA 2", time.Hour*24)
+	require.NoError(t, err)
+
+	intCAPrivKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	intCACert, err := rootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{
+			CommonName:   "Test Int CA 1",
+			Organization: []string{"Test"},
+			Country:      []string{"EU"},
+		}),
+		testsupport.WithIsCA(),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&intCAPrivKey.PublicKey, x509.ECDSAWithSHA384))
+	require.NoError(t, err)
+
+	intCA := testsupport.NewCA(intCAPrivKey, intCACert)
+
+	eePrivKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	clientCert, err := intCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{
+			CommonName:         "Test Client",
+			Organization:       []string{"Test"},
+			OrganizationalUnit: []string{"Clients"},
+			Country:            []string{"EU"},
+		}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&eePrivKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth),
+		testsupport.WithDNSNames("client.example.com"),
+		testsupport.WithEmailAddresses("client@example.com"),
+		testsupport.WithURIs(&url.URL{Scheme: "spiffe", Host: "example.com", Path: "/ns/default/sa/client"}))
+	require.NoError(t, err)
+
+	serverCert, err := intCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "Test Server"}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&eePrivKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageServerAuth))
+	require.NoError(t, err)
+
+	untrustedCert, err := otherRootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "Test Client"}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&eePrivKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth))
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(rootCA.Certificate))
+	require.NoError(t, err)
+
+	trustStorePath := filepath.Join(t.TempDir(), "trust_store.pem")
+	require.NoError(t, os.WriteFile(trustStorePath, pemBytes, 0o600))
+
+	return &mtlsTestPKI{
+		trustStorePath: trustStorePath,
+		intCACert:      intCACert,
+		clientCert:     clientCert,
+		serverCert:     serverCert,
+		untrustedCert:  untrustedCert,
+	}
+}
+
+func urlEncodedPEM(t *testing.T, certs ...*x509.Certificate) string {
+	t.Helper()
+
+	opts := make([]pemx.EntryOption, len(certs))
+	for idx, cert := range certs {
+		opts[idx] = pemx.WithX509Certificate(cert)
+	}
+
+	pemBytes, err := pemx.BuildPEM(opts...)
+	require.NoError(t, err)
+
+	return url.PathEscape(string(pemBytes))
+}
+
+func TestCreateMTLSAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	pki := newMTLSTestPKI(t)
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *mtlsAuthenticator)
+	}{
+		{
+			uc: "without trust store",
+			config: []byte(`
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *mtlsAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires trust_store")
+			},
+		},
+		{
+			uc: "with unsupported properties",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+foo: bar`),
+			assert: func(t *testing.T, err error, auth *mtlsAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to decode")
+			},
+		},
+		{
+			uc: "with certificate source without header",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  format: xfcc`),
+			assert: func(t *testing.T, err error, auth *mtlsAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "certificate_from.header")
+			},
+		},
+		{
+			uc: "with unsupported certificate format",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  header: X-Client-Cert
+  format: der`),
+			assert: func(t *testing.T, err error, auth *mtlsAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported certificate format 'der'")
+			},
+		},
+		{
+			uc: "with minimal valid configuration",
+			id: "auth1",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath),
+			assert: func(t *testing.T, err error, auth *mtlsAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "auth1", auth.HandlerID())
+				assert.Len(t, auth.trustStore, 1)
+				assert.Nil(t, auth.source)
+				assert.Equal(t, "subject.common_name", auth.si.IDFrom)
+				assert.Empty(t, auth.si.AttributesFrom)
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with full configuration and default certificate format",
+			id: "auth2",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  header: X-Client-Cert
+subject:
+  id: san.uri.0
+  attributes: subject
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *mtlsAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "auth2", auth.HandlerID())
+				require.NotNil(t, auth.source)
+				assert.Equal(t, "X-Client-Cert", auth.source.Header)
+				assert.Equal(t, "pem", auth.source.Format)
+				assert.Equal(t, "san.uri.0", auth.si.IDFrom)
+				assert.Equal(t, "subject", auth.si.AttributesFrom)
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newMTLSAuthenticator(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateMTLSAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	pki := newMTLSTestPKI(t)
+
+	for _, tc := range []struct {
+		uc        string
+		prototype []byte
+		config    []byte
+		assert    func(t *testing.T, err error, prototype *mtlsAuthenticator, configured *mtlsAuthenticator)
+	}{
+		{
+			uc:        "without target config",
+			prototype: []byte(`trust_store: ` + pki.trustStorePath),
+			assert: func(t *testing.T, err error, prototype *mtlsAuthenticator, configured *mtlsAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:        "with not overridable trust store",
+			prototype: []byte(`trust_store: ` + pki.trustStorePath),
+			config:    []byte(`trust_store: ` + pki.trustStorePath),
+			assert: func(t *testing.T, err error, prototype *mtlsAuthenticator, configured *mtlsAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "has invalid keys: trust_store")
+			},
+		},
+		{
+			uc: "with subject and fallback redefined",
+			prototype: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  header: X-Forwarded-Client-Cert
+  format: xfcc`),
+			config: []byte(`
+subject:
+  attributes: san
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *mtlsAuthenticator, configured *mtlsAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.id, configured.id)
+				assert.Equal(t, prototype.trustStore, configured.trustStore)
+				assert.Equal(t, prototype.source, configured.source)
+				assert.Equal(t, "subject.common_name", configured.si.IDFrom)
+				assert.Equal(t, "san", configured.si.AttributesFrom)
+				assert.False(t, prototype.IsFallbackOnErrorAllowed())
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig(tc.prototype)
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newMTLSAuthenticator("auth", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				mtlsAuth *mtlsAuthenticator
+				ok       bool
+			)
+
+			if err == nil {
+				mtlsAuth, ok = auth.(*mtlsAuthenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, mtlsAuth)
+		})
+	}
+}
+
+func TestMTLSAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	type HandlerIdentifier interface {
+		HandlerID() string
+	}
+
+	pki := newMTLSTestPKI(t)
+	fingerprint := sha256.Sum256(pki.clientCert.Raw)
+
+	for _, tc := range []struct {
+		uc               string
+		config           []byte
+		configureContext func(t *testing.T, ctx *mocks.ContextMock)
+		assert           func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc:     "no client certificate presented in the TLS handshake",
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no client certificate present")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth1", identifier.HandlerID())
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:     "client certificate issued by an untrusted ca",
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{pki.untrustedCert},
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "validation failed")
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:     "client certificate without intermediate ca certificate",
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{pki.clientCert},
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "validation failed")
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:     "certificate not usable for client authentication",
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{pki.serverCert, pki.intCACert},
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "validation failed")
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:     "valid client certificate chain from the TLS handshake",
+			config: []byte(`trust_store: ` + pki.trustStorePath),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{pki.clientCert, pki.intCACert},
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "Test Client", sub.ID)
+				assert.Equal(t, hex.EncodeToString(fingerprint[:]), sub.Attributes["fingerprint"])
+				assert.Equal(t, pki.clientCert.SerialNumber.String(), sub.Attributes["serial_number"])
+				assert.Equal(t, map[string]any{
+					"dn":                  "CN=Test Client,OU=Clients,O=Test,C=EU",
+					"common_name":         "Test Client",
+					"organization":        []any{"Test"},
+					"organizational_unit": []any{"Clients"},
+					"country":             []any{"EU"},
+				}, sub.Attributes["subject"])
+				assert.Equal(t, map[string]any{
+					"dns":   []any{"client.example.com"},
+					"email": []any{"client@example.com"},
+					"uri":   []any{"spiffe://example.com/ns/default/sa/client"},
+				}, sub.Attributes["san"])
+			},
+		},
+		{
+			uc: "certificate header not present",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  header: X-Client-Cert`),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Client-Cert").Return("")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions:   fnt,
+					ClientCertificates: []*x509.Certificate{pki.clientCert, pki.intCACert},
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no client certificate present")
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc: "certificate header with malformed value",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  header: X-Client-Cert`),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Client-Cert").Return("foobar")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.NotErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "failed to read client certificate")
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc: "valid client certificate chain from url encoded PEM header",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  header: X-Client-Cert
+subject:
+  id: san.uri.0
+  attributes: subject`),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Client-Cert").Return(urlEncodedPEM(t, pki.clientCert, pki.intCACert))
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "spiffe://example.com/ns/default/sa/client", sub.ID)
+				assert.Equal(t, "Test Client", sub.Attributes["common_name"])
+			},
+		},
+		{
+			uc: "valid client certificate chain from XFCC header",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  header: X-Forwarded-Client-Cert
+  format: xfcc
+subject:
+  id: fingerprint`),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Return(
+					`By=spiffe://example.com/heimdall;Hash=foo;Subject="CN=Test Client,OU=Clients,O=Test,C=EU";` +
+						`Chain="` + urlEncodedPEM(t, pki.clientCert, pki.intCACert) + `";` +
+						`URI=spiffe://example.com/ns/default/sa/client,By=spiffe://example.com/other;Hash=bar`)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, hex.EncodeToString(fingerprint[:]), sub.ID)
+			},
+		},
+		{
+			uc: "XFCC header without certificate",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+certificate_from:
+  header: X-Forwarded-Client-Cert
+  format: xfcc`),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("X-Forwarded-Client-Cert").Return(`By=spiffe://example.com/heimdall;Hash=foo`)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no client certificate present")
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc: "subject id cannot be extracted",
+			config: []byte(`
+trust_store: ` + pki.trustStorePath + `
+subject:
+  id: san.ip.0`),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{pki.clientCert, pki.intCACert},
+				})
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to extract subject")
+
+				assert.Nil(t, sub)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			auth, err := newMTLSAuthenticator("auth1", conf)
+			require.NoError(t, err)
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+			tc.configureContext(t, ctx)
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
+
+func TestParseXFCC(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		value    string
+		expected []map[string]string
+	}{
+		{uc: "empty value", value: ""},
+		{
+			uc:    "single element",
+			value: `By=spiffe://foo;Hash=abc;URI=spiffe://bar`,
+			expected: []map[string]string{
+				{"by": "spiffe://foo", "hash": "abc", "uri": "spiffe://bar"},
+			},
+		},
+		{
+			uc:    "multiple elements with quoted and escaped values",
+			value: `By=spiffe://foo;Subject="CN=a,O=\"b;c\"", By=spiffe://bar;DNS=x.example.com;DNS=y.example.com`,
+			expected: []map[string]string{
+				{"by": "spiffe://foo", "subject": `CN=a,O="b;c"`},
+				{"by": "spiffe://bar", "dns": "y.example.com"},
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			elements := parseXFCC(tc.value)
+
+			// THEN
+			assert.Equal(t, tc.expected, elements)
+		})
+	}
+}
@@ -61,3 +61,7 @@ func (a *noopAuthenticator) IsFallbackOnErrorAllowed() bool {
 	// not allowed, as no error can happen when this authenticator is executed
 	return false
 }
+
+func (a *noopAuthenticator) HandlerID() string {
+	return a.id
+}
@@ -59,11 +59,13 @@ func init() {
 type oauth2IntrospectionAuthenticator struct {
 	id                   string
 	e                    endpoint.Endpoint
+	md                   oauth2.ServerMetadataResolver
 	a                    oauth2.Expectation
 	sf                   SubjectFactory
 	ads                  extractors.AuthDataExtractStrategy
 	ttl                  *time.Duration
 	allowFallbackOnError bool
+	sc                   *senderConstraintVerifier
 }
 
 func newOAuth2IntrospectionAuthenticator(id string, rawConfig map[string]any) (
@@ -72,11 +74,13 @@ func newOAuth2IntrospectionAuthenticator(id string, rawConfig map[string]any) (
 ) {
 	type Config struct {
 		Endpoint             endpoint.Endpoint                   `mapstructure:"introspection_endpoint"`
+		MetadataEndpoint     *endpoint.Endpoint                  `mapstructure:"metadata_endpoint"`
 		AuthDataSource       extractors.CompositeExtractStrategy `mapstructure:"token_source"`
 		Assertions           oauth2.Expectation                  `mapstructure:"assertions"`
 		SubjectInfo          SubjectInfo                         `mapstructure:"subject"`
 		CacheTTL             *time.Duration                      `mapstructure:"cache_ttl"`
 		AllowFallbackOnError bool                                `mapstructure:"allow_fallback_on_error"`
+		SenderConstraint     *senderConstraintConfig             `mapstructure:"sender_constraint"`
 	}
 
 	var conf Config
@@ -87,13 +91,27 @@ func newOAuth2IntrospectionAuthenticator(id string, rawConfig map[string]any) (
 			CausedBy(err)
 	}
 
-	if err := conf.Endpoint.Validate(); err != nil {
-		return nil, errorchain.
-			NewWithMessage(heimdall.ErrConfiguration, "failed to validate endpoint configuration").
-			CausedBy(err)
+	md, err := newServerMetadataResolver(conf.MetadataEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := newSenderConstraintVerifier(conf.SenderConstraint)
+	if err != nil {
+		return nil, err
+	}
+
+	// the url of the introspection endpoint can be taken from the metadata
+	if md == nil || len(conf.Endpoint.URL) != 0 {
+		if err = conf.Endpoint.Validate(); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrConfiguration, "failed to validate endpoint configuration").
+				CausedBy(err)
+		}
 	}
 
-	if len(conf.Assertions.TrustedIssuers) == 0 {
+	// the issuer can be taken from the metadata
+	if md == nil && len(conf.Assertions.TrustedIssuers) == 0 {
 		return nil, errorchain.
 			NewWithMessage(heimdall.ErrConfiguration, "no trusted issuers configured")
 	}
@@ -118,7 +136,8 @@ func newOAuth2IntrospectionAuthenticator(id string, rawConfig map[string]any) (
 		conf.Endpoint.Method = http.MethodPost
 	}
 
-	if len(conf.Assertions.AllowedAlgorithms) == 0 {
+	// if metadata is used, the allowed algorithms are derived from it
+	if md == nil && len(conf.Assertions.AllowedAlgorithms) == 0 {
 		conf.Assertions.AllowedAlgorithms = defaultAllowedAlgorithms()
 	}
 
@@ -141,10 +160,12 @@ func newOAuth2IntrospectionAuthenticator(id string, rawConfig map[string]any) (
 		id:                   id,
 		ads:                  ads,
 		e:                    conf.Endpoint,
+		md:                   md,
 		a:                    conf.Assertions,
 		sf:                   &conf.SubjectInfo,
 		ttl:                  conf.CacheTTL,
 		allowFallbackOnError: conf.AllowFallbackOnError,
+		sc:                   sc,
 	}, nil
 }
 
@@ -160,11 +181,23 @@ func (a *oauth2IntrospectionAuthenticator) Execute(ctx heimdall.Context) (*subje
 			CausedBy(err)
 	}
 
-	rawResp, err := a.getSubjectInformation(ctx, accessToken)
+	auth, err := a.withServerMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawResp, err := auth.getSubjectInformation(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = a.sc.verify(ctx, accessToken, rawResp); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "sender constraint verification failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
 	sub, err := a.sf.CreateSubject(rawResp)
 	if err != nil {
 		return nil, errorchain.
@@ -199,6 +232,7 @@ func (a *oauth2IntrospectionAuthenticator) WithConfig(rawConfig map[string]any)
 	return &oauth2IntrospectionAuthenticator{
 		id:  a.id,
 		e:   a.e,
+		md:  a.md,
 		a:   conf.Assertions.Merge(&a.a),
 		sf:  a.sf,
 		ads: a.ads,
@@ -206,6 +240,7 @@ func (a *oauth2IntrospectionAuthenticator) WithConfig(rawConfig map[string]any)
 		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
 			func() bool { return *conf.AllowFallbackOnError },
 			func() bool { return a.allowFallbackOnError }),
+		sc: a.sc,
 	}, nil
 }
 
@@ -217,6 +252,53 @@ func (a *oauth2IntrospectionAuthenticator) HandlerID() string {
 	return a.id
 }
 
+// RequiresCache reports whether DPoP bound tokens are accepted, as the detection of replayed
+// DPoP proofs relies on the cache.
+func (a *oauth2IntrospectionAuthenticator) RequiresCache() bool {
+	return a.sc.requiresCache()
+}
+
+// withServerMetadata returns an authenticator with the introspection endpoint url, the trusted issuers and
+// the allowed algorithms taken from the authorization server metadata, if these are not configured explicitly.
+func (a *oauth2IntrospectionAuthenticator) withServerMetadata(
+	ctx heimdall.Context,
+) (*oauth2IntrospectionAuthenticator, error) {
+	if a.md == nil {
+		return a, nil
+	}
+
+	md, err := a.md.Get(ctx.AppContext())
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "failed to retrieve authorization server metadata").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	auth := *a
+
+	if len(auth.e.URL) == 0 {
+		if len(md.IntrospectionEndpointURL) == 0 {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrInternal,
+					"authorization server metadata does not contain introspection_endpoint").
+				WithErrorContext(a)
+		}
+
+		auth.e.URL = md.IntrospectionEndpointURL
+	}
+
+	if len(auth.a.TrustedIssuers) == 0 {
+		auth.a.TrustedIssuers = []string{md.Issuer}
+	}
+
+	if len(auth.a.AllowedAlgorithms) == 0 {
+		auth.a.AllowedAlgorithms = allowedAlgorithmsFrom(md.IDTokenSigningAlgorithms)
+	}
+
+	return &auth, nil
+}
+
 func (a *oauth2IntrospectionAuthenticator) getSubjectInformation(ctx heimdall.Context, token string) ([]byte, error) {
 	cch := cache.Ctx(ctx.AppContext())
 	logger := zerolog.Ctx(ctx.AppContext())
@@ -18,6 +18,9 @@ package authenticators
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -253,6 +256,40 @@ allow_fallback_on_error: true
 				assert.Equal(t, "auth1", auth.HandlerID())
 			},
 		},
+		{
+			uc: "with metadata endpoint config only",
+			id: "auth1",
+			config: []byte(`
+metadata_endpoint:
+  url: http://test.com/.well-known/openid-configuration
+`),
+			assert: func(t *testing.T, err error, auth *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.NotNil(t, auth.md)
+				assert.Empty(t, auth.e.URL)
+				assert.Equal(t, http.MethodPost, auth.e.Method)
+				assert.Empty(t, auth.a.TrustedIssuers)
+				assert.Empty(t, auth.a.AllowedAlgorithms)
+				assert.Equal(t, "auth1", auth.HandlerID())
+			},
+		},
+		{
+			uc: "with invalid metadata endpoint config",
+			config: []byte(`
+metadata_endpoint:
+  method: GET
+`),
+			assert: func(t *testing.T, err error, _ *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "metadata endpoint configuration")
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -513,6 +550,9 @@ func TestOauth2IntrospectionAuthenticatorExecute(t *testing.T) {
 
 	zeroTTL := time.Duration(0)
 
+	clientCert := &x509.Certificate{Raw: []byte("certificate")}
+	clientCertDigest := sha256.Sum256(clientCert.Raw)
+
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		endpointCalled = true
 
@@ -533,6 +573,21 @@ func TestOauth2IntrospectionAuthenticatorExecute(t *testing.T) {
 	}))
 	defer srv.Close()
 
+	var mdSrv *httptest.Server
+
+	mdSrv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{"issuer":"` + mdSrv.URL + `","introspection_endpoint":"` + srv.URL + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer mdSrv.Close()
+
 	for _, tc := range []struct {
 		uc             string
 		authenticator  *oauth2IntrospectionAuthenticator
@@ -1147,6 +1202,247 @@ func TestOauth2IntrospectionAuthenticatorExecute(t *testing.T) {
 				assert.NotEmpty(t, sub.Attributes["exp"])
 			},
 		},
+		{
+			uc: "with failing metadata retrieval",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				md: oauth2.NewServerMetadataResolver(endpoint.Endpoint{URL: mdSrv.URL + "/foo"}),
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "authorization server metadata")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth3", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "with introspection endpoint and issuer from metadata and successful execution",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				e: endpoint.Endpoint{
+					Method: http.MethodPost,
+					Headers: map[string]string{
+						"Content-Type": "application/x-www-form-urlencoded",
+						"Accept":       "application/json",
+					},
+				},
+				md: oauth2.NewServerMetadataResolver(
+					endpoint.Endpoint{URL: mdSrv.URL + "/.well-known/openid-configuration"}),
+				a:   oauth2.Expectation{ScopesMatcher: oauth2.NoopMatcher{}},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &zeroTTL,
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				rawIntrospectResponse, err := json.Marshal(map[string]any{
+					"active": true,
+					"sub":    "foo",
+					"iss":    mdSrv.URL,
+					"exp":    time.Now().Unix() + 30,
+				})
+				require.NoError(t, err)
+
+				responseContentType = "application/json"
+				responseContent = rawIntrospectResponse
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, "foo", sub.ID)
+			},
+		},
+		{
+			uc: "with certificate bound token presented without the client certificate",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				e:  endpoint.Endpoint{URL: srv.URL, Method: http.MethodPost},
+				a: oauth2.Expectation{
+					TrustedIssuers: []string{"foobar"},
+					ScopesMatcher:  oauth2.NoopMatcher{},
+				},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &zeroTTL,
+				sc:  &senderConstraintVerifier{certificateBound: true},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				ctx.EXPECT().Request().Return(&heimdall.Request{})
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				rawIntrospectResponse, err := json.Marshal(map[string]any{
+					"active": true,
+					"sub":    "foo",
+					"iss":    "foobar",
+					"exp":    time.Now().Unix() + 30,
+					"cnf":    map[string]any{"x5t#S256": base64.RawURLEncoding.EncodeToString(clientCertDigest[:])},
+				})
+				require.NoError(t, err)
+
+				responseContentType = "application/json"
+				responseContent = rawIntrospectResponse
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "no client certificate")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth3", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "with certificate bound token and successful execution",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				e:  endpoint.Endpoint{URL: srv.URL, Method: http.MethodPost},
+				a: oauth2.Expectation{
+					TrustedIssuers: []string{"foobar"},
+					ScopesMatcher:  oauth2.NoopMatcher{},
+				},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &zeroTTL,
+				sc:  &senderConstraintVerifier{required: true, certificateBound: true},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				_ *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					ClientCertificates: []*x509.Certificate{clientCert},
+				})
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				rawIntrospectResponse, err := json.Marshal(map[string]any{
+					"active": true,
+					"sub":    "foo",
+					"iss":    "foobar",
+					"exp":    time.Now().Unix() + 30,
+					"cnf":    map[string]any{"x5t#S256": base64.RawURLEncoding.EncodeToString(clientCertDigest[:])},
+				})
+				require.NoError(t, err)
+
+				responseContentType = "application/json"
+				responseContent = rawIntrospectResponse
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, "foo", sub.ID)
+				assert.Contains(t, sub.Attributes, "cnf")
+			},
+		},
+		{
+			uc: "with issuer from metadata not matching the one in the introspection response",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				e:  endpoint.Endpoint{Method: http.MethodPost},
+				md: oauth2.NewServerMetadataResolver(
+					endpoint.Endpoint{URL: mdSrv.URL + "/.well-known/openid-configuration"}),
+				a:   oauth2.Expectation{ScopesMatcher: oauth2.NoopMatcher{}},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &zeroTTL,
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				rawIntrospectResponse, err := json.Marshal(map[string]any{
+					"active": true,
+					"sub":    "foo",
+					"iss":    "barfoo",
+					"exp":    time.Now().Unix() + 30,
+				})
+				require.NoError(t, err)
+
+				responseContentType = "application/json"
+				responseContent = rawIntrospectResponse
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "assertion conditions")
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
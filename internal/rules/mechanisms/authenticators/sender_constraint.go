@@ -0,0 +1,364 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"golang.org/x/exp/slices"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	dpopHeader              = "DPoP"
+	dpopProofType           = "dpop+jwt"
+	defaultDPoPProofMaxAge  = 1 * time.Minute
+	dpopReplayCacheKeyInfix = "dpop-proof"
+)
+
+var errSenderConstraint = errors.New("sender constraint violation")
+
+// dpopReplayScript remembers the jti of a DPoP proof, if not known yet. It returns 1 if the jti
+// has been stored and 0 if it was already present. Being evaluated atomically, a proof can be
+// used only once, even if sent concurrently to different heimdall instances.
+const dpopReplayScript = `
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+  return 1
+end
+
+return 0
+`
+
+type dpopConfig struct {
+	AllowedAlgorithms []string       `mapstructure:"allowed_algorithms"`
+	ProofMaxAge       *time.Duration `mapstructure:"proof_max_age"`
+}
+
+type senderConstraintConfig struct {
+	Required         bool        `mapstructure:"required"`
+	DPoP             *dpopConfig `mapstructure:"dpop"`
+	CertificateBound bool        `mapstructure:"certificate_bound"`
+}
+
+// senderConstraintVerifier verifies the binding of an access token to the client presenting it
+// as expressed by the cnf claim. Supported are DPoP bound tokens (RFC 9449) and certificate
+// bound tokens (RFC 8705).
+type senderConstraintVerifier struct {
+	// guards the lookup and the update of the replay cache, if the cache
+	// cannot do that atomically
+	mut sync.Mutex
+
+	required          bool
+	dpopEnabled       bool
+	allowedAlgorithms []string
+	proofMaxAge       time.Duration
+	certificateBound  bool
+}
+
+type confirmation struct {
+	JKT     string `json:"jkt"`
+	X5TS256 string `json:"x5t#S256"` //nolint:tagliatelle
+}
+
+type dpopProofClaims struct {
+	ID       string              `json:"jti"`
+	Method   string              `json:"htm"`
+	URL      string              `json:"htu"`
+	IssuedAt *oauth2.NumericDate `json:"iat"`
+	ATHash   string              `json:"ath"`
+}
+
+func newSenderConstraintVerifier(conf *senderConstraintConfig) (*senderConstraintVerifier, error) {
+	if conf == nil {
+		return nil, nil // nolint: nilnil
+	}
+
+	if conf.DPoP == nil && !conf.CertificateBound {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"sender_constraint requires dpop and/or certificate_bound to be configured")
+	}
+
+	verifier := &senderConstraintVerifier{
+		required:         conf.Required,
+		certificateBound: conf.CertificateBound,
+	}
+
+	if conf.DPoP != nil {
+		for _, alg := range conf.DPoP.AllowedAlgorithms {
+			if !isAsymmetricAlgorithm(alg) {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"algorithm %s is not allowed for DPoP proofs", alg)
+			}
+		}
+
+		verifier.dpopEnabled = true
+		verifier.allowedAlgorithms = x.IfThenElse(len(conf.DPoP.AllowedAlgorithms) != 0,
+			conf.DPoP.AllowedAlgorithms, defaultAllowedAlgorithms())
+		verifier.proofMaxAge = x.IfThenElseExec(conf.DPoP.ProofMaxAge != nil,
+			func() time.Duration { return *conf.DPoP.ProofMaxAge },
+			func() time.Duration { return defaultDPoPProofMaxAge })
+	}
+
+	return verifier, nil
+}
+
+func (v *senderConstraintVerifier) requiresCache() bool {
+	return v != nil && v.dpopEnabled
+}
+
+// verify checks the cnf claim from the given token claims against the current request. Tokens without
+// a cnf claim are only accepted, if sender constrained tokens are not required.
+func (v *senderConstraintVerifier) verify(ctx heimdall.Context, accessToken string, rawClaims []byte) error {
+	if v == nil {
+		return nil
+	}
+
+	var claims struct {
+		Confirmation *confirmation `json:"cnf"`
+	}
+
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return errorchain.NewWithMessage(errSenderConstraint, "failed to read cnf claim").CausedBy(err)
+	}
+
+	cnf := claims.Confirmation
+
+	switch {
+	case cnf == nil || (len(cnf.JKT) == 0 && len(cnf.X5TS256) == 0):
+		if v.required {
+			return errorchain.NewWithMessage(errSenderConstraint, "token is not sender constrained")
+		}
+
+		return nil
+	case len(cnf.JKT) != 0:
+		if !v.dpopEnabled {
+			return errorchain.NewWithMessage(errSenderConstraint, "DPoP bound tokens are not accepted")
+		}
+
+		return v.verifyDPoPProof(ctx, accessToken, cnf.JKT)
+	default:
+		if !v.certificateBound {
+			return errorchain.NewWithMessage(errSenderConstraint, "certificate bound tokens are not accepted")
+		}
+
+		return v.verifyCertificateBinding(ctx, cnf.X5TS256)
+	}
+}
+
+func (v *senderConstraintVerifier) verifyCertificateBinding(ctx heimdall.Context, thumbprint string) error {
+	certs := ctx.Request().ClientCertificates
+	if len(certs) == 0 {
+		return errorchain.NewWithMessage(errSenderConstraint, "no client certificate present")
+	}
+
+	digest := sha256.Sum256(certs[0].Raw)
+
+	if base64.RawURLEncoding.EncodeToString(digest[:]) != thumbprint {
+		return errorchain.NewWithMessage(errSenderConstraint,
+			"client certificate does not match the certificate the token is bound to")
+	}
+
+	return nil
+}
+
+func (v *senderConstraintVerifier) verifyDPoPProof(ctx heimdall.Context, accessToken, jkt string) error {
+	proof := ctx.Request().Header(dpopHeader)
+	if len(proof) == 0 {
+		return errorchain.NewWithMessage(errSenderConstraint, "no DPoP proof present")
+	}
+
+	jws, err := jose.ParseSigned(proof)
+	if err != nil {
+		return errorchain.NewWithMessage(errSenderConstraint, "failed to parse DPoP proof").CausedBy(err)
+	}
+
+	if len(jws.Signatures) != 1 {
+		return errorchain.NewWithMessage(errSenderConstraint, "DPoP proof must have exactly one signature")
+	}
+
+	header := jws.Signatures[0].Protected
+
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != dpopProofType {
+		return errorchain.NewWithMessagef(errSenderConstraint, "unexpected DPoP proof type %s", typ)
+	}
+
+	if !slices.Contains(v.allowedAlgorithms, header.Algorithm) {
+		return errorchain.NewWithMessagef(errSenderConstraint,
+			"algorithm %s is not allowed for DPoP proofs", header.Algorithm)
+	}
+
+	jwk := header.JSONWebKey
+	if jwk == nil || !jwk.IsPublic() || !jwk.Valid() {
+		return errorchain.NewWithMessage(errSenderConstraint, "DPoP proof does not contain a valid public key")
+	}
+
+	payload, err := jws.Verify(jwk)
+	if err != nil {
+		return errorchain.NewWithMessage(errSenderConstraint, "invalid DPoP proof signature").CausedBy(err)
+	}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return errorchain.NewWithMessage(errSenderConstraint, "failed to calculate DPoP key thumbprint").
+			CausedBy(err)
+	}
+
+	if base64.RawURLEncoding.EncodeToString(thumbprint) != jkt {
+		return errorchain.NewWithMessage(errSenderConstraint,
+			"DPoP proof key does not match the key the token is bound to")
+	}
+
+	var claims dpopProofClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return errorchain.NewWithMessage(errSenderConstraint, "failed to read DPoP proof claims").CausedBy(err)
+	}
+
+	if err = v.verifyDPoPProofClaims(ctx, accessToken, &claims); err != nil {
+		return err
+	}
+
+	return v.checkReplay(ctx, jkt, claims.ID)
+}
+
+func (v *senderConstraintVerifier) verifyDPoPProofClaims(
+	ctx heimdall.Context, accessToken string, claims *dpopProofClaims,
+) error {
+	req := ctx.Request()
+
+	if len(claims.ID) == 0 {
+		return errorchain.NewWithMessage(errSenderConstraint, "DPoP proof has no jti claim")
+	}
+
+	if claims.Method != req.Method {
+		return errorchain.NewWithMessagef(errSenderConstraint,
+			"DPoP proof is issued for %s method", claims.Method)
+	}
+
+	if !dpopTargetMatches(claims.URL, req.URL) {
+		return errorchain.NewWithMessagef(errSenderConstraint,
+			"DPoP proof is issued for %s url", claims.URL)
+	}
+
+	if claims.IssuedAt == nil {
+		return errorchain.NewWithMessage(errSenderConstraint, "DPoP proof has no iat claim")
+	}
+
+	if age := time.Since(claims.IssuedAt.Time()); age > v.proofMaxAge || age < -v.proofMaxAge {
+		return errorchain.NewWithMessage(errSenderConstraint, "DPoP proof is expired or issued in the future")
+	}
+
+	digest := sha256.Sum256(stringx.ToBytes(accessToken))
+
+	if claims.ATHash != base64.RawURLEncoding.EncodeToString(digest[:]) {
+		return errorchain.NewWithMessage(errSenderConstraint, "DPoP proof is not issued for the presented token")
+	}
+
+	return nil
+}
+
+// checkReplay ensures a DPoP proof is used only once. The jti is remembered as long as the proof
+// would be accepted from the iat perspective. Caches shared between heimdall instances are updated
+// atomically. Otherwise, the lookup and the update are done while holding a lock.
+func (v *senderConstraintVerifier) checkReplay(ctx heimdall.Context, jkt, jti string) error {
+	cch := cache.Ctx(ctx.AppContext())
+	ttl := 2 * v.proofMaxAge //nolint:gomnd
+
+	digest := sha256.New()
+	digest.Write(stringx.ToBytes(dpopReplayCacheKeyInfix))
+	digest.Write(stringx.ToBytes(jkt))
+	digest.Write(stringx.ToBytes(jti))
+
+	key := base64.RawURLEncoding.EncodeToString(digest.Sum(nil))
+
+	if se, ok := cch.(cache.ScriptEvaluator); ok {
+		res, err := se.Eval(ctx.AppContext(), dpopReplayScript, []string{key}, jti, ttl.Milliseconds())
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrCommunication,
+				"failed to check DPoP proof for replay").CausedBy(err)
+		}
+
+		stored, ok := res.(int64)
+		if !ok {
+			return errorchain.NewWithMessage(heimdall.ErrInternal,
+				"unexpected result of the DPoP proof replay check")
+		}
+
+		if stored != 1 {
+			return errorchain.NewWithMessage(errSenderConstraint, "DPoP proof has already been used")
+		}
+
+		return nil
+	}
+
+	v.mut.Lock()
+	defer v.mut.Unlock()
+
+	if cch.Get(key) != nil {
+		return errorchain.NewWithMessage(errSenderConstraint, "DPoP proof has already been used")
+	}
+
+	cch.Set(key, jti, ttl)
+
+	return nil
+}
+
+// dpopTargetMatches compares the htu claim of a DPoP proof with the url of the request ignoring
+// query and fragment parts, as well as default ports.
+func dpopTargetMatches(htu string, reqURL *url.URL) bool {
+	if reqURL == nil {
+		return false
+	}
+
+	target, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(target.Scheme, reqURL.Scheme) &&
+		strings.EqualFold(hostWithoutDefaultPort(target), hostWithoutDefaultPort(reqURL)) &&
+		pathOf(target) == pathOf(reqURL)
+}
+
+func pathOf(u *url.URL) string {
+	return x.IfThenElse(len(u.EscapedPath()) != 0, u.EscapedPath(), "/")
+}
+
+func hostWithoutDefaultPort(u *url.URL) string {
+	port := u.Port()
+
+	if (port == "443" && strings.EqualFold(u.Scheme, "https")) ||
+		(port == "80" && strings.EqualFold(u.Scheme, "http")) {
+		return u.Hostname()
+	}
+
+	return u.Host
+}
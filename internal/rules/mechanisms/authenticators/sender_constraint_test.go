@@ -0,0 +1,648 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/cache/redis"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/x"
+)
+
+func createDPoPProof(
+	t *testing.T, key *ecdsa.PrivateKey, alg jose.SignatureAlgorithm, typ string, claims map[string]any,
+) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: alg, Key: key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType(jose.ContentType(typ)))
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+
+	raw, err := jws.CompactSerialize()
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestNewSenderConstraintVerifier(t *testing.T) {
+	t.Parallel()
+
+	maxAge := 10 * time.Second
+
+	for _, tc := range []struct {
+		uc     string
+		conf   *senderConstraintConfig
+		assert func(t *testing.T, err error, verifier *senderConstraintVerifier)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, verifier *senderConstraintVerifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Nil(t, verifier)
+			},
+		},
+		{
+			uc:   "without dpop and certificate binding",
+			conf: &senderConstraintConfig{Required: true},
+			assert: func(t *testing.T, err error, _ *senderConstraintVerifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "dpop and/or certificate_bound")
+			},
+		},
+		{
+			uc:   "with symmetric dpop algorithm",
+			conf: &senderConstraintConfig{DPoP: &dpopConfig{AllowedAlgorithms: []string{"HS256"}}},
+			assert: func(t *testing.T, err error, _ *senderConstraintVerifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "HS256 is not allowed")
+			},
+		},
+		{
+			uc:   "with dpop using defaults",
+			conf: &senderConstraintConfig{DPoP: &dpopConfig{}},
+			assert: func(t *testing.T, err error, verifier *senderConstraintVerifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, verifier)
+				assert.False(t, verifier.required)
+				assert.True(t, verifier.dpopEnabled)
+				assert.False(t, verifier.certificateBound)
+				assert.Equal(t, defaultAllowedAlgorithms(), verifier.allowedAlgorithms)
+				assert.Equal(t, defaultDPoPProofMaxAge, verifier.proofMaxAge)
+			},
+		},
+		{
+			uc: "with full configuration",
+			conf: &senderConstraintConfig{
+				Required:         true,
+				CertificateBound: true,
+				DPoP: &dpopConfig{
+					AllowedAlgorithms: []string{string(jose.ES384), string(jose.EdDSA)},
+					ProofMaxAge:       &maxAge,
+				},
+			},
+			assert: func(t *testing.T, err error, verifier *senderConstraintVerifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, verifier)
+				assert.True(t, verifier.required)
+				assert.True(t, verifier.dpopEnabled)
+				assert.True(t, verifier.certificateBound)
+				assert.Equal(t, []string{string(jose.ES384), string(jose.EdDSA)}, verifier.allowedAlgorithms)
+				assert.Equal(t, maxAge, verifier.proofMaxAge)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			verifier, err := newSenderConstraintVerifier(tc.conf)
+
+			// THEN
+			tc.assert(t, err, verifier)
+		})
+	}
+}
+
+func TestSenderConstraintVerifierVerify(t *testing.T) {
+	t.Parallel()
+
+	const accessToken = "foo.bar.baz"
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	thumbprint, err := (&jose.JSONWebKey{Key: key.Public()}).Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+
+	jkt := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	ath := sha256.Sum256([]byte(accessToken))
+
+	cert := &x509.Certificate{Raw: []byte("certificate")}
+	certDigest := sha256.Sum256(cert.Raw)
+	x5t := base64.RawURLEncoding.EncodeToString(certDigest[:])
+
+	proofClaims := func(modify func(claims map[string]any)) map[string]any {
+		claims := map[string]any{
+			"jti": "foo",
+			"htm": "POST",
+			"htu": "https://foo.bar:443/baz",
+			"iat": time.Now().Unix(),
+			"ath": base64.RawURLEncoding.EncodeToString(ath[:]),
+		}
+
+		if modify != nil {
+			modify(claims)
+		}
+
+		return claims
+	}
+
+	dpopBound := []byte(`{"sub": "foo", "cnf": {"jkt": "` + jkt + `"}}`)
+	certBound := []byte(`{"sub": "foo", "cnf": {"x5t#S256": "` + x5t + `"}}`)
+	dpop := &senderConstraintConfig{DPoP: &dpopConfig{}}
+
+	for _, tc := range []struct {
+		uc             string
+		conf           *senderConstraintConfig
+		claims         []byte
+		proof          string
+		certs          []*x509.Certificate
+		configureCache func(t *testing.T, cch *mocks.CacheMock)
+		assert         func(t *testing.T, err error)
+	}{
+		{
+			uc:     "without verifier",
+			claims: dpopBound,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:     "not sender constrained token, which is not required",
+			conf:   dpop,
+			claims: []byte(`{"sub": "foo"}`),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:     "not sender constrained token, which is required",
+			conf:   &senderConstraintConfig{Required: true, DPoP: &dpopConfig{}},
+			claims: []byte(`{"sub": "foo"}`),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "not sender constrained")
+			},
+		},
+		{
+			uc:     "dpop bound token without dpop being enabled",
+			conf:   &senderConstraintConfig{CertificateBound: true},
+			claims: dpopBound,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "DPoP bound tokens are not accepted")
+			},
+		},
+		{
+			uc:     "certificate bound token without certificate binding being enabled",
+			conf:   dpop,
+			claims: certBound,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "certificate bound tokens are not accepted")
+			},
+		},
+		{
+			uc:     "certificate bound token without client certificate",
+			conf:   &senderConstraintConfig{CertificateBound: true},
+			claims: certBound,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "no client certificate")
+			},
+		},
+		{
+			uc:     "certificate bound token with other client certificate",
+			conf:   &senderConstraintConfig{CertificateBound: true},
+			claims: certBound,
+			certs:  []*x509.Certificate{{Raw: []byte("other")}},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "does not match")
+			},
+		},
+		{
+			uc:     "certificate bound token with matching client certificate",
+			conf:   &senderConstraintConfig{CertificateBound: true},
+			claims: certBound,
+			certs:  []*x509.Certificate{cert},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:     "dpop bound token without proof",
+			conf:   dpop,
+			claims: dpopBound,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "no DPoP proof")
+			},
+		},
+		{
+			uc:     "dpop bound token with malformed proof",
+			conf:   dpop,
+			claims: dpopBound,
+			proof:  "foo.bar",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "failed to parse DPoP proof")
+			},
+		},
+		{
+			uc:     "dpop bound token with proof of wrong type",
+			conf:   dpop,
+			claims: dpopBound,
+			proof:  createDPoPProof(t, key, jose.ES256, "JWT", proofClaims(nil)),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "unexpected DPoP proof type JWT")
+			},
+		},
+		{
+			uc:     "dpop bound token with proof signed using not allowed algorithm",
+			conf:   &senderConstraintConfig{DPoP: &dpopConfig{AllowedAlgorithms: []string{string(jose.ES384)}}},
+			claims: dpopBound,
+			proof:  createDPoPProof(t, key, jose.ES256, dpopProofType, proofClaims(nil)),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "ES256 is not allowed")
+			},
+		},
+		{
+			uc:     "dpop bound token with proof signed by other key",
+			conf:   dpop,
+			claims: dpopBound,
+			proof:  createDPoPProof(t, otherKey, jose.ES256, dpopProofType, proofClaims(nil)),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "key does not match")
+			},
+		},
+		{
+			uc:     "dpop bound token with proof without jti",
+			conf:   dpop,
+			claims: dpopBound,
+			proof: createDPoPProof(t, key, jose.ES256, dpopProofType,
+				proofClaims(func(claims map[string]any) { delete(claims, "jti") })),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "no jti claim")
+			},
+		},
+		{
+			uc:     "dpop bound token with proof for other method",
+			conf:   dpop,
+			claims: dpopBound,
+			proof: createDPoPProof(t, key, jose.ES256, dpopProofType,
+				proofClaims(func(claims map[string]any) { claims["htm"] = "GET" })),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "issued for GET method")
+			},
+		},
+		{
+			uc:     "dpop bound token with proof for other url",
+			conf:   dpop,
+			claims: dpopBound,
+			proof: createDPoPProof(t, key, jose.ES256, dpopProofType,
+				proofClaims(func(claims map[string]any) { claims["htu"] = "https://foo.bar/other" })),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "issued for https://foo.bar/other url")
+			},
+		},
+		{
+			uc:     "dpop bound token with proof without iat",
+			conf:   dpop,
+			claims: dpopBound,
+			proof: createDPoPProof(t, key, jose.ES256, dpopProofType,
+				proofClaims(func(claims map[string]any) { delete(claims, "iat") })),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "no iat claim")
+			},
+		},
+		{
+			uc:     "dpop bound token with outdated proof",
+			conf:   dpop,
+			claims: dpopBound,
+			proof: createDPoPProof(t, key, jose.ES256, dpopProofType,
+				proofClaims(func(claims map[string]any) { claims["iat"] = time.Now().Add(-2 * time.Minute).Unix() })),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "expired")
+			},
+		},
+		{
+			uc:     "dpop bound token with proof issued for other token",
+			conf:   dpop,
+			claims: dpopBound,
+			proof: createDPoPProof(t, key, jose.ES256, dpopProofType,
+				proofClaims(func(claims map[string]any) { claims["ath"] = "foo" })),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "not issued for the presented token")
+			},
+		},
+		{
+			uc:     "dpop bound token with replayed proof",
+			conf:   dpop,
+			claims: dpopBound,
+			proof:  createDPoPProof(t, key, jose.ES256, dpopProofType, proofClaims(nil)),
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return("foo")
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errSenderConstraint)
+				assert.Contains(t, err.Error(), "already been used")
+			},
+		},
+		{
+			uc:     "dpop bound token with valid proof",
+			conf:   dpop,
+			claims: dpopBound,
+			proof:  createDPoPProof(t, key, jose.ES256, dpopProofType, proofClaims(nil)),
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+				cch.EXPECT().Set(mock.Anything, "foo", 2*defaultDPoPProofMaxAge)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureCache := x.IfThenElse(tc.configureCache != nil,
+				tc.configureCache,
+				func(t *testing.T, _ *mocks.CacheMock) { t.Helper() })
+
+			verifier, err := newSenderConstraintVerifier(tc.conf)
+			require.NoError(t, err)
+
+			cch := mocks.NewCacheMock(t)
+			configureCache(t, cch)
+
+			reqf := heimdallmocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Header(dpopHeader).Return(tc.proof).Maybe()
+
+			ctx := heimdallmocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch)).Maybe()
+			ctx.EXPECT().Request().Return(&heimdall.Request{
+				RequestFunctions:   reqf,
+				Method:             "POST",
+				URL:                &url.URL{Scheme: "https", Host: "foo.bar", Path: "/baz", RawQuery: "foo=bar"},
+				ClientCertificates: tc.certs,
+			}).Maybe()
+
+			// WHEN
+			err = verifier.verify(ctx, accessToken, tc.claims)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestSenderConstraintVerifierCheckReplay(t *testing.T) {
+	t.Parallel()
+
+	const concurrentRequests = 20
+
+	for _, tc := range []struct {
+		uc       string
+		newCache func(t *testing.T) cache.Cache
+		assert   func(t *testing.T, errs []error)
+	}{
+		{
+			uc: "local cache",
+			newCache: func(t *testing.T) cache.Cache {
+				t.Helper()
+
+				return memory.New()
+			},
+			assert: func(t *testing.T, errs []error) {
+				t.Helper()
+
+				assertProofAcceptedOnce(t, errs)
+			},
+		},
+		{
+			uc: "shared cache",
+			newCache: func(t *testing.T) cache.Cache {
+				t.Helper()
+
+				srv := miniredis.RunT(t)
+
+				cch, err := redis.NewCache(map[string]any{
+					"address": srv.Addr(),
+					"tls":     map[string]any{"disabled": true},
+				}, log.Logger)
+				require.NoError(t, err)
+
+				t.Cleanup(func() { cch.Stop(context.Background()) })
+
+				return cch
+			},
+			assert: func(t *testing.T, errs []error) {
+				t.Helper()
+
+				assertProofAcceptedOnce(t, errs)
+			},
+		},
+		{
+			uc: "unavailable shared cache",
+			newCache: func(t *testing.T) cache.Cache {
+				t.Helper()
+
+				srv := miniredis.RunT(t)
+
+				cch, err := redis.NewCache(map[string]any{
+					"address": srv.Addr(),
+					"tls":     map[string]any{"disabled": true},
+				}, log.Logger)
+				require.NoError(t, err)
+
+				t.Cleanup(func() { cch.Stop(context.Background()) })
+
+				srv.Close()
+
+				return cch
+			},
+			assert: func(t *testing.T, errs []error) {
+				t.Helper()
+
+				for _, err := range errs {
+					require.Error(t, err)
+					assert.ErrorIs(t, err, heimdall.ErrCommunication)
+					assert.Contains(t, err.Error(), "failed to check DPoP proof for replay")
+				}
+			},
+		},
+	} {
+		tc := tc
+
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			verifier, err := newSenderConstraintVerifier(&senderConstraintConfig{DPoP: &dpopConfig{}})
+			require.NoError(t, err)
+
+			ctx := heimdallmocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), tc.newCache(t)))
+
+			var wg sync.WaitGroup
+
+			errs := make([]error, concurrentRequests)
+
+			// WHEN
+			for idx := range errs {
+				wg.Add(1)
+
+				go func(idx int) {
+					defer wg.Done()
+
+					errs[idx] = verifier.checkReplay(ctx, "foo", "bar")
+				}(idx)
+			}
+
+			wg.Wait()
+
+			// THEN
+			tc.assert(t, errs)
+		})
+	}
+}
+
+func assertProofAcceptedOnce(t *testing.T, errs []error) {
+	t.Helper()
+
+	accepted := 0
+
+	for _, err := range errs {
+		if err == nil {
+			accepted++
+
+			continue
+		}
+
+		assert.ErrorIs(t, err, errSenderConstraint)
+		assert.Contains(t, err.Error(), "already been used")
+	}
+
+	assert.Equal(t, 1, accepted)
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const defaultSessionCookieName = "heimdall_session"
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthenticatorTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorSession {
+				return false, nil, nil
+			}
+
+			auth, err := newSessionAuthenticator(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type sessionAuthenticator struct {
+	id                   string
+	cookieName           string
+	codec                *session.Codec
+	idleTimeout          time.Duration
+	absoluteTimeout      time.Duration
+	checkRevocation      bool
+	allowFallbackOnError bool
+}
+
+func newSessionAuthenticator(id string, rawConfig map[string]any) (*sessionAuthenticator, error) {
+	type Config struct {
+		CookieName string `mapstructure:"cookie_name"`
+		KeyStore   struct {
+			Path     string `mapstructure:"path"`
+			Password string `mapstructure:"password"`
+		} `mapstructure:"key_store"`
+		KeyID                string        `mapstructure:"key_id"`
+		Encrypted            bool          `mapstructure:"encrypted"`
+		IdleTimeout          time.Duration `mapstructure:"idle_timeout"`
+		AbsoluteTimeout      time.Duration `mapstructure:"absolute_timeout"`
+		CheckRevocation      bool          `mapstructure:"check_revocation"`
+		AllowFallbackOnError bool          `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to decode session authenticator config").
+			CausedBy(err)
+	}
+
+	if len(conf.KeyStore.Path) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"session authenticator requires key_store path to be set")
+	}
+
+	if conf.IdleTimeout < 0 || conf.AbsoluteTimeout < 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"session authenticator timeouts must not be negative")
+	}
+
+	ks, err := keystore.NewKeyStoreFromPEMFile(conf.KeyStore.Path, conf.KeyStore.Password)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed loading session key store").CausedBy(err)
+	}
+
+	codec, err := session.NewCodec(ks, conf.KeyID, conf.Encrypted)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to configure session codec").CausedBy(err)
+	}
+
+	return &sessionAuthenticator{
+		id:                   id,
+		cookieName:           x.IfThenElse(len(conf.CookieName) != 0, conf.CookieName, defaultSessionCookieName),
+		codec:                codec,
+		idleTimeout:          conf.IdleTimeout,
+		absoluteTimeout:      conf.AbsoluteTimeout,
+		checkRevocation:      conf.CheckRevocation,
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}, nil
+}
+
+func (a *sessionAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using session authenticator")
+
+	cookie := ctx.Request().Cookie(a.cookieName)
+	if len(cookie) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no session cookie present").
+			WithErrorContext(a).
+			CausedBy(heimdall.ErrArgument)
+	}
+
+	sess, err := a.codec.Decode(cookie)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "invalid session cookie").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if err = a.validate(ctx, sess); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "session is not valid").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return &subject.Subject{
+		ID:         sess.SubjectID,
+		Attributes: x.IfThenElse(sess.Attributes != nil, sess.Attributes, map[string]any{}),
+	}, nil
+}
+
+// validate checks the absolute and the idle timeouts, as well as the revocation state of the given
+// session. The idle timeout is tracked in the cache. A session without recorded activity is idle, if it
+// has been issued earlier than the idle timeout. On success, the activity of the session is recorded.
+func (a *sessionAuthenticator) validate(ctx heimdall.Context, sess *session.Session) error {
+	now := time.Now()
+	store := session.NewStore(cache.Ctx(ctx.AppContext()))
+
+	if len(sess.SubjectID) == 0 {
+		return errorchain.NewWithMessage(session.ErrInvalidSession, "session has no subject")
+	}
+
+	if !sess.ExpiresAt.IsZero() && now.After(sess.ExpiresAt) {
+		return errorchain.NewWithMessage(session.ErrInvalidSession, "session expired")
+	}
+
+	if a.absoluteTimeout != 0 && now.After(sess.IssuedAt.Add(a.absoluteTimeout)) {
+		return errorchain.NewWithMessage(session.ErrInvalidSession, "absolute session timeout reached")
+	}
+
+	if a.checkRevocation && store.IsRevoked(sess.ID) {
+		return errorchain.NewWithMessage(session.ErrInvalidSession, "session has been revoked")
+	}
+
+	if a.idleTimeout == 0 {
+		return nil
+	}
+
+	if now.After(sess.IssuedAt.Add(a.idleTimeout)) && !store.IsActive(sess.ID) {
+		return errorchain.NewWithMessage(session.ErrInvalidSession, "idle session timeout reached")
+	}
+
+	store.Touch(sess.ID, a.idleTimeout)
+
+	return nil
+}
+
+func (a *sessionAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows only the fallback behavior to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		AllowFallbackOnError *bool `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to decode session authenticator config").
+			CausedBy(err)
+	}
+
+	return &sessionAuthenticator{
+		id:              a.id,
+		cookieName:      a.cookieName,
+		codec:           a.codec,
+		idleTimeout:     a.idleTimeout,
+		absoluteTimeout: a.absoluteTimeout,
+		checkRevocation: a.checkRevocation,
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *sessionAuthenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *sessionAuthenticator) HandlerID() string {
+	return a.id
+}
@@ -0,0 +1,504 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateSessionAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	ksFile := testsupport.CreateKeyStoreFile(t, "session")
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *sessionAuthenticator)
+	}{
+		{
+			uc:     "without key store",
+			config: []byte(`cookie_name: foo`),
+			assert: func(t *testing.T, err error, _ *sessionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires key_store path")
+			},
+		},
+		{
+			uc: "with unsupported properties",
+			config: []byte(`
+key_store:
+  path: ` + ksFile + `
+foo: bar`),
+			assert: func(t *testing.T, err error, _ *sessionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to decode")
+			},
+		},
+		{
+			uc: "with negative timeout",
+			config: []byte(`
+key_store:
+  path: ` + ksFile + `
+idle_timeout: -1s`),
+			assert: func(t *testing.T, err error, _ *sessionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "must not be negative")
+			},
+		},
+		{
+			uc: "with not existing key store",
+			config: []byte(`
+key_store:
+  path: /no/such/file.pem`),
+			assert: func(t *testing.T, err error, _ *sessionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed loading session key store")
+			},
+		},
+		{
+			uc: "with not existing key id",
+			config: []byte(`
+key_store:
+  path: ` + ksFile + `
+key_id: foo`),
+			assert: func(t *testing.T, err error, _ *sessionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.ErrorIs(t, err, keystore.ErrNoSuchKey)
+			},
+		},
+		{
+			uc: "with minimal configuration",
+			id: "auth1",
+			config: []byte(`
+key_store:
+  path: ` + ksFile),
+			assert: func(t *testing.T, err error, auth *sessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "auth1", auth.HandlerID())
+				assert.Equal(t, defaultSessionCookieName, auth.cookieName)
+				assert.NotNil(t, auth.codec)
+				assert.Zero(t, auth.idleTimeout)
+				assert.Zero(t, auth.absoluteTimeout)
+				assert.False(t, auth.checkRevocation)
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with full configuration",
+			id: "auth2",
+			config: []byte(`
+cookie_name: my_session
+key_store:
+  path: ` + ksFile + `
+key_id: session
+encrypted: true
+idle_timeout: 15m
+absolute_timeout: 8h
+check_revocation: true
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *sessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "auth2", auth.HandlerID())
+				assert.Equal(t, "my_session", auth.cookieName)
+				assert.NotNil(t, auth.codec)
+				assert.Equal(t, 15*time.Minute, auth.idleTimeout)
+				assert.Equal(t, 8*time.Hour, auth.absoluteTimeout)
+				assert.True(t, auth.checkRevocation)
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newSessionAuthenticator(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateSessionAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	ksFile := testsupport.CreateKeyStoreFile(t, "session")
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *sessionAuthenticator, configured *sessionAuthenticator)
+	}{
+		{
+			uc: "without target config",
+			assert: func(t *testing.T, err error, prototype *sessionAuthenticator, configured *sessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with not overridable idle timeout",
+			config: []byte(`idle_timeout: 1m`),
+			assert: func(t *testing.T, err error, _ *sessionAuthenticator, _ *sessionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "idle_timeout")
+			},
+		},
+		{
+			uc:     "with fallback redefined",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *sessionAuthenticator, configured *sessionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.id, configured.id)
+				assert.Equal(t, prototype.cookieName, configured.cookieName)
+				assert.Same(t, prototype.codec, configured.codec)
+				assert.Equal(t, prototype.idleTimeout, configured.idleTimeout)
+				assert.Equal(t, prototype.absoluteTimeout, configured.absoluteTimeout)
+				assert.Equal(t, prototype.checkRevocation, configured.checkRevocation)
+				assert.False(t, prototype.IsFallbackOnErrorAllowed())
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+key_store:
+  path: ` + ksFile + `
+idle_timeout: 10m`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newSessionAuthenticator("auth", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				sessAuth *sessionAuthenticator
+				ok       bool
+			)
+
+			if err == nil {
+				sessAuth, ok = auth.(*sessionAuthenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, sessAuth)
+		})
+	}
+}
+
+func TestSessionAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	type HandlerIdentifier interface {
+		HandlerID() string
+	}
+
+	ks, err := keystore.NewKeyStoreFromPEMFile(testsupport.CreateKeyStoreFile(t, "session"), "")
+	require.NoError(t, err)
+
+	codec, err := session.NewCodec(ks, "", false)
+	require.NoError(t, err)
+
+	createCookie := func(t *testing.T, sess *session.Session) string {
+		t.Helper()
+
+		value, err := codec.Encode(sess)
+		require.NoError(t, err)
+
+		return value
+	}
+
+	for _, tc := range []struct {
+		uc             string
+		authenticator  *sessionAuthenticator
+		cookie         func(t *testing.T) string
+		configureCache func(t *testing.T, cch *mocks.CacheMock)
+		assert         func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc:            "without session cookie",
+			authenticator: &sessionAuthenticator{id: "auth1"},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no session cookie")
+
+				var identifier HandlerIdentifier
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "auth1", identifier.HandlerID())
+			},
+		},
+		{
+			uc:            "with malformed session cookie",
+			authenticator: &sessionAuthenticator{id: "auth1"},
+			cookie: func(t *testing.T) string {
+				t.Helper()
+
+				return "foo.bar.baz"
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.ErrorIs(t, err, session.ErrInvalidSession)
+				assert.Contains(t, err.Error(), "invalid session cookie")
+			},
+		},
+		{
+			uc:            "with session without subject",
+			authenticator: &sessionAuthenticator{id: "auth1"},
+			cookie: func(t *testing.T) string {
+				t.Helper()
+
+				return createCookie(t, &session.Session{})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no subject")
+			},
+		},
+		{
+			uc:            "with expired session",
+			authenticator: &sessionAuthenticator{id: "auth1"},
+			cookie: func(t *testing.T) string {
+				t.Helper()
+
+				return createCookie(t, &session.Session{
+					SubjectID: "foo",
+					ExpiresAt: time.Now().Add(-1 * time.Minute),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "session expired")
+			},
+		},
+		{
+			uc:            "with absolute timeout reached",
+			authenticator: &sessionAuthenticator{id: "auth1", absoluteTimeout: 1 * time.Hour},
+			cookie: func(t *testing.T) string {
+				t.Helper()
+
+				return createCookie(t, &session.Session{
+					SubjectID: "foo",
+					IssuedAt:  time.Now().Add(-2 * time.Hour),
+				})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "absolute session timeout")
+			},
+		},
+		{
+			uc:            "with revoked session",
+			authenticator: &sessionAuthenticator{id: "auth1", checkRevocation: true},
+			cookie: func(t *testing.T) string {
+				t.Helper()
+
+				return createCookie(t, &session.Session{SubjectID: "foo"})
+			},
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return("foo")
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "revoked")
+			},
+		},
+		{
+			uc:            "with idle timeout reached",
+			authenticator: &sessionAuthenticator{id: "auth1", idleTimeout: 10 * time.Minute},
+			cookie: func(t *testing.T) string {
+				t.Helper()
+
+				return createCookie(t, &session.Session{
+					SubjectID: "foo",
+					IssuedAt:  time.Now().Add(-20 * time.Minute),
+				})
+			},
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "idle session timeout")
+			},
+		},
+		{
+			uc:            "with recent activity on an older session",
+			authenticator: &sessionAuthenticator{id: "auth1", idleTimeout: 10 * time.Minute},
+			cookie: func(t *testing.T) string {
+				t.Helper()
+
+				return createCookie(t, &session.Session{
+					ID:         "bar",
+					SubjectID:  "foo",
+					Attributes: map[string]any{"baz": "zab"},
+					IssuedAt:   time.Now().Add(-20 * time.Minute),
+				})
+			},
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(mock.Anything).Return("bar")
+				cch.EXPECT().Set(mock.Anything, "bar", 10*time.Minute)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{ID: "foo", Attributes: map[string]any{"baz": "zab"}}, sub)
+			},
+		},
+		{
+			uc:            "with fresh session and idle timeout",
+			authenticator: &sessionAuthenticator{id: "auth1", idleTimeout: 10 * time.Minute},
+			cookie: func(t *testing.T) string {
+				t.Helper()
+
+				return createCookie(t, &session.Session{ID: "bar", SubjectID: "foo"})
+			},
+			configureCache: func(t *testing.T, cch *mocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Set(mock.Anything, "bar", 10*time.Minute)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &subject.Subject{ID: "foo", Attributes: map[string]any{}}, sub)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			cookie := x.IfThenElse(tc.cookie != nil,
+				tc.cookie,
+				func(t *testing.T) string {
+					t.Helper()
+
+					return ""
+				})
+			configureCache := x.IfThenElse(tc.configureCache != nil,
+				tc.configureCache,
+				func(t *testing.T, _ *mocks.CacheMock) { t.Helper() })
+
+			tc.authenticator.cookieName = "session"
+			tc.authenticator.codec = codec
+
+			cch := mocks.NewCacheMock(t)
+			configureCache(t, cch)
+
+			reqf := heimdallmocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Cookie("session").Return(cookie(t))
+
+			ctx := heimdallmocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch))
+			ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+
+			// WHEN
+			sub, err := tc.authenticator.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
@@ -27,4 +27,5 @@ type Authorizer interface {
 	Execute(heimdall.Context, *subject.Subject) error
 	WithConfig(config map[string]any) (Authorizer, error)
 	ContinueOnError() bool
+	HandlerID() string
 }
@@ -17,15 +17,12 @@
 package authorizers
 
 import (
-	"fmt"
-
 	"github.com/google/cel-go/cel"
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/cellib"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
-	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -102,10 +99,7 @@ func (a *celAuthorizer) Execute(ctx heimdall.Context, sub *subject.Subject) erro
 		}
 
 		if !ok {
-			return errorchain.NewWithMessage(heimdall.ErrAuthorization,
-				x.IfThenElse(len(expression.Message) != 0, expression.Message,
-					fmt.Sprintf("expression %d failed", i+1))).
-				WithErrorContext(a)
+			return expressionFailedError(a, i, expression)
 		}
 	}
 
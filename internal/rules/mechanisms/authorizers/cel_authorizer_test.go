@@ -250,11 +250,48 @@ expressions:
 				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
 				assert.Contains(t, err.Error(), "expression 1 failed")
 
+				var denial *heimdall.DenialError
+				require.ErrorAs(t, err, &denial)
+				assert.Equal(t, "1", denial.ExpressionID)
+				assert.Empty(t, denial.RequiredScopes)
+
 				var identifier interface{ HandlerID() string }
 				require.True(t, errors.As(err, &identifier))
 				assert.Equal(t, "authz1", identifier.HandlerID())
 			},
 		},
+		{
+			uc: "denied by expression with id, message and required scopes",
+			id: "authz2",
+			config: []byte(`
+expressions:
+  - id: is_admin
+    expression: "'admin' in Subject.Attributes.scopes"
+    message: admin scope required
+    required_scopes: [ admin ]
+`),
+			configureContextAndSubject: func(t *testing.T, ctx *mocks.ContextMock, sub *subject.Subject) {
+				t.Helper()
+
+				sub.ID = "foo"
+				sub.Attributes = map[string]any{"scopes": []string{"read"}}
+
+				ctx.EXPECT().Request().Return(nil)
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "admin scope required")
+
+				var denial *heimdall.DenialError
+				require.ErrorAs(t, err, &denial)
+				assert.Equal(t, "is_admin", denial.ExpressionID)
+				assert.Equal(t, "admin scope required", denial.Message)
+				assert.Equal(t, []string{"admin"}, denial.RequiredScopes)
+			},
+		},
 		{
 			uc: "expressions can use subject and request properties",
 			id: "authz2",
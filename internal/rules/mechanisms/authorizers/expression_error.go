@@ -0,0 +1,42 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/cellib"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// expressionFailedError creates the authorization error for the failed expression at the given
+// position. The error is caused by a heimdall.DenialError, error handlers can expose to the client.
+func expressionFailedError(errCtx any, idx int, expression *cellib.Expression) error {
+	message := x.IfThenElse(len(expression.Message) != 0, expression.Message,
+		fmt.Sprintf("expression %d failed", idx+1))
+
+	return errorchain.NewWithMessage(heimdall.ErrAuthorization, message).
+		WithErrorContext(errCtx).
+		CausedBy(&heimdall.DenialError{
+			ExpressionID:   x.IfThenElse(len(expression.ID) != 0, expression.ID, strconv.Itoa(idx+1)),
+			Message:        message,
+			RequiredScopes: expression.RequiredScopes,
+		})
+}
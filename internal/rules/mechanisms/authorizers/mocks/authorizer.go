@@ -108,6 +108,47 @@ func (_c *AuthorizerMock_Execute_Call) RunAndReturn(run func(heimdall.Context, *
 	return _c
 }
 
+// HandlerID provides a mock function with given fields:
+func (_m *AuthorizerMock) HandlerID() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// AuthorizerMock_HandlerID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandlerID'
+type AuthorizerMock_HandlerID_Call struct {
+	*mock.Call
+}
+
+// HandlerID is a helper method to define mock.On call
+func (_e *AuthorizerMock_Expecter) HandlerID() *AuthorizerMock_HandlerID_Call {
+	return &AuthorizerMock_HandlerID_Call{Call: _e.mock.On("HandlerID")}
+}
+
+func (_c *AuthorizerMock_HandlerID_Call) Run(run func()) *AuthorizerMock_HandlerID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *AuthorizerMock_HandlerID_Call) Return(_a0 string) *AuthorizerMock_HandlerID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *AuthorizerMock_HandlerID_Call) RunAndReturn(run func() string) *AuthorizerMock_HandlerID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // WithConfig provides a mock function with given fields: config
 func (_m *AuthorizerMock) WithConfig(config map[string]interface{}) (authorizers.Authorizer, error) {
 	ret := _m.Called(config)
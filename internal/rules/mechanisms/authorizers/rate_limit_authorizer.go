@@ -0,0 +1,274 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/accesscontext"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	rateLimitStoreMemory       = "memory"
+	rateLimitStoreCache        = "cache"
+	rateLimitDefaultKey        = "{{ .Subject.ID }}"
+	rateLimitMaxLocalEntries   = 100000
+	rateLimitStateKeyPrefix    = "rate_limit:"
+	rateLimitStateKeySeparator = ":"
+	rateLimitLockStripes       = 256
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthorizerTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authorizer, error) {
+			if typ != AuthorizerRateLimit {
+				return false, nil, nil
+			}
+
+			auth, err := newRateLimitAuthorizer(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type rateLimitAuthorizer struct {
+	id        string
+	key       template.Template
+	algorithm string
+	limit     int
+	window    time.Duration
+	// 0 means the burst equals the limit
+	burst int
+	l     rateLimiter
+	// local state store, nil if the configured cache is used
+	store cache.Cache
+	// serialize the read-modify-write cycles on states held in process local stores. Shared
+	// by all instances created from the same prototype.
+	locks *rateLimitLocks
+}
+
+// rateLimitLocks maps the state keys to a fixed set of mutexes, so that requests for
+// different keys do not wait for each other in most cases.
+type rateLimitLocks [rateLimitLockStripes]sync.Mutex
+
+func (l *rateLimitLocks) forKey(key string) *sync.Mutex {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+
+	return &l[hash.Sum32()%rateLimitLockStripes]
+}
+
+type rateLimitConfig struct {
+	Key       template.Template `mapstructure:"key"`
+	Algorithm string            `mapstructure:"algorithm"`
+	Limit     int               `mapstructure:"limit"`
+	Window    time.Duration     `mapstructure:"window"`
+	Burst     int               `mapstructure:"burst"`
+	Store     string            `mapstructure:"store"`
+}
+
+func newRateLimitAuthorizer(id string, rawConfig map[string]any) (*rateLimitAuthorizer, error) {
+	var conf rateLimitConfig
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal rate_limit authorizer config").
+			CausedBy(err)
+	}
+
+	algorithm := x.IfThenElse(len(conf.Algorithm) != 0, conf.Algorithm, rateLimitAlgorithmTokenBucket)
+	if algorithm != rateLimitAlgorithmTokenBucket && algorithm != rateLimitAlgorithmSlidingWindow {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported rate_limit authorizer algorithm: %s", algorithm)
+	}
+
+	var store cache.Cache
+
+	switch conf.Store {
+	case "", rateLimitStoreMemory:
+		store = memory.New(memory.WithMaxEntries(rateLimitMaxLocalEntries))
+	case rateLimitStoreCache:
+	default:
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported rate_limit authorizer store: %s", conf.Store)
+	}
+
+	key := conf.Key
+	if key == nil {
+		// the default template is known to be valid
+		key, _ = template.New(rateLimitDefaultKey)
+	}
+
+	auth := &rateLimitAuthorizer{
+		id:        id,
+		key:       key,
+		algorithm: algorithm,
+		store:     store,
+		locks:     &rateLimitLocks{},
+	}
+
+	if err := auth.configureLimits(conf.Limit, conf.Window, conf.Burst); err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+func (a *rateLimitAuthorizer) configureLimits(limit int, window time.Duration, burst int) error {
+	if limit <= 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"rate_limit authorizer requires 'limit' to be a positive number")
+	}
+
+	if window <= 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"rate_limit authorizer requires 'window' to be a positive duration")
+	}
+
+	if burst < 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"rate_limit authorizer 'burst' must not be negative")
+	}
+
+	if burst != 0 && a.algorithm != rateLimitAlgorithmTokenBucket {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"rate_limit authorizer supports 'burst' for the token_bucket algorithm only")
+	}
+
+	a.limit = limit
+	a.window = window
+	a.burst = burst
+	a.l = newRateLimiter(a.algorithm, limit, window, x.IfThenElse(burst != 0, burst, limit))
+
+	return nil
+}
+
+func (a *rateLimitAuthorizer) Execute(ctx heimdall.Context, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authorizing using rate_limit authorizer")
+
+	key, err := a.key.Render(map[string]any{
+		"Subject": sub,
+		"Request": ctx.Request(),
+		"RuleID":  accesscontext.RuleID(ctx.AppContext()),
+	})
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to render rate limit key").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	allowed, retryAfter := a.allow(ctx.AppContext(), a.stateKey(key), time.Now())
+	if !allowed {
+		logger.Debug().Str("_id", a.id).Dur("_retry_after", retryAfter).Msg("Rate limit exceeded")
+
+		return errorchain.NewWithMessage(heimdall.ErrTooManyRequests, "rate limit exceeded").
+			WithErrorContext(a).
+			CausedBy(&heimdall.TooManyRequestsError{RetryAfter: retryAfter})
+	}
+
+	return nil
+}
+
+func (a *rateLimitAuthorizer) WithConfig(rawConfig map[string]any) (Authorizer, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Key    template.Template `mapstructure:"key"`
+		Limit  int               `mapstructure:"limit"`
+		Window time.Duration     `mapstructure:"window"`
+		Burst  int               `mapstructure:"burst"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal rate_limit authorizer config").
+			CausedBy(err)
+	}
+
+	auth := &rateLimitAuthorizer{
+		id:        a.id,
+		key:       x.IfThenElse(conf.Key != nil, conf.Key, a.key),
+		algorithm: a.algorithm,
+		store:     a.store,
+		locks:     a.locks,
+	}
+
+	err := auth.configureLimits(
+		x.IfThenElse(conf.Limit != 0, conf.Limit, a.limit),
+		x.IfThenElse(conf.Window != 0, conf.Window, a.window),
+		x.IfThenElse(conf.Burst != 0, conf.Burst, a.burst),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+func (a *rateLimitAuthorizer) HandlerID() string { return a.id }
+
+func (a *rateLimitAuthorizer) ContinueOnError() bool { return false }
+
+func (a *rateLimitAuthorizer) allow(ctx context.Context, key string, now time.Time) (bool, time.Duration) {
+	cch := x.IfThenElseExec(a.store != nil,
+		func() cache.Cache { return a.store },
+		func() cache.Cache { return cache.Ctx(ctx) })
+
+	if se, ok := cch.(cache.ScriptEvaluator); ok {
+		allowed, retryAfter, err := a.l.allowShared(ctx, se, key, now)
+		if err != nil {
+			// like with an unavailable local state, the request is not limited
+			zerolog.Ctx(ctx).Warn().Err(err).Str("_id", a.id).Msg("Failed to evaluate rate limit")
+
+			return true, 0
+		}
+
+		return allowed, retryAfter
+	}
+
+	mut := a.locks.forKey(key)
+	mut.Lock()
+	defer mut.Unlock()
+
+	return a.l.allow(cch, key, now)
+}
+
+func (a *rateLimitAuthorizer) stateKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+
+	return rateLimitStateKeyPrefix + a.id + rateLimitStateKeySeparator + hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,449 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/accesscontext"
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateRateLimitAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *rateLimitAuthorizer)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'limit' to be a positive number")
+			},
+		},
+		{
+			uc:     "without window",
+			config: []byte(`limit: 10`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'window' to be a positive duration")
+			},
+		},
+		{
+			uc: "with negative burst",
+			config: []byte(`
+limit: 10
+window: 1m
+burst: -1
+`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "must not be negative")
+			},
+		},
+		{
+			uc: "with burst for sliding window algorithm",
+			config: []byte(`
+limit: 10
+window: 1m
+burst: 20
+algorithm: sliding_window
+`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "token_bucket algorithm only")
+			},
+		},
+		{
+			uc: "with unsupported algorithm",
+			config: []byte(`
+limit: 10
+window: 1m
+algorithm: leaky_bucket
+`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported rate_limit authorizer algorithm")
+			},
+		},
+		{
+			uc: "with unsupported store",
+			config: []byte(`
+limit: 10
+window: 1m
+store: redis
+`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported rate_limit authorizer store")
+			},
+		},
+		{
+			uc: "with unsupported fields",
+			config: []byte(`
+limit: 10
+window: 1m
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with minimal valid configuration",
+			id: "authz",
+			config: []byte(`
+limit: 10
+window: 1m
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "authz", auth.HandlerID())
+				assert.Equal(t, "authz", auth.HandlerID())
+				assert.False(t, auth.ContinueOnError())
+				assert.Equal(t, rateLimitAlgorithmTokenBucket, auth.algorithm)
+				assert.Equal(t, 10, auth.limit)
+				assert.Equal(t, 1*time.Minute, auth.window)
+				assert.Equal(t, 0, auth.burst)
+				assert.NotNil(t, auth.store)
+				require.IsType(t, &tokenBucketLimiter{}, auth.l)
+
+				limiter := auth.l.(*tokenBucketLimiter) // nolint: forcetypeassert
+				assert.InDelta(t, 10.0, limiter.capacity, 0.0001)
+
+				key, err := auth.key.Render(map[string]any{"Subject": &subject.Subject{ID: "foo"}})
+				require.NoError(t, err)
+				assert.Equal(t, "foo", key)
+			},
+		},
+		{
+			uc: "with full valid configuration",
+			config: []byte(`
+key: "{{ .RuleID }}:{{ .Request.ClientIP | first }}"
+algorithm: sliding_window
+limit: 100
+window: 1h
+store: cache
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, rateLimitAlgorithmSlidingWindow, auth.algorithm)
+				assert.Equal(t, 100, auth.limit)
+				assert.Equal(t, 1*time.Hour, auth.window)
+				assert.Nil(t, auth.store)
+				require.IsType(t, &slidingWindowLimiter{}, auth.l)
+
+				key, err := auth.key.Render(map[string]any{
+					"RuleID":  "rule1",
+					"Request": &heimdall.Request{ClientIP: []string{"10.0.0.1"}},
+				})
+				require.NoError(t, err)
+				assert.Equal(t, "rule1:10.0.0.1", key)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newRateLimitAuthorizer(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateRateLimitAuthorizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc              string
+		prototypeConfig []byte
+		config          []byte
+		assert          func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer)
+	}{
+		{
+			uc: "no new configuration provided",
+			prototypeConfig: []byte(`
+limit: 10
+window: 1m
+`),
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc: "with not overridable algorithm",
+			prototypeConfig: []byte(`
+limit: 10
+window: 1m
+`),
+			config: []byte(`algorithm: sliding_window`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with invalid limit",
+			prototypeConfig: []byte(`
+limit: 10
+window: 1m
+`),
+			config: []byte(`limit: -1`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'limit' to be a positive number")
+			},
+		},
+		{
+			uc: "with new limit and key for sliding window",
+			prototypeConfig: []byte(`
+limit: 10
+window: 1m
+algorithm: sliding_window
+`),
+			config: []byte(`
+key: "{{ .Request.URL.Host }}"
+limit: 5
+`),
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, configured)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.HandlerID(), configured.HandlerID())
+				assert.Equal(t, prototype.algorithm, configured.algorithm)
+				assert.Equal(t, prototype.window, configured.window)
+				assert.Equal(t, 5, configured.limit)
+				assert.NotEqual(t, prototype.key, configured.key)
+				assert.Same(t, prototype.store, configured.store)
+				assert.Same(t, prototype.locks, configured.locks)
+			},
+		},
+		{
+			uc: "with new burst for token bucket",
+			prototypeConfig: []byte(`
+limit: 10
+window: 1m
+`),
+			config: []byte(`burst: 50`),
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, configured)
+				assert.Equal(t, prototype.limit, configured.limit)
+				assert.Equal(t, prototype.key, configured.key)
+				assert.Equal(t, 50, configured.burst)
+
+				limiter := configured.l.(*tokenBucketLimiter) // nolint: forcetypeassert
+				assert.InDelta(t, 50.0, limiter.capacity, 0.0001)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig(tc.prototypeConfig)
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newRateLimitAuthorizer("authz", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var configured *rateLimitAuthorizer
+			if err == nil {
+				configured = auth.(*rateLimitAuthorizer) // nolint: forcetypeassert
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestRateLimitAuthorizerExecute(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		subjectID string
+		host      string
+	}
+
+	for _, tc := range []struct {
+		uc       string
+		config   []byte
+		requests []request
+		assert   func(t *testing.T, errs []error)
+	}{
+		{
+			uc: "limits requests per subject using local store",
+			config: []byte(`
+limit: 2
+window: 1m
+`),
+			requests: []request{
+				{subjectID: "alice"}, {subjectID: "alice"}, {subjectID: "bob"}, {subjectID: "alice"},
+			},
+			assert: func(t *testing.T, errs []error) {
+				t.Helper()
+
+				require.NoError(t, errs[0])
+				require.NoError(t, errs[1])
+				require.NoError(t, errs[2])
+				require.Error(t, errs[3])
+				assert.ErrorIs(t, errs[3], heimdall.ErrTooManyRequests)
+				assert.Contains(t, errs[3].Error(), "rate limit exceeded")
+
+				var tmrErr *heimdall.TooManyRequestsError
+				require.ErrorAs(t, errs[3], &tmrErr)
+				assert.InDelta(t, 30*time.Second, tmrErr.RetryAfter, float64(time.Second))
+
+				var identifier interface{ HandlerID() string }
+				require.ErrorAs(t, errs[3], &identifier)
+				assert.Equal(t, "authz", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "limits requests per rule and host using shared cache",
+			config: []byte(`
+key: "{{ .RuleID }}:{{ .Request.URL.Host }}"
+algorithm: sliding_window
+limit: 1
+window: 1h
+store: cache
+`),
+			requests: []request{
+				{subjectID: "alice", host: "foo.local"},
+				{subjectID: "bob", host: "foo.local"},
+				{subjectID: "bob", host: "bar.local"},
+			},
+			assert: func(t *testing.T, errs []error) {
+				t.Helper()
+
+				require.NoError(t, errs[0])
+				require.Error(t, errs[1])
+				assert.ErrorIs(t, errs[1], heimdall.ErrTooManyRequests)
+				require.NoError(t, errs[2])
+			},
+		},
+		{
+			uc: "fails rendering key",
+			config: []byte(`
+key: "{{ .Request.Header }}"
+limit: 1
+window: 1h
+`),
+			requests: []request{{subjectID: "alice"}},
+			assert: func(t *testing.T, errs []error) {
+				t.Helper()
+
+				require.Error(t, errs[0])
+				assert.ErrorIs(t, errs[0], heimdall.ErrInternal)
+				assert.Contains(t, errs[0].Error(), "failed to render")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			appCtx := cache.WithContext(accesscontext.New(context.Background()), memory.New())
+			accesscontext.SetRuleID(appCtx, "rule1")
+
+			auth, err := newRateLimitAuthorizer("authz", conf)
+			require.NoError(t, err)
+
+			errs := make([]error, len(tc.requests))
+
+			for idx, req := range tc.requests {
+				ctx := mocks.NewContextMock(t)
+				ctx.EXPECT().AppContext().Return(appCtx)
+				ctx.EXPECT().Request().
+					Return(&heimdall.Request{URL: &url.URL{Scheme: "http", Host: req.host, Path: "/foo"}})
+
+				// WHEN
+				errs[idx] = auth.Execute(ctx, &subject.Subject{ID: req.subjectID})
+			}
+
+			// THEN
+			tc.assert(t, errs)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/cache"
+)
+
+const (
+	rateLimitAlgorithmTokenBucket   = "token_bucket"
+	rateLimitAlgorithmSlidingWindow = "sliding_window"
+)
+
+type rateLimiter interface {
+	// allow consumes one request from the state stored under the given key. If the request
+	// is not allowed, the duration to wait before the next request can be allowed is returned.
+	allow(cch cache.Cache, key string, now time.Time) (bool, time.Duration)
+	// allowShared does the same as allow, but atomically on the side of a store shared between
+	// heimdall instances.
+	allowShared(ctx context.Context, se cache.ScriptEvaluator, key string, now time.Time) (
+		bool, time.Duration, error)
+}
+
+var errUnexpectedScriptResult = errors.New("unexpected rate limit script result")
+
+func newRateLimiter(algorithm string, limit int, window time.Duration, burst int) rateLimiter {
+	if algorithm == rateLimitAlgorithmSlidingWindow {
+		return &slidingWindowLimiter{limit: limit, window: window}
+	}
+
+	return &tokenBucketLimiter{
+		rate:     float64(limit) / window.Seconds(),
+		capacity: float64(burst),
+	}
+}
+
+// tokenBucketLimiter refills the bucket continuously with limit tokens per window. Each request
+// consumes one token. The capacity of the bucket defines the allowed burst.
+type tokenBucketLimiter struct {
+	rate     float64
+	capacity float64
+}
+
+type tokenBucketState struct {
+	Tokens    float64 `json:"tokens"`
+	UpdatedAt int64   `json:"updated_at"`
+}
+
+func (l *tokenBucketLimiter) allow(cch cache.Cache, key string, now time.Time) (bool, time.Duration) {
+	state := tokenBucketState{Tokens: l.capacity, UpdatedAt: now.UnixNano()}
+	loadRateLimitState(cch, key, &state)
+
+	if elapsed := now.Sub(time.Unix(0, state.UpdatedAt)); elapsed > 0 {
+		state.Tokens = math.Min(l.capacity, state.Tokens+elapsed.Seconds()*l.rate)
+	}
+
+	state.UpdatedAt = now.UnixNano()
+
+	allowed := state.Tokens >= 1
+	if allowed {
+		state.Tokens--
+	}
+
+	// the state is not required anymore once the bucket is full again
+	storeRateLimitState(cch, key, state, l.durationFor(l.capacity-state.Tokens))
+
+	if allowed {
+		return true, 0
+	}
+
+	return false, l.durationFor(1 - state.Tokens)
+}
+
+// tokenBucketScript implements the same logic as tokenBucketLimiter.allow. Timestamps and
+// durations are in microseconds to not exceed the precision of lua numbers.
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(state[1]) or capacity
+local updated_at = tonumber(state[2]) or now
+
+if now > updated_at then
+  tokens = math.min(capacity, tokens + (now - updated_at) / 1000000 * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+local ttl = math.ceil((capacity - tokens) / rate * 1000)
+if ttl > 0 then
+  redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', now)
+  redis.call('PEXPIRE', KEYS[1], ttl)
+else
+  redis.call('DEL', KEYS[1])
+end
+
+if allowed == 1 then
+  return {1, 0}
+end
+
+return {0, math.ceil((1 - tokens) / rate * 1000000)}
+`
+
+func (l *tokenBucketLimiter) allowShared(
+	ctx context.Context, se cache.ScriptEvaluator, key string, now time.Time,
+) (bool, time.Duration, error) {
+	return evalRateLimitScript(ctx, se, tokenBucketScript, key, l.rate, l.capacity, now.UnixMicro())
+}
+
+func (l *tokenBucketLimiter) durationFor(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.rate * float64(time.Second)))
+}
+
+// slidingWindowLimiter approximates a sliding window by weighting the count of the previous
+// fixed window with the part of it, which still overlaps with the sliding window.
+type slidingWindowLimiter struct {
+	limit  int
+	window time.Duration
+}
+
+type slidingWindowState struct {
+	WindowStart int64 `json:"window_start"`
+	Current     int   `json:"current"`
+	Previous    int   `json:"previous"`
+}
+
+func (l *slidingWindowLimiter) allow(cch cache.Cache, key string, now time.Time) (bool, time.Duration) {
+	windowStart := now.Truncate(l.window)
+
+	var state slidingWindowState
+	loadRateLimitState(cch, key, &state)
+
+	if state.WindowStart != windowStart.UnixNano() {
+		if state.WindowStart == windowStart.Add(-l.window).UnixNano() {
+			state.Previous = state.Current
+		} else {
+			state.Previous = 0
+		}
+
+		state.WindowStart = windowStart.UnixNano()
+		state.Current = 0
+	}
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - float64(elapsed)/float64(l.window)
+
+	if float64(state.Previous)*weight+float64(state.Current)+1 <= float64(l.limit) {
+		state.Current++
+
+		storeRateLimitState(cch, key, state, 2*l.window-elapsed)
+
+		return true, 0
+	}
+
+	// the current window is exhausted on its own, so only the next window can help
+	if state.Current+1 > l.limit || state.Previous == 0 {
+		return false, l.window - elapsed
+	}
+
+	// wait until the weight of the previous window dropped enough
+	required := 1 - float64(l.limit-state.Current-1)/float64(state.Previous)
+
+	return false, time.Duration(math.Ceil(required*float64(l.window))) - elapsed
+}
+
+// slidingWindowScript implements the same logic as slidingWindowLimiter.allow. Timestamps and
+// durations are in microseconds to not exceed the precision of lua numbers.
+const slidingWindowScript = `
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local window_start = now - (now % window)
+local state = redis.call('HMGET', KEYS[1], 'window_start', 'current', 'previous')
+local current = tonumber(state[2]) or 0
+local previous = tonumber(state[3]) or 0
+
+if tonumber(state[1]) ~= window_start then
+  if tonumber(state[1]) == window_start - window then
+    previous = current
+  else
+    previous = 0
+  end
+
+  current = 0
+end
+
+local elapsed = now - window_start
+local weight = 1 - elapsed / window
+
+if previous * weight + current + 1 <= limit then
+  current = current + 1
+
+  redis.call('HSET', KEYS[1], 'window_start', window_start, 'current', current, 'previous', previous)
+  redis.call('PEXPIRE', KEYS[1], math.ceil((2 * window - elapsed) / 1000))
+
+  return {1, 0}
+end
+
+if current + 1 > limit or previous == 0 then
+  return {0, window - elapsed}
+end
+
+local required = 1 - (limit - current - 1) / previous
+
+return {0, math.ceil(required * window) - elapsed}
+`
+
+func (l *slidingWindowLimiter) allowShared(
+	ctx context.Context, se cache.ScriptEvaluator, key string, now time.Time,
+) (bool, time.Duration, error) {
+	return evalRateLimitScript(ctx, se, slidingWindowScript, key, l.limit, l.window.Microseconds(), now.UnixMicro())
+}
+
+func evalRateLimitScript(
+	ctx context.Context, se cache.ScriptEvaluator, script, key string, args ...any,
+) (bool, time.Duration, error) {
+	res, err := se.Eval(ctx, script, []string{key}, args...)
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := res.([]any)
+	if !ok || len(values) != 2 { //nolint:gomnd
+		return false, 0, errUnexpectedScriptResult
+	}
+
+	allowed, ok := values[0].(int64)
+	if !ok {
+		return false, 0, errUnexpectedScriptResult
+	}
+
+	retryAfter, ok := values[1].(int64)
+	if !ok {
+		return false, 0, errUnexpectedScriptResult
+	}
+
+	return allowed == 1, time.Duration(retryAfter) * time.Microsecond, nil
+}
+
+func loadRateLimitState(cch cache.Cache, key string, state any) {
+	if rawState, ok := cch.Get(key).(string); ok {
+		// a broken state is treated like a missing one
+		_ = json.Unmarshal([]byte(rawState), state)
+	}
+}
+
+func storeRateLimitState(cch cache.Cache, key string, state any, ttl time.Duration) {
+	rawState, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+
+	cch.Set(key, string(rawState), ttl)
+}
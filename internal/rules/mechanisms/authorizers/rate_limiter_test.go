@@ -0,0 +1,131 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/cache/redis"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	t.Parallel()
+
+	// aligned to the windows used below
+	start := time.Unix(1000, 0)
+
+	type step struct {
+		at         time.Duration
+		allowed    bool
+		retryAfter time.Duration
+	}
+
+	for _, tc := range []struct {
+		uc      string
+		limiter rateLimiter
+		steps   []step
+	}{
+		{
+			uc:      "token bucket without burst",
+			limiter: newRateLimiter(rateLimitAlgorithmTokenBucket, 2, 1*time.Second, 2),
+			steps: []step{
+				{at: 0, allowed: true},
+				{at: 0, allowed: true},
+				{at: 0, allowed: false, retryAfter: 500 * time.Millisecond},
+				{at: 500 * time.Millisecond, allowed: true},
+				{at: 500 * time.Millisecond, allowed: false, retryAfter: 500 * time.Millisecond},
+				{at: 10 * time.Second, allowed: true},
+				{at: 10 * time.Second, allowed: true},
+				{at: 10 * time.Second, allowed: false, retryAfter: 500 * time.Millisecond},
+			},
+		},
+		{
+			uc:      "token bucket with burst",
+			limiter: newRateLimiter(rateLimitAlgorithmTokenBucket, 1, 1*time.Second, 3),
+			steps: []step{
+				{at: 0, allowed: true},
+				{at: 0, allowed: true},
+				{at: 0, allowed: true},
+				{at: 0, allowed: false, retryAfter: 1 * time.Second},
+				{at: 250 * time.Millisecond, allowed: false, retryAfter: 750 * time.Millisecond},
+				{at: 1 * time.Second, allowed: true},
+			},
+		},
+		{
+			uc:      "sliding window",
+			limiter: newRateLimiter(rateLimitAlgorithmSlidingWindow, 2, 10*time.Second, 0),
+			steps: []step{
+				{at: 0, allowed: true},
+				{at: 1 * time.Second, allowed: true},
+				{at: 2 * time.Second, allowed: false, retryAfter: 8 * time.Second},
+				// the previous window counts with the half of its requests
+				{at: 15 * time.Second, allowed: true},
+				{at: 15 * time.Second, allowed: false, retryAfter: 5 * time.Second},
+				{at: 18 * time.Second, allowed: false, retryAfter: 2 * time.Second},
+				// previous window is not adjacent anymore
+				{at: 40 * time.Second, allowed: true},
+				{at: 40 * time.Second, allowed: true},
+				{at: 40 * time.Second, allowed: false, retryAfter: 10 * time.Second},
+			},
+		},
+	} {
+		t.Run("case="+tc.uc+" using local store", func(t *testing.T) {
+			// GIVEN
+			cch := memory.New()
+
+			for idx, s := range tc.steps {
+				// WHEN
+				allowed, retryAfter := tc.limiter.allow(cch, "foo", start.Add(s.at))
+
+				// THEN
+				assert.Equal(t, s.allowed, allowed, "step %d", idx)
+				assert.Equal(t, s.retryAfter, retryAfter, "step %d", idx)
+			}
+		})
+
+		t.Run("case="+tc.uc+" using shared store", func(t *testing.T) {
+			// GIVEN
+			srv := miniredis.RunT(t)
+
+			cch, err := redis.NewCache(map[string]any{
+				"address": srv.Addr(),
+				"tls":     map[string]any{"disabled": true},
+			}, log.Logger)
+			require.NoError(t, err)
+
+			defer cch.Stop(context.Background())
+
+			for idx, s := range tc.steps {
+				// WHEN
+				allowed, retryAfter, err := tc.limiter.allowShared(context.Background(), cch, "foo", start.Add(s.at))
+
+				// THEN
+				require.NoError(t, err)
+				assert.Equal(t, s.allowed, allowed, "step %d", idx)
+				assert.Equal(t, s.retryAfter, retryAfter, "step %d", idx)
+			}
+		})
+	}
+}
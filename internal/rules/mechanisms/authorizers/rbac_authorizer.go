@@ -0,0 +1,250 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthorizerTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authorizer, error) {
+			if typ != AuthorizerRBAC {
+				return false, nil, nil
+			}
+
+			auth, err := newRBACAuthorizer(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type rbacAuthorizer struct {
+	id       string
+	file     *rbacPolicyFile
+	policy   *rbacPolicy
+	resource template.Template
+}
+
+func newRBACAuthorizer(id string, rawConfig map[string]any) (*rbacAuthorizer, error) {
+	type Config struct {
+		PolicyFile string            `mapstructure:"policy_file"`
+		Policy     *rbacPolicyConfig `mapstructure:"policy"`
+		Resource   template.Template `mapstructure:"resource"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal rbac authorizer config").
+			CausedBy(err)
+	}
+
+	if len(conf.PolicyFile) == 0 && conf.Policy == nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"rbac authorizer requires either policy or policy_file to be set")
+	}
+
+	auth := &rbacAuthorizer{id: id, resource: conf.Resource}
+
+	if conf.Policy != nil {
+		policy, err := newRBACPolicy(conf.Policy)
+		if err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrConfiguration, "failed to create rbac authorizer policy").
+				CausedBy(err)
+		}
+
+		auth.policy = policy
+	}
+
+	if len(conf.PolicyFile) != 0 {
+		file, err := newRBACPolicyFile(conf.PolicyFile)
+		if err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrConfiguration, "failed to load rbac authorizer policy file").
+				CausedBy(err)
+		}
+
+		auth.file = file
+	}
+
+	return auth, nil
+}
+
+func (a *rbacAuthorizer) Execute(ctx heimdall.Context, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authorizing using rbac authorizer")
+
+	if sub == nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to execute rbac authorizer due to 'nil' subject").
+			WithErrorContext(a)
+	}
+
+	req := ctx.Request()
+
+	var resource string
+
+	if a.resource != nil {
+		var err error
+
+		resource, err = a.resource.Render(map[string]any{
+			"Request": req,
+			"Subject": sub,
+		})
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to render resource").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
+	// the inline policy takes precedence over the policy from the file
+	policies := make([]*rbacPolicy, 0, 2) //nolint:gomnd
+	if a.policy != nil {
+		policies = append(policies, a.policy)
+	}
+
+	if a.file != nil {
+		policies = append(policies, a.file.current())
+	}
+
+	path := x.IfThenElseExec(req.URL != nil,
+		func() string { return req.URL.Path },
+		func() string { return "" })
+
+	role, permission := a.findGrant(policies, sub, req.Method, path, resource)
+	if permission == nil {
+		return errorchain.NewWithMessage(heimdall.ErrAuthorization, "no permission grants access").
+			WithErrorContext(a)
+	}
+
+	logger.Info().
+		Str("_id", a.id).
+		Str("_subject", sub.ID).
+		Str("_role", role).
+		Str("_permission", permission.name).
+		Msg("Access granted")
+
+	return nil
+}
+
+// findGrant returns the first permission of the roles bound to the subject, which matches
+// the given request properties together with the role it belongs to. A role defined in
+// multiple policies is taken from the first policy defining it.
+func (a *rbacAuthorizer) findGrant(
+	policies []*rbacPolicy, sub *subject.Subject, method, path, resource string,
+) (string, *rbacPermission) {
+	var roles []string
+
+	for _, policy := range policies {
+		for _, role := range policy.rolesOf(sub) {
+			if !slices.Contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	for _, role := range roles {
+		for _, policy := range policies {
+			permissions, defined := policy.roles[role]
+			if !defined {
+				continue
+			}
+
+			for _, permission := range permissions {
+				if permission.matches(method, path, resource) {
+					return role, permission
+				}
+			}
+
+			break
+		}
+	}
+
+	return "", nil
+}
+
+func (a *rbacAuthorizer) WithConfig(rawConfig map[string]any) (Authorizer, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Policy   *rbacPolicyConfig `mapstructure:"policy"`
+		Resource template.Template `mapstructure:"resource"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal rbac authorizer config").
+			CausedBy(err)
+	}
+
+	policy := a.policy
+
+	if conf.Policy != nil {
+		var err error
+
+		policy, err = newRBACPolicy(conf.Policy)
+		if err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrConfiguration, "failed to create rbac authorizer policy").
+				CausedBy(err)
+		}
+	}
+
+	return &rbacAuthorizer{
+		id:       a.id,
+		file:     a.file,
+		policy:   policy,
+		resource: x.IfThenElse(conf.Resource != nil, conf.Resource, a.resource),
+	}, nil
+}
+
+func (a *rbacAuthorizer) HandlerID() string { return a.id }
+
+// Start starts watching the policy file, if configured.
+func (a *rbacAuthorizer) Start(logger zerolog.Logger) error {
+	if a.file == nil {
+		return nil
+	}
+
+	return a.file.start(logger)
+}
+
+func (a *rbacAuthorizer) Stop() error {
+	if a.file == nil {
+		return nil
+	}
+
+	return a.file.stop()
+}
+
+func (a *rbacAuthorizer) ContinueOnError() bool { return false }
@@ -0,0 +1,593 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+const testRBACPolicy = `
+role_bindings:
+  - roles: [ reader ]
+    attributes:
+      groups: users
+  - roles: [ admin, reader ]
+    subjects: [ alice ]
+roles:
+  reader:
+    permissions:
+      - name: read_articles
+        methods: [ get, head ]
+        paths: [ "/articles/<**>" ]
+  admin:
+    permissions:
+      - name: manage_articles
+        methods: [ POST, PUT, DELETE ]
+        paths: [ "/articles/<**>" ]
+      - name: manage_tenant
+        resources: [ "tenant:<{acme,example}>" ]
+`
+
+func TestCreateRBACAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte(testRBACPolicy), 0o600))
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *rbacAuthorizer)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "either policy or policy_file")
+			},
+		},
+		{
+			uc:     "with unsupported fields",
+			config: []byte(`foo: bar`),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc:     "with not existing policy file",
+			config: []byte(`policy_file: /does/not/exist.yaml`),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to read the policy file")
+			},
+		},
+		{
+			uc: "with role binding without roles",
+			config: []byte(`
+policy:
+  role_bindings:
+    - subjects: [ foo ]
+`),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no roles defined in role binding 1")
+			},
+		},
+		{
+			uc: "with role binding without subjects and attributes",
+			config: []byte(`
+policy:
+  role_bindings:
+    - roles: [ foo ]
+`),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "neither subjects, nor attributes")
+			},
+		},
+		{
+			uc: "with permission without name",
+			config: []byte(`
+policy:
+  roles:
+    foo:
+      permissions:
+        - methods: [ GET ]
+`),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "invalid permission 1 of role foo")
+			},
+		},
+		{
+			uc: "with unsupported matching strategy",
+			config: []byte(`
+policy:
+  matching_strategy: foo
+`),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported matching strategy")
+			},
+		},
+		{
+			uc: "with invalid pattern",
+			config: []byte(`
+policy:
+  matching_strategy: regex
+  roles:
+    foo:
+      permissions:
+        - name: bar
+          paths: [ "/<(foo>" ]
+`),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to compile pattern")
+			},
+		},
+		{
+			uc: "with inline policy and policy file",
+			id: "authz",
+			config: []byte(`
+policy_file: ` + policyFile + `
+resource: "tenant:{{ .Request.URL.Host }}"
+policy:
+  role_bindings:
+    - roles: [ auditor ]
+      subjects: [ bob ]
+  roles:
+    auditor:
+      permissions:
+        - name: audit
+          methods: [ GET ]
+`),
+			assert: func(t *testing.T, err error, auth *rbacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "authz", auth.HandlerID())
+				assert.Equal(t, "authz", auth.HandlerID())
+				assert.False(t, auth.ContinueOnError())
+				assert.NotNil(t, auth.resource)
+
+				require.NotNil(t, auth.policy)
+				assert.Len(t, auth.policy.bindings, 1)
+				assert.Len(t, auth.policy.roles, 1)
+
+				require.NotNil(t, auth.file)
+				policy := auth.file.current()
+				require.NotNil(t, policy)
+				assert.Len(t, policy.bindings, 2)
+				assert.Len(t, policy.roles["reader"], 1)
+				assert.Len(t, policy.roles["admin"], 2)
+				assert.Equal(t, []string{"GET", "HEAD"}, policy.roles["reader"][0].methods)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newRBACAuthorizer(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateRBACAuthorizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte(testRBACPolicy), 0o600))
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *rbacAuthorizer, configured *rbacAuthorizer)
+	}{
+		{
+			uc: "no new configuration provided",
+			assert: func(t *testing.T, err error, prototype *rbacAuthorizer, configured *rbacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with not overridable policy file",
+			config: []byte(`policy_file: ` + policyFile),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with invalid policy",
+			config: []byte(`
+policy:
+  role_bindings:
+    - roles: [ foo ]
+`),
+			assert: func(t *testing.T, err error, _ *rbacAuthorizer, _ *rbacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to create rbac authorizer policy")
+			},
+		},
+		{
+			uc: "with rule specific policy",
+			config: []byte(`
+policy:
+  role_bindings:
+    - roles: [ reader ]
+      subjects: [ bob ]
+`),
+			assert: func(t *testing.T, err error, prototype *rbacAuthorizer, configured *rbacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, configured)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.HandlerID(), configured.HandlerID())
+				assert.Same(t, prototype.file, configured.file)
+				assert.Equal(t, prototype.resource, configured.resource)
+				assert.NotSame(t, prototype.policy, configured.policy)
+				assert.Len(t, configured.policy.bindings, 1)
+			},
+		},
+		{
+			uc:     "with new resource",
+			config: []byte(`resource: "{{ .Request.URL.Path }}"`),
+			assert: func(t *testing.T, err error, prototype *rbacAuthorizer, configured *rbacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, configured)
+				assert.Same(t, prototype.file, configured.file)
+				assert.Same(t, prototype.policy, configured.policy)
+				assert.NotEqual(t, prototype.resource, configured.resource)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+policy_file: ` + policyFile + `
+resource: "tenant:{{ .Request.URL.Host }}"
+policy:
+  role_bindings:
+    - roles: [ admin ]
+      subjects: [ bob ]
+`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newRBACAuthorizer("authz", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var configured *rbacAuthorizer
+			if err == nil {
+				configured = auth.(*rbacAuthorizer) // nolint: forcetypeassert
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestRBACAuthorizerExecute(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc      string
+		config  []byte
+		subject *subject.Subject
+		method  string
+		url     string
+		assert  func(t *testing.T, err error, sub *subject.Subject, logs string)
+	}{
+		{
+			uc: "nil subject",
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "'nil' subject")
+			},
+		},
+		{
+			uc:      "access granted via role bound by attribute",
+			subject: &subject.Subject{ID: "bob", Attributes: map[string]any{"groups": []any{"users"}}},
+			method:  "GET",
+			url:     "http://acme.local/articles/1",
+			assert: func(t *testing.T, err error, sub *subject.Subject, logs string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotContains(t, sub.Attributes, "authz")
+				assert.Contains(t, logs, `"_role":"reader","_permission":"read_articles"`)
+			},
+		},
+		{
+			uc:      "access granted via role bound by subject id",
+			subject: &subject.Subject{ID: "alice"},
+			method:  "DELETE",
+			url:     "http://acme.local/articles/1",
+			assert: func(t *testing.T, err error, sub *subject.Subject, logs string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotContains(t, sub.Attributes, "authz")
+				assert.Contains(t, logs, `"_role":"admin","_permission":"manage_articles"`)
+			},
+		},
+		{
+			uc:      "access denied due to not matching method",
+			subject: &subject.Subject{ID: "bob", Attributes: map[string]any{"groups": "users"}},
+			method:  "POST",
+			url:     "http://acme.local/articles/1",
+			assert: func(t *testing.T, err error, _ *subject.Subject, logs string) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "no permission grants access")
+				assert.NotContains(t, logs, "Access granted")
+
+				var identifier interface{ HandlerID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "authz", identifier.HandlerID())
+			},
+		},
+		{
+			uc:      "access denied due to missing role binding",
+			subject: &subject.Subject{ID: "bob", Attributes: map[string]any{"groups": []any{"guests"}}},
+			method:  "GET",
+			url:     "http://acme.local/articles/1",
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+			},
+		},
+		{
+			uc:      "access denied due to permission requiring resource, which is not configured",
+			subject: &subject.Subject{ID: "alice"},
+			method:  "GET",
+			url:     "http://acme.local/tenant",
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+			},
+		},
+		{
+			uc:      "access granted based on resource",
+			config:  []byte(`resource: "tenant:{{ .Request.URL.Hostname | splitList \".\" | first }}"`),
+			subject: &subject.Subject{ID: "alice"},
+			method:  "GET",
+			url:     "http://acme.local/tenant",
+			assert: func(t *testing.T, err error, sub *subject.Subject, logs string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotContains(t, sub.Attributes, "authz")
+				assert.Contains(t, logs, `"_role":"admin","_permission":"manage_tenant"`)
+			},
+		},
+		{
+			uc:      "access denied based on resource",
+			config:  []byte(`resource: "tenant:{{ .Request.URL.Hostname | splitList \".\" | first }}"`),
+			subject: &subject.Subject{ID: "alice"},
+			method:  "GET",
+			url:     "http://foo.local/tenant",
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+			},
+		},
+		{
+			uc: "role defined in inline policy takes precedence",
+			config: []byte(`
+policy:
+  role_bindings:
+    - roles: [ reader ]
+      subjects: [ bob ]
+  roles:
+    reader:
+      permissions:
+        - name: read_public_articles
+          methods: [ GET ]
+          paths: [ "/articles/public/<**>" ]
+`),
+			subject: &subject.Subject{ID: "bob", Attributes: map[string]any{"groups": "users"}},
+			method:  "GET",
+			url:     "http://acme.local/articles/1",
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+			},
+		},
+		{
+			uc:      "fails rendering resource",
+			config:  []byte(`resource: "{{ .Request.Header }}"`),
+			subject: &subject.Subject{ID: "alice"},
+			method:  "GET",
+			url:     "http://acme.local/tenant",
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ string) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to render resource")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+			require.NoError(t, os.WriteFile(policyFile, []byte(testRBACPolicy), 0o600))
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			if conf == nil {
+				conf = make(map[string]any)
+			}
+
+			conf["policy_file"] = policyFile
+
+			auth, err := newRBACAuthorizer("authz", conf)
+			require.NoError(t, err)
+
+			logs := &bytes.Buffer{}
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(zerolog.New(logs).WithContext(context.Background()))
+
+			if tc.subject != nil {
+				reqURL, err := url.Parse(tc.url)
+				require.NoError(t, err)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{Method: tc.method, URL: reqURL})
+			}
+
+			// WHEN
+			err = auth.Execute(ctx, tc.subject)
+
+			// THEN
+			tc.assert(t, err, tc.subject, logs.String())
+		})
+	}
+}
+
+func TestRBACAuthorizerReloadsPolicyFile(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte(testRBACPolicy), 0o600))
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`policy_file: ` + policyFile))
+	require.NoError(t, err)
+
+	auth, err := newRBACAuthorizer("authz", conf)
+	require.NoError(t, err)
+
+	require.NoError(t, auth.Start(zerolog.Nop()))
+	t.Cleanup(func() { auth.Stop() })
+
+	execute := func(subjectID string) error {
+		ctx := mocks.NewContextMock(t)
+		ctx.EXPECT().AppContext().Return(context.Background())
+		ctx.EXPECT().Request().
+			Return(&heimdall.Request{Method: "GET", URL: &url.URL{Path: "/articles/1"}})
+
+		return auth.Execute(ctx, &subject.Subject{ID: subjectID})
+	}
+
+	require.NoError(t, execute("alice"))
+	require.Error(t, execute("bob"))
+
+	// WHEN
+	require.NoError(t, os.WriteFile(policyFile, []byte(`
+role_bindings:
+  - roles: [ reader ]
+    subjects: [ bob ]
+roles:
+  reader:
+    permissions:
+      - name: read_articles
+        methods: [ GET ]
+`), 0o600))
+
+	// THEN
+	assert.Eventually(t, func() bool { return execute("bob") == nil }, 2*time.Second, 10*time.Millisecond)
+	require.Error(t, execute("alice"))
+
+	// WHEN
+	require.NoError(t, os.WriteFile(policyFile, []byte(`foo: bar`), 0o600))
+	time.Sleep(100 * time.Millisecond)
+
+	// THEN
+	require.NoError(t, execute("bob"))
+}
@@ -0,0 +1,318 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+type rbacPolicyConfig struct {
+	MatchingStrategy string                    `mapstructure:"matching_strategy"`
+	RoleBindings     []rbacRoleBindingConfig   `mapstructure:"role_bindings"`
+	Roles            map[string]rbacRoleConfig `mapstructure:"roles"`
+}
+
+type rbacRoleBindingConfig struct {
+	Roles      []string          `mapstructure:"roles"`
+	Subjects   []string          `mapstructure:"subjects"`
+	Attributes map[string]string `mapstructure:"attributes"`
+}
+
+type rbacRoleConfig struct {
+	Permissions []rbacPermissionConfig `mapstructure:"permissions"`
+}
+
+type rbacPermissionConfig struct {
+	Name      string   `mapstructure:"name"`
+	Methods   []string `mapstructure:"methods"`
+	Paths     []string `mapstructure:"paths"`
+	Resources []string `mapstructure:"resources"`
+}
+
+type rbacRoleBinding struct {
+	roles      []string
+	subjects   []string
+	attributes map[string]string
+}
+
+type rbacPermission struct {
+	name      string
+	methods   []string
+	paths     []patternmatcher.PatternMatcher
+	resources []patternmatcher.PatternMatcher
+}
+
+type rbacPolicy struct {
+	bindings []rbacRoleBinding
+	roles    map[string][]*rbacPermission
+}
+
+func newRBACPolicy(conf *rbacPolicyConfig) (*rbacPolicy, error) {
+	strategy := x.IfThenElse(len(conf.MatchingStrategy) != 0, conf.MatchingStrategy, "glob")
+	if strategy != "glob" && strategy != "regex" {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported matching strategy %s", strategy)
+	}
+
+	policy := &rbacPolicy{roles: make(map[string][]*rbacPermission, len(conf.Roles))}
+
+	for idx, binding := range conf.RoleBindings {
+		if len(binding.Roles) == 0 {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"no roles defined in role binding %d", idx+1)
+		}
+
+		if len(binding.Subjects) == 0 && len(binding.Attributes) == 0 {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"neither subjects, nor attributes defined in role binding %d", idx+1)
+		}
+
+		policy.bindings = append(policy.bindings, rbacRoleBinding{
+			roles:      binding.Roles,
+			subjects:   binding.Subjects,
+			attributes: binding.Attributes,
+		})
+	}
+
+	for name, role := range conf.Roles {
+		permissions := make([]*rbacPermission, len(role.Permissions))
+
+		for idx, permission := range role.Permissions {
+			perm, err := newRBACPermission(strategy, permission)
+			if err != nil {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"invalid permission %d of role %s", idx+1, name).CausedBy(err)
+			}
+
+			permissions[idx] = perm
+		}
+
+		policy.roles[name] = permissions
+	}
+
+	return policy, nil
+}
+
+func newRBACPermission(strategy string, conf rbacPermissionConfig) (*rbacPermission, error) {
+	if len(conf.Name) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "no name defined")
+	}
+
+	compile := func(patterns []string) ([]patternmatcher.PatternMatcher, error) {
+		matchers := make([]patternmatcher.PatternMatcher, len(patterns))
+
+		for idx, pattern := range patterns {
+			matcher, err := patternmatcher.NewPatternMatcher(strategy, pattern)
+			if err != nil {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"failed to compile pattern %s", pattern).CausedBy(err)
+			}
+
+			matchers[idx] = matcher
+		}
+
+		return matchers, nil
+	}
+
+	paths, err := compile(conf.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := compile(conf.Resources)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := make([]string, len(conf.Methods))
+	for idx, method := range conf.Methods {
+		methods[idx] = strings.ToUpper(method)
+	}
+
+	return &rbacPermission{name: conf.Name, methods: methods, paths: paths, resources: resources}, nil
+}
+
+// rolesOf returns the roles bound to the given subject by this policy.
+func (p *rbacPolicy) rolesOf(sub *subject.Subject) []string {
+	var roles []string
+
+	for _, binding := range p.bindings {
+		if binding.matches(sub) {
+			roles = append(roles, binding.roles...)
+		}
+	}
+
+	return roles
+}
+
+func (b rbacRoleBinding) matches(sub *subject.Subject) bool {
+	if len(b.subjects) != 0 && !slices.Contains(b.subjects, sub.ID) {
+		return false
+	}
+
+	for path, expected := range b.attributes {
+		if !attributeHasValue(sub.Attributes, path, expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// attributeHasValue checks whether the attribute referenced by the given dot separated path
+// has the expected value. If the attribute is a list, it is sufficient if one of its entries
+// has the expected value.
+func attributeHasValue(attributes map[string]any, path, expected string) bool {
+	var value any = attributes
+
+	for _, key := range strings.Split(path, ".") {
+		entries, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		if value, ok = entries[key]; !ok {
+			return false
+		}
+	}
+
+	if values, ok := value.([]any); ok {
+		return slices.ContainsFunc(values, func(v any) bool { return fmt.Sprint(v) == expected })
+	}
+
+	return value != nil && fmt.Sprint(value) == expected
+}
+
+func (p *rbacPermission) matches(method, path, resource string) bool {
+	if len(p.methods) != 0 && !slices.Contains(p.methods, method) {
+		return false
+	}
+
+	matchesAny := func(matchers []patternmatcher.PatternMatcher, value string) bool {
+		return len(matchers) == 0 ||
+			slices.ContainsFunc(matchers, func(m patternmatcher.PatternMatcher) bool { return m.Match(value) })
+	}
+
+	if !matchesAny(p.paths, path) {
+		return false
+	}
+
+	// permissions restricted to resources never match if no resource is available
+	return len(p.resources) == 0 || (len(resource) != 0 && matchesAny(p.resources, resource))
+}
+
+// rbacPolicyFile holds the policy loaded from a file. The file is reloaded after it
+// has been changed.
+type rbacPolicyFile struct {
+	path string
+	w    *watcher.Watcher
+
+	mut      sync.RWMutex
+	fileHash []byte
+	policy   *rbacPolicy
+}
+
+func newRBACPolicyFile(path string) (*rbacPolicyFile, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to get the absolute path for the policy file").CausedBy(err)
+	}
+
+	file := &rbacPolicyFile{path: absPath}
+
+	if err = file.load(); err != nil {
+		return nil, err
+	}
+
+	file.w = watcher.New(absPath)
+
+	return file, nil
+}
+
+func (f *rbacPolicyFile) start(logger zerolog.Logger) error { return f.w.Start(logger, f.load) }
+
+func (f *rbacPolicyFile) stop() error { return f.w.Stop() }
+
+func (f *rbacPolicyFile) load() error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to read the policy file").
+			CausedBy(err)
+	}
+
+	hash := sha256.Sum256(data)
+
+	f.mut.RLock()
+	unchanged := bytes.Equal(f.fileHash, hash[:])
+	f.mut.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	var (
+		rawPolicy map[string]any
+		conf      rbacPolicyConfig
+	)
+
+	if err = yaml.Unmarshal(data, &rawPolicy); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to parse the policy file").
+			CausedBy(err)
+	}
+
+	if err = decodeConfig(rawPolicy, &conf); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to decode the policy file").
+			CausedBy(err)
+	}
+
+	policy, err := newRBACPolicy(&conf)
+	if err != nil {
+		return err
+	}
+
+	f.mut.Lock()
+	f.fileHash = hash[:]
+	f.policy = policy
+	f.mut.Unlock()
+
+	return nil
+}
+
+func (f *rbacPolicyFile) current() *rbacPolicy {
+	f.mut.RLock()
+	defer f.mut.RUnlock()
+
+	return f.policy
+}
@@ -0,0 +1,427 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/endpoint"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/values"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	rebacConsistencyMinimizeLatency = "minimize_latency"
+	rebacConsistencyFullyConsistent = "fully_consistent"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthorizerTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authorizer, error) {
+			if typ != AuthorizerReBAC {
+				return false, nil, nil
+			}
+
+			auth, err := newReBACAuthorizer(id, conf)
+
+			return true, auth, err
+		})
+}
+
+// rebacTuple describes a single (object, relation, subject) check.
+type rebacTuple struct {
+	Object   template.Template `mapstructure:"object"`
+	Relation string            `mapstructure:"relation"`
+	Subject  template.Template `mapstructure:"subject"`
+}
+
+type rebacDecision struct {
+	Allowed   bool   `json:"allowed"`
+	CheckedAt string `json:"checked_at,omitempty"`
+}
+
+type rebacAuthorizer struct {
+	id               string
+	api              string
+	c                rebacChecker
+	checks           []rebacTuple
+	consistency      string
+	consistencyToken template.Template
+	ttl              time.Duration
+	v                values.Values
+}
+
+func newReBACAuthorizer(id string, rawConfig map[string]any) (*rebacAuthorizer, error) {
+	type Config struct {
+		API              string             `mapstructure:"api"`
+		GRPC             *rebacGRPCConfig   `mapstructure:"grpc"`
+		Endpoint         *endpoint.Endpoint `mapstructure:"endpoint"`
+		Checks           []rebacTuple       `mapstructure:"checks"`
+		Consistency      string             `mapstructure:"consistency"`
+		ConsistencyToken template.Template  `mapstructure:"consistency_token"`
+		CacheTTL         time.Duration      `mapstructure:"cache_ttl"`
+		Values           values.Values      `mapstructure:"values"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal rebac authorizer config").
+			CausedBy(err)
+	}
+
+	if (conf.GRPC == nil) == (conf.Endpoint == nil) {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"rebac authorizer requires either grpc or endpoint to be set")
+	}
+
+	if err := validateReBACChecks(conf.Checks); err != nil {
+		return nil, err
+	}
+
+	api := x.IfThenElse(len(conf.API) != 0, conf.API, rebacAPISpiceDB)
+	if api != rebacAPISpiceDB && api != rebacAPIOpenFGA && api != rebacAPIKeto {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported rebac authorizer api %s", api)
+	}
+
+	if conf.GRPC != nil && api != rebacAPISpiceDB {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"rebac authorizer supports grpc for the %s api only", rebacAPISpiceDB)
+	}
+
+	consistency := x.IfThenElse(len(conf.Consistency) != 0, conf.Consistency, rebacConsistencyMinimizeLatency)
+	if consistency != rebacConsistencyMinimizeLatency && consistency != rebacConsistencyFullyConsistent {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported rebac authorizer consistency %s", consistency)
+	}
+
+	if consistency == rebacConsistencyFullyConsistent && api == rebacAPIKeto {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"rebac authorizer does not support %s consistency for the %s api", consistency, api)
+	}
+
+	if err := validateReBACConsistencyToken(api, conf.ConsistencyToken); err != nil {
+		return nil, err
+	}
+
+	var (
+		checker rebacChecker
+		err     error
+	)
+
+	if conf.GRPC != nil {
+		checker, err = newGRPCRebacChecker(conf.GRPC)
+	} else {
+		checker, err = newHTTPRebacChecker(conf.Endpoint, newRebacHTTPAPI(api))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &rebacAuthorizer{
+		id:               id,
+		api:              api,
+		c:                checker,
+		checks:           conf.Checks,
+		consistency:      consistency,
+		consistencyToken: conf.ConsistencyToken,
+		ttl:              conf.CacheTTL,
+		v:                conf.Values,
+	}, nil
+}
+
+func validateReBACChecks(checks []rebacTuple) error {
+	if len(checks) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "no checks provided for rebac authorizer")
+	}
+
+	for idx, check := range checks {
+		if check.Object == nil || len(check.Relation) == 0 || check.Subject == nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"check %d requires object, relation and subject to be set", idx+1)
+		}
+	}
+
+	return nil
+}
+
+func validateReBACConsistencyToken(api string, token template.Template) error {
+	if token != nil && api != rebacAPISpiceDB {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"rebac authorizer supports consistency_token for the %s api only", rebacAPISpiceDB)
+	}
+
+	return nil
+}
+
+func (a *rebacAuthorizer) Execute(ctx heimdall.Context, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authorizing using rebac authorizer")
+
+	if sub == nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to execute rebac authorizer due to 'nil' subject").
+			WithErrorContext(a)
+	}
+
+	tplData := map[string]any{
+		"Request": ctx.Request(),
+		"Subject": sub,
+		"Values":  a.v,
+	}
+
+	var token string
+
+	if a.consistencyToken != nil {
+		var err error
+
+		token, err = a.consistencyToken.Render(tplData)
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to render consistency token").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
+	checks := make([]*rebacCheck, len(a.checks))
+
+	for idx, tuple := range a.checks {
+		chk, err := a.newCheck(tuple, tplData, token)
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrInternal, "failed to create check %d", idx+1).
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+
+		checks[idx] = chk
+	}
+
+	// none of the supported apis offers a bulk check in the used versions, so each check is
+	// sent as a separate request. These are sent concurrently.
+	decisions := make([]*rebacDecision, len(checks))
+	grp, grpCtx := errgroup.WithContext(ctx.AppContext())
+
+	for idx, chk := range checks {
+		idx, chk := idx, chk
+
+		grp.Go(func() error {
+			decision, err := a.check(grpCtx, chk)
+			decisions[idx] = decision
+
+			return err
+		})
+	}
+
+	if err := grp.Wait(); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrCommunication, "relation check failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	for idx, decision := range decisions {
+		if !decision.Allowed {
+			return errorchain.NewWithMessagef(heimdall.ErrAuthorization,
+				"check %d failed: %s", idx+1, checks[idx]).
+				WithErrorContext(a)
+		}
+	}
+
+	return nil
+}
+
+func (a *rebacAuthorizer) WithConfig(rawConfig map[string]any) (Authorizer, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Checks           []rebacTuple      `mapstructure:"checks"`
+		ConsistencyToken template.Template `mapstructure:"consistency_token"`
+		CacheTTL         *time.Duration    `mapstructure:"cache_ttl"`
+		Values           values.Values     `mapstructure:"values"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal rebac authorizer config").
+			CausedBy(err)
+	}
+
+	if len(conf.Checks) != 0 {
+		if err := validateReBACChecks(conf.Checks); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateReBACConsistencyToken(a.api, conf.ConsistencyToken); err != nil {
+		return nil, err
+	}
+
+	return &rebacAuthorizer{
+		id:               a.id,
+		api:              a.api,
+		c:                a.c,
+		checks:           x.IfThenElse(len(conf.Checks) != 0, conf.Checks, a.checks),
+		consistency:      a.consistency,
+		consistencyToken: x.IfThenElse(conf.ConsistencyToken != nil, conf.ConsistencyToken, a.consistencyToken),
+		ttl: x.IfThenElseExec(conf.CacheTTL != nil,
+			func() time.Duration { return *conf.CacheTTL },
+			func() time.Duration { return a.ttl }),
+		v: a.v.Merge(conf.Values),
+	}, nil
+}
+
+func (a *rebacAuthorizer) HandlerID() string { return a.id }
+
+func (a *rebacAuthorizer) Start(_ zerolog.Logger) error { return nil }
+
+// Stop closes the connection to the relationship based access control system.
+func (a *rebacAuthorizer) Stop() error { return a.c.close() }
+
+func (a *rebacAuthorizer) ContinueOnError() bool { return false }
+
+func (a *rebacAuthorizer) newCheck(tuple rebacTuple, tplData map[string]any, token string) (*rebacCheck, error) {
+	rawObject, err := tuple.Object.Render(tplData)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSubject, err := tuple.Subject.Render(tplData)
+	if err != nil {
+		return nil, err
+	}
+
+	objectType, objectID, err := parseReBACObject(rawObject)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSubject, subjectRelation, _ := strings.Cut(rawSubject, "#")
+
+	subjectType, subjectID, err := parseReBACObject(rawSubject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rebacCheck{
+		objectType:       objectType,
+		objectID:         objectID,
+		relation:         tuple.Relation,
+		subjectType:      subjectType,
+		subjectID:        subjectID,
+		subjectRelation:  subjectRelation,
+		consistencyToken: token,
+		fullyConsistent:  a.consistency == rebacConsistencyFullyConsistent,
+	}, nil
+}
+
+func (a *rebacAuthorizer) check(ctx context.Context, chk *rebacCheck) (*rebacDecision, error) {
+	logger := zerolog.Ctx(ctx)
+	cch := cache.Ctx(ctx)
+
+	var cacheKey string
+
+	if a.ttl > 0 {
+		cacheKey = a.calculateCacheKey(chk)
+
+		if entry, ok := cch.Get(cacheKey).(string); ok {
+			var decision rebacDecision
+			if err := json.Unmarshal(stringx.ToBytes(entry), &decision); err == nil {
+				logger.Debug().Msg("Reusing check decision from cache")
+
+				return &decision, nil
+			}
+
+			logger.Warn().Msg("Wrong check decision format in cache")
+			cch.Delete(cacheKey)
+		}
+	}
+
+	result, err := a.c.check(ctx, chk)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &rebacDecision{Allowed: result.allowed, CheckedAt: result.checkedAt}
+
+	logger.Debug().
+		Str("_check", chk.String()).
+		Bool("_allowed", decision.Allowed).
+		Str("_checked_at", decision.CheckedAt).
+		Msg("Check decision received")
+
+	if a.ttl > 0 {
+		if rawDecision, err := json.Marshal(decision); err == nil {
+			cch.Set(cacheKey, stringx.ToString(rawDecision), a.ttl)
+		}
+	}
+
+	return decision, nil
+}
+
+// calculateCacheKey takes the consistency requirement into account, so that a decision
+// made for an older consistency token is not reused for requests with a newer one. Each
+// field is length prefixed, so that values cannot be shifted between fields.
+func (a *rebacAuthorizer) calculateCacheKey(chk *rebacCheck) string {
+	const int64BytesCount = 8
+
+	lenBytes := make([]byte, int64BytesCount)
+	hash := sha256.New()
+
+	for _, value := range []string{
+		stringx.ToString(a.c.identity()), a.id,
+		chk.objectType, chk.objectID, chk.relation, chk.subjectType, chk.subjectID, chk.subjectRelation,
+		x.IfThenElse(len(chk.consistencyToken) != 0, "at_least_as_fresh:"+chk.consistencyToken, a.consistency),
+	} {
+		binary.LittleEndian.PutUint64(lenBytes, uint64(len(value)))
+		hash.Write(lenBytes)
+		hash.Write(stringx.ToBytes(value))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func parseReBACObject(value string) (string, string, error) {
+	objectType, objectID, found := strings.Cut(value, ":")
+	if !found || len(objectType) == 0 || len(objectID) == 0 {
+		return "", "", errorchain.NewWithMessagef(heimdall.ErrInternal,
+			"'%s' is not a valid object reference of the form <type>:<id>", value)
+	}
+
+	return objectType, objectID, nil
+}
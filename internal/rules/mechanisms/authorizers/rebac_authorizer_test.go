@@ -0,0 +1,1035 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+// fakePermissionsService grants the permissions for the configured tuples only.
+type fakePermissionsService struct {
+	v1.UnimplementedPermissionsServiceServer
+
+	mut      sync.Mutex
+	allowed  []string
+	err      error
+	requests []*v1.CheckPermissionRequest
+	tokens   []string
+}
+
+func (s *fakePermissionsService) CheckPermission(
+	ctx context.Context, req *v1.CheckPermissionRequest,
+) (*v1.CheckPermissionResponse, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.requests = append(s.requests, req)
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		s.tokens = append(s.tokens, md.Get("authorization")...)
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	permissionship := v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+
+	for _, tuple := range s.allowed {
+		if tuple == spiceDBTupleString(req) {
+			permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+		}
+	}
+
+	return &v1.CheckPermissionResponse{
+		CheckedAt:      &v1.ZedToken{Token: "checked-at-token"},
+		Permissionship: permissionship,
+	}, nil
+}
+
+func spiceDBTupleString(req *v1.CheckPermissionRequest) string {
+	subjectRef := req.GetSubject()
+	subjectStr := subjectRef.GetObject().GetObjectType() + ":" + subjectRef.GetObject().GetObjectId()
+
+	if len(subjectRef.GetOptionalRelation()) != 0 {
+		subjectStr += "#" + subjectRef.GetOptionalRelation()
+	}
+
+	return req.GetResource().GetObjectType() + ":" + req.GetResource().GetObjectId() + "#" +
+		req.GetPermission() + "@" + subjectStr
+}
+
+func (s *fakePermissionsService) received() []*v1.CheckPermissionRequest {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return s.requests
+}
+
+func startFakeGRPCPermissionsService(t *testing.T, srv *fakePermissionsService) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	v1.RegisterPermissionsServiceServer(server, srv)
+
+	go func() { _ = server.Serve(listener) }()
+
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func startFakeHTTPPermissionsService(t *testing.T, srv *fakePermissionsService) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+			rw.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var checkReq v1.CheckPermissionRequest
+		require.NoError(t, protojson.Unmarshal(body, &checkReq))
+
+		md := metadata.New(map[string]string{"authorization": req.Header.Get("Authorization")})
+
+		resp, err := srv.CheckPermission(metadata.NewIncomingContext(req.Context(), md), &checkReq)
+		if err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		rawResp, err := protojson.Marshal(resp)
+		require.NoError(t, err)
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, err = rw.Write(rawResp)
+		require.NoError(t, err)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server.URL + "/v1/permissions/check"
+}
+
+func TestCreateReBACAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *rebacAuthorizer)
+	}{
+		{
+			uc: "without transport",
+			config: []byte(`
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "either grpc or endpoint")
+			},
+		},
+		{
+			uc: "with both transports",
+			config: []byte(`
+grpc:
+  address: 127.0.0.1:50051
+endpoint:
+  url: http://127.0.0.1:8443/v1/permissions/check
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "either grpc or endpoint")
+			},
+		},
+		{
+			uc: "without checks",
+			config: []byte(`
+grpc:
+  address: 127.0.0.1:50051
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no checks provided")
+			},
+		},
+		{
+			uc: "with incomplete check",
+			config: []byte(`
+grpc:
+  address: 127.0.0.1:50051
+checks:
+  - object: "document:1"
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "check 1 requires object, relation and subject")
+			},
+		},
+		{
+			uc: "with unsupported consistency",
+			config: []byte(`
+grpc:
+  address: 127.0.0.1:50051
+consistency: at_exact_snapshot
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported rebac authorizer consistency")
+			},
+		},
+		{
+			uc: "with unsupported api",
+			config: []byte(`
+api: zanzibar
+grpc:
+  address: 127.0.0.1:50051
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported rebac authorizer api")
+			},
+		},
+		{
+			uc: "with grpc for openfga api",
+			config: []byte(`
+api: openfga
+grpc:
+  address: 127.0.0.1:50051
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "grpc for the spicedb api only")
+			},
+		},
+		{
+			uc: "with fully consistent checks for keto api",
+			config: []byte(`
+api: keto
+endpoint:
+  url: http://127.0.0.1:4466/relation-tuples/check
+consistency: fully_consistent
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "does not support fully_consistent consistency for the keto api")
+			},
+		},
+		{
+			uc: "with consistency token for openfga api",
+			config: []byte(`
+api: openfga
+endpoint:
+  url: http://127.0.0.1:8080/stores/foo/check
+consistency_token: '{{ .Request.Header "X-Consistency-Token" }}'
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "consistency_token for the spicedb api only")
+			},
+		},
+		{
+			uc: "with grpc without address",
+			config: []byte(`
+grpc:
+  insecure: true
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "grpc requires address")
+			},
+		},
+		{
+			uc: "with invalid endpoint",
+			config: []byte(`
+endpoint:
+  method: POST
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to validate endpoint")
+			},
+		},
+		{
+			uc: "with unsupported fields",
+			config: []byte(`
+grpc:
+  address: 127.0.0.1:50051
+  foo: bar
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with valid grpc configuration",
+			id: "authz",
+			config: []byte(`
+grpc:
+  address: 127.0.0.1:50051
+  insecure: true
+  token: secret
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, auth *rebacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "authz", auth.HandlerID())
+				assert.Equal(t, "authz", auth.HandlerID())
+				assert.False(t, auth.ContinueOnError())
+				assert.Len(t, auth.checks, 1)
+				assert.Equal(t, rebacAPISpiceDB, auth.api)
+				assert.Equal(t, rebacConsistencyMinimizeLatency, auth.consistency)
+				assert.Nil(t, auth.consistencyToken)
+				assert.Zero(t, auth.ttl)
+				require.IsType(t, &grpcRebacChecker{}, auth.c)
+				assert.Equal(t, "127.0.0.1:50051", auth.c.(*grpcRebacChecker).address) // nolint: forcetypeassert
+			},
+		},
+		{
+			uc: "with valid http configuration",
+			id: "authz",
+			config: []byte(`
+endpoint:
+  url: http://127.0.0.1:8443/v1/permissions/check
+consistency: fully_consistent
+consistency_token: '{{ .Request.Header "X-Consistency-Token" }}'
+cache_ttl: 1m
+values:
+  foo: bar
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+  - object: "folder:2"
+    relation: view
+    subject: "group:admins#member"
+`),
+			assert: func(t *testing.T, err error, auth *rebacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Len(t, auth.checks, 2)
+				assert.Equal(t, rebacConsistencyFullyConsistent, auth.consistency)
+				assert.NotNil(t, auth.consistencyToken)
+				assert.Equal(t, 1*time.Minute, auth.ttl)
+				assert.Equal(t, "bar", auth.v["foo"])
+				require.IsType(t, &httpRebacChecker{}, auth.c)
+				assert.Equal(t, spiceDBAPI{}, auth.c.(*httpRebacChecker).api) // nolint: forcetypeassert
+			},
+		},
+		{
+			uc: "with valid http configuration for openfga api",
+			id: "authz",
+			config: []byte(`
+api: openfga
+endpoint:
+  url: http://127.0.0.1:8080/stores/foo/check
+consistency: fully_consistent
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, auth *rebacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, rebacAPIOpenFGA, auth.api)
+				assert.Equal(t, rebacConsistencyFullyConsistent, auth.consistency)
+				require.IsType(t, &httpRebacChecker{}, auth.c)
+				assert.Equal(t, openFGAAPI{}, auth.c.(*httpRebacChecker).api) // nolint: forcetypeassert
+			},
+		},
+		{
+			uc: "with valid http configuration for keto api",
+			id: "authz",
+			config: []byte(`
+api: keto
+endpoint:
+  url: http://127.0.0.1:4466/relation-tuples/check
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`),
+			assert: func(t *testing.T, err error, auth *rebacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, rebacAPIKeto, auth.api)
+				require.IsType(t, &httpRebacChecker{}, auth.c)
+				assert.Equal(t, ketoAPI{}, auth.c.(*httpRebacChecker).api) // nolint: forcetypeassert
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newReBACAuthorizer(tc.id, conf)
+			if err == nil {
+				if checker, ok := auth.c.(*grpcRebacChecker); ok {
+					t.Cleanup(func() { checker.close() })
+				}
+			}
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateReBACAuthorizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		api    string
+		config []byte
+		assert func(t *testing.T, err error, prototype *rebacAuthorizer, configured *rebacAuthorizer)
+	}{
+		{
+			uc: "no new configuration provided",
+			assert: func(t *testing.T, err error, prototype *rebacAuthorizer, configured *rebacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with not overridable consistency",
+			config: []byte(`consistency: fully_consistent`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with invalid check",
+			config: []byte(`
+checks:
+  - object: "document:1"
+`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "check 1 requires")
+			},
+		},
+		{
+			uc: "with new checks, consistency token, cache ttl and values",
+			config: []byte(`
+checks:
+  - object: "folder:1"
+    relation: edit
+    subject: "user:{{ .Subject.ID }}"
+consistency_token: '{{ .Request.Header "X-Consistency-Token" }}'
+cache_ttl: 0s
+values:
+  bar: baz
+`),
+			assert: func(t *testing.T, err error, prototype *rebacAuthorizer, configured *rebacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, configured)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.HandlerID(), configured.HandlerID())
+				assert.Same(t, prototype.c, configured.c)
+				assert.Equal(t, prototype.consistency, configured.consistency)
+				assert.NotEqual(t, prototype.checks, configured.checks)
+				assert.Len(t, configured.checks, 1)
+				assert.NotNil(t, configured.consistencyToken)
+				assert.Equal(t, 1*time.Minute, prototype.ttl)
+				assert.Zero(t, configured.ttl)
+				assert.Equal(t, "bar", configured.v["foo"])
+				assert.Equal(t, "baz", configured.v["bar"])
+			},
+		},
+		{
+			uc:     "with consistency token for api not supporting it",
+			api:    rebacAPIKeto,
+			config: []byte(`consistency_token: '{{ .Request.Header "X-Consistency-Token" }}'`),
+			assert: func(t *testing.T, err error, _ *rebacAuthorizer, _ *rebacAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "consistency_token for the spicedb api only")
+			},
+		},
+		{
+			uc:     "with values only",
+			config: []byte(`values: { foo: baz }`),
+			assert: func(t *testing.T, err error, prototype *rebacAuthorizer, configured *rebacAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, configured)
+				assert.Equal(t, prototype.checks, configured.checks)
+				assert.Equal(t, prototype.ttl, configured.ttl)
+				assert.Nil(t, configured.consistencyToken)
+				assert.Equal(t, "baz", configured.v["foo"])
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+api: ` + x.IfThenElse(len(tc.api) != 0, tc.api, rebacAPISpiceDB) + `
+endpoint:
+  url: http://127.0.0.1:8443/v1/permissions/check
+cache_ttl: 1m
+values:
+  foo: bar
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newReBACAuthorizer("authz", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var configured *rebacAuthorizer
+			if err == nil {
+				configured = auth.(*rebacAuthorizer) // nolint: forcetypeassert
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestReBACAuthorizerExecute(t *testing.T) {
+	t.Parallel()
+
+	transports := map[string]func(t *testing.T, srv *fakePermissionsService) string{
+		"grpc": func(t *testing.T, srv *fakePermissionsService) string {
+			t.Helper()
+
+			return `
+grpc:
+  address: ` + startFakeGRPCPermissionsService(t, srv) + `
+  insecure: true
+  token: secret`
+		},
+		"http": func(t *testing.T, srv *fakePermissionsService) string {
+			t.Helper()
+
+			return `
+endpoint:
+  url: ` + startFakeHTTPPermissionsService(t, srv) + `
+  auth:
+    type: api_key
+    config:
+      in: header
+      name: Authorization
+      value: Bearer secret`
+		},
+	}
+
+	for _, tc := range []struct {
+		uc      string
+		config  string
+		subject *subject.Subject
+		tokens  []string
+		srvErr  error
+		allowed []string
+		assert  func(t *testing.T, err error, srv *fakePermissionsService, execute func(token string) error)
+	}{
+		{
+			uc: "nil subject",
+			config: `
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"`,
+			assert: func(t *testing.T, err error, srv *fakePermissionsService, _ func(string) error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "'nil' subject")
+				assert.Empty(t, srv.received())
+			},
+		},
+		{
+			uc: "all checks allowed",
+			config: `
+checks:
+  - object: "document:{{ .Request.URL.Path | base }}"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+  - object: "tenant:{{ .Values.tenant }}"
+    relation: member
+    subject: "user:{{ .Subject.ID }}"
+values:
+  tenant: acme`,
+			subject: &subject.Subject{ID: "alice"},
+			allowed: []string{"document:1#view@user:alice", "tenant:acme#member@user:alice"},
+			assert: func(t *testing.T, err error, srv *fakePermissionsService, _ func(string) error) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				requests := srv.received()
+				require.Len(t, requests, 2)
+
+				for _, req := range requests {
+					assert.True(t, req.GetConsistency().GetMinimizeLatency())
+				}
+
+				assert.Equal(t, []string{"Bearer secret", "Bearer secret"}, srv.tokens)
+			},
+		},
+		{
+			uc: "one of the checks denied",
+			config: `
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+  - object: "folder:2"
+    relation: view
+    subject: "group:admins#member"`,
+			subject: &subject.Subject{ID: "alice"},
+			allowed: []string{"document:1#view@user:alice"},
+			assert: func(t *testing.T, err error, srv *fakePermissionsService, _ func(string) error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "check 2 failed: folder:2#view@group:admins#member")
+				assert.Len(t, srv.received(), 2)
+
+				var identifier interface{ HandlerID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "authz", identifier.HandlerID())
+			},
+		},
+		{
+			uc: "with fully consistent checks",
+			config: `
+consistency: fully_consistent
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"`,
+			subject: &subject.Subject{ID: "alice"},
+			allowed: []string{"document:1#view@user:alice"},
+			assert: func(t *testing.T, err error, srv *fakePermissionsService, _ func(string) error) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				requests := srv.received()
+				require.Len(t, requests, 1)
+				assert.True(t, requests[0].GetConsistency().GetFullyConsistent())
+			},
+		},
+		{
+			uc: "decisions are cached per consistency token",
+			config: `
+consistency_token: '{{ .Request.Header "X-Consistency-Token" }}'
+cache_ttl: 1m
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"`,
+			subject: &subject.Subject{ID: "alice"},
+			tokens:  []string{"token1", "token1", "token2"},
+			allowed: []string{"document:1#view@user:alice"},
+			assert: func(t *testing.T, err error, srv *fakePermissionsService, execute func(string) error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NoError(t, execute("token1"))
+				require.NoError(t, execute("token2"))
+
+				requests := srv.received()
+				require.Len(t, requests, 2)
+				assert.Equal(t, "token1", requests[0].GetConsistency().GetAtLeastAsFresh().GetToken())
+				assert.Equal(t, "token2", requests[1].GetConsistency().GetAtLeastAsFresh().GetToken())
+			},
+		},
+		{
+			uc: "check request fails",
+			config: `
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"`,
+			subject: &subject.Subject{ID: "alice"},
+			srvErr:  status.Error(codes.Unavailable, "not available"),
+			assert: func(t *testing.T, err error, _ *fakePermissionsService, _ func(string) error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "relation check failed")
+			},
+		},
+		{
+			uc: "rendered object is malformed",
+			config: `
+checks:
+  - object: "{{ .Subject.ID }}"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"`,
+			subject: &subject.Subject{ID: "alice"},
+			assert: func(t *testing.T, err error, srv *fakePermissionsService, _ func(string) error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to create check 1")
+				assert.Contains(t, err.Error(), "not a valid object reference")
+				assert.Empty(t, srv.received())
+			},
+		},
+	} {
+		for transport, startServer := range transports {
+			tc, transport, startServer := tc, transport, startServer
+
+			t.Run("case="+tc.uc+"/transport="+transport, func(t *testing.T) {
+				t.Parallel()
+
+				// GIVEN
+				srv := &fakePermissionsService{allowed: tc.allowed, err: tc.srvErr}
+
+				conf, err := testsupport.DecodeTestConfig([]byte(startServer(t, srv) + tc.config))
+				require.NoError(t, err)
+
+				auth, err := newReBACAuthorizer("authz", conf)
+				require.NoError(t, err)
+
+				if checker, ok := auth.c.(*grpcRebacChecker); ok {
+					t.Cleanup(func() { checker.close() })
+				}
+
+				appCtx := cache.WithContext(context.Background(), memory.New())
+
+				execute := func(token string) error {
+					fnt := mocks.NewRequestFunctionsMock(t)
+					fnt.EXPECT().Header("X-Consistency-Token").Return(token).Maybe()
+
+					ctx := mocks.NewContextMock(t)
+					ctx.EXPECT().AppContext().Return(appCtx)
+					ctx.EXPECT().Request().Return(&heimdall.Request{
+						RequestFunctions: fnt,
+						Method:           http.MethodGet,
+						URL:              &url.URL{Scheme: "http", Host: "foo.local", Path: "/documents/1"},
+					}).Maybe()
+
+					return auth.Execute(ctx, tc.subject)
+				}
+
+				// WHEN
+				err = execute(x.IfThenElseExec(len(tc.tokens) != 0,
+					func() string { return tc.tokens[0] },
+					func() string { return "" }))
+
+				// THEN
+				tc.assert(t, err, srv, execute)
+			})
+		}
+	}
+}
+
+func TestReBACAuthorizerExecuteWithOtherAPIs(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc          string
+		api         string
+		path        string
+		consistency string
+		denyStatus  int
+		assert      func(t *testing.T, err error, requests []map[string]any)
+	}{
+		{
+			uc:         "openfga api",
+			api:        rebacAPIOpenFGA,
+			path:       "/stores/foo/check",
+			denyStatus: http.StatusOK,
+			assert: func(t *testing.T, err error, requests []map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "check 2 failed: folder:2#view@group:admins#member")
+
+				require.Len(t, requests, 2)
+				assert.ElementsMatch(t, []any{
+					map[string]any{"user": "user:alice", "relation": "view", "object": "document:1"},
+					map[string]any{"user": "group:admins#member", "relation": "view", "object": "folder:2"},
+				}, []any{requests[0]["tuple_key"], requests[1]["tuple_key"]})
+				assert.NotContains(t, requests[0], "consistency")
+			},
+		},
+		{
+			uc:          "openfga api with fully consistent checks",
+			api:         rebacAPIOpenFGA,
+			path:        "/stores/foo/check",
+			consistency: rebacConsistencyFullyConsistent,
+			denyStatus:  http.StatusOK,
+			assert: func(t *testing.T, err error, requests []map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+
+				require.Len(t, requests, 2)
+				assert.Equal(t, openFGAConsistencyHigher, requests[0]["consistency"])
+				assert.Equal(t, openFGAConsistencyHigher, requests[1]["consistency"])
+			},
+		},
+		{
+			uc:         "keto api",
+			api:        rebacAPIKeto,
+			path:       "/relation-tuples/check",
+			denyStatus: http.StatusForbidden,
+			assert: func(t *testing.T, err error, requests []map[string]any) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "check 2 failed: folder:2#view@group:admins#member")
+
+				require.Len(t, requests, 2)
+				assert.ElementsMatch(t, []any{
+					map[string]any{
+						"namespace": "document", "object": "1", "relation": "view",
+						"subject_set": map[string]any{"namespace": "user", "object": "alice", "relation": ""},
+					},
+					map[string]any{
+						"namespace": "folder", "object": "2", "relation": "view",
+						"subject_set": map[string]any{"namespace": "group", "object": "admins", "relation": "member"},
+					},
+				}, []any{requests[0], requests[1]})
+			},
+		},
+	} {
+		tc := tc
+
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			var (
+				mut      sync.Mutex
+				requests []map[string]any
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPost || req.URL.Path != tc.path {
+					rw.WriteHeader(http.StatusBadRequest)
+
+					return
+				}
+
+				var checkReq map[string]any
+				require.NoError(t, json.NewDecoder(req.Body).Decode(&checkReq))
+
+				mut.Lock()
+				requests = append(requests, checkReq)
+				mut.Unlock()
+
+				tupleKey, _ := checkReq["tuple_key"].(map[string]any)
+				allowed := checkReq["object"] == "1" || tupleKey["object"] == "document:1"
+
+				rw.Header().Set("Content-Type", "application/json")
+				rw.WriteHeader(x.IfThenElse(allowed, http.StatusOK, tc.denyStatus))
+				_, err := rw.Write([]byte(x.IfThenElse(allowed, `{"allowed":true}`, `{"allowed":false}`)))
+				require.NoError(t, err)
+			}))
+			t.Cleanup(srv.Close)
+
+			conf, err := testsupport.DecodeTestConfig([]byte(`
+api: ` + tc.api + `
+endpoint:
+  url: ` + srv.URL + tc.path + `
+consistency: ` + x.IfThenElse(len(tc.consistency) != 0, tc.consistency, rebacConsistencyMinimizeLatency) + `
+checks:
+  - object: "document:1"
+    relation: view
+    subject: "user:{{ .Subject.ID }}"
+  - object: "folder:2"
+    relation: view
+    subject: "group:admins#member"
+`))
+			require.NoError(t, err)
+
+			auth, err := newReBACAuthorizer("authz", conf)
+			require.NoError(t, err)
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), memory.New()))
+			ctx.EXPECT().Request().Return(&heimdall.Request{
+				Method: http.MethodGet,
+				URL:    &url.URL{Scheme: "http", Host: "foo.local", Path: "/documents/1"},
+			}).Maybe()
+
+			// WHEN
+			err = auth.Execute(ctx, &subject.Subject{ID: "alice"})
+
+			// THEN
+			mut.Lock()
+			defer mut.Unlock()
+
+			tc.assert(t, err, requests)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/url"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/dadrus/heimdall/internal/endpoint"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// rebacCheck describes a single (object, relation, subject) check independent of the api
+// of the relationship based access control system used.
+type rebacCheck struct {
+	objectType      string
+	objectID        string
+	relation        string
+	subjectType     string
+	subjectID       string
+	subjectRelation string
+	// consistencyToken, if set, requires the decision to be at least as fresh as the state
+	// identified by the token. Takes precedence over fullyConsistent.
+	consistencyToken string
+	fullyConsistent  bool
+}
+
+func (c *rebacCheck) String() string {
+	subjectStr := c.subjectType + ":" + c.subjectID
+	if len(c.subjectRelation) != 0 {
+		subjectStr += "#" + c.subjectRelation
+	}
+
+	return c.objectType + ":" + c.objectID + "#" + c.relation + "@" + subjectStr
+}
+
+type rebacCheckResult struct {
+	allowed bool
+	// checkedAt is the consistency token of the state the decision has been made at, if
+	// supported by the api.
+	checkedAt string
+}
+
+// rebacChecker sends check requests to a relationship based access control system. Each
+// check results in a separate request.
+type rebacChecker interface {
+	check(ctx context.Context, chk *rebacCheck) (*rebacCheckResult, error)
+	// identity is used to distinguish decisions of different systems in the cache.
+	identity() []byte
+	// close releases the resources, like connections, held by the checker.
+	close() error
+}
+
+type rebacGRPCConfig struct {
+	Address  string `mapstructure:"address"`
+	Insecure bool   `mapstructure:"insecure"`
+	Token    string `mapstructure:"token"`
+}
+
+type grpcRebacChecker struct {
+	address string
+	conn    *grpc.ClientConn
+	client  v1.PermissionsServiceClient
+}
+
+func newGRPCRebacChecker(conf *rebacGRPCConfig) (*grpcRebacChecker, error) {
+	if len(conf.Address) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "grpc requires address to be set")
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	}
+
+	if conf.Insecure {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(
+			credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})))
+	}
+
+	if len(conf.Token) != 0 {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerTokenCredentials{
+			token:      conf.Token,
+			requireTLS: !conf.Insecure,
+		}))
+	}
+
+	// the connection is established lazily on the first call
+	conn, err := grpc.Dial(conf.Address, opts...)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to create grpc client").
+			CausedBy(err)
+	}
+
+	return &grpcRebacChecker{address: conf.Address, conn: conn, client: v1.NewPermissionsServiceClient(conn)}, nil
+}
+
+func (c *grpcRebacChecker) check(ctx context.Context, chk *rebacCheck) (*rebacCheckResult, error) {
+	resp, err := c.client.CheckPermission(ctx, spiceDBCheckRequest(chk))
+	if err != nil {
+		if status.Code(err) == codes.DeadlineExceeded {
+			return nil, errorchain.NewWithMessage(heimdall.ErrCommunicationTimeout, "check request timed out").
+				CausedBy(err)
+		}
+
+		return nil, errorchain.NewWithMessage(heimdall.ErrCommunication, "check request failed").
+			CausedBy(err)
+	}
+
+	return spiceDBCheckResult(resp), nil
+}
+
+func (c *grpcRebacChecker) identity() []byte { return stringx.ToBytes(c.address) }
+
+func (c *grpcRebacChecker) close() error { return c.conn.Close() }
+
+type bearerTokenCredentials struct {
+	token      string
+	requireTLS bool
+}
+
+func (c bearerTokenCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c bearerTokenCredentials) RequireTransportSecurity() bool { return c.requireTLS }
+
+type httpRebacChecker struct {
+	e   *endpoint.Endpoint
+	api rebacHTTPAPI
+}
+
+func newHTTPRebacChecker(ep *endpoint.Endpoint, api rebacHTTPAPI) (*httpRebacChecker, error) {
+	if err := ep.Validate(); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to validate endpoint configuration").
+			CausedBy(err)
+	}
+
+	return &httpRebacChecker{e: ep, api: api}, nil
+}
+
+func (c *httpRebacChecker) check(ctx context.Context, chk *rebacCheck) (*rebacCheckResult, error) {
+	body, err := c.api.marshalRequest(chk)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to marshal check request").
+			CausedBy(err)
+	}
+
+	req, err := c.e.CreateRequest(ctx, bytes.NewReader(body), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.e.CreateClient(req.URL.Hostname()).Do(req)
+	if err != nil {
+		var clientErr *url.Error
+		if errors.As(err, &clientErr) && clientErr.Timeout() {
+			return nil, errorchain.NewWithMessage(heimdall.ErrCommunicationTimeout, "check request timed out").
+				CausedBy(err)
+		}
+
+		return nil, errorchain.NewWithMessage(heimdall.ErrCommunication, "check request failed").
+			CausedBy(err)
+	}
+
+	defer resp.Body.Close()
+
+	if !c.api.acceptsStatus(resp.StatusCode) {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"unexpected response code: %v", resp.StatusCode)
+	}
+
+	rawData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to read response").
+			CausedBy(err)
+	}
+
+	result, err := c.api.unmarshalResponse(rawData)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to unmarshal check response").
+			CausedBy(err)
+	}
+
+	return result, nil
+}
+
+func (c *httpRebacChecker) identity() []byte { return c.e.Hash() }
+
+func (c *httpRebacChecker) close() error { return nil }
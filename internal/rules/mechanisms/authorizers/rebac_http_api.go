@@ -0,0 +1,203 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"net/http"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/goccy/go-json"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	rebacAPISpiceDB = "spicedb"
+	rebacAPIOpenFGA = "openfga"
+	rebacAPIKeto    = "keto"
+
+	openFGAConsistencyHigher = "HIGHER_CONSISTENCY"
+)
+
+// rebacHTTPAPI maps checks to the messages of the HTTP check api of a particular
+// relationship based access control system.
+type rebacHTTPAPI interface {
+	marshalRequest(chk *rebacCheck) ([]byte, error)
+	acceptsStatus(code int) bool
+	unmarshalResponse(data []byte) (*rebacCheckResult, error)
+}
+
+func newRebacHTTPAPI(name string) rebacHTTPAPI {
+	switch name {
+	case rebacAPIOpenFGA:
+		return openFGAAPI{}
+	case rebacAPIKeto:
+		return ketoAPI{}
+	default:
+		return spiceDBAPI{}
+	}
+}
+
+// spiceDBAPI implements the HTTP/JSON gateway of the SpiceDB gRPC api,
+// like https://spicedb:8443/v1/permissions/check.
+type spiceDBAPI struct{}
+
+func (spiceDBAPI) marshalRequest(chk *rebacCheck) ([]byte, error) {
+	return protojson.Marshal(spiceDBCheckRequest(chk))
+}
+
+func (spiceDBAPI) acceptsStatus(code int) bool { return code == http.StatusOK }
+
+func (spiceDBAPI) unmarshalResponse(data []byte) (*rebacCheckResult, error) {
+	var resp v1.CheckPermissionResponse
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	return spiceDBCheckResult(&resp), nil
+}
+
+func spiceDBCheckRequest(chk *rebacCheck) *v1.CheckPermissionRequest {
+	consistency := &v1.Consistency{}
+
+	switch {
+	case len(chk.consistencyToken) != 0:
+		consistency.Requirement = &v1.Consistency_AtLeastAsFresh{
+			AtLeastAsFresh: &v1.ZedToken{Token: chk.consistencyToken},
+		}
+	case chk.fullyConsistent:
+		consistency.Requirement = &v1.Consistency_FullyConsistent{FullyConsistent: true}
+	default:
+		consistency.Requirement = &v1.Consistency_MinimizeLatency{MinimizeLatency: true}
+	}
+
+	return &v1.CheckPermissionRequest{
+		Consistency: consistency,
+		Resource:    &v1.ObjectReference{ObjectType: chk.objectType, ObjectId: chk.objectID},
+		Permission:  chk.relation,
+		Subject: &v1.SubjectReference{
+			Object:           &v1.ObjectReference{ObjectType: chk.subjectType, ObjectId: chk.subjectID},
+			OptionalRelation: chk.subjectRelation,
+		},
+	}
+}
+
+func spiceDBCheckResult(resp *v1.CheckPermissionResponse) *rebacCheckResult {
+	return &rebacCheckResult{
+		allowed:   resp.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+		checkedAt: resp.GetCheckedAt().GetToken(),
+	}
+}
+
+// openFGAAPI implements the check api of OpenFGA, like https://openfga:8080/stores/<store id>/check.
+// Consistency tokens are not supported by OpenFGA.
+type openFGAAPI struct{}
+
+type openFGATupleKey struct {
+	User     string `json:"user"`
+	Relation string `json:"relation"`
+	Object   string `json:"object"`
+}
+
+type openFGACheckRequest struct {
+	TupleKey    openFGATupleKey `json:"tuple_key"`
+	Consistency string          `json:"consistency,omitempty"`
+}
+
+type openFGACheckResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+func (openFGAAPI) marshalRequest(chk *rebacCheck) ([]byte, error) {
+	user := chk.subjectType + ":" + chk.subjectID
+	if len(chk.subjectRelation) != 0 {
+		user += "#" + chk.subjectRelation
+	}
+
+	req := openFGACheckRequest{
+		TupleKey: openFGATupleKey{
+			User:     user,
+			Relation: chk.relation,
+			Object:   chk.objectType + ":" + chk.objectID,
+		},
+	}
+
+	if chk.fullyConsistent {
+		req.Consistency = openFGAConsistencyHigher
+	}
+
+	return json.Marshal(req)
+}
+
+func (openFGAAPI) acceptsStatus(code int) bool { return code == http.StatusOK }
+
+func (openFGAAPI) unmarshalResponse(data []byte) (*rebacCheckResult, error) {
+	var resp openFGACheckResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	return &rebacCheckResult{allowed: resp.Allowed}, nil
+}
+
+// ketoAPI implements the check api of Ory Keto, like https://keto:4466/relation-tuples/check.
+// The type of objects and subjects is mapped to the namespace. Subjects are always sent as
+// subject sets. Consistency requirements are not supported by Keto.
+type ketoAPI struct{}
+
+type ketoSubjectSet struct {
+	Namespace string `json:"namespace"`
+	Object    string `json:"object"`
+	Relation  string `json:"relation"`
+}
+
+type ketoCheckRequest struct {
+	Namespace  string         `json:"namespace"`
+	Object     string         `json:"object"`
+	Relation   string         `json:"relation"`
+	SubjectSet ketoSubjectSet `json:"subject_set"`
+}
+
+type ketoCheckResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+func (ketoAPI) marshalRequest(chk *rebacCheck) ([]byte, error) {
+	return json.Marshal(ketoCheckRequest{
+		Namespace: chk.objectType,
+		Object:    chk.objectID,
+		Relation:  chk.relation,
+		SubjectSet: ketoSubjectSet{
+			Namespace: chk.subjectType,
+			Object:    chk.subjectID,
+			Relation:  chk.subjectRelation,
+		},
+	})
+}
+
+// acceptsStatus accepts 403 as well, as Keto responds with it if the check is denied.
+func (ketoAPI) acceptsStatus(code int) bool {
+	return code == http.StatusOK || code == http.StatusForbidden
+}
+
+func (ketoAPI) unmarshalResponse(data []byte) (*rebacCheckResult, error) {
+	var resp ketoCheckResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	return &rebacCheckResult{allowed: resp.Allowed}, nil
+}
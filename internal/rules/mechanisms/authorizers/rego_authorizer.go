@@ -0,0 +1,221 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"github.com/mitchellh/mapstructure"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/values"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthorizerTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authorizer, error) {
+			if typ != AuthorizerRego {
+				return false, nil, nil
+			}
+
+			auth, err := newRegoAuthorizer(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type regoAuthorizer struct {
+	id string
+	p  *regoPolicy
+	v  values.Values
+}
+
+// regoDecision is the result of the policy evaluation. The query can either evaluate
+// to a boolean, or to an object with a mandatory allow property, an optional reason,
+// used as the error message if the request is denied, and optional headers to be
+// forwarded to the upstream service if the request is allowed.
+type regoDecision struct {
+	Allow   bool              `mapstructure:"allow"`
+	Reason  string            `mapstructure:"reason"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+func newRegoAuthorizer(id string, rawConfig map[string]any) (*regoAuthorizer, error) {
+	type Config struct {
+		Policies []string      `mapstructure:"policies"`
+		Data     []string      `mapstructure:"data"`
+		Query    string        `mapstructure:"query"`
+		Values   values.Values `mapstructure:"values"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal rego authorizer config").
+			CausedBy(err)
+	}
+
+	if len(conf.Policies) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "no policies provided for rego authorizer")
+	}
+
+	if len(conf.Query) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "no query provided for rego authorizer")
+	}
+
+	policy, err := newRegoPolicy(conf.Query, conf.Policies, conf.Data)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to load policies for rego authorizer").
+			CausedBy(err)
+	}
+
+	return &regoAuthorizer{id: id, p: policy, v: conf.Values}, nil
+}
+
+func (a *regoAuthorizer) Execute(ctx heimdall.Context, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authorizing using rego authorizer")
+
+	if sub == nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to execute rego authorizer due to 'nil' subject").
+			WithErrorContext(a)
+	}
+
+	results, err := a.p.eval(ctx.AppContext(), map[string]any{
+		"Subject": map[string]any{"ID": sub.ID, "Attributes": sub.Attributes},
+		"Request": a.requestInput(ctx.Request()),
+		"Values":  a.v,
+	})
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed evaluating rego query").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	decision, err := a.decision(results)
+	if err != nil {
+		return err
+	}
+
+	if !decision.Allow {
+		return errorchain.NewWithMessage(heimdall.ErrAuthorization,
+			x.IfThenElse(len(decision.Reason) != 0, decision.Reason, "request denied by policy")).
+			WithErrorContext(a)
+	}
+
+	for name, value := range decision.Headers {
+		ctx.AddHeaderForUpstream(name, value)
+	}
+
+	return nil
+}
+
+func (a *regoAuthorizer) WithConfig(rawConfig map[string]any) (Authorizer, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Values values.Values `mapstructure:"values"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal rego authorizer config").
+			CausedBy(err)
+	}
+
+	return &regoAuthorizer{
+		id: a.id,
+		p:  a.p,
+		v:  a.v.Merge(conf.Values),
+	}, nil
+}
+
+func (a *regoAuthorizer) HandlerID() string { return a.id }
+
+// Start starts watching the configured policies and data documents.
+func (a *regoAuthorizer) Start(logger zerolog.Logger) error { return a.p.start(logger) }
+
+func (a *regoAuthorizer) Stop() error { return a.p.stop() }
+
+func (a *regoAuthorizer) ContinueOnError() bool { return false }
+
+func (a *regoAuthorizer) requestInput(req *heimdall.Request) map[string]any {
+	input := map[string]any{
+		"Method":   req.Method,
+		"ClientIP": req.ClientIP,
+	}
+
+	if req.URL != nil {
+		input["URL"] = map[string]any{
+			"Scheme":   req.URL.Scheme,
+			"Host":     req.URL.Host,
+			"Path":     req.URL.Path,
+			"RawQuery": req.URL.RawQuery,
+			"Query":    map[string][]string(req.URL.Query()),
+		}
+	}
+
+	if req.RequestFunctions != nil {
+		input["Headers"] = req.Headers()
+	}
+
+	return input
+}
+
+func (a *regoAuthorizer) decision(results rego.ResultSet) (*regoDecision, error) {
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return &regoDecision{Reason: "policy decision is undefined"}, nil
+	}
+
+	switch value := results[0].Expressions[0].Value.(type) {
+	case bool:
+		return &regoDecision{Allow: value}, nil
+	case map[string]any:
+		if _, ok := value["allow"].(bool); !ok {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrInternal, "policy decision does not contain a boolean allow property").
+				WithErrorContext(a)
+		}
+
+		var decision regoDecision
+		if err := mapstructure.Decode(value, &decision); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrInternal, "failed to decode policy decision").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+
+		return &decision, nil
+	default:
+		return nil, errorchain.
+			NewWithMessagef(heimdall.ErrInternal, "unexpected policy decision type %T", value).
+			WithErrorContext(a)
+	}
+}
@@ -0,0 +1,505 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+const testRegoPolicy = `
+package authz
+
+import future.keywords.if
+import future.keywords.in
+
+default allow := false
+
+allow if input.Subject.ID in data.users
+
+default decision := {"allow": false, "reason": "subject is not allowed"}
+
+decision := {"allow": true, "headers": {"X-User": input.Subject.ID, "X-Tenant": input.Values.tenant}} if {
+	input.Subject.ID in data.users
+	input.Request.Method == "GET"
+	input.Request.URL.Path == "/foo"
+	input.Request.Headers["X-Foo"] == "bar"
+}
+
+no_allow := {"reason": "foo"}
+`
+
+func writeRegoTestFiles(t *testing.T, policy, data string) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy.rego")
+	dataFile := filepath.Join(dir, "data.json")
+
+	require.NoError(t, os.WriteFile(policyFile, []byte(policy), 0o600))
+	require.NoError(t, os.WriteFile(dataFile, []byte(data), 0o600))
+
+	return policyFile, dataFile
+}
+
+func TestCreateRegoAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	policyFile, dataFile := writeRegoTestFiles(t, testRegoPolicy, `{"users": ["alice"]}`)
+	invalidPolicyFile, _ := writeRegoTestFiles(t, `package authz foo bar`, `{}`)
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *regoAuthorizer)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, _ *regoAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no policies provided")
+			},
+		},
+		{
+			uc: "without query",
+			config: []byte(`
+policies:
+  - ` + policyFile),
+			assert: func(t *testing.T, err error, _ *regoAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no query provided")
+			},
+		},
+		{
+			uc: "with unsupported fields",
+			config: []byte(`
+policies:
+  - ` + policyFile + `
+query: data.authz.allow
+foo: bar`),
+			assert: func(t *testing.T, err error, _ *regoAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with not existing policy file",
+			config: []byte(`
+policies:
+  - /does/not/exist.rego
+query: data.authz.allow`),
+			assert: func(t *testing.T, err error, _ *regoAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to load rego policies")
+			},
+		},
+		{
+			uc: "with invalid policy",
+			config: []byte(`
+policies:
+  - ` + invalidPolicyFile + `
+query: data.authz.allow`),
+			assert: func(t *testing.T, err error, _ *regoAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to load rego policies")
+			},
+		},
+		{
+			uc: "without rego modules",
+			config: []byte(`
+policies:
+  - ` + dataFile + `
+query: data.authz.allow`),
+			assert: func(t *testing.T, err error, _ *regoAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no rego modules found")
+			},
+		},
+		{
+			uc: "with invalid query",
+			config: []byte(`
+policies:
+  - ` + policyFile + `
+query: "data.authz["`),
+			assert: func(t *testing.T, err error, _ *regoAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to prepare rego query")
+			},
+		},
+		{
+			uc: "with valid configuration",
+			id: "authz",
+			config: []byte(`
+policies:
+  - ` + policyFile + `
+data:
+  - ` + dataFile + `
+query: data.authz.decision
+values:
+  tenant: foo`),
+			assert: func(t *testing.T, err error, auth *regoAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth)
+
+				assert.Equal(t, "authz", auth.HandlerID())
+				assert.Equal(t, "authz", auth.HandlerID())
+				assert.False(t, auth.ContinueOnError())
+				assert.Equal(t, "data.authz.decision", auth.p.query)
+				assert.Len(t, auth.p.paths, 2)
+				assert.Equal(t, "foo", auth.v["tenant"])
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newRegoAuthorizer(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateRegoAuthorizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	policyFile, dataFile := writeRegoTestFiles(t, testRegoPolicy, `{"users": ["alice"]}`)
+	prototypeConfig := []byte(`
+policies:
+  - ` + policyFile + `
+data:
+  - ` + dataFile + `
+query: data.authz.decision
+values:
+  tenant: foo
+  region: eu`)
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *regoAuthorizer, configured *regoAuthorizer)
+	}{
+		{
+			uc: "no new configuration provided",
+			assert: func(t *testing.T, err error, prototype *regoAuthorizer, configured *regoAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with not overridable query",
+			config: []byte(`query: data.authz.allow`),
+			assert: func(t *testing.T, err error, _ *regoAuthorizer, _ *regoAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with new values",
+			config: []byte(`
+values:
+  tenant: bar`),
+			assert: func(t *testing.T, err error, prototype *regoAuthorizer, configured *regoAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, configured)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.HandlerID(), configured.HandlerID())
+				assert.Same(t, prototype.p, configured.p)
+				assert.Equal(t, "foo", prototype.v["tenant"])
+				assert.Equal(t, "bar", configured.v["tenant"])
+				assert.Equal(t, "eu", configured.v["region"])
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig(prototypeConfig)
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newRegoAuthorizer("authz", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var configured *regoAuthorizer
+			if err == nil {
+				configured = auth.(*regoAuthorizer) // nolint: forcetypeassert
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestRegoAuthorizerExecute(t *testing.T) {
+	t.Parallel()
+
+	policyFile, dataFile := writeRegoTestFiles(t, testRegoPolicy, `{"users": ["alice"]}`)
+
+	for _, tc := range []struct {
+		uc             string
+		query          string
+		subject        *subject.Subject
+		configureMocks func(t *testing.T, ctx *mocks.ContextMock)
+		assert         func(t *testing.T, err error)
+	}{
+		{
+			uc:    "nil subject",
+			query: "data.authz.allow",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "'nil' subject")
+			},
+		},
+		{
+			uc:      "boolean decision allowing the request",
+			query:   "data.authz.allow",
+			subject: &subject.Subject{ID: "alice"},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:      "boolean decision denying the request",
+			query:   "data.authz.allow",
+			subject: &subject.Subject{ID: "bob"},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "request denied by policy")
+
+				var identifier interface{ HandlerID() string }
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "authz", identifier.HandlerID())
+			},
+		},
+		{
+			uc:      "object decision allowing the request and forwarding headers",
+			query:   "data.authz.decision",
+			subject: &subject.Subject{ID: "alice"},
+			configureMocks: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().AddHeaderForUpstream("X-User", "alice")
+				ctx.EXPECT().AddHeaderForUpstream("X-Tenant", "foo")
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:      "object decision denying the request with reason",
+			query:   "data.authz.decision",
+			subject: &subject.Subject{ID: "bob"},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "subject is not allowed")
+			},
+		},
+		{
+			uc:      "undefined decision",
+			query:   "data.authz.unknown",
+			subject: &subject.Subject{ID: "alice"},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthorization)
+				assert.Contains(t, err.Error(), "policy decision is undefined")
+			},
+		},
+		{
+			uc:      "object decision without allow property",
+			query:   "data.authz.no_allow",
+			subject: &subject.Subject{ID: "alice"},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "boolean allow property")
+			},
+		},
+		{
+			uc:      "decision of unexpected type",
+			query:   "data.users",
+			subject: &subject.Subject{ID: "alice"},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "unexpected policy decision type")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *mocks.ContextMock) { t.Helper() })
+
+			conf, err := testsupport.DecodeTestConfig([]byte(`
+policies:
+  - ` + policyFile + `
+data:
+  - ` + dataFile + `
+query: ` + tc.query + `
+values:
+  tenant: foo`))
+			require.NoError(t, err)
+
+			auth, err := newRegoAuthorizer("authz", conf)
+			require.NoError(t, err)
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+
+			if tc.subject != nil {
+				fnt.EXPECT().Headers().Return(map[string]string{"X-Foo": "bar"})
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           "GET",
+					URL:              &url.URL{Scheme: "http", Host: "foo.local", Path: "/foo"},
+					ClientIP:         []string{"127.0.0.1"},
+				})
+			}
+
+			configureMocks(t, ctx)
+
+			// WHEN
+			err = auth.Execute(ctx, tc.subject)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestRegoAuthorizerReloadsPolicies(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	policyFile, dataFile := writeRegoTestFiles(t, testRegoPolicy, `{"users": ["alice"]}`)
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+policies:
+  - ` + policyFile + `
+data:
+  - ` + dataFile + `
+query: data.authz.allow`))
+	require.NoError(t, err)
+
+	auth, err := newRegoAuthorizer("authz", conf)
+	require.NoError(t, err)
+
+	require.NoError(t, auth.Start(zerolog.Nop()))
+	t.Cleanup(func() { auth.Stop() })
+
+	execute := func(subjectID string) error {
+		fnt := mocks.NewRequestFunctionsMock(t)
+		fnt.EXPECT().Headers().Return(map[string]string{})
+
+		ctx := mocks.NewContextMock(t)
+		ctx.EXPECT().AppContext().Return(context.Background())
+		ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: fnt, Method: "GET"})
+
+		return auth.Execute(ctx, &subject.Subject{ID: subjectID})
+	}
+
+	require.NoError(t, execute("alice"))
+	require.Error(t, execute("bob"))
+
+	// WHEN
+	require.NoError(t, os.WriteFile(dataFile, []byte(`{"users": ["bob"]}`), 0o600))
+
+	// THEN
+	assert.Eventually(t, func() bool { return execute("bob") == nil }, 2*time.Second, 10*time.Millisecond)
+	require.Error(t, execute("alice"))
+
+	// WHEN
+	require.NoError(t, os.WriteFile(policyFile, []byte(`package authz foo bar`), 0o600))
+	time.Sleep(100 * time.Millisecond)
+
+	// THEN
+	require.NoError(t, execute("bob"))
+}
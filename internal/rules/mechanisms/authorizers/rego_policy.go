@@ -0,0 +1,122 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/open-policy-agent/opa/loader"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/watcher"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// regoPolicy holds the prepared query compiled from the Rego modules and data documents
+// loaded from the configured paths. The policy is recompiled after any of the watched
+// files has been changed.
+type regoPolicy struct {
+	query string
+	paths []string
+	w     *watcher.Watcher
+
+	mut sync.RWMutex
+	pq  rego.PreparedEvalQuery
+}
+
+func newRegoPolicy(query string, policies, data []string) (*regoPolicy, error) {
+	paths := make([]string, 0, len(policies)+len(data))
+
+	for _, sources := range [][]string{policies, data} {
+		for _, path := range sources {
+			absPath, err := filepath.Abs(path)
+			if err != nil {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"failed to get the absolute path for %s", path).CausedBy(err)
+			}
+
+			paths = append(paths, absPath)
+		}
+	}
+
+	policy := &regoPolicy{query: query, paths: paths}
+
+	if err := policy.load(); err != nil {
+		return nil, err
+	}
+
+	policy.w = watcher.New(paths...)
+
+	return policy, nil
+}
+
+func (p *regoPolicy) start(logger zerolog.Logger) error { return p.w.Start(logger, p.load) }
+
+func (p *regoPolicy) stop() error { return p.w.Stop() }
+
+func (p *regoPolicy) load() error {
+	result, err := loader.NewFileLoader().All(p.paths)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to load rego policies").
+			CausedBy(err)
+	}
+
+	if len(result.Modules) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "no rego modules found")
+	}
+
+	compiler, err := result.Compiler()
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to compile rego modules").
+			CausedBy(err)
+	}
+
+	store, err := result.Store()
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to load data documents").
+			CausedBy(err)
+	}
+
+	pq, err := rego.New(
+		rego.Query(p.query),
+		rego.Compiler(compiler),
+		rego.Store(store),
+		rego.StrictBuiltinErrors(true),
+	).PrepareForEval(context.Background())
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to prepare rego query").
+			CausedBy(err)
+	}
+
+	p.mut.Lock()
+	p.pq = pq
+	p.mut.Unlock()
+
+	return nil
+}
+
+func (p *regoPolicy) eval(ctx context.Context, input map[string]any) (rego.ResultSet, error) {
+	p.mut.RLock()
+	pq := p.pq
+	p.mut.RUnlock()
+
+	return pq.Eval(ctx, rego.EvalInput(input))
+}
@@ -21,17 +21,18 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/goccy/go-json"
 	"github.com/google/cel-go/cel"
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/codec"
 	"github.com/dadrus/heimdall/internal/endpoint"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/cellib"
@@ -50,6 +51,8 @@ var errNoContent = errors.New("no payload received")
 //
 //nolint:gochecknoinits
 func init() {
+	codec.Register(&authorizationInformation{})
+
 	registerAuthorizerTypeFactory(
 		func(id string, typ string, conf map[string]any) (bool, Authorizer, error) {
 			if typ != AuthorizerRemote {
@@ -78,6 +81,28 @@ type authorizationInformation struct {
 	payload any
 }
 
+type authorizationInformationDTO struct {
+	Headers http.Header `json:"headers,omitempty"`
+	Payload any         `json:"payload,omitempty"`
+}
+
+func (ai *authorizationInformation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(authorizationInformationDTO{Headers: ai.headers, Payload: ai.payload})
+}
+
+func (ai *authorizationInformation) UnmarshalJSON(data []byte) error {
+	var dto authorizationInformationDTO
+
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+
+	ai.headers = dto.Headers
+	ai.payload = dto.Payload
+
+	return nil
+}
+
 func (ai *authorizationInformation) addHeadersTo(headerNames []string, ctx heimdall.Context) {
 	for _, headerName := range headerNames {
 		headerValue := ai.headers.Get(headerName)
@@ -407,10 +432,7 @@ func (a *remoteAuthorizer) verify(ctx heimdall.Context, result any) error {
 		}
 
 		if !ok {
-			return errorchain.NewWithMessage(heimdall.ErrAuthorization,
-				x.IfThenElse(len(expression.Message) != 0, expression.Message,
-					fmt.Sprintf("expression %d failed", i+1))).
-				WithErrorContext(a)
+			return expressionFailedError(a, i, expression)
 		}
 	}
 
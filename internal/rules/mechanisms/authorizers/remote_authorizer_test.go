@@ -36,6 +36,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/codec"
 	"github.com/dadrus/heimdall/internal/cache/mocks"
 	"github.com/dadrus/heimdall/internal/endpoint"
 	"github.com/dadrus/heimdall/internal/heimdall"
@@ -1207,3 +1208,24 @@ func TestRemoteAuthorizerExecute(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizationInformationCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	info := &authorizationInformation{
+		headers: http.Header{"X-Foo": []string{"bar"}},
+		payload: map[string]any{"baz": "zab"},
+	}
+
+	// WHEN
+	data, err := codec.Encode(info)
+	require.NoError(t, err)
+
+	decoded, err := codec.Decode(data)
+	require.NoError(t, err)
+
+	// THEN
+	require.IsType(t, &authorizationInformation{}, decoded)
+	assert.Equal(t, info, decoded)
+}
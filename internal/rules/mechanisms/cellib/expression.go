@@ -27,8 +27,10 @@ import (
 var errCELResultType = errors.New("result type error")
 
 type Expression struct {
-	Value   string `mapstructure:"expression"`
-	Message string `mapstructure:"message"`
+	ID             string   `mapstructure:"id"`
+	Value          string   `mapstructure:"expression"`
+	Message        string   `mapstructure:"message"`
+	RequiredScopes []string `mapstructure:"required_scopes"`
 
 	program cel.Program
 }
@@ -0,0 +1,79 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cellib
+
+import (
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func JWTs() cel.EnvOption {
+	return cel.Lib(jwtsLib{})
+}
+
+type jwtsLib struct{}
+
+func (jwtsLib) LibraryName() string {
+	return "dadrus.heimdall.jwts"
+}
+
+func (jwtsLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (jwtsLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("jwtPayload",
+			cel.MemberOverload("string_jwtPayload",
+				[]*cel.Type{cel.StringType}, cel.MapType(cel.StringType, cel.DynType),
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					payload, err := jwtPayload(value.Value().(string)) // nolint: forcetypeassert
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return types.DefaultTypeAdapter.NativeToValue(payload)
+				}),
+			),
+		),
+	}
+}
+
+// jwtPayload decodes the claims of the given JWT without verifying its signature. A leading
+// "Bearer" scheme, as used in the Authorization header, is removed.
+func jwtPayload(value string) (map[string]any, error) {
+	rawToken := strings.TrimSpace(value)
+	if scheme, token, found := strings.Cut(rawToken, " "); found && strings.EqualFold(scheme, "Bearer") {
+		rawToken = strings.TrimSpace(token)
+	}
+
+	token, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err = token.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
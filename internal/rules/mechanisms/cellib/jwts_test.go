@@ -0,0 +1,64 @@
+package cellib
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTs(t *testing.T) {
+	t.Parallel()
+
+	env, err := cel.NewEnv(
+		cel.StdLib(),
+		cel.Variable("token", cel.StringType),
+		JWTs(),
+	)
+	require.NoError(t, err)
+
+	// not verified by the library, thus a fake signature is sufficient
+	token := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+		"eyJzdWIiOiJmb28iLCJyb2xlcyI6WyJhZG1pbiIsInVzZXIiXSwiZXhwIjoxNjkwMDAwMDAwfQ." +
+		"c2lnbmF0dXJl"
+
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{expr: `token.jwtPayload().sub == "foo"`},
+		{expr: `"admin" in token.jwtPayload().roles`},
+		{expr: `timestamp(int(token.jwtPayload().exp)) < timestamp("2023-08-01T00:00:00Z")`},
+		{expr: `("Bearer " + token).jwtPayload().sub == "foo"`},
+		{expr: `("bearer  " + token).jwtPayload().sub == "foo"`},
+		{expr: `!has(token.jwtPayload().aud)`},
+		{expr: `"foo.bar.baz".jwtPayload().sub == "foo"`, err: "invalid character"},
+		{expr: `"foo".jwtPayload().sub == "foo"`, err: "compact JWS format must have three parts"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			ast, iss = env.Check(ast)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+			require.NoError(t, err)
+
+			out, _, err := prg.Eval(map[string]any{"token": token})
+			if len(tc.err) != 0 {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, true, out.Value())
+		})
+	}
+}
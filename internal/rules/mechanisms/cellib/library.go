@@ -44,6 +44,10 @@ func (heimdallLibrary) CompileOptions() []cel.EnvOption {
 		Strings(),
 		Urls(),
 		Requests(),
+		Networks(),
+		Times(),
+		JWTs(),
+		Versions(),
 		ext.NativeTypes(reflect.TypeOf(&subject.Subject{})),
 		cel.Variable("Payload", cel.DynType),
 		cel.Variable("Subject", cel.DynType),
@@ -0,0 +1,141 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cellib
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// nolint: gochecknoglobals
+var ipType = cel.OpaqueType("net.IP")
+
+type ipAddr netip.Addr
+
+func (ip ipAddr) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	if reflect.TypeOf(netip.Addr(ip)).AssignableTo(typeDesc) {
+		return netip.Addr(ip), nil
+	}
+
+	if reflect.TypeOf("").AssignableTo(typeDesc) {
+		return netip.Addr(ip).String(), nil
+	}
+
+	// nolint: goerr113
+	return nil, fmt.Errorf("type conversion error from 'net.IP' to '%v'", typeDesc)
+}
+
+func (ip ipAddr) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case ipType:
+		return ip
+	case types.StringType:
+		return types.String(netip.Addr(ip).String())
+	case types.TypeType:
+		return ipType
+	default:
+		return types.NewErr("type conversion error from '%s' to '%s'", ipType, typeVal)
+	}
+}
+
+func (ip ipAddr) Equal(other ref.Val) ref.Val {
+	otherIP, ok := other.(ipAddr)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+
+	return types.Bool(netip.Addr(ip).Unmap() == netip.Addr(otherIP).Unmap())
+}
+
+func (ip ipAddr) Type() ref.Type { return ipType }
+
+func (ip ipAddr) Value() any { return netip.Addr(ip) }
+
+func Networks() cel.EnvOption {
+	return cel.Lib(networksLib{})
+}
+
+type networksLib struct{}
+
+func (networksLib) LibraryName() string {
+	return "dadrus.heimdall.networks"
+}
+
+func (networksLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (networksLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("ip",
+			cel.Overload("string_to_ip",
+				[]*cel.Type{cel.StringType}, ipType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					addr, err := netip.ParseAddr(value.Value().(string)) // nolint: forcetypeassert
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return ipAddr(addr)
+				}),
+			),
+		),
+		cel.Function("inCIDR",
+			cel.MemberOverload("ip_inCIDR",
+				[]*cel.Type{ipType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(ipVal ref.Val, cidrVal ref.Val) ref.Val {
+					prefix, err := netip.ParsePrefix(cidrVal.Value().(string)) // nolint: forcetypeassert
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					// nolint: forcetypeassert
+					return types.Bool(prefix.Contains(netip.Addr(ipVal.(ipAddr)).Unmap()))
+				}),
+			),
+		),
+		cel.Function("isLoopback",
+			cel.MemberOverload("ip_isLoopback",
+				[]*cel.Type{ipType}, cel.BoolType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					return types.Bool(netip.Addr(value.(ipAddr)).Unmap().IsLoopback()) // nolint: forcetypeassert
+				}),
+			),
+		),
+		cel.Function("isPrivate",
+			cel.MemberOverload("ip_isPrivate",
+				[]*cel.Type{ipType}, cel.BoolType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					return types.Bool(netip.Addr(value.(ipAddr)).Unmap().IsPrivate()) // nolint: forcetypeassert
+				}),
+			),
+		),
+		cel.Function("String",
+			cel.MemberOverload("ip_String",
+				[]*cel.Type{ipType}, cel.StringType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					return types.String(netip.Addr(value.(ipAddr)).String()) // nolint: forcetypeassert
+				}),
+			),
+		),
+	}
+}
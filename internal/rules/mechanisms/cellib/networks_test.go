@@ -0,0 +1,62 @@
+package cellib
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworks(t *testing.T) {
+	t.Parallel()
+
+	env, err := cel.NewEnv(
+		cel.Variable("ips", cel.ListType(cel.StringType)),
+		Networks(),
+		Urls(),
+	)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{expr: `ip("10.1.2.3").inCIDR("10.0.0.0/8")`},
+		{expr: `!ip("192.168.1.1").inCIDR("10.0.0.0/8")`},
+		{expr: `ip("::ffff:10.1.2.3").inCIDR("10.0.0.0/8")`},
+		{expr: `ip("2001:db8::1").inCIDR("2001:db8::/32")`},
+		{expr: `ips.all(i, ip(i).inCIDR("10.0.0.0/8") || ip(i).isLoopback())`},
+		{expr: `ip("192.168.1.1").isPrivate() && !ip("1.1.1.1").isPrivate()`},
+		{expr: `ip("127.0.0.1").isLoopback()`},
+		{expr: `ip("10.1.2.3") == ip("::ffff:10.1.2.3")`},
+		{expr: `ip("10.1.2.3").String() == "10.1.2.3"`},
+		{expr: `ip("foo").isLoopback()`, err: "unable to parse IP"},
+		{expr: `ip("10.1.2.3").inCIDR("10.0.0.0")`, err: "no '/'"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			ast, iss = env.Check(ast)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+			require.NoError(t, err)
+
+			out, _, err := prg.Eval(map[string]any{"ips": []string{"10.10.10.10", "127.0.0.1"}})
+			if len(tc.err) != 0 {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, true, out.Value())
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/google/cel-go/ext"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/contenttype"
+	"github.com/dadrus/heimdall/internal/x/stringx"
 )
 
 func Requests() cel.EnvOption {
@@ -55,5 +57,37 @@ func (requestsLib) CompileOptions() []cel.EnvOption {
 				}),
 			),
 		),
+		cel.Function("Body",
+			cel.MemberOverload("request_Body",
+				[]*cel.Type{requestType}, cel.DynType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					// nolint: forcetypeassert
+					req := value.Value().(*heimdall.Request)
+
+					body, err := decodeBody(req)
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return types.DefaultTypeAdapter.NativeToValue(body)
+				}),
+			),
+		),
 	}
 }
+
+// decodeBody decodes the request body according to its content type. Bodies of content types, no
+// decoder is available for, are returned as string.
+func decodeBody(req *heimdall.Request) (any, error) {
+	rawBody := req.Body()
+	if len(rawBody) == 0 {
+		return map[string]any{}, nil
+	}
+
+	decoder, err := contenttype.NewDecoder(req.Header("Content-Type"))
+	if err != nil {
+		return stringx.ToString(rawBody), nil // nolint: nilerr
+	}
+
+	return decoder.Decode(rawBody)
+}
@@ -29,6 +29,8 @@ func TestRequests(t *testing.T) {
 	reqf := mocks.NewRequestFunctionsMock(t)
 	reqf.EXPECT().Cookie("foo").Return("bar")
 	reqf.EXPECT().Header("bar").Return("baz")
+	reqf.EXPECT().Header("Content-Type").Return("application/json")
+	reqf.EXPECT().Body().Return([]byte(`{"foo": {"bar": ["baz"]}}`))
 
 	req := &heimdall.Request{
 		RequestFunctions: reqf,
@@ -44,6 +46,7 @@ func TestRequests(t *testing.T) {
 		{expr: `req.URL.String() == "` + rawURI + `"`},
 		{expr: `req.Cookie("foo") == "bar"`},
 		{expr: `req.Header("bar") == "baz"`},
+		{expr: `req.Body().foo.bar == ["baz"]`},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			ast, iss := env.Compile(tc.expr)
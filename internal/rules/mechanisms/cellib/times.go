@@ -0,0 +1,133 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cellib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func Times() cel.EnvOption {
+	return cel.Lib(timesLib{})
+}
+
+type timesLib struct{}
+
+func (timesLib) LibraryName() string {
+	return "dadrus.heimdall.times"
+}
+
+func (timesLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (timesLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("now",
+			cel.Overload("now",
+				[]*cel.Type{}, cel.TimestampType,
+				cel.FunctionBinding(func(_ ...ref.Val) ref.Val {
+					return types.Timestamp{Time: time.Now().UTC()}
+				}),
+			),
+		),
+		cel.Function("isWeekday",
+			cel.MemberOverload("timestamp_isWeekday",
+				[]*cel.Type{cel.TimestampType}, cel.BoolType,
+				cel.UnaryBinding(func(tsVal ref.Val) ref.Val {
+					return types.Bool(isWeekday(tsVal.(types.Timestamp).Time)) // nolint: forcetypeassert
+				}),
+			),
+			cel.MemberOverload("timestamp_isWeekday_string",
+				[]*cel.Type{cel.TimestampType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(tsVal ref.Val, tzVal ref.Val) ref.Val {
+					loc, err := time.LoadLocation(tzVal.Value().(string)) // nolint: forcetypeassert
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return types.Bool(isWeekday(tsVal.(types.Timestamp).In(loc))) // nolint: forcetypeassert
+				}),
+			),
+		),
+		cel.Function("inTimeRange",
+			cel.MemberOverload("timestamp_inTimeRange_string_string",
+				[]*cel.Type{cel.TimestampType, cel.StringType, cel.StringType}, cel.BoolType,
+				cel.FunctionBinding(func(values ...ref.Val) ref.Val {
+					// nolint: forcetypeassert
+					result, err := inTimeRange(values[0].(types.Timestamp).Time,
+						values[1].Value().(string), values[2].Value().(string))
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return types.Bool(result)
+				}),
+			),
+			cel.MemberOverload("timestamp_inTimeRange_string_string_string",
+				[]*cel.Type{cel.TimestampType, cel.StringType, cel.StringType, cel.StringType}, cel.BoolType,
+				cel.FunctionBinding(func(values ...ref.Val) ref.Val {
+					loc, err := time.LoadLocation(values[3].Value().(string)) // nolint: forcetypeassert
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					// nolint: forcetypeassert
+					result, err := inTimeRange(values[0].(types.Timestamp).In(loc),
+						values[1].Value().(string), values[2].Value().(string))
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return types.Bool(result)
+				}),
+			),
+		),
+	}
+}
+
+func isWeekday(value time.Time) bool {
+	return value.Weekday() != time.Saturday && value.Weekday() != time.Sunday
+}
+
+// inTimeRange checks whether the time of day of the given value is within [from, to). If to is before
+// from, the range spans midnight.
+func inTimeRange(value time.Time, from, to string) (bool, error) {
+	start, err := time.Parse("15:04", from)
+	if err != nil {
+		return false, fmt.Errorf("invalid start of the time range: %w", err)
+	}
+
+	end, err := time.Parse("15:04", to)
+	if err != nil {
+		return false, fmt.Errorf("invalid end of the time range: %w", err)
+	}
+
+	startMinutes := start.Hour()*60 + start.Minute() // nolint: gomnd
+	endMinutes := end.Hour()*60 + end.Minute()       // nolint: gomnd
+	current := value.Hour()*60 + value.Minute()      // nolint: gomnd
+
+	if startMinutes <= endMinutes {
+		return current >= startMinutes && current < endMinutes, nil
+	}
+
+	return current >= startMinutes || current < endMinutes, nil
+}
@@ -0,0 +1,68 @@
+package cellib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimes(t *testing.T) {
+	t.Parallel()
+
+	env, err := cel.NewEnv(
+		cel.StdLib(),
+		cel.Variable("ts", cel.TimestampType),
+		Times(),
+	)
+	require.NoError(t, err)
+
+	// a wednesday
+	ts := time.Date(2023, 8, 2, 16, 30, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{expr: `now() - ts > duration("1h")`},
+		{expr: `now() > timestamp("2023-01-01T00:00:00Z")`},
+		{expr: `ts.isWeekday()`},
+		{expr: `!(ts + duration("72h")).isWeekday()`},
+		{expr: `!timestamp("2023-08-04T23:30:00Z").isWeekday("Europe/Berlin")`},
+		{expr: `ts.inTimeRange("09:00", "17:00")`},
+		{expr: `!ts.inTimeRange("09:00", "17:00", "Europe/Berlin")`},
+		{expr: `ts.inTimeRange("22:00", "17:00")`},
+		{expr: `!ts.inTimeRange("17:00", "08:00")`},
+		{expr: `ts.isWeekday("America/New_York") && ts.inTimeRange("09:00", "17:00", "America/New_York")`},
+		{expr: `ts.isWeekday("Foo/Bar")`, err: "unknown time zone"},
+		{expr: `ts.inTimeRange("9", "17:00")`, err: "invalid start"},
+		{expr: `ts.inTimeRange("09:00", "25:00")`, err: "invalid end"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			ast, iss = env.Check(ast)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+			require.NoError(t, err)
+
+			out, _, err := prg.Eval(map[string]any{"ts": ts})
+			if len(tc.err) != 0 {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, true, out.Value())
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cellib
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func Versions() cel.EnvOption {
+	return cel.Lib(versionsLib{})
+}
+
+type versionsLib struct{}
+
+func (versionsLib) LibraryName() string {
+	return "dadrus.heimdall.versions"
+}
+
+func (versionsLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (versionsLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("semverCompare",
+			cel.MemberOverload("string_semverCompare",
+				[]*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+				cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+					result, err := semverCompare(lhs.Value().(string), rhs.Value().(string)) // nolint: forcetypeassert
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return types.Int(result)
+				}),
+			),
+		),
+		cel.Function("semverMatches",
+			cel.MemberOverload("string_semverMatches",
+				[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(versionVal ref.Val, constraintVal ref.Val) ref.Val {
+					// nolint: forcetypeassert
+					result, err := semverMatches(versionVal.Value().(string), constraintVal.Value().(string))
+					if err != nil {
+						return types.WrapErr(err)
+					}
+
+					return types.Bool(result)
+				}),
+			),
+		),
+	}
+}
+
+func semverCompare(lhs, rhs string) (int, error) {
+	lhsVersion, err := semver.NewVersion(lhs)
+	if err != nil {
+		return 0, err
+	}
+
+	rhsVersion, err := semver.NewVersion(rhs)
+	if err != nil {
+		return 0, err
+	}
+
+	return lhsVersion.Compare(rhsVersion), nil
+}
+
+func semverMatches(version, constraint string) (bool, error) {
+	ver, err := semver.NewVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	constraints, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+
+	return constraints.Check(ver), nil
+}
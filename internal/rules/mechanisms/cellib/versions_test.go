@@ -0,0 +1,55 @@
+package cellib
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	env, err := cel.NewEnv(Versions())
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{expr: `"1.2.3".semverCompare("1.10.0") == -1`},
+		{expr: `"v2.0.0".semverCompare("1.10.0") == 1`},
+		{expr: `"1.2.3".semverCompare("1.2.3") == 0`},
+		{expr: `"1.2.3-beta.1".semverCompare("1.2.3") == -1`},
+		{expr: `"1.4.2".semverMatches(">= 1.2, < 2.0")`},
+		{expr: `!"2.0.0".semverMatches("~1.4")`},
+		{expr: `"foo".semverCompare("1.0.0") == 0`, err: "Invalid Semantic Version"},
+		{expr: `"1.0.0".semverMatches("foo")`, err: "improper constraint"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			ast, iss = env.Check(ast)
+			if iss != nil {
+				require.NoError(t, iss.Err())
+			}
+
+			prg, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+			require.NoError(t, err)
+
+			out, _, err := prg.Eval(cel.NoVars())
+			if len(tc.err) != 0 {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, true, out.Value())
+		})
+	}
+}
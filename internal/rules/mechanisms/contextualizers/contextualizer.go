@@ -27,4 +27,5 @@ type Contextualizer interface {
 	Execute(heimdall.Context, *subject.Subject) error
 	WithConfig(config map[string]any) (Contextualizer, error)
 	ContinueOnError() bool
+	HandlerID() string
 }
@@ -27,9 +27,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/goccy/go-json"
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/codec"
 	"github.com/dadrus/heimdall/internal/endpoint"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/contenttype"
@@ -51,6 +53,8 @@ var errNoContent = errors.New("no payload received")
 //
 //nolint:gochecknoinits
 func init() {
+	codec.Register(&contextualizerData{})
+
 	registerContextualizerTypeFactory(
 		func(id string, typ string, conf map[string]any) (bool, Contextualizer, error) {
 			if typ != ContextualizerGeneric {
@@ -67,6 +71,14 @@ type contextualizerData struct {
 	payload any
 }
 
+func (cd *contextualizerData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cd.payload)
+}
+
+func (cd *contextualizerData) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &cd.payload)
+}
+
 type genericContextualizer struct {
 	id              string
 	e               endpoint.Endpoint
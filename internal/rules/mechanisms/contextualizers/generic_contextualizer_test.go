@@ -32,6 +32,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/codec"
 	"github.com/dadrus/heimdall/internal/cache/mocks"
 	"github.com/dadrus/heimdall/internal/endpoint"
 	"github.com/dadrus/heimdall/internal/heimdall"
@@ -888,3 +889,21 @@ func TestGenericContextualizerExecute(t *testing.T) {
 		})
 	}
 }
+
+func TestContextualizerDataCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cd := &contextualizerData{payload: map[string]any{"foo": "bar"}}
+
+	// WHEN
+	data, err := codec.Encode(cd)
+	require.NoError(t, err)
+
+	decoded, err := codec.Decode(data)
+	require.NoError(t, err)
+
+	// THEN
+	require.IsType(t, &contextualizerData{}, decoded)
+	assert.Equal(t, cd, decoded)
+}
@@ -108,6 +108,47 @@ func (_c *ContextualizerMock_Execute_Call) RunAndReturn(run func(heimdall.Contex
 	return _c
 }
 
+// HandlerID provides a mock function with given fields:
+func (_m *ContextualizerMock) HandlerID() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ContextualizerMock_HandlerID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandlerID'
+type ContextualizerMock_HandlerID_Call struct {
+	*mock.Call
+}
+
+// HandlerID is a helper method to define mock.On call
+func (_e *ContextualizerMock_Expecter) HandlerID() *ContextualizerMock_HandlerID_Call {
+	return &ContextualizerMock_HandlerID_Call{Call: _e.mock.On("HandlerID")}
+}
+
+func (_c *ContextualizerMock_HandlerID_Call) Run(run func()) *ContextualizerMock_HandlerID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *ContextualizerMock_HandlerID_Call) Return(_a0 string) *ContextualizerMock_HandlerID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ContextualizerMock_HandlerID_Call) RunAndReturn(run func() string) *ContextualizerMock_HandlerID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // WithConfig provides a mock function with given fields: config
 func (_m *ContextualizerMock) WithConfig(config map[string]interface{}) (contextualizers.Contextualizer, error) {
 	ret := _m.Called(config)
@@ -19,6 +19,7 @@ package errorhandlers
 import (
 	"github.com/mitchellh/mapstructure"
 
+	"github.com/dadrus/heimdall/internal/endpoint"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers/matcher"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
 )
@@ -27,6 +28,9 @@ func decodeConfig(input any, output any) error {
 	dec, err := mapstructure.NewDecoder(
 		&mapstructure.DecoderConfig{
 			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				endpoint.DecodeAuthenticationStrategyHookFunc(),
+				endpoint.DecodeEndpointHookFunc(),
+				mapstructure.StringToTimeDurationHookFunc(),
 				matcher.DecodeCIDRMatcherHookFunc(),
 				matcher.DecodeErrorTypeMatcherHookFunc(),
 				template.DecodeTemplateHookFunc(),
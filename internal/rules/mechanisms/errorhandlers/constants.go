@@ -20,4 +20,6 @@ const (
 	ErrorHandlerDefault         = "default"
 	ErrorHandlerRedirect        = "redirect"
 	ErrorHandlerWWWAuthenticate = "www_authenticate"
+	ErrorHandlerOIDCLogin       = "oidc_login"
+	ErrorHandlerProblemDetails  = "problem_details"
 )
@@ -25,4 +25,5 @@ import (
 type ErrorHandler interface {
 	Execute(ctx heimdall.Context, err error) (bool, error)
 	WithConfig(config map[string]any) (ErrorHandler, error)
+	HandlerID() string
 }
@@ -0,0 +1,180 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// LoginCallbackPath is the path of the endpoint exposed by the proxy service, the authorization
+// server redirects the user agent to after the login.
+const LoginCallbackPath = "/_heimdall/oidc/callback"
+
+// LogoutPath is the path of the endpoint exposed by the proxy service, which ends the session
+// issued by the error handler referenced in the handler query parameter.
+const LogoutPath = "/_heimdall/oidc/logout"
+
+// LoginStateCookieName is the name of the cookie binding a login transaction to the user agent,
+// which started it.
+const LoginStateCookieName = "heimdall_login_state"
+
+const (
+	loginTransactionTTL       = 10 * time.Minute
+	loginTransactionKeyPrefix = "oidc_login_transaction:"
+)
+
+// LoginFlow is implemented by error handlers, which start a login at an authorization server,
+// are able to complete it on the callback and to end the established session.
+type LoginFlow interface {
+	CompleteLogin(ctx context.Context, state, code string) (*LoginResult, error)
+	// Logout revokes the session held in the session cookie, which is looked up using the given
+	// function.
+	Logout(ctx context.Context, cookie func(name string) string) (*LoginResult, error)
+}
+
+// LoginResult describes the response to be sent to the user agent after a successful login
+// or logout.
+type LoginResult struct {
+	RedirectTo string
+	Cookies    []*http.Cookie
+}
+
+// VerifyLoginState checks whether the login transaction identified by the given state has been
+// started by the user agent, which sent the given value of the login state cookie. That way, a
+// login transaction started by an attacker cannot be completed by a victim (login CSRF).
+func VerifyLoginState(state, cookieValue string) error {
+	if len(cookieValue) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication, "no login state cookie present")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(loginStateBinding(state)), []byte(cookieValue)) != 1 {
+		return errorchain.NewWithMessage(heimdall.ErrAuthentication,
+			"login state does not belong to the user agent")
+	}
+
+	return nil
+}
+
+// LoginFlowID returns the id of the error handler, which started the login transaction
+// identified by the given state.
+func LoginFlowID(cch cache.Cache, state string) (string, error) {
+	tx, err := loadLoginTransaction(cch, state)
+	if err != nil {
+		return "", err
+	}
+
+	return tx.HandlerID, nil
+}
+
+type loginTransaction struct {
+	HandlerID    string `json:"handler_id"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	ReturnTo     string `json:"return_to"`
+}
+
+func loginStateBinding(state string) string {
+	digest := sha256.Sum256([]byte(state))
+
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// loginStateCookie returns the cookie binding the login transaction identified by the given
+// state to the user agent. It is sent to the callback endpoint only. Without a state, the
+// returned cookie removes a previously set one.
+func loginStateCookie(state, domain string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     LoginStateCookieName,
+		Path:     LoginCallbackPath,
+		Domain:   domain,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	if len(state) == 0 {
+		cookie.Expires = time.Unix(0, 0)
+	} else {
+		cookie.Value = loginStateBinding(state)
+		cookie.Expires = time.Now().Add(loginTransactionTTL)
+	}
+
+	return cookie
+}
+
+func loginTransactionKey(state string) string {
+	digest := sha256.Sum256([]byte(state))
+
+	return loginTransactionKeyPrefix + hex.EncodeToString(digest[:])
+}
+
+func storeLoginTransaction(cch cache.Cache, state string, tx *loginTransaction) error {
+	rawTx, err := json.Marshal(tx)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to marshal login transaction").CausedBy(err)
+	}
+
+	cch.Set(loginTransactionKey(state), string(rawTx), loginTransactionTTL)
+
+	return nil
+}
+
+func loadLoginTransaction(cch cache.Cache, state string) (*loginTransaction, error) {
+	if len(state) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "no state present")
+	}
+
+	rawTx, ok := cch.Get(loginTransactionKey(state)).(string)
+	if !ok {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication,
+			"unknown or expired login transaction")
+	}
+
+	var tx loginTransaction
+	if err := json.Unmarshal([]byte(rawTx), &tx); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to unmarshal login transaction").CausedBy(err)
+	}
+
+	return &tx, nil
+}
+
+// takeLoginTransaction loads the transaction and removes it from the cache, so that
+// each state can be used only once.
+func takeLoginTransaction(cch cache.Cache, state string) (*loginTransaction, error) {
+	tx, err := loadLoginTransaction(cch, state)
+	if err != nil {
+		return nil, err
+	}
+
+	cch.Delete(loginTransactionKey(state))
+
+	return tx, nil
+}
@@ -74,6 +74,8 @@ func DecodeErrorTypeMatcherHookFunc() mapstructure.DecodeHookFunc {
 			matcher.Errors = []error{heimdall.ErrInternal, heimdall.ErrConfiguration}
 		case "precondition_error":
 			matcher.Errors = []error{heimdall.ErrArgument}
+		case "too_many_requests_error":
+			matcher.Errors = []error{heimdall.ErrTooManyRequests}
 		default:
 			return ErrorDescriptor{}, errorchain.
 				NewWithMessagef(heimdall.ErrConfiguration, "unsupported error type: %s", conf["type"])
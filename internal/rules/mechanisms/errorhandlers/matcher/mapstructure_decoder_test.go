@@ -83,13 +83,14 @@ error:
     raised_by: bar
   - type: internal_error
   - type: precondition_error
+  - type: too_many_requests_error
 `),
 			assert: func(t *testing.T, err error, result Type) {
 				t.Helper()
 
 				require.NoError(t, err)
 
-				require.Len(t, result.Matcher, 4)
+				require.Len(t, result.Matcher, 5)
 				assert.ElementsMatch(t, result.Matcher[0].Errors, []error{heimdall.ErrAuthentication})
 				assert.Equal(t, "foo", result.Matcher[0].HandlerID)
 				assert.ElementsMatch(t, result.Matcher[1].Errors, []error{heimdall.ErrAuthorization})
@@ -98,6 +99,8 @@ error:
 				assert.Empty(t, result.Matcher[2].HandlerID)
 				assert.ElementsMatch(t, result.Matcher[3].Errors, []error{heimdall.ErrArgument})
 				assert.Empty(t, result.Matcher[3].HandlerID)
+				assert.ElementsMatch(t, result.Matcher[4].Errors, []error{heimdall.ErrTooManyRequests})
+				assert.Empty(t, result.Matcher[4].HandlerID)
 			},
 		},
 		{
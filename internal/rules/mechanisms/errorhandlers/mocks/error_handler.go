@@ -75,6 +75,47 @@ func (_c *ErrorHandlerMock_Execute_Call) RunAndReturn(run func(heimdall.Context,
 	return _c
 }
 
+// HandlerID provides a mock function with given fields:
+func (_m *ErrorHandlerMock) HandlerID() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ErrorHandlerMock_HandlerID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandlerID'
+type ErrorHandlerMock_HandlerID_Call struct {
+	*mock.Call
+}
+
+// HandlerID is a helper method to define mock.On call
+func (_e *ErrorHandlerMock_Expecter) HandlerID() *ErrorHandlerMock_HandlerID_Call {
+	return &ErrorHandlerMock_HandlerID_Call{Call: _e.mock.On("HandlerID")}
+}
+
+func (_c *ErrorHandlerMock_HandlerID_Call) Run(run func()) *ErrorHandlerMock_HandlerID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *ErrorHandlerMock_HandlerID_Call) Return(_a0 string) *ErrorHandlerMock_HandlerID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ErrorHandlerMock_HandlerID_Call) RunAndReturn(run func() string) *ErrorHandlerMock_HandlerID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // WithConfig provides a mock function with given fields: config
 func (_m *ErrorHandlerMock) WithConfig(config map[string]interface{}) (errorhandlers.ErrorHandler, error) {
 	ret := _m.Called(config)
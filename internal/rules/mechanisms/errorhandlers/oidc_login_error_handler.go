@@ -0,0 +1,627 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/endpoint"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers/matcher"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	defaultLoginSessionCookieName = "heimdall_session"
+	defaultLoginSessionLifetime   = 8 * time.Hour
+	loginRandomValueLength        = 32
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerErrorHandlerTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, ErrorHandler, error) {
+			if typ != ErrorHandlerOIDCLogin {
+				return false, nil, nil
+			}
+
+			eh, err := newOIDCLoginErrorHandler(id, conf)
+
+			return true, eh, err
+		})
+}
+
+type oidcLoginErrorHandler struct {
+	id                    string
+	clientID              string
+	clientSecret          string
+	redirectURI           string
+	scopes                []string
+	md                    oauth2.ServerMetadataResolver
+	authorizationEndpoint string
+	tokenEndpoint         string
+	jwksEndpoint          string
+	issuer                string
+	cookieName            string
+	cookieDomain          string
+	postLogoutRedirectURI string
+	codec                 *session.Codec
+	lifetime              time.Duration
+	subjectClaim          string
+	code                  int
+	m                     []matcher.ErrorConditionMatcher
+}
+
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+}
+
+func newOIDCLoginErrorHandler(id string, rawConfig map[string]any) (*oidcLoginErrorHandler, error) {
+	type SessionConfig struct {
+		CookieName   string `mapstructure:"cookie_name"`
+		CookieDomain string `mapstructure:"cookie_domain"`
+		KeyStore     struct {
+			Path     string `mapstructure:"path"`
+			Password string `mapstructure:"password"`
+		} `mapstructure:"key_store"`
+		KeyID     string        `mapstructure:"key_id"`
+		Encrypted bool          `mapstructure:"encrypted"`
+		Lifetime  time.Duration `mapstructure:"lifetime"`
+	}
+
+	type Config struct {
+		ClientID              string                          `mapstructure:"client_id"`
+		ClientSecret          string                          `mapstructure:"client_secret"`
+		RedirectURI           string                          `mapstructure:"redirect_uri"`
+		PostLogoutRedirectURI string                          `mapstructure:"post_logout_redirect_uri"`
+		Scopes                []string                        `mapstructure:"scopes"`
+		MetadataEndpoint      *endpoint.Endpoint              `mapstructure:"metadata_endpoint"`
+		AuthorizationEndpoint string                          `mapstructure:"authorization_endpoint"`
+		TokenEndpoint         string                          `mapstructure:"token_endpoint"`
+		JWKSEndpoint          string                          `mapstructure:"jwks_endpoint"`
+		Issuer                string                          `mapstructure:"issuer"`
+		Session               SessionConfig                   `mapstructure:"session"`
+		SubjectClaim          string                          `mapstructure:"subject_claim"`
+		Code                  int                             `mapstructure:"code"`
+		When                  []matcher.ErrorConditionMatcher `mapstructure:"when"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal oidc_login error handler config").
+			CausedBy(err)
+	}
+
+	if len(conf.ClientID) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"oidc_login error handler requires 'client_id' parameter to be set")
+	}
+
+	if len(conf.RedirectURI) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"oidc_login error handler requires 'redirect_uri' parameter to be set")
+	}
+
+	redirectURI, err := url.Parse(conf.RedirectURI)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to parse 'redirect_uri' of the oidc_login error handler").CausedBy(err)
+	}
+
+	if redirectURI.Path != LoginCallbackPath {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"path of the 'redirect_uri' of the oidc_login error handler must be %s", LoginCallbackPath)
+	}
+
+	var md oauth2.ServerMetadataResolver
+
+	if conf.MetadataEndpoint != nil {
+		if err = conf.MetadataEndpoint.Validate(); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrConfiguration, "failed to validate metadata endpoint configuration").
+				CausedBy(err)
+		}
+
+		md = oauth2.NewServerMetadataResolver(*conf.MetadataEndpoint)
+	} else if len(conf.AuthorizationEndpoint) == 0 || len(conf.TokenEndpoint) == 0 ||
+		len(conf.JWKSEndpoint) == 0 || len(conf.Issuer) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"oidc_login error handler requires either 'metadata_endpoint' or 'authorization_endpoint', "+
+				"'token_endpoint', 'jwks_endpoint' and 'issuer' to be set")
+	}
+
+	if len(conf.Session.KeyStore.Path) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"oidc_login error handler requires session key_store path to be set")
+	}
+
+	if conf.Session.Lifetime < 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"oidc_login error handler session lifetime must not be negative")
+	}
+
+	if len(conf.When) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"no 'when' error handler conditions defined for the oidc_login error handler")
+	}
+
+	ks, err := keystore.NewKeyStoreFromPEMFile(conf.Session.KeyStore.Path, conf.Session.KeyStore.Password)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed loading session key store").CausedBy(err)
+	}
+
+	codec, err := session.NewCodec(ks, conf.Session.KeyID, conf.Session.Encrypted)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to configure session codec").CausedBy(err)
+	}
+
+	return &oidcLoginErrorHandler{
+		id:                    id,
+		clientID:              conf.ClientID,
+		clientSecret:          conf.ClientSecret,
+		redirectURI:           conf.RedirectURI,
+		scopes:                x.IfThenElse(len(conf.Scopes) != 0, conf.Scopes, []string{"openid"}),
+		md:                    md,
+		authorizationEndpoint: conf.AuthorizationEndpoint,
+		tokenEndpoint:         conf.TokenEndpoint,
+		jwksEndpoint:          conf.JWKSEndpoint,
+		issuer:                conf.Issuer,
+		cookieName: x.IfThenElse(len(conf.Session.CookieName) != 0,
+			conf.Session.CookieName, defaultLoginSessionCookieName),
+		cookieDomain: conf.Session.CookieDomain,
+		postLogoutRedirectURI: x.IfThenElse(len(conf.PostLogoutRedirectURI) != 0,
+			conf.PostLogoutRedirectURI, "/"),
+		codec: codec,
+		lifetime: x.IfThenElse(conf.Session.Lifetime != 0,
+			conf.Session.Lifetime, defaultLoginSessionLifetime),
+		subjectClaim: x.IfThenElse(len(conf.SubjectClaim) != 0, conf.SubjectClaim, "sub"),
+		code:         x.IfThenElse(conf.Code != 0, conf.Code, http.StatusFound),
+		m:            conf.When,
+	}, nil
+}
+
+func (eh *oidcLoginErrorHandler) Execute(ctx heimdall.Context, err error) (bool, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+
+	for _, ecm := range eh.m {
+		if !ecm.Match(ctx, err) {
+			return false, nil
+		}
+	}
+
+	logger.Debug().Str("_id", eh.id).Msg("Handling error using oidc_login error handler")
+
+	md, err := eh.serverMetadata(ctx.AppContext())
+	if err != nil {
+		return true, err
+	}
+
+	if len(md.AuthorizationEndpointURL) == 0 {
+		return true, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"no authorization endpoint available").WithErrorContext(eh)
+	}
+
+	authURL, err := url.Parse(md.AuthorizationEndpointURL)
+	if err != nil {
+		return true, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to parse authorization endpoint url").WithErrorContext(eh).CausedBy(err)
+	}
+
+	state, err := randomValue()
+	if err != nil {
+		return true, err
+	}
+
+	nonce, err := randomValue()
+	if err != nil {
+		return true, err
+	}
+
+	codeVerifier, err := randomValue()
+	if err != nil {
+		return true, err
+	}
+
+	err = storeLoginTransaction(cache.Ctx(ctx.AppContext()), state, &loginTransaction{
+		HandlerID:    eh.id,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ReturnTo:     ctx.Request().URL.String(),
+	})
+	if err != nil {
+		return true, err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", eh.clientID)
+	query.Set("redirect_uri", eh.redirectURI)
+	query.Set("scope", strings.Join(eh.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	ctx.SetPipelineError(&heimdall.ResponseError{
+		Err: &heimdall.RedirectError{
+			Message:    "login required",
+			Code:       eh.code,
+			RedirectTo: authURL.String(),
+		},
+		Headers: map[string]string{"Set-Cookie": loginStateCookie(state, eh.cookieDomain).String()},
+	})
+
+	return true, nil
+}
+
+func (eh *oidcLoginErrorHandler) CompleteLogin(ctx context.Context, state, code string) (*LoginResult, error) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Str("_id", eh.id).Msg("Completing login using oidc_login error handler")
+
+	if len(code) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "no authorization code present").
+			WithErrorContext(eh)
+	}
+
+	tx, err := takeLoginTransaction(cache.Ctx(ctx), state)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.HandlerID != eh.id {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication,
+			"login transaction was not started by this error handler").WithErrorContext(eh)
+	}
+
+	md, err := eh.serverMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := eh.exchangeCode(ctx, md.TokenEndpointURL, code, tx.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := eh.verifyIDToken(ctx, resp.IDToken, md, tx.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectID, _ := claims[eh.subjectClaim].(string)
+	if len(subjectID) == 0 {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrAuthentication,
+			"id token does not contain the %s claim", eh.subjectClaim).WithErrorContext(eh)
+	}
+
+	sess := &session.Session{
+		SubjectID:  subjectID,
+		Attributes: claims,
+		ExpiresAt:  time.Now().Add(eh.lifetime),
+	}
+
+	value, err := eh.codec.Encode(sess)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to encode session").
+			WithErrorContext(eh).
+			CausedBy(err)
+	}
+
+	return &LoginResult{
+		RedirectTo: tx.ReturnTo,
+		Cookies: []*http.Cookie{
+			{
+				Name:     eh.cookieName,
+				Value:    value,
+				Path:     "/",
+				Domain:   eh.cookieDomain,
+				Expires:  sess.ExpiresAt,
+				Secure:   true,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			},
+			loginStateCookie("", eh.cookieDomain),
+		},
+	}, nil
+}
+
+func (eh *oidcLoginErrorHandler) Logout(ctx context.Context, cookie func(name string) string) (*LoginResult, error) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Str("_id", eh.id).Msg("Ending session using oidc_login error handler")
+
+	// the cookie is removed in any case, even if it does not hold a valid session
+	if value := cookie(eh.cookieName); len(value) != 0 {
+		sess, err := eh.codec.Decode(value)
+		if err != nil {
+			logger.Debug().Err(err).Msg("Session cookie is not valid. Nothing to revoke")
+		} else if ttl := time.Until(sess.ExpiresAt); ttl > 0 {
+			session.NewStore(cache.Ctx(ctx)).Revoke(sess.ID, ttl)
+		}
+	}
+
+	return &LoginResult{
+		RedirectTo: eh.postLogoutRedirectURI,
+		Cookies: []*http.Cookie{
+			{
+				Name:     eh.cookieName,
+				Path:     "/",
+				Domain:   eh.cookieDomain,
+				Expires:  time.Unix(0, 0),
+				Secure:   true,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			},
+		},
+	}, nil
+}
+
+func (eh *oidcLoginErrorHandler) WithConfig(rawConfig map[string]any) (ErrorHandler, error) {
+	if len(rawConfig) == 0 {
+		return eh, nil
+	}
+
+	type Config struct {
+		When []matcher.ErrorConditionMatcher `mapstructure:"when"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal oidc_login error handler config").
+			CausedBy(err)
+	}
+
+	if len(conf.When) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration,
+				"no error handler conditions defined for the oidc_login error handler")
+	}
+
+	handler := *eh
+	handler.m = conf.When
+
+	return &handler, nil
+}
+
+func (eh *oidcLoginErrorHandler) HandlerID() string { return eh.id }
+
+// serverMetadata returns the metadata of the authorization server. Explicitly configured
+// endpoints and issuer take precedence over the values retrieved from the metadata endpoint.
+func (eh *oidcLoginErrorHandler) serverMetadata(ctx context.Context) (oauth2.ServerMetadata, error) {
+	var (
+		md  oauth2.ServerMetadata
+		err error
+	)
+
+	if eh.md != nil {
+		md, err = eh.md.Get(ctx)
+		if err != nil {
+			return md, errorchain.NewWithMessage(heimdall.ErrInternal,
+				"failed retrieving server metadata").WithErrorContext(eh).CausedBy(err)
+		}
+	}
+
+	md.AuthorizationEndpointURL = x.IfThenElse(len(eh.authorizationEndpoint) != 0,
+		eh.authorizationEndpoint, md.AuthorizationEndpointURL)
+	md.TokenEndpointURL = x.IfThenElse(len(eh.tokenEndpoint) != 0, eh.tokenEndpoint, md.TokenEndpointURL)
+	md.JWKSEndpointURL = x.IfThenElse(len(eh.jwksEndpoint) != 0, eh.jwksEndpoint, md.JWKSEndpointURL)
+	md.Issuer = x.IfThenElse(len(eh.issuer) != 0, eh.issuer, md.Issuer)
+
+	return md, nil
+}
+
+func (eh *oidcLoginErrorHandler) exchangeCode(
+	ctx context.Context, tokenURL, code, codeVerifier string,
+) (*tokenResponse, error) {
+	zerolog.Ctx(ctx).Debug().Msg("Exchanging authorization code")
+
+	ept := endpoint.Endpoint{
+		URL:    tokenURL,
+		Method: http.MethodPost,
+		Headers: map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+			"Accept":       "application/json",
+		},
+	}
+
+	data := url.Values{
+		"grant_type":    []string{"authorization_code"},
+		"code":          []string{code},
+		"redirect_uri":  []string{eh.redirectURI},
+		"code_verifier": []string{codeVerifier},
+		"client_id":     []string{eh.clientID},
+	}
+
+	// public clients authenticate the request with PKCE only
+	if len(eh.clientSecret) != 0 {
+		ept.AuthStrategy = &endpoint.BasicAuthStrategy{
+			User:     url.QueryEscape(eh.clientID),
+			Password: url.QueryEscape(eh.clientSecret),
+		}
+	}
+
+	rawData, err := ept.SendRequest(ctx, strings.NewReader(data.Encode()), nil)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "authorization code exchange failed").
+			WithErrorContext(eh).
+			CausedBy(err)
+	}
+
+	var resp tokenResponse
+	if err = json.Unmarshal(rawData, &resp); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal token response").
+			WithErrorContext(eh).
+			CausedBy(err)
+	}
+
+	if len(resp.IDToken) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "token response does not contain an id token").
+			WithErrorContext(eh)
+	}
+
+	return &resp, nil
+}
+
+// verifyIDToken verifies the signature of the id token using the keys retrieved from the jwks
+// endpoint of the OpenID Provider and validates its claims.
+func (eh *oidcLoginErrorHandler) verifyIDToken(
+	ctx context.Context, idToken string, md oauth2.ServerMetadata, nonce string,
+) (map[string]any, error) {
+	token, err := jwt.ParseSigned(idToken)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "failed to parse id token").
+			WithErrorContext(eh).
+			CausedBy(err)
+	}
+
+	if len(token.Headers) != 1 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication,
+			"id token must have exactly one signature").WithErrorContext(eh)
+	}
+
+	jwks, err := eh.fetchJWKS(ctx, md.JWKSEndpointURL)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := jwks.Keys
+	if kid := token.Headers[0].KeyID; len(kid) != 0 {
+		keys = jwks.Key(kid)
+	}
+
+	var (
+		claims    oauth2.Claims
+		rawClaims map[string]any
+		verified  bool
+	)
+
+	for idx := range keys {
+		// symmetric keys must never be used to verify id tokens
+		if !keys[idx].IsPublic() {
+			continue
+		}
+
+		if err = token.Claims(keys[idx].Key, &claims, &rawClaims); err == nil {
+			verified = true
+
+			break
+		}
+	}
+
+	if !verified {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication,
+			"id token signature verification failed").WithErrorContext(eh)
+	}
+
+	if claims.Expiry == nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "id token does not expire").
+			WithErrorContext(eh)
+	}
+
+	exp := oauth2.Expectation{
+		ScopesMatcher:   oauth2.NoopMatcher{},
+		TargetAudiences: []string{eh.clientID},
+		TrustedIssuers:  []string{md.Issuer},
+	}
+
+	if err = claims.Validate(exp); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "id token is not valid").
+			WithErrorContext(eh).
+			CausedBy(err)
+	}
+
+	if tokenNonce, _ := rawClaims["nonce"].(string); tokenNonce != nonce {
+		return nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "id token nonce mismatch").
+			WithErrorContext(eh)
+	}
+
+	return rawClaims, nil
+}
+
+func (eh *oidcLoginErrorHandler) fetchJWKS(ctx context.Context, jwksURL string) (*jose.JSONWebKeySet, error) {
+	zerolog.Ctx(ctx).Debug().Msg("Retrieving JWKS")
+
+	if len(jwksURL) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"no jwks endpoint available").WithErrorContext(eh)
+	}
+
+	cacheEnabled := true
+	ept := endpoint.Endpoint{
+		URL:              jwksURL,
+		Method:           http.MethodGet,
+		Headers:          map[string]string{"Accept": "application/json"},
+		HTTPCacheEnabled: &cacheEnabled,
+	}
+
+	rawData, err := ept.SendRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "request to JWKS endpoint failed").
+			WithErrorContext(eh).
+			CausedBy(err)
+	}
+
+	var jwks jose.JSONWebKeySet
+	if err = json.Unmarshal(rawData, &jwks); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal received jwks").
+			WithErrorContext(eh).
+			CausedBy(err)
+	}
+
+	return &jwks, nil
+}
+
+func randomValue() (string, error) {
+	buf := make([]byte, loginRandomValueLength)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to generate random value").CausedBy(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,968 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	cachemocks "github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/session"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func createIDToken(t *testing.T, key *ecdsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithHeader("kid", "idp"))
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestCreateOIDCLoginErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	ksFile := testsupport.CreateKeyStoreFile(t, "session")
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, eh *oidcLoginErrorHandler)
+	}{
+		{
+			uc: "without client_id",
+			config: []byte(`
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+`),
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires 'client_id'")
+			},
+		},
+		{
+			uc:     "without redirect_uri",
+			config: []byte(`client_id: foo`),
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires 'redirect_uri'")
+			},
+		},
+		{
+			uc: "with redirect_uri not pointing to the callback endpoint",
+			config: []byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/callback
+`),
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), LoginCallbackPath)
+			},
+		},
+		{
+			uc: "without metadata endpoint and with incomplete endpoint configuration",
+			config: []byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+authorization_endpoint: https://idp.local/authorize
+`),
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires either 'metadata_endpoint'")
+			},
+		},
+		{
+			uc: "without session key store",
+			config: []byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+metadata_endpoint:
+  url: https://idp.local/.well-known/openid-configuration
+`),
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "requires session key_store path")
+			},
+		},
+		{
+			uc: "without when conditions",
+			config: []byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+metadata_endpoint:
+  url: https://idp.local/.well-known/openid-configuration
+session:
+  key_store:
+    path: ` + ksFile + `
+`),
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no 'when' error handler conditions defined")
+			},
+		},
+		{
+			uc: "with unexpected fields in configuration",
+			config: []byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with minimal valid configuration",
+			config: []byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+metadata_endpoint:
+  url: https://idp.local/.well-known/openid-configuration
+session:
+  key_store:
+    path: ` + ksFile + `
+when:
+  - error:
+    - type: authentication_error
+`),
+			assert: func(t *testing.T, err error, eh *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, eh)
+				assert.Equal(t, "with minimal valid configuration", eh.HandlerID())
+				assert.Equal(t, "foo", eh.clientID)
+				assert.Empty(t, eh.clientSecret)
+				assert.Equal(t, []string{"openid"}, eh.scopes)
+				assert.NotNil(t, eh.md)
+				assert.Equal(t, defaultLoginSessionCookieName, eh.cookieName)
+				assert.Empty(t, eh.cookieDomain)
+				assert.Equal(t, "/", eh.postLogoutRedirectURI)
+				assert.Equal(t, defaultLoginSessionLifetime, eh.lifetime)
+				assert.Equal(t, "sub", eh.subjectClaim)
+				assert.Equal(t, http.StatusFound, eh.code)
+				assert.NotNil(t, eh.codec)
+				assert.Len(t, eh.m, 1)
+			},
+		},
+		{
+			uc: "with full valid configuration",
+			config: []byte(`
+client_id: foo
+client_secret: bar
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+scopes: [openid, profile]
+authorization_endpoint: https://idp.local/authorize
+token_endpoint: https://idp.local/token
+jwks_endpoint: https://idp.local/jwks
+issuer: https://idp.local
+subject_claim: email
+code: 303
+post_logout_redirect_uri: https://app.local/bye
+session:
+  cookie_name: sid
+  cookie_domain: example.com
+  key_store:
+    path: ` + ksFile + `
+  key_id: session
+  encrypted: true
+  lifetime: 1h
+when:
+  - error:
+    - type: authentication_error
+`),
+			assert: func(t *testing.T, err error, eh *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, eh)
+				assert.Equal(t, "bar", eh.clientSecret)
+				assert.Equal(t, []string{"openid", "profile"}, eh.scopes)
+				assert.Nil(t, eh.md)
+				assert.Equal(t, "https://idp.local/authorize", eh.authorizationEndpoint)
+				assert.Equal(t, "https://idp.local/token", eh.tokenEndpoint)
+				assert.Equal(t, "https://idp.local", eh.issuer)
+				assert.Equal(t, "sid", eh.cookieName)
+				assert.Equal(t, "example.com", eh.cookieDomain)
+				assert.Equal(t, "https://app.local/bye", eh.postLogoutRedirectURI)
+				assert.Equal(t, 1*time.Hour, eh.lifetime)
+				assert.Equal(t, "email", eh.subjectClaim)
+				assert.Equal(t, http.StatusSeeOther, eh.code)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			eh, err := newOIDCLoginErrorHandler(tc.uc, conf)
+
+			// THEN
+			tc.assert(t, err, eh)
+		})
+	}
+}
+
+func TestCreateOIDCLoginErrorHandlerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	ksFile := testsupport.CreateKeyStoreFile(t, "session")
+
+	prototypeConfig := []byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+authorization_endpoint: https://idp.local/authorize
+token_endpoint: https://idp.local/token
+jwks_endpoint: https://idp.local/jwks
+issuer: https://idp.local
+session:
+  key_store:
+    path: ` + ksFile + `
+when:
+  - error:
+    - type: authentication_error
+`)
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *oidcLoginErrorHandler, configured *oidcLoginErrorHandler)
+	}{
+		{
+			uc: "no new configuration provided",
+			assert: func(t *testing.T, err error, prototype *oidcLoginErrorHandler, configured *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with unsupported fields",
+			config: []byte(`client_id: bar`),
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler, _ *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with new when conditions",
+			config: []byte(`
+when:
+  - error:
+    - type: internal_error
+  - error:
+    - type: authorization_error
+`),
+			assert: func(t *testing.T, err error, prototype *oidcLoginErrorHandler, configured *oidcLoginErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.HandlerID(), configured.HandlerID())
+				assert.Equal(t, prototype.clientID, configured.clientID)
+				assert.Equal(t, prototype.codec, configured.codec)
+				assert.Len(t, prototype.m, 1)
+				assert.Len(t, configured.m, 2)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			pc, err := testsupport.DecodeTestConfig(prototypeConfig)
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newOIDCLoginErrorHandler(tc.uc, pc)
+			require.NoError(t, err)
+
+			// WHEN
+			eh, err := prototype.WithConfig(conf)
+
+			// THEN
+			var configured *oidcLoginErrorHandler
+			if err == nil {
+				configured = eh.(*oidcLoginErrorHandler) // nolint: forcetypeassert
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestOIDCLoginErrorHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	ksFile := testsupport.CreateKeyStoreFile(t, "session")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{
+  "issuer": "https://idp.local",
+  "authorization_endpoint": "https://idp.local/authorize?tenant=foo",
+  "token_endpoint": "https://idp.local/token"
+}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		uc             string
+		config         []byte
+		error          error
+		configureMocks func(t *testing.T, ctx *mocks.ContextMock, cch *cachemocks.CacheMock)
+		assert         func(t *testing.T, wasResponsible bool, err error)
+	}{
+		{
+			uc: "not responsible for error",
+			config: []byte(`
+authorization_endpoint: https://idp.local/authorize
+token_endpoint: https://idp.local/token
+jwks_endpoint: https://idp.local/jwks
+issuer: https://idp.local
+`),
+			error: heimdall.ErrInternal,
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
+				assert.NoError(t, err)
+				assert.False(t, wasResponsible)
+			},
+		},
+		{
+			uc: "responsible for error with metadata from configuration",
+			config: []byte(`
+authorization_endpoint: https://idp.local/authorize
+token_endpoint: https://idp.local/token
+jwks_endpoint: https://idp.local/jwks
+issuer: https://idp.local
+scopes: [openid, email]
+`),
+			error: heimdall.ErrAuthentication,
+			configureMocks: func(t *testing.T, ctx *mocks.ContextMock, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				var codeVerifier string
+
+				requestURL, err := url.Parse("https://app.local/foo?bar=baz")
+				require.NoError(t, err)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: requestURL})
+				cch.EXPECT().Set(mock.Anything, mock.MatchedBy(func(value string) bool {
+					t.Helper()
+
+					var tx loginTransaction
+					require.NoError(t, json.Unmarshal([]byte(value), &tx))
+
+					assert.Equal(t, "foo", tx.HandlerID)
+					assert.Equal(t, "https://app.local/foo?bar=baz", tx.ReturnTo)
+					assert.NotEmpty(t, tx.Nonce)
+					assert.NotEmpty(t, tx.CodeVerifier)
+
+					codeVerifier = tx.CodeVerifier
+
+					return true
+				}), loginTransactionTTL)
+				ctx.EXPECT().SetPipelineError(mock.MatchedBy(func(respErr *heimdall.ResponseError) bool {
+					t.Helper()
+
+					var redirErr *heimdall.RedirectError
+					require.ErrorAs(t, respErr, &redirErr)
+
+					redirectURL, err := url.Parse(redirErr.RedirectTo)
+					require.NoError(t, err)
+
+					cookies := (&http.Response{Header: http.Header{"Set-Cookie": {respErr.Headers["Set-Cookie"]}}}).Cookies()
+					require.Len(t, cookies, 1)
+
+					stateCookie := cookies[0]
+					assert.Equal(t, LoginStateCookieName, stateCookie.Name)
+					assert.Equal(t, LoginCallbackPath, stateCookie.Path)
+					assert.True(t, stateCookie.Secure)
+					assert.True(t, stateCookie.HttpOnly)
+					require.NoError(t, VerifyLoginState(redirectURL.Query().Get("state"), stateCookie.Value))
+
+					challenge := sha256.Sum256([]byte(codeVerifier))
+
+					assert.Equal(t, http.StatusFound, redirErr.Code)
+					assert.Equal(t, "idp.local", redirectURL.Host)
+					assert.Equal(t, "/authorize", redirectURL.Path)
+
+					query := redirectURL.Query()
+					assert.Equal(t, "code", query.Get("response_type"))
+					assert.Equal(t, "foo", query.Get("client_id"))
+					assert.Equal(t, "https://heimdall.local/_heimdall/oidc/callback", query.Get("redirect_uri"))
+					assert.Equal(t, "openid email", query.Get("scope"))
+					assert.NotEmpty(t, query.Get("state"))
+					assert.NotEmpty(t, query.Get("nonce"))
+					assert.Equal(t, "S256", query.Get("code_challenge_method"))
+					assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), query.Get("code_challenge"))
+
+					return true
+				}))
+			},
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
+				assert.NoError(t, err)
+				assert.True(t, wasResponsible)
+			},
+		},
+		{
+			uc: "responsible for error with metadata from metadata endpoint",
+			config: []byte(`
+metadata_endpoint:
+  url: ` + srv.URL + `
+  enable_http_cache: false
+code: 303
+`),
+			error: heimdall.ErrAuthentication,
+			configureMocks: func(t *testing.T, ctx *mocks.ContextMock, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				requestURL, err := url.Parse("https://app.local/foo")
+				require.NoError(t, err)
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: requestURL})
+				cch.EXPECT().Set(mock.Anything, mock.Anything, loginTransactionTTL)
+				ctx.EXPECT().SetPipelineError(mock.MatchedBy(func(respErr *heimdall.ResponseError) bool {
+					t.Helper()
+
+					var redirErr *heimdall.RedirectError
+					require.ErrorAs(t, respErr, &redirErr)
+
+					redirectURL, err := url.Parse(redirErr.RedirectTo)
+					require.NoError(t, err)
+
+					assert.Equal(t, http.StatusSeeOther, redirErr.Code)
+					assert.Equal(t, "/authorize", redirectURL.Path)
+					assert.Equal(t, "foo", redirectURL.Query().Get("tenant"))
+					assert.Equal(t, "openid", redirectURL.Query().Get("scope"))
+
+					return true
+				}))
+			},
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
+				assert.NoError(t, err)
+				assert.True(t, wasResponsible)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *mocks.ContextMock, _ *cachemocks.CacheMock) { t.Helper() })
+
+			conf, err := testsupport.DecodeTestConfig(append([]byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+session:
+  key_store:
+    path: `+ksFile+`
+when:
+  - error:
+    - type: authentication_error
+`), tc.config...))
+			require.NoError(t, err)
+
+			cch := cachemocks.NewCacheMock(t)
+			mctx := mocks.NewContextMock(t)
+			mctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch))
+
+			configureMocks(t, mctx, cch)
+
+			errorHandler, err := newOIDCLoginErrorHandler("foo", conf)
+			require.NoError(t, err)
+
+			// WHEN
+			wasResponsible, err := errorHandler.Execute(mctx, tc.error)
+
+			// THEN
+			tc.assert(t, wasResponsible, err)
+		})
+	}
+}
+
+func TestOIDCLoginErrorHandlerCompleteLogin(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tokenEndpointCalled bool
+		checkRequest        func(t *testing.T, req *http.Request)
+		responseContent     []byte
+		responseCode        int
+	)
+
+	idpKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rawJWKS, err := json.Marshal(jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{{Key: idpKey.Public(), KeyID: "idp", Algorithm: string(jose.ES256), Use: "sig"}},
+	})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jwks" {
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write(rawJWKS)
+			assert.NoError(t, err)
+
+			return
+		}
+
+		tokenEndpointCalled = true
+
+		checkRequest(t, r)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(responseCode)
+
+		if responseContent != nil {
+			_, err := w.Write(responseContent)
+			assert.NoError(t, err)
+		}
+	}))
+	defer srv.Close()
+
+	ksFile := testsupport.CreateKeyStoreFile(t, "session")
+
+	validClaims := func(nonce string) map[string]any {
+		return map[string]any{
+			"iss":   "https://idp.local",
+			"aud":   "foo",
+			"sub":   "alice",
+			"exp":   time.Now().Add(5 * time.Minute).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": nonce,
+			"email": "alice@example.com",
+		}
+	}
+
+	rawTx := func(handlerID string) string {
+		return `{"handler_id":"` + handlerID + `","code_verifier":"verifier","nonce":"nonce",` +
+			`"return_to":"https://app.local/foo"}`
+	}
+
+	for _, tc := range []struct {
+		uc             string
+		state          string
+		code           string
+		configureMocks func(t *testing.T, cch *cachemocks.CacheMock)
+		instructServer func(t *testing.T)
+		assert         func(t *testing.T, err error, eh *oidcLoginErrorHandler, res *LoginResult)
+	}{
+		{
+			uc:    "without authorization code",
+			state: "state",
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler, _ *LoginResult) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no authorization code")
+			},
+		},
+		{
+			uc:    "with unknown state",
+			state: "state",
+			code:  "code",
+			configureMocks: func(t *testing.T, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(loginTransactionKey("state")).Return(nil)
+			},
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler, _ *LoginResult) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "unknown or expired")
+			},
+		},
+		{
+			uc:    "with transaction started by another error handler",
+			state: "state",
+			code:  "code",
+			configureMocks: func(t *testing.T, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(loginTransactionKey("state")).Return(rawTx("bar"))
+				cch.EXPECT().Delete(loginTransactionKey("state"))
+			},
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler, _ *LoginResult) {
+				t.Helper()
+
+				assert.False(t, tokenEndpointCalled)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "not started by this error handler")
+			},
+		},
+		{
+			uc:    "with failing token endpoint",
+			state: "state",
+			code:  "code",
+			configureMocks: func(t *testing.T, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(loginTransactionKey("state")).Return(rawTx("foo"))
+				cch.EXPECT().Delete(loginTransactionKey("state"))
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusBadRequest
+			},
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler, _ *LoginResult) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrCommunication)
+			},
+		},
+		{
+			uc:    "with id token signed by an unknown key",
+			state: "state",
+			code:  "code",
+			configureMocks: func(t *testing.T, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(loginTransactionKey("state")).Return(rawTx("foo"))
+				cch.EXPECT().Delete(loginTransactionKey("state"))
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				require.NoError(t, err)
+
+				responseContent = []byte(`{"id_token":"` + createIDToken(t, otherKey, validClaims("nonce")) + `"}`)
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler, _ *LoginResult) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "signature verification failed")
+			},
+		},
+		{
+			uc:    "with id token having unexpected nonce",
+			state: "state",
+			code:  "code",
+			configureMocks: func(t *testing.T, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(loginTransactionKey("state")).Return(rawTx("foo"))
+				cch.EXPECT().Delete(loginTransactionKey("state"))
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseContent = []byte(`{"id_token":"` + createIDToken(t, idpKey, validClaims("foo")) + `"}`)
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler, _ *LoginResult) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "nonce mismatch")
+			},
+		},
+		{
+			uc:    "with id token issued for another client",
+			state: "state",
+			code:  "code",
+			configureMocks: func(t *testing.T, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(loginTransactionKey("state")).Return(rawTx("foo"))
+				cch.EXPECT().Delete(loginTransactionKey("state"))
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				claims := validClaims("nonce")
+				claims["aud"] = "bar"
+
+				responseContent = []byte(`{"id_token":"` + createIDToken(t, idpKey, claims) + `"}`)
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, _ *oidcLoginErrorHandler, _ *LoginResult) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "id token is not valid")
+			},
+		},
+		{
+			uc:    "successful login",
+			state: "state",
+			code:  "code",
+			configureMocks: func(t *testing.T, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Get(loginTransactionKey("state")).Return(rawTx("foo"))
+				cch.EXPECT().Delete(loginTransactionKey("state"))
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkRequest = func(t *testing.T, req *http.Request) {
+					t.Helper()
+
+					assert.Equal(t, http.MethodPost, req.Method)
+
+					user, password, ok := req.BasicAuth()
+					assert.True(t, ok)
+					assert.Equal(t, "foo", user)
+					assert.Equal(t, "bar", password)
+
+					require.NoError(t, req.ParseForm())
+					assert.Equal(t, "authorization_code", req.PostForm.Get("grant_type"))
+					assert.Equal(t, "code", req.PostForm.Get("code"))
+					assert.Equal(t, "verifier", req.PostForm.Get("code_verifier"))
+					assert.Equal(t, "foo", req.PostForm.Get("client_id"))
+					assert.Equal(t, "https://heimdall.local/_heimdall/oidc/callback",
+						req.PostForm.Get("redirect_uri"))
+				}
+
+				responseContent = []byte(`{"id_token":"` + createIDToken(t, idpKey, validClaims("nonce")) + `"}`)
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, eh *oidcLoginErrorHandler, res *LoginResult) {
+				t.Helper()
+
+				assert.True(t, tokenEndpointCalled)
+				require.NoError(t, err)
+				require.NotNil(t, res)
+
+				assert.Equal(t, "https://app.local/foo", res.RedirectTo)
+				require.Len(t, res.Cookies, 2)
+
+				sessionCookie := res.Cookies[0]
+				assert.Equal(t, defaultLoginSessionCookieName, sessionCookie.Name)
+				assert.Equal(t, "/", sessionCookie.Path)
+				assert.True(t, sessionCookie.Secure)
+				assert.True(t, sessionCookie.HttpOnly)
+				assert.Equal(t, http.SameSiteLaxMode, sessionCookie.SameSite)
+				assert.WithinDuration(t, time.Now().Add(defaultLoginSessionLifetime), sessionCookie.Expires, time.Minute)
+
+				stateCookie := res.Cookies[1]
+				assert.Equal(t, LoginStateCookieName, stateCookie.Name)
+				assert.Equal(t, LoginCallbackPath, stateCookie.Path)
+				assert.Empty(t, stateCookie.Value)
+				assert.True(t, stateCookie.Expires.Before(time.Now()))
+
+				sess, err := eh.codec.Decode(sessionCookie.Value)
+				require.NoError(t, err)
+				assert.Equal(t, "alice", sess.SubjectID)
+				assert.Equal(t, "alice@example.com", sess.Attributes["email"])
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			tokenEndpointCalled = false
+			responseContent = nil
+			responseCode = http.StatusOK
+			checkRequest = func(t *testing.T, _ *http.Request) { t.Helper() }
+
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *cachemocks.CacheMock) { t.Helper() })
+			instructServer := x.IfThenElse(tc.instructServer != nil,
+				tc.instructServer,
+				func(t *testing.T) { t.Helper() })
+
+			conf, err := testsupport.DecodeTestConfig([]byte(`
+client_id: foo
+client_secret: bar
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+authorization_endpoint: https://idp.local/authorize
+token_endpoint: ` + srv.URL + `/token
+jwks_endpoint: ` + srv.URL + `/jwks
+issuer: https://idp.local
+session:
+  key_store:
+    path: ` + ksFile + `
+when:
+  - error:
+    - type: authentication_error
+`))
+			require.NoError(t, err)
+
+			cch := cachemocks.NewCacheMock(t)
+
+			configureMocks(t, cch)
+			// used by the http cache while retrieving the jwks
+			cch.EXPECT().Get(mock.Anything).Return(nil).Maybe()
+			cch.EXPECT().Set(mock.Anything, mock.Anything, mock.Anything).Maybe()
+			instructServer(t)
+
+			eh, err := newOIDCLoginErrorHandler("foo", conf)
+			require.NoError(t, err)
+
+			// WHEN
+			res, err := eh.CompleteLogin(cache.WithContext(context.Background(), cch), tc.state, tc.code)
+
+			// THEN
+			tc.assert(t, err, eh, res)
+		})
+	}
+}
+
+func TestOIDCLoginErrorHandlerLogout(t *testing.T) {
+	t.Parallel()
+
+	ksFile := testsupport.CreateKeyStoreFile(t, "session")
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+client_id: foo
+redirect_uri: https://heimdall.local/_heimdall/oidc/callback
+authorization_endpoint: https://idp.local/authorize
+token_endpoint: https://idp.local/token
+jwks_endpoint: https://idp.local/jwks
+issuer: https://idp.local
+post_logout_redirect_uri: https://app.local/bye
+session:
+  key_store:
+    path: ` + ksFile + `
+when:
+  - error:
+    - type: authentication_error
+`))
+	require.NoError(t, err)
+
+	eh, err := newOIDCLoginErrorHandler("foo", conf)
+	require.NoError(t, err)
+
+	sess := &session.Session{SubjectID: "alice", ExpiresAt: time.Now().Add(1 * time.Hour)}
+
+	validCookie, err := eh.codec.Encode(sess)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc             string
+		cookie         string
+		configureMocks func(t *testing.T, cch *cachemocks.CacheMock)
+	}{
+		{uc: "without session cookie"},
+		{uc: "with invalid session cookie", cookie: "foo"},
+		{
+			uc:     "with valid session cookie",
+			cookie: validCookie,
+			configureMocks: func(t *testing.T, cch *cachemocks.CacheMock) {
+				t.Helper()
+
+				cch.EXPECT().Delete(mock.Anything)
+				cch.EXPECT().Set(mock.Anything, sess.ID, mock.MatchedBy(func(ttl time.Duration) bool {
+					return ttl > 0 && ttl <= 1*time.Hour
+				}))
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *cachemocks.CacheMock) { t.Helper() })
+
+			cch := cachemocks.NewCacheMock(t)
+			configureMocks(t, cch)
+
+			// WHEN
+			res, err := eh.Logout(cache.WithContext(context.Background(), cch), func(name string) string {
+				return x.IfThenElse(name == defaultLoginSessionCookieName, tc.cookie, "")
+			})
+
+			// THEN
+			require.NoError(t, err)
+			require.NotNil(t, res)
+			assert.Equal(t, "https://app.local/bye", res.RedirectTo)
+			require.Len(t, res.Cookies, 1)
+			assert.Equal(t, defaultLoginSessionCookieName, res.Cookies[0].Name)
+			assert.Empty(t, res.Cookies[0].Value)
+			assert.True(t, res.Cookies[0].Expires.Before(time.Now()))
+		})
+	}
+}
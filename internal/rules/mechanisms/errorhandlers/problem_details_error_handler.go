@@ -0,0 +1,106 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/errorhandlers/matcher"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerErrorHandlerTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, ErrorHandler, error) {
+			if typ != ErrorHandlerProblemDetails {
+				return false, nil, nil
+			}
+
+			eh, err := newProblemDetailsErrorHandler(id, conf)
+
+			return true, eh, err
+		})
+}
+
+type problemDetailsErrorHandler struct {
+	id string
+	m  []matcher.ErrorConditionMatcher
+}
+
+func newProblemDetailsErrorHandler(id string, rawConfig map[string]any) (*problemDetailsErrorHandler, error) {
+	type Config struct {
+		When []matcher.ErrorConditionMatcher `mapstructure:"when"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal problem details error handler config").
+			CausedBy(err)
+	}
+
+	return &problemDetailsErrorHandler{id: id, m: conf.When}, nil
+}
+
+func (eh *problemDetailsErrorHandler) Execute(ctx heimdall.Context, err error) (bool, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+
+	for _, ecm := range eh.m {
+		if !ecm.Match(ctx, err) {
+			return false, nil
+		}
+	}
+
+	logger.Debug().Str("_id", eh.id).Msg("Handling error using problem details error handler")
+
+	ctx.SetPipelineError(&heimdall.ResponseError{Err: err, ProblemDetails: true})
+
+	return true, nil
+}
+
+func (eh *problemDetailsErrorHandler) WithConfig(rawConfig map[string]any) (ErrorHandler, error) {
+	if len(rawConfig) == 0 {
+		return eh, nil
+	}
+
+	type Config struct {
+		When *[]matcher.ErrorConditionMatcher `mapstructure:"when"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration,
+				"failed to unmarshal problem details error handler config").
+			CausedBy(err)
+	}
+
+	return &problemDetailsErrorHandler{
+		id: eh.id,
+		m: x.IfThenElseExec(conf.When != nil,
+			func() []matcher.ErrorConditionMatcher { return *conf.When },
+			func() []matcher.ErrorConditionMatcher { return eh.m },
+		),
+	}, nil
+}
+
+func (eh *problemDetailsErrorHandler) HandlerID() string { return eh.id }
@@ -0,0 +1,279 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package errorhandlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateProblemDetailsErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, errorHandler *problemDetailsErrorHandler)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, errorHandler *problemDetailsErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, errorHandler)
+				assert.Equal(t, "without configuration", errorHandler.HandlerID())
+				assert.Equal(t, "without configuration", errorHandler.HandlerID())
+				assert.Empty(t, errorHandler.m)
+			},
+		},
+		{
+			uc:     "with configuration containing unsupported fields",
+			config: []byte(`foo: bar`),
+			assert: func(t *testing.T, err error, _ *problemDetailsErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with error conditions",
+			config: []byte(`
+when:
+  - error:
+      - type: authorization_error
+`),
+			assert: func(t *testing.T, err error, errorHandler *problemDetailsErrorHandler) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, errorHandler)
+				require.Len(t, errorHandler.m, 1)
+				require.NotNil(t, errorHandler.m[0].Error)
+				errorDescriptors := *errorHandler.m[0].Error
+				require.Len(t, errorDescriptors, 1)
+				assert.Equal(t, []error{heimdall.ErrAuthorization}, errorDescriptors[0].Errors)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			errorHandler, err := newProblemDetailsErrorHandler(tc.uc, conf)
+
+			// THEN
+			tc.assert(t, err, errorHandler)
+		})
+	}
+}
+
+func TestCreateProblemDetailsErrorHandlerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *problemDetailsErrorHandler,
+			configured *problemDetailsErrorHandler)
+	}{
+		{
+			uc: "no new configuration provided",
+			assert: func(t *testing.T, err error, prototype *problemDetailsErrorHandler,
+				configured *problemDetailsErrorHandler,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "unsupported fields provided",
+			config: []byte(`to: foo`),
+			assert: func(t *testing.T, err error, _ *problemDetailsErrorHandler, _ *problemDetailsErrorHandler) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with 'when' reconfigured",
+			config: []byte(`
+when:
+  - error:
+      - type: authentication_error
+`),
+			assert: func(t *testing.T, err error, prototype *problemDetailsErrorHandler,
+				configured *problemDetailsErrorHandler,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, configured)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.HandlerID(), configured.HandlerID())
+				require.Len(t, configured.m, 1)
+				errorDescriptors := *configured.m[0].Error
+				require.Len(t, errorDescriptors, 1)
+				assert.Equal(t, []error{heimdall.ErrAuthentication}, errorDescriptors[0].Errors)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+when:
+  - error:
+      - type: authorization_error
+`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newProblemDetailsErrorHandler("foo", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			errorHandler, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				pdEH *problemDetailsErrorHandler
+				ok   bool
+			)
+
+			if err == nil {
+				pdEH, ok = errorHandler.(*problemDetailsErrorHandler)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, pdEH)
+		})
+	}
+}
+
+func TestProblemDetailsErrorHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	denialErr := errorchain.NewWithMessage(heimdall.ErrAuthorization, "admin scope required").
+		CausedBy(&heimdall.DenialError{
+			ExpressionID:   "is_admin",
+			Message:        "admin scope required",
+			RequiredScopes: []string{"admin"},
+		})
+
+	for _, tc := range []struct {
+		uc               string
+		config           []byte
+		error            error
+		configureContext func(t *testing.T, ctx *mocks.ContextMock)
+		assert           func(t *testing.T, wasResponsible bool, err error)
+	}{
+		{
+			uc: "not responsible for error",
+			config: []byte(`
+when:
+  - error:
+      - type: authorization_error
+`),
+			error: heimdall.ErrAuthentication,
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.False(t, wasResponsible)
+			},
+		},
+		{
+			uc:    "responsible for any error without conditions",
+			error: heimdall.ErrAuthentication,
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(&heimdall.ResponseError{
+					Err:            heimdall.ErrAuthentication,
+					ProblemDetails: true,
+				})
+			},
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, wasResponsible)
+			},
+		},
+		{
+			uc: "responsible for denial",
+			config: []byte(`
+when:
+  - error:
+      - type: authorization_error
+`),
+			error: denialErr,
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(&heimdall.ResponseError{Err: denialErr, ProblemDetails: true})
+			},
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, wasResponsible)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureContext := x.IfThenElse(tc.configureContext != nil,
+				tc.configureContext,
+				func(t *testing.T, ctx *mocks.ContextMock) { t.Helper() })
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			mctx := mocks.NewContextMock(t)
+			mctx.EXPECT().AppContext().Return(context.Background())
+
+			configureContext(t, mctx)
+
+			errorHandler, err := newProblemDetailsErrorHandler("foo", conf)
+			require.NoError(t, err)
+
+			// WHEN
+			wasResponsible, err := errorHandler.Execute(mctx, tc.error)
+
+			// THEN
+			tc.assert(t, wasResponsible, err)
+		})
+	}
+}
@@ -17,7 +17,9 @@
 package errorhandlers
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/rs/zerolog"
 
@@ -44,15 +46,19 @@ func init() {
 }
 
 type wwwAuthenticateErrorHandler struct {
-	id    string
-	realm string
-	m     []matcher.ErrorConditionMatcher
+	id                string
+	scheme            string
+	realm             string
+	insufficientScope bool
+	m                 []matcher.ErrorConditionMatcher
 }
 
 func newWWWAuthenticateErrorHandler(id string, rawConfig map[string]any) (*wwwAuthenticateErrorHandler, error) {
 	type Config struct {
-		Realm string                          `mapstructure:"realm"`
-		When  []matcher.ErrorConditionMatcher `mapstructure:"when"`
+		Scheme            string                          `mapstructure:"scheme"`
+		Realm             string                          `mapstructure:"realm"`
+		InsufficientScope bool                            `mapstructure:"insufficient_scope"`
+		When              []matcher.ErrorConditionMatcher `mapstructure:"when"`
 	}
 
 	var conf Config
@@ -69,9 +75,11 @@ func newWWWAuthenticateErrorHandler(id string, rawConfig map[string]any) (*wwwAu
 	}
 
 	return &wwwAuthenticateErrorHandler{
-		id:    id,
-		realm: x.IfThenElse(len(conf.Realm) != 0, conf.Realm, "Please authenticate"),
-		m:     conf.When,
+		id:                id,
+		scheme:            x.IfThenElse(len(conf.Scheme) != 0, conf.Scheme, "Basic"),
+		realm:             x.IfThenElse(len(conf.Realm) != 0, conf.Realm, "Please authenticate"),
+		insufficientScope: conf.InsufficientScope,
+		m:                 conf.When,
 	}, nil
 }
 
@@ -86,7 +94,21 @@ func (eh *wwwAuthenticateErrorHandler) Execute(ctx heimdall.Context, err error)
 
 	logger.Debug().Str("_id", eh.id).Msg("Handling error using www-authenticate error handler")
 
-	ctx.AddHeaderForUpstream("WWW-Authenticate", fmt.Sprintf("Basic realm=%s", eh.realm))
+	var denial *heimdall.DenialError
+	if eh.insufficientScope && errors.As(err, &denial) && len(denial.RequiredScopes) != 0 {
+		// see RFC 6750, section 3.1
+		ctx.SetPipelineError(&heimdall.ResponseError{
+			Err: err,
+			Headers: map[string]string{
+				"WWW-Authenticate": fmt.Sprintf(`%s realm="%s", error="insufficient_scope", scope="%s"`,
+					eh.scheme, eh.realm, strings.Join(denial.RequiredScopes, " ")),
+			},
+		})
+
+		return true, nil
+	}
+
+	ctx.AddHeaderForUpstream("WWW-Authenticate", fmt.Sprintf(`%s realm="%s"`, eh.scheme, eh.realm))
 	ctx.SetPipelineError(heimdall.ErrAuthentication)
 
 	return true, nil
@@ -98,8 +120,10 @@ func (eh *wwwAuthenticateErrorHandler) WithConfig(rawConfig map[string]any) (Err
 	}
 
 	type Config struct {
-		Realm *string                          `mapstructure:"realm"`
-		When  *[]matcher.ErrorConditionMatcher `mapstructure:"when"`
+		Scheme            *string                          `mapstructure:"scheme"`
+		Realm             *string                          `mapstructure:"realm"`
+		InsufficientScope *bool                            `mapstructure:"insufficient_scope"`
+		When              *[]matcher.ErrorConditionMatcher `mapstructure:"when"`
 	}
 
 	var conf Config
@@ -112,9 +136,15 @@ func (eh *wwwAuthenticateErrorHandler) WithConfig(rawConfig map[string]any) (Err
 
 	return &wwwAuthenticateErrorHandler{
 		id: eh.id,
+		scheme: x.IfThenElseExec(conf.Scheme != nil,
+			func() string { return *conf.Scheme },
+			func() string { return eh.scheme }),
 		realm: x.IfThenElseExec(conf.Realm != nil,
 			func() string { return *conf.Realm },
 			func() string { return eh.realm }),
+		insufficientScope: x.IfThenElseExec(conf.InsufficientScope != nil,
+			func() bool { return *conf.InsufficientScope },
+			func() bool { return eh.insufficientScope }),
 		m: x.IfThenElseExec(conf.When != nil,
 			func() []matcher.ErrorConditionMatcher { return *conf.When },
 			func() []matcher.ErrorConditionMatcher { return eh.m },
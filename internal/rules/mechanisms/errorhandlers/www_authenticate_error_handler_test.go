@@ -28,6 +28,7 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/heimdall/mocks"
 	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
@@ -101,7 +102,9 @@ when:
 				require.NoError(t, err)
 				require.NotNil(t, errorHandler)
 				assert.Equal(t, "with minimum required configuration", errorHandler.HandlerID())
+				assert.Equal(t, "Basic", errorHandler.scheme)
 				assert.Equal(t, "Please authenticate", errorHandler.realm)
+				assert.False(t, errorHandler.insufficientScope)
 				require.Len(t, errorHandler.m, 1)
 				assert.Nil(t, errorHandler.m[0].CIDR)
 				assert.Nil(t, errorHandler.m[0].Headers)
@@ -116,7 +119,9 @@ when:
 		{
 			uc: "with all possible attributes",
 			config: []byte(`
+scheme: Bearer
 realm: "What is your password"
+insufficient_scope: true
 when:
   - error:
       - type: precondition_error
@@ -127,7 +132,9 @@ when:
 				require.NoError(t, err)
 				require.NotNil(t, errorHandler)
 				assert.Equal(t, "with all possible attributes", errorHandler.HandlerID())
+				assert.Equal(t, "Bearer", errorHandler.scheme)
 				assert.Equal(t, "What is your password", errorHandler.realm)
+				assert.True(t, errorHandler.insufficientScope)
 				require.Len(t, errorHandler.m, 1)
 				assert.Nil(t, errorHandler.m[0].CIDR)
 				assert.Nil(t, errorHandler.m[0].Headers)
@@ -275,6 +282,33 @@ when:
 				assert.Equal(t, prototype.m, configured.m)
 			},
 		},
+		{
+			uc: "with 'scheme' and 'insufficient_scope' reconfigured",
+			prototypeConfig: []byte(`
+when:
+  - error:
+      - type: authorization_error
+`),
+			config: []byte(`
+scheme: Bearer
+insufficient_scope: true
+`),
+			assert: func(t *testing.T, err error, prototype *wwwAuthenticateErrorHandler,
+				configured *wwwAuthenticateErrorHandler,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.NotNil(t, configured)
+				assert.Equal(t, "Basic", prototype.scheme)
+				assert.Equal(t, "Bearer", configured.scheme)
+				assert.False(t, prototype.insufficientScope)
+				assert.True(t, configured.insufficientScope)
+				assert.Equal(t, prototype.realm, configured.realm)
+				assert.Equal(t, prototype.m, configured.m)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			pc, err := testsupport.DecodeTestConfig(tc.prototypeConfig)
@@ -308,6 +342,9 @@ when:
 func TestWWWAuthenticateErrorHandlerExecute(t *testing.T) {
 	t.Parallel()
 
+	denialErr := errorchain.NewWithMessage(heimdall.ErrAuthorization, "write access required").
+		CausedBy(&heimdall.DenialError{ExpressionID: "1", RequiredScopes: []string{"admin", "write"}})
+
 	for _, tc := range []struct {
 		uc               string
 		config           []byte
@@ -346,7 +383,7 @@ when:
 					mock.MatchedBy(func(val string) bool {
 						assert.True(t, strings.HasPrefix(val, "Basic "))
 						realm := strings.TrimLeft(val, "Basic ")
-						assert.Equal(t, "realm=Please authenticate", realm)
+						assert.Equal(t, `realm="Please authenticate"`, realm)
 
 						return true
 					}))
@@ -375,7 +412,7 @@ when:
 					mock.MatchedBy(func(val string) bool {
 						assert.True(t, strings.HasPrefix(val, "Basic "))
 						realm := strings.TrimLeft(val, "Basic ")
-						assert.Equal(t, "realm=Your password please", realm)
+						assert.Equal(t, `realm="Your password please"`, realm)
 
 						return true
 					}))
@@ -383,6 +420,57 @@ when:
 			assert: func(t *testing.T, wasResponsible bool, err error) {
 				t.Helper()
 
+				assert.NoError(t, err)
+				assert.True(t, wasResponsible)
+			},
+		},
+		{
+			uc: "responsible for denial with required scopes",
+			config: []byte(`
+scheme: Bearer
+realm: api
+insufficient_scope: true
+when:
+  - error:
+      - type: authorization_error
+`),
+			error: denialErr,
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(&heimdall.ResponseError{
+					Err: denialErr,
+					Headers: map[string]string{
+						"WWW-Authenticate": `Bearer realm="api", error="insufficient_scope", scope="admin write"`,
+					},
+				})
+			},
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
+				assert.NoError(t, err)
+				assert.True(t, wasResponsible)
+			},
+		},
+		{
+			uc: "responsible for denial with required scopes, but insufficient scope not enabled",
+			config: []byte(`
+scheme: Bearer
+realm: api
+when:
+  - error:
+      - type: authorization_error
+`),
+			error: denialErr,
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(heimdall.ErrAuthentication)
+				ctx.EXPECT().AddHeaderForUpstream("WWW-Authenticate", `Bearer realm="api"`)
+			},
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
 				assert.NoError(t, err)
 				assert.True(t, wasResponsible)
 			},
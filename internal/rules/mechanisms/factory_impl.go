@@ -1,6 +1,8 @@
 package mechanisms
 
 import (
+	"context"
+
 	"github.com/rs/zerolog"
 
 	"github.com/dadrus/heimdall/internal/config"
@@ -22,13 +24,18 @@ func NewFactory(conf *config.Configuration, logger zerolog.Logger) (Factory, err
 		return nil, err
 	}
 
-	return &mechanismsFactory{r: repository}, nil
+	return &mechanismsFactory{r: repository, l: logger}, nil
 }
 
 type mechanismsFactory struct {
 	r *prototypeRepository
+	l zerolog.Logger
 }
 
+func (hf *mechanismsFactory) start(_ context.Context) error { return hf.r.start(hf.l) }
+
+func (hf *mechanismsFactory) stop(_ context.Context) error { return hf.r.stop() }
+
 func (hf *mechanismsFactory) CreateAuthenticator(_, id string, conf config.MechanismConfig) (
 	authenticators.Authenticator, error,
 ) {
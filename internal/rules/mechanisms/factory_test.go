@@ -545,6 +545,61 @@ func TestCreateHandlerFactory(t *testing.T) {
 				assert.ErrorIs(t, err, authenticators.ErrUnsupportedAuthenticatorType)
 			},
 		},
+		{
+			uc: "fails with dpop enabled authenticator and disabled cache",
+			conf: &config.Configuration{
+				Cache: config.CacheConfig{Type: "noop"},
+				Rules: config.Rules{
+					Prototypes: &config.MechanismPrototypes{
+						Authenticators: []config.Mechanism{
+							{
+								ID:   "foo",
+								Type: authenticators.AuthenticatorJwt,
+								Config: config.MechanismConfig{
+									"jwks_endpoint":     map[string]any{"url": "http://foo.bar"},
+									"assertions":        map[string]any{"issuers": []string{"foo"}},
+									"sender_constraint": map[string]any{"dpop": map[string]any{}},
+								},
+							},
+						},
+					},
+				},
+			},
+			assert: func(t *testing.T, err error, factory *mechanismsFactory) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "foo requires a cache")
+			},
+		},
+		{
+			uc: "dpop enabled authenticator with enabled cache",
+			conf: &config.Configuration{
+				Rules: config.Rules{
+					Prototypes: &config.MechanismPrototypes{
+						Authenticators: []config.Mechanism{
+							{
+								ID:   "foo",
+								Type: authenticators.AuthenticatorJwt,
+								Config: config.MechanismConfig{
+									"jwks_endpoint":     map[string]any{"url": "http://foo.bar"},
+									"assertions":        map[string]any{"issuers": []string{"foo"}},
+									"sender_constraint": map[string]any{"dpop": map[string]any{}},
+								},
+							},
+						},
+					},
+				},
+			},
+			assert: func(t *testing.T, err error, factory *mechanismsFactory) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, factory)
+				assert.Len(t, factory.r.authenticators, 1)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			var (
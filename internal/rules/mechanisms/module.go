@@ -17,9 +17,29 @@
 package mechanisms
 
 import (
+	"context"
+
 	"go.uber.org/fx"
 )
 
 var Module = fx.Options( //nolint:gochecknoglobals
-	fx.Provide(NewFactory),
+	fx.Provide(
+		fx.Annotate(
+			NewFactory,
+			fx.OnStart(func(ctx context.Context, factory Factory) error {
+				if f, ok := factory.(*mechanismsFactory); ok {
+					return f.start(ctx)
+				}
+
+				return nil
+			}),
+			fx.OnStop(func(ctx context.Context, factory Factory) error {
+				if f, ok := factory.(*mechanismsFactory); ok {
+					return f.stop(ctx)
+				}
+
+				return nil
+			}),
+		),
+	),
 )
@@ -0,0 +1,188 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/dadrus/heimdall/internal/endpoint"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	defaultMetadataRefreshInterval = 15 * time.Minute
+	oidcDiscoveryPath              = "/.well-known/openid-configuration"
+	oauth2ServerMetadataPath       = "/.well-known/oauth-authorization-server"
+)
+
+// ServerMetadata holds the parts of the OpenID Connect Discovery, respectively the
+// RFC 8414 authorization server metadata, heimdall makes use of.
+type ServerMetadata struct {
+	Issuer                   string   `json:"issuer"`
+	AuthorizationEndpointURL string   `json:"authorization_endpoint"`
+	JWKSEndpointURL          string   `json:"jwks_uri"`
+	IntrospectionEndpointURL string   `json:"introspection_endpoint"`
+	TokenEndpointURL         string   `json:"token_endpoint"`
+	IDTokenSigningAlgorithms []string `json:"id_token_signing_alg_values_supported"`
+}
+
+type ServerMetadataResolver interface {
+	Get(ctx context.Context) (ServerMetadata, error)
+}
+
+type serverMetadataResolver struct {
+	e               endpoint.Endpoint
+	refreshInterval time.Duration
+	sf              singleflight.Group
+
+	mut       sync.RWMutex
+	md        *ServerMetadata
+	fetchedAt time.Time
+}
+
+// NewServerMetadataResolver creates a resolver, which fetches the metadata from the given
+// endpoint on first usage and refreshes it periodically. If a refresh fails, the previously
+// fetched metadata is used until the next refresh attempt.
+func NewServerMetadataResolver(ep endpoint.Endpoint) ServerMetadataResolver {
+	if ep.Headers == nil {
+		ep.Headers = make(map[string]string)
+	}
+
+	if _, ok := ep.Headers["Accept"]; !ok {
+		ep.Headers["Accept"] = "application/json"
+	}
+
+	if len(ep.Method) == 0 {
+		ep.Method = http.MethodGet
+	}
+
+	if ep.HTTPCacheEnabled == nil {
+		enabled := true
+		ep.HTTPCacheEnabled = &enabled
+	}
+
+	return &serverMetadataResolver{e: ep, refreshInterval: defaultMetadataRefreshInterval}
+}
+
+func (r *serverMetadataResolver) Get(ctx context.Context) (ServerMetadata, error) {
+	r.mut.RLock()
+	md, fetchedAt := r.md, r.fetchedAt
+	r.mut.RUnlock()
+
+	if md != nil && time.Since(fetchedAt) < r.refreshInterval {
+		return *md, nil
+	}
+
+	// concurrent callers wait for the same request instead of fetching the metadata on their own
+	result, err, _ := r.sf.Do(r.e.URL, func() (any, error) { return r.refresh(ctx) })
+	if err != nil {
+		return ServerMetadata{}, err
+	}
+
+	return result.(ServerMetadata), nil // nolint: forcetypeassert
+}
+
+func (r *serverMetadataResolver) refresh(ctx context.Context) (ServerMetadata, error) {
+	md, err := r.fetch(ctx)
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if err != nil {
+		if r.md == nil {
+			return ServerMetadata{}, err
+		}
+
+		zerolog.Ctx(ctx).Warn().Err(err).Str("_endpoint", r.e.URL).
+			Msg("Failed to refresh authorization server metadata. Using previously fetched one")
+
+		// don't retry on each request
+		r.fetchedAt = time.Now()
+
+		return *r.md, nil
+	}
+
+	r.md = md
+	r.fetchedAt = time.Now()
+
+	return *md, nil
+}
+
+func (r *serverMetadataResolver) fetch(ctx context.Context) (*ServerMetadata, error) {
+	zerolog.Ctx(ctx).Debug().Str("_endpoint", r.e.URL).Msg("Retrieving authorization server metadata")
+
+	rawData, err := r.e.SendRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrCommunication,
+			"failed to retrieve authorization server metadata").CausedBy(err)
+	}
+
+	var md ServerMetadata
+	if err = json.Unmarshal(rawData, &md); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to unmarshal authorization server metadata").CausedBy(err)
+	}
+
+	if len(md.Issuer) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"authorization server metadata does not contain an issuer")
+	}
+
+	if expected := expectedIssuer(r.e.URL); len(expected) != 0 &&
+		strings.TrimSuffix(md.Issuer, "/") != expected {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"issuer %s from the authorization server metadata does not match the issuer %s "+
+				"of the metadata endpoint", md.Issuer, expected)
+	}
+
+	return &md, nil
+}
+
+// expectedIssuer derives the issuer from the url of the metadata endpoint as defined by
+// OpenID Connect Discovery §4 and RFC 8414 §3. If the url does not follow these definitions,
+// an empty string is returned.
+func expectedIssuer(metadataURL string) string {
+	mdURL, err := url.Parse(metadataURL)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case strings.HasSuffix(mdURL.Path, oidcDiscoveryPath):
+		mdURL.Path = strings.TrimSuffix(mdURL.Path, oidcDiscoveryPath)
+	case strings.HasPrefix(mdURL.Path, oauth2ServerMetadataPath):
+		mdURL.Path = strings.TrimPrefix(mdURL.Path, oauth2ServerMetadataPath)
+	default:
+		return ""
+	}
+
+	mdURL.RawPath = ""
+	mdURL.RawQuery = ""
+	mdURL.Fragment = ""
+
+	return strings.TrimSuffix(mdURL.String(), "/")
+}
@@ -0,0 +1,338 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/endpoint"
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func TestServerMetadataResolverGet(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls           int
+		responseCode    int
+		responseContent string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(responseCode)
+		_, err := w.Write([]byte(responseContent))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		uc     string
+		steps  []func(t *testing.T, r *serverMetadataResolver)
+		assert func(t *testing.T, md ServerMetadata, err error)
+	}{
+		{
+			uc: "with unexpected response code",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, _ *serverMetadataResolver) {
+					t.Helper()
+
+					responseCode = http.StatusNotFound
+				},
+			},
+			assert: func(t *testing.T, _ ServerMetadata, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Equal(t, 1, calls)
+			},
+		},
+		{
+			uc: "with malformed response",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, _ *serverMetadataResolver) {
+					t.Helper()
+
+					responseCode = http.StatusOK
+					responseContent = "foo"
+				},
+			},
+			assert: func(t *testing.T, _ ServerMetadata, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "unmarshal")
+			},
+		},
+		{
+			uc: "without issuer in the response",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, _ *serverMetadataResolver) {
+					t.Helper()
+
+					responseCode = http.StatusOK
+					responseContent = `{"jwks_uri":"https://foo.bar/jwks"}`
+				},
+			},
+			assert: func(t *testing.T, _ ServerMetadata, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "issuer")
+			},
+		},
+		{
+			uc: "with issuer not matching the openid discovery endpoint",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, r *serverMetadataResolver) {
+					t.Helper()
+
+					r.e.URL = srv.URL + "/tenant/.well-known/openid-configuration"
+					responseCode = http.StatusOK
+					responseContent = `{"issuer":"https://foo.bar/tenant"}`
+				},
+			},
+			assert: func(t *testing.T, _ ServerMetadata, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "does not match")
+			},
+		},
+		{
+			uc: "with issuer not matching the oauth2 authorization server metadata endpoint",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, r *serverMetadataResolver) {
+					t.Helper()
+
+					r.e.URL = srv.URL + "/.well-known/oauth-authorization-server/tenant"
+					responseCode = http.StatusOK
+					responseContent = `{"issuer":"` + srv.URL + `/other"}`
+				},
+			},
+			assert: func(t *testing.T, _ ServerMetadata, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "does not match")
+			},
+		},
+		{
+			uc: "with issuer matching the openid discovery endpoint",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, r *serverMetadataResolver) {
+					t.Helper()
+
+					r.e.URL = srv.URL + "/tenant/.well-known/openid-configuration"
+					responseCode = http.StatusOK
+					responseContent = `{"issuer":"` + srv.URL + `/tenant/"}`
+				},
+			},
+			assert: func(t *testing.T, md ServerMetadata, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, srv.URL+"/tenant/", md.Issuer)
+			},
+		},
+		{
+			uc: "with issuer matching the oauth2 authorization server metadata endpoint",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, r *serverMetadataResolver) {
+					t.Helper()
+
+					r.e.URL = srv.URL + "/.well-known/oauth-authorization-server"
+					responseCode = http.StatusOK
+					responseContent = `{"issuer":"` + srv.URL + `"}`
+				},
+			},
+			assert: func(t *testing.T, md ServerMetadata, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, srv.URL, md.Issuer)
+			},
+		},
+		{
+			uc: "successful and cached",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, r *serverMetadataResolver) {
+					t.Helper()
+
+					responseCode = http.StatusOK
+					responseContent = `{
+"issuer":"https://foo.bar",
+"jwks_uri":"https://foo.bar/jwks",
+"introspection_endpoint":"https://foo.bar/introspect",
+"token_endpoint":"https://foo.bar/token",
+"id_token_signing_alg_values_supported":["RS256","ES384"]
+}`
+
+					_, err := r.Get(context.Background())
+					require.NoError(t, err)
+
+					responseContent = `{"issuer":"https://bar.foo"}`
+				},
+			},
+			assert: func(t *testing.T, md ServerMetadata, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 1, calls)
+				assert.Equal(t, "https://foo.bar", md.Issuer)
+				assert.Equal(t, "https://foo.bar/jwks", md.JWKSEndpointURL)
+				assert.Equal(t, "https://foo.bar/introspect", md.IntrospectionEndpointURL)
+				assert.Equal(t, "https://foo.bar/token", md.TokenEndpointURL)
+				assert.Equal(t, []string{"RS256", "ES384"}, md.IDTokenSigningAlgorithms)
+			},
+		},
+		{
+			uc: "successful refresh after refresh interval",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, r *serverMetadataResolver) {
+					t.Helper()
+
+					responseCode = http.StatusOK
+					responseContent = `{"issuer":"https://foo.bar"}`
+
+					_, err := r.Get(context.Background())
+					require.NoError(t, err)
+
+					r.fetchedAt = time.Now().Add(-r.refreshInterval)
+					responseContent = `{"issuer":"https://bar.foo"}`
+				},
+			},
+			assert: func(t *testing.T, md ServerMetadata, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 2, calls)
+				assert.Equal(t, "https://bar.foo", md.Issuer)
+			},
+		},
+		{
+			uc: "failed refresh after refresh interval results in previous metadata",
+			steps: []func(t *testing.T, r *serverMetadataResolver){
+				func(t *testing.T, r *serverMetadataResolver) {
+					t.Helper()
+
+					responseCode = http.StatusOK
+					responseContent = `{"issuer":"https://foo.bar"}`
+
+					_, err := r.Get(context.Background())
+					require.NoError(t, err)
+
+					r.fetchedAt = time.Now().Add(-r.refreshInterval)
+					responseCode = http.StatusInternalServerError
+				},
+			},
+			assert: func(t *testing.T, md ServerMetadata, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 2, calls)
+				assert.Equal(t, "https://foo.bar", md.Issuer)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			calls = 0
+			responseContent = ""
+
+			disabled := false
+			resolver := NewServerMetadataResolver(endpoint.Endpoint{URL: srv.URL, HTTPCacheEnabled: &disabled})
+			r, ok := resolver.(*serverMetadataResolver)
+			require.True(t, ok)
+
+			for _, step := range tc.steps {
+				step(t, r)
+			}
+
+			// WHEN
+			md, err := r.Get(context.Background())
+
+			// THEN
+			tc.assert(t, md, err)
+		})
+	}
+}
+
+func TestServerMetadataResolverGetConcurrently(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		<-release
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{"issuer":"https://foo.bar"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	disabled := false
+	resolver := NewServerMetadataResolver(endpoint.Endpoint{URL: srv.URL, HTTPCacheEnabled: &disabled})
+
+	var wg sync.WaitGroup
+
+	// WHEN
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			md, err := resolver.Get(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "https://foo.bar", md.Issuer)
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// THEN
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -21,7 +21,9 @@ import (
 
 	"github.com/rs/zerolog"
 
+	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/authorizers"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/contextualizers"
@@ -46,6 +48,12 @@ func newPrototypeRepository(
 		return nil, err
 	}
 
+	if err = verifyCacheAvailable(conf, authenticatorMap); err != nil {
+		logger.Error().Err(err).Msg("Failed loading authenticators definitions")
+
+		return nil, err
+	}
+
 	logger.Debug().Msg("Loading definitions for authorizers")
 
 	authorizerMap, err := createPipelineObjects(conf.Rules.Prototypes.Authorizers, logger,
@@ -115,6 +123,44 @@ func createPipelineObjects[T any](
 	return objects, nil
 }
 
+// managedMechanism is implemented by mechanisms holding resources, like file watchers or
+// connections, which are bound to the lifecycle of heimdall.
+type managedMechanism interface {
+	Start(logger zerolog.Logger) error
+	Stop() error
+}
+
+func appendManaged[T any](managed []managedMechanism, objects map[string]T) []managedMechanism {
+	for _, object := range objects {
+		if mm, ok := any(object).(managedMechanism); ok {
+			managed = append(managed, mm)
+		}
+	}
+
+	return managed
+}
+
+// cacheDependentMechanism is implemented by mechanisms, which rely on the cache for their
+// security properties, like the detection of replayed DPoP proofs.
+type cacheDependentMechanism interface {
+	RequiresCache() bool
+}
+
+func verifyCacheAvailable[T any](conf *config.Configuration, objects map[string]T) error {
+	if cache.Enabled(conf) {
+		return nil
+	}
+
+	for id, object := range objects {
+		if cdm, ok := any(object).(cacheDependentMechanism); ok && cdm.RequiresCache() {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"mechanism %s requires a cache, but the cache is disabled", id)
+		}
+	}
+
+	return nil
+}
+
 type prototypeRepository struct {
 	authenticators  map[string]authenticators.Authenticator
 	authorizers     map[string]authorizers.Authorizer
@@ -172,3 +218,36 @@ func (r *prototypeRepository) ErrorHandler(id string) (errorhandlers.ErrorHandle
 
 	return errorHandler, nil
 }
+
+func (r *prototypeRepository) managed() []managedMechanism {
+	var managed []managedMechanism
+
+	managed = appendManaged(managed, r.authenticators)
+	managed = appendManaged(managed, r.authorizers)
+	managed = appendManaged(managed, r.contextualizers)
+	managed = appendManaged(managed, r.unifiers)
+
+	return appendManaged(managed, r.errorHandlers)
+}
+
+func (r *prototypeRepository) start(logger zerolog.Logger) error {
+	for _, mm := range r.managed() {
+		if err := mm.Start(logger); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *prototypeRepository) stop() error {
+	var errs []error
+
+	for _, mm := range r.managed() {
+		if err := mm.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
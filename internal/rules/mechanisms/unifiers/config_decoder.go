@@ -19,6 +19,7 @@ package unifiers
 import (
 	"github.com/mitchellh/mapstructure"
 
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
 )
 
@@ -28,6 +29,7 @@ func decodeConfig(input any, output any) error {
 			DecodeHook: mapstructure.ComposeDecodeHookFunc(
 				mapstructure.StringToTimeDurationHookFunc(),
 				template.DecodeTemplateHookFunc(),
+				extractors.DecodeCompositeExtractStrategyHookFunc(),
 			),
 			Result:      output,
 			ErrorUnused: true,
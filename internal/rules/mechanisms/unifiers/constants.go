@@ -17,8 +17,9 @@
 package unifiers
 
 const (
-	UnifierNoop   = "noop"
-	UnifierJwt    = "jwt"
-	UnifierHeader = "header"
-	UnifierCookie = "cookie"
+	UnifierNoop          = "noop"
+	UnifierJwt           = "jwt"
+	UnifierHeader        = "header"
+	UnifierCookie        = "cookie"
+	UnifierTokenExchange = "token_exchange"
 )
@@ -0,0 +1,405 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unifiers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/endpoint"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerUnifierTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Unifier, error) {
+			if typ != UnifierTokenExchange {
+				return false, nil, nil
+			}
+
+			unifier, err := newTokenExchangeUnifier(id, conf)
+
+			return true, unifier, err
+		})
+}
+
+type tokenExchangeResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+}
+
+type tokenExchangeUnifier struct {
+	id                 string
+	tokenURL           string
+	clientID           string
+	clientSecret       string
+	audience           []string
+	resource           []string
+	scopes             []string
+	subjectToken       template.Template
+	subjectTokenSource extractors.AuthDataExtractStrategy
+	subjectTokenType   string
+	requestedTokenType string
+	ttl                *time.Duration
+	headerName         string
+	headerScheme       string
+}
+
+func newTokenExchangeUnifier(id string, rawConfig map[string]any) (*tokenExchangeUnifier, error) {
+	type HeaderConfig struct {
+		Name   string `mapstructure:"name"`
+		Scheme string `mapstructure:"scheme"`
+	}
+
+	type Config struct {
+		TokenURL           string                              `mapstructure:"token_url"`
+		ClientID           string                              `mapstructure:"client_id"`
+		ClientSecret       string                              `mapstructure:"client_secret"`
+		Audience           []string                            `mapstructure:"audience"`
+		Resource           []string                            `mapstructure:"resource"`
+		Scopes             []string                            `mapstructure:"scopes"`
+		SubjectToken       template.Template                   `mapstructure:"subject_token"`
+		SubjectTokenSource extractors.CompositeExtractStrategy `mapstructure:"subject_token_source"`
+		SubjectTokenType   string                              `mapstructure:"subject_token_type"`
+		RequestedTokenType string                              `mapstructure:"requested_token_type"`
+		CacheTTL           *time.Duration                      `mapstructure:"cache_ttl"`
+		Header             *HeaderConfig                       `mapstructure:"header"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal token exchange unifier config").
+			CausedBy(err)
+	}
+
+	if len(conf.TokenURL) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "token exchange unifier requires token_url to be set")
+	}
+
+	if _, err := url.Parse(conf.TokenURL); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to parse token_url").
+			CausedBy(err)
+	}
+
+	if len(conf.ClientID) == 0 || len(conf.ClientSecret) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"token exchange unifier requires client_id and client_secret to be set")
+	}
+
+	if conf.SubjectToken != nil && conf.SubjectTokenSource != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"subject_token and subject_token_source cannot be configured together")
+	}
+
+	if conf.Header != nil && len(strings.TrimSpace(conf.Header.Name)) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "configured header name is an empty string")
+	}
+
+	var source extractors.AuthDataExtractStrategy
+
+	if conf.SubjectToken == nil {
+		source = x.IfThenElseExec(conf.SubjectTokenSource == nil,
+			func() extractors.CompositeExtractStrategy {
+				return extractors.CompositeExtractStrategy{
+					extractors.HeaderValueExtractStrategy{Name: "Authorization", Schema: "Bearer"},
+				}
+			},
+			func() extractors.CompositeExtractStrategy { return conf.SubjectTokenSource },
+		)
+	}
+
+	return &tokenExchangeUnifier{
+		id:                 id,
+		tokenURL:           conf.TokenURL,
+		clientID:           conf.ClientID,
+		clientSecret:       conf.ClientSecret,
+		audience:           conf.Audience,
+		resource:           conf.Resource,
+		scopes:             conf.Scopes,
+		subjectToken:       conf.SubjectToken,
+		subjectTokenSource: source,
+		subjectTokenType: x.IfThenElse(len(conf.SubjectTokenType) != 0,
+			conf.SubjectTokenType, tokenTypeAccessToken),
+		requestedTokenType: x.IfThenElse(len(conf.RequestedTokenType) != 0,
+			conf.RequestedTokenType, tokenTypeAccessToken),
+		ttl: conf.CacheTTL,
+		headerName: x.IfThenElseExec(conf.Header != nil,
+			func() string { return conf.Header.Name },
+			func() string { return "Authorization" }),
+		headerScheme: x.IfThenElseExec(conf.Header != nil,
+			func() string { return conf.Header.Scheme },
+			func() string { return "Bearer" }),
+	}, nil
+}
+
+func (u *tokenExchangeUnifier) Execute(ctx heimdall.Context, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", u.id).Msg("Unifying using token exchange unifier")
+
+	if sub == nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to execute token exchange unifier due to 'nil' subject").
+			WithErrorContext(u)
+	}
+
+	subjectToken, err := u.getSubjectToken(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	cch := cache.Ctx(ctx.AppContext())
+	cacheKey := u.calculateCacheKey(subjectToken)
+
+	var token string
+
+	if entry := cch.Get(cacheKey); entry != nil {
+		var ok bool
+
+		if token, ok = entry.(string); !ok {
+			logger.Warn().Msg("Wrong object type from cache")
+			cch.Delete(cacheKey)
+		} else {
+			logger.Debug().Msg("Reusing exchanged token from cache")
+		}
+	}
+
+	if len(token) == 0 {
+		resp, err := u.exchangeToken(ctx, subjectToken)
+		if err != nil {
+			return err
+		}
+
+		token = resp.AccessToken
+
+		if cacheTTL := u.getCacheTTL(resp); cacheTTL > 0 {
+			cch.Set(cacheKey, token, cacheTTL)
+		}
+	}
+
+	ctx.AddHeaderForUpstream(u.headerName,
+		x.IfThenElseExec(len(u.headerScheme) != 0,
+			func() string { return fmt.Sprintf("%s %s", u.headerScheme, token) },
+			func() string { return token }))
+
+	return nil
+}
+
+func (u *tokenExchangeUnifier) WithConfig(rawConfig map[string]any) (Unifier, error) {
+	if len(rawConfig) == 0 {
+		return u, nil
+	}
+
+	type Config struct {
+		Audience []string       `mapstructure:"audience"`
+		Resource []string       `mapstructure:"resource"`
+		Scopes   []string       `mapstructure:"scopes"`
+		CacheTTL *time.Duration `mapstructure:"cache_ttl"`
+	}
+
+	var conf Config
+	if err := decodeConfig(rawConfig, &conf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to unmarshal token exchange unifier config").
+			CausedBy(err)
+	}
+
+	unifier := *u
+	unifier.audience = x.IfThenElse(len(conf.Audience) != 0, conf.Audience, u.audience)
+	unifier.resource = x.IfThenElse(len(conf.Resource) != 0, conf.Resource, u.resource)
+	unifier.scopes = x.IfThenElse(len(conf.Scopes) != 0, conf.Scopes, u.scopes)
+	unifier.ttl = x.IfThenElse(conf.CacheTTL != nil, conf.CacheTTL, u.ttl)
+
+	return &unifier, nil
+}
+
+func (u *tokenExchangeUnifier) HandlerID() string { return u.id }
+
+func (u *tokenExchangeUnifier) ContinueOnError() bool { return false }
+
+func (u *tokenExchangeUnifier) getSubjectToken(ctx heimdall.Context, sub *subject.Subject) (string, error) {
+	if u.subjectToken == nil {
+		token, err := u.subjectTokenSource.GetAuthData(ctx)
+		if err != nil {
+			return "", errorchain.
+				NewWithMessage(heimdall.ErrArgument, "no subject token present").
+				WithErrorContext(u).
+				CausedBy(err)
+		}
+
+		return token, nil
+	}
+
+	token, err := u.subjectToken.Render(map[string]any{
+		"Request": ctx.Request(),
+		"Subject": sub,
+	})
+	if err != nil {
+		return "", errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to render subject token").
+			WithErrorContext(u).
+			CausedBy(err)
+	}
+
+	if len(token) == 0 {
+		return "", errorchain.
+			NewWithMessage(heimdall.ErrArgument, "rendered subject token is empty").
+			WithErrorContext(u)
+	}
+
+	return token, nil
+}
+
+func (u *tokenExchangeUnifier) exchangeToken(
+	ctx heimdall.Context, subjectToken string,
+) (*tokenExchangeResponse, error) {
+	zerolog.Ctx(ctx.AppContext()).Debug().Msg("Exchanging subject token")
+
+	ept := endpoint.Endpoint{
+		URL:    u.tokenURL,
+		Method: http.MethodPost,
+		AuthStrategy: &endpoint.BasicAuthStrategy{
+			User:     url.QueryEscape(u.clientID),
+			Password: url.QueryEscape(u.clientSecret),
+		},
+		Headers: map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+			"Accept":       "application/json",
+		},
+	}
+
+	data := url.Values{
+		"grant_type":           []string{grantTypeTokenExchange},
+		"subject_token":        []string{subjectToken},
+		"subject_token_type":   []string{u.subjectTokenType},
+		"requested_token_type": []string{u.requestedTokenType},
+	}
+
+	for _, aud := range u.audience {
+		data.Add("audience", aud)
+	}
+
+	for _, res := range u.resource {
+		data.Add("resource", res)
+	}
+
+	if len(u.scopes) != 0 {
+		data.Add("scope", strings.Join(u.scopes, " "))
+	}
+
+	rawData, err := ept.SendRequest(ctx.AppContext(), strings.NewReader(data.Encode()), nil)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "token exchange failed").
+			WithErrorContext(u).
+			CausedBy(err)
+	}
+
+	var resp tokenExchangeResponse
+	if err = json.Unmarshal(rawData, &resp); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal token exchange response").
+			WithErrorContext(u).
+			CausedBy(err)
+	}
+
+	if len(resp.AccessToken) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "token exchange response does not contain a token").
+			WithErrorContext(u)
+	}
+
+	return &resp, nil
+}
+
+func (u *tokenExchangeUnifier) getCacheTTL(resp *tokenExchangeResponse) time.Duration {
+	expiresIn := x.IfThenElseExec(resp.ExpiresIn > 0,
+		func() time.Duration { return time.Duration(resp.ExpiresIn)*time.Second - defaultCacheLeeway },
+		func() time.Duration { return 0 })
+
+	switch {
+	case u.ttl == nil:
+		return x.IfThenElse(expiresIn > 0, expiresIn, 0)
+	case expiresIn <= 0:
+		return *u.ttl
+	default:
+		return x.IfThenElse(*u.ttl < expiresIn, *u.ttl, expiresIn)
+	}
+}
+
+func (u *tokenExchangeUnifier) calculateCacheKey(subjectToken string) string {
+	hash := sha256.New()
+
+	// each field is length prefixed, so that values cannot be shifted between fields
+	writeCacheKeyField(hash, u.tokenURL)
+	writeCacheKeyField(hash, u.clientID)
+	writeCacheKeyField(hash, u.audience...)
+	writeCacheKeyField(hash, u.resource...)
+	writeCacheKeyField(hash, u.scopes...)
+	writeCacheKeyField(hash, u.subjectTokenType)
+	writeCacheKeyField(hash, u.requestedTokenType)
+	writeCacheKeyField(hash, subjectToken)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeCacheKeyField writes the number of the given values followed by each value prefixed
+// with its length.
+func writeCacheKeyField(digest hash.Hash, values ...string) {
+	const int64BytesCount = 8
+
+	lenBytes := make([]byte, int64BytesCount)
+
+	binary.LittleEndian.PutUint64(lenBytes, uint64(len(values)))
+	digest.Write(lenBytes)
+
+	for _, value := range values {
+		binary.LittleEndian.PutUint64(lenBytes, uint64(len(value)))
+		digest.Write(lenBytes)
+		digest.Write(stringx.ToBytes(value))
+	}
+}
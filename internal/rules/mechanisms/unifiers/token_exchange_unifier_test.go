@@ -0,0 +1,638 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unifiers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateTokenExchangeUnifier(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, unifier *tokenExchangeUnifier)
+	}{
+		{
+			uc: "without config",
+			assert: func(t *testing.T, err error, _ *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "token_url")
+			},
+		},
+		{
+			uc: "with unsupported attributes",
+			config: []byte(`
+token_url: https://foo.bar/token
+foo: bar
+`),
+			assert: func(t *testing.T, err error, _ *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "without client credentials",
+			config: []byte(`
+token_url: https://foo.bar/token
+client_id: foo
+`),
+			assert: func(t *testing.T, err error, _ *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "client_id and client_secret")
+			},
+		},
+		{
+			uc: "with subject token and subject token source",
+			config: []byte(`
+token_url: https://foo.bar/token
+client_id: foo
+client_secret: bar
+subject_token: "{{ .Subject.Attributes.token }}"
+subject_token_source:
+  - header: X-Token
+`),
+			assert: func(t *testing.T, err error, _ *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "cannot be configured together")
+			},
+		},
+		{
+			uc: "with empty header name",
+			config: []byte(`
+token_url: https://foo.bar/token
+client_id: foo
+client_secret: bar
+header:
+  name: " "
+`),
+			assert: func(t *testing.T, err error, _ *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "header name is an empty string")
+			},
+		},
+		{
+			uc: "with minimal config",
+			id: "tex",
+			config: []byte(`
+token_url: https://foo.bar/token
+client_id: foo
+client_secret: bar
+`),
+			assert: func(t *testing.T, err error, unifier *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, "tex", unifier.HandlerID())
+				assert.Equal(t, "tex", unifier.HandlerID())
+				assert.False(t, unifier.ContinueOnError())
+				assert.Equal(t, "https://foo.bar/token", unifier.tokenURL)
+				assert.Equal(t, "foo", unifier.clientID)
+				assert.Equal(t, "bar", unifier.clientSecret)
+				assert.Empty(t, unifier.audience)
+				assert.Empty(t, unifier.resource)
+				assert.Empty(t, unifier.scopes)
+				assert.Nil(t, unifier.subjectToken)
+				assert.Equal(t, extractors.CompositeExtractStrategy{
+					extractors.HeaderValueExtractStrategy{Name: "Authorization", Schema: "Bearer"},
+				}, unifier.subjectTokenSource)
+				assert.Equal(t, tokenTypeAccessToken, unifier.subjectTokenType)
+				assert.Equal(t, tokenTypeAccessToken, unifier.requestedTokenType)
+				assert.Nil(t, unifier.ttl)
+				assert.Equal(t, "Authorization", unifier.headerName)
+				assert.Equal(t, "Bearer", unifier.headerScheme)
+			},
+		},
+		{
+			uc: "with full config",
+			id: "tex",
+			config: []byte(`
+token_url: https://foo.bar/token
+client_id: foo
+client_secret: bar
+audience:
+  - baz
+resource:
+  - https://baz.local
+scopes:
+  - foo
+  - bar
+subject_token: "{{ .Subject.Attributes.token }}"
+subject_token_type: urn:ietf:params:oauth:token-type:id_token
+requested_token_type: urn:ietf:params:oauth:token-type:jwt
+cache_ttl: 1m
+header:
+  name: X-Token
+`),
+			assert: func(t *testing.T, err error, unifier *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, []string{"baz"}, unifier.audience)
+				assert.Equal(t, []string{"https://baz.local"}, unifier.resource)
+				assert.Equal(t, []string{"foo", "bar"}, unifier.scopes)
+				assert.NotNil(t, unifier.subjectToken)
+				assert.Nil(t, unifier.subjectTokenSource)
+				assert.Equal(t, "urn:ietf:params:oauth:token-type:id_token", unifier.subjectTokenType)
+				assert.Equal(t, "urn:ietf:params:oauth:token-type:jwt", unifier.requestedTokenType)
+				assert.Equal(t, time.Minute, *unifier.ttl)
+				assert.Equal(t, "X-Token", unifier.headerName)
+				assert.Empty(t, unifier.headerScheme)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			unifier, err := newTokenExchangeUnifier(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, unifier)
+		})
+	}
+}
+
+func TestCreateTokenExchangeUnifierFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *tokenExchangeUnifier, configured *tokenExchangeUnifier)
+	}{
+		{
+			uc: "without config",
+			assert: func(t *testing.T, err error, prototype *tokenExchangeUnifier, configured *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with not overridable attributes",
+			config: []byte(`token_url: https://bar.foo/token`),
+			assert: func(t *testing.T, err error, _ *tokenExchangeUnifier, _ *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc: "with overridden attributes",
+			config: []byte(`
+audience:
+  - zab
+scopes:
+  - baz
+cache_ttl: 10s
+`),
+			assert: func(t *testing.T, err error, prototype *tokenExchangeUnifier, configured *tokenExchangeUnifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.HandlerID(), configured.HandlerID())
+				assert.Equal(t, prototype.tokenURL, configured.tokenURL)
+				assert.Equal(t, []string{"zab"}, configured.audience)
+				assert.Equal(t, prototype.resource, configured.resource)
+				assert.Equal(t, []string{"baz"}, configured.scopes)
+				assert.Equal(t, 10*time.Second, *configured.ttl)
+				assert.Equal(t, []string{"baz"}, prototype.audience)
+				assert.Nil(t, prototype.ttl)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+token_url: https://foo.bar/token
+client_id: foo
+client_secret: bar
+audience:
+  - baz
+resource:
+  - https://baz.local
+`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newTokenExchangeUnifier("tex", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			unifier, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				configured *tokenExchangeUnifier
+				ok         bool
+			)
+
+			if err == nil {
+				configured, ok = unifier.(*tokenExchangeUnifier)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, configured)
+		})
+	}
+}
+
+func TestTokenExchangeUnifierExecute(t *testing.T) {
+	t.Parallel()
+
+	var (
+		endpointCalled  bool
+		checkRequest    func(t *testing.T, req *http.Request)
+		responseCode    int
+		responseContent string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpointCalled = true
+
+		checkRequest(t, r)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(responseCode)
+		_, err := w.Write([]byte(responseContent))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	config := []byte(`
+token_url: ` + srv.URL + `
+client_id: foo
+client_secret: bar
+audience:
+  - baz
+scopes:
+  - foo
+  - bar
+`)
+
+	for _, tc := range []struct {
+		uc             string
+		config         []byte
+		subject        *subject.Subject
+		instructServer func(t *testing.T)
+		configureMocks func(t *testing.T, ctx *heimdallmocks.ContextMock, cch *mocks.CacheMock)
+		assert         func(t *testing.T, err error)
+	}{
+		{
+			uc:     "with nil subject",
+			config: config,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "'nil' subject")
+
+				var identifier interface{ HandlerID() string }
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "tex", identifier.HandlerID())
+			},
+		},
+		{
+			uc:      "without subject token",
+			config:  config,
+			subject: &subject.Subject{ID: "foo"},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, _ *mocks.CacheMock) {
+				t.Helper()
+
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "no subject token present")
+			},
+		},
+		{
+			uc:      "with exchanged token from cache",
+			config:  config,
+			subject: &subject.Subject{ID: "foo"},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, cch *mocks.CacheMock) {
+				t.Helper()
+
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("Bearer subject-token")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+				ctx.EXPECT().AddHeaderForUpstream("Authorization", "Bearer exchanged-token")
+
+				cch.EXPECT().Get(mock.Anything).Return("exchanged-token")
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:      "with error response from the token endpoint",
+			config:  config,
+			subject: &subject.Subject{ID: "foo"},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, cch *mocks.CacheMock) {
+				t.Helper()
+
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("Bearer subject-token")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusBadRequest
+				responseContent = `{"error":"invalid_request"}`
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "token exchange failed")
+
+				var identifier interface{ HandlerID() string }
+				require.True(t, errors.As(err, &identifier))
+				assert.Equal(t, "tex", identifier.HandlerID())
+			},
+		},
+		{
+			uc:      "with response without token",
+			config:  config,
+			subject: &subject.Subject{ID: "foo"},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, cch *mocks.CacheMock) {
+				t.Helper()
+
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("Bearer subject-token")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				responseCode = http.StatusOK
+				responseContent = `{"token_type":"Bearer"}`
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "does not contain a token")
+			},
+		},
+		{
+			uc:      "successful exchange using subject token from request",
+			config:  config,
+			subject: &subject.Subject{ID: "foo"},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, cch *mocks.CacheMock) {
+				t.Helper()
+
+				reqf := heimdallmocks.NewRequestFunctionsMock(t)
+				reqf.EXPECT().Header("Authorization").Return("Bearer subject-token")
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{RequestFunctions: reqf})
+				ctx.EXPECT().AddHeaderForUpstream("Authorization", "Bearer exchanged-token")
+
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+				cch.EXPECT().Set(mock.Anything, "exchanged-token", 300*time.Second-defaultCacheLeeway)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkRequest = func(t *testing.T, req *http.Request) {
+					t.Helper()
+
+					user, password, ok := req.BasicAuth()
+					assert.True(t, ok)
+					assert.Equal(t, "foo", user)
+					assert.Equal(t, "bar", password)
+
+					assert.Equal(t, http.MethodPost, req.Method)
+					assert.NoError(t, req.ParseForm())
+					assert.Equal(t, grantTypeTokenExchange, req.PostForm.Get("grant_type"))
+					assert.Equal(t, "subject-token", req.PostForm.Get("subject_token"))
+					assert.Equal(t, tokenTypeAccessToken, req.PostForm.Get("subject_token_type"))
+					assert.Equal(t, tokenTypeAccessToken, req.PostForm.Get("requested_token_type"))
+					assert.Equal(t, []string{"baz"}, req.PostForm["audience"])
+					assert.Equal(t, "foo bar", req.PostForm.Get("scope"))
+					assert.Empty(t, req.PostForm["resource"])
+				}
+
+				responseCode = http.StatusOK
+				responseContent = `{
+"access_token":"exchanged-token",
+"issued_token_type":"urn:ietf:params:oauth:token-type:access_token",
+"token_type":"Bearer",
+"expires_in":300
+}`
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc: "successful exchange using subject token from subject with custom header and cache ttl",
+			config: []byte(`
+token_url: ` + srv.URL + `
+client_id: foo
+client_secret: bar
+subject_token: "{{ .Subject.Attributes.token }}"
+cache_ttl: 10s
+header:
+  name: X-Token
+`),
+			subject: &subject.Subject{ID: "foo", Attributes: map[string]any{"token": "subject-token"}},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, cch *mocks.CacheMock) {
+				t.Helper()
+
+				ctx.EXPECT().Request().Return(&heimdall.Request{})
+				ctx.EXPECT().AddHeaderForUpstream("X-Token", "exchanged-token")
+
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+				cch.EXPECT().Set(mock.Anything, "exchanged-token", 10*time.Second)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkRequest = func(t *testing.T, req *http.Request) {
+					t.Helper()
+
+					assert.NoError(t, req.ParseForm())
+					assert.Equal(t, "subject-token", req.PostForm.Get("subject_token"))
+					assert.Empty(t, req.PostForm["audience"])
+					assert.Empty(t, req.PostForm["scope"])
+				}
+
+				responseCode = http.StatusOK
+				responseContent = `{"access_token":"exchanged-token","token_type":"N_A","expires_in":300}`
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			endpointCalled = false
+			responseCode = http.StatusOK
+			responseContent = ""
+			checkRequest = func(t *testing.T, _ *http.Request) { t.Helper() }
+
+			instructServer := x.IfThenElse(tc.instructServer != nil,
+				tc.instructServer,
+				func(t *testing.T) { t.Helper() })
+
+			configureMocks := x.IfThenElse(tc.configureMocks != nil,
+				tc.configureMocks,
+				func(t *testing.T, _ *heimdallmocks.ContextMock, _ *mocks.CacheMock) { t.Helper() })
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			cch := mocks.NewCacheMock(t)
+			mctx := heimdallmocks.NewContextMock(t)
+			mctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch))
+
+			instructServer(t)
+			configureMocks(t, mctx, cch)
+
+			unifier, err := newTokenExchangeUnifier("tex", conf)
+			require.NoError(t, err)
+
+			// WHEN
+			err = unifier.Execute(mctx, tc.subject)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestTokenExchangeUnifierCacheKey(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc  string
+		lhs *tokenExchangeUnifier
+		rhs *tokenExchangeUnifier
+	}{
+		{
+			uc:  "swapped audience and resource",
+			lhs: &tokenExchangeUnifier{tokenURL: "https://foo.bar/token", audience: []string{"a"}},
+			rhs: &tokenExchangeUnifier{tokenURL: "https://foo.bar/token", resource: []string{"a"}},
+		},
+		{
+			uc:  "swapped resource and scopes",
+			lhs: &tokenExchangeUnifier{tokenURL: "https://foo.bar/token", resource: []string{"a"}},
+			rhs: &tokenExchangeUnifier{tokenURL: "https://foo.bar/token", scopes: []string{"a"}},
+		},
+		{
+			uc:  "list elements containing the separator",
+			lhs: &tokenExchangeUnifier{tokenURL: "https://foo.bar/token", audience: []string{"a", "b"}},
+			rhs: &tokenExchangeUnifier{tokenURL: "https://foo.bar/token", audience: []string{"a,b"}},
+		},
+		{
+			uc:  "value shifted between token types",
+			lhs: &tokenExchangeUnifier{tokenURL: "https://foo.bar/token", subjectTokenType: "ab"},
+			rhs: &tokenExchangeUnifier{
+				tokenURL: "https://foo.bar/token", subjectTokenType: "a", requestedTokenType: "b",
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			lhsKey := tc.lhs.calculateCacheKey("token")
+			rhsKey := tc.rhs.calculateCacheKey("token")
+
+			// THEN
+			assert.NotEqual(t, lhsKey, rhsKey)
+		})
+	}
+}
@@ -27,4 +27,5 @@ type Unifier interface {
 	Execute(ctx heimdall.Context, sub *subject.Subject) error
 	WithConfig(config map[string]any) (Unifier, error)
 	ContinueOnError() bool
+	HandlerID() string
 }
@@ -26,8 +26,8 @@ import (
 func TestCreateUnifierPrototype(t *testing.T) {
 	t.Parallel()
 
-	// there are 4 unifiers implemented, which should have been registered
-	require.Len(t, typeFactories, 4)
+	// there are 5 unifiers implemented, which should have been registered
+	require.Len(t, typeFactories, 5)
 
 	for _, tc := range []struct {
 		uc     string
@@ -106,6 +106,47 @@ func (_c *SubjectHandlerMock_Execute_Call) RunAndReturn(run func(heimdall.Contex
 	return _c
 }
 
+// HandlerID provides a mock function with given fields:
+func (_m *SubjectHandlerMock) HandlerID() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// SubjectHandlerMock_HandlerID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandlerID'
+type SubjectHandlerMock_HandlerID_Call struct {
+	*mock.Call
+}
+
+// HandlerID is a helper method to define mock.On call
+func (_e *SubjectHandlerMock_Expecter) HandlerID() *SubjectHandlerMock_HandlerID_Call {
+	return &SubjectHandlerMock_HandlerID_Call{Call: _e.mock.On("HandlerID")}
+}
+
+func (_c *SubjectHandlerMock_HandlerID_Call) Run(run func()) *SubjectHandlerMock_HandlerID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *SubjectHandlerMock_HandlerID_Call) Return(_a0 string) *SubjectHandlerMock_HandlerID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SubjectHandlerMock_HandlerID_Call) RunAndReturn(run func() string) *SubjectHandlerMock_HandlerID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 type mockConstructorTestingTNewSubjectHandlerMock interface {
 	mock.TestingT
 	Cleanup(func())
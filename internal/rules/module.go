@@ -22,6 +22,7 @@ import (
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 
+	"github.com/dadrus/heimdall/internal/readiness"
 	"github.com/dadrus/heimdall/internal/rules/event"
 	"github.com/dadrus/heimdall/internal/rules/provider"
 	"github.com/dadrus/heimdall/internal/rules/rule"
@@ -54,7 +55,9 @@ var Module = fx.Options(
 			fx.OnStop(func(ctx context.Context, o *repository) error { return o.Stop(ctx) }),
 		),
 		func(r *repository) rule.Repository { return r },
+		func(r *repository) rule.Inspector { return r },
 		NewRuleSetProcessor,
 	),
+	fx.Invoke(func(registry readiness.Registry, r *repository) { registry.Register(r) }),
 	provider.Module,
 )
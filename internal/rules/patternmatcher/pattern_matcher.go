@@ -18,6 +18,7 @@ package patternmatcher
 
 import (
 	"errors"
+	"strings"
 )
 
 var ErrUnsupportedPatternMatcher = errors.New("unsupported pattern matcher")
@@ -36,3 +37,15 @@ func NewPatternMatcher(typ, pattern string) (PatternMatcher, error) {
 		return nil, ErrUnsupportedPatternMatcher
 	}
 }
+
+// LiteralPrefix returns the part of the given pattern, which precedes the first
+// '<' delimited glob or regex expression. Since everything outside of these
+// delimiters is matched literally by both, the glob and the regex matcher, each
+// value matching the pattern starts with the returned prefix.
+func LiteralPrefix(pattern string) string {
+	if idx := strings.IndexByte(pattern, '<'); idx >= 0 {
+		return pattern[:idx]
+	}
+
+	return pattern
+}
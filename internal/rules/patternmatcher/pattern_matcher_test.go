@@ -0,0 +1,43 @@
+// Copyright 2022 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package patternmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiteralPrefix(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		pattern string
+		prefix  string
+	}{
+		{pattern: "", prefix: ""},
+		{pattern: "<**>", prefix: ""},
+		{pattern: "http://foo.bar/baz", prefix: "http://foo.bar/baz"},
+		{pattern: "http://foo.bar/<**>", prefix: "http://foo.bar/"},
+		{pattern: "http://<*>.bar/baz/<**>", prefix: "http://"},
+		{pattern: "<http|https>://foo.bar/baz", prefix: ""},
+	} {
+		t.Run(tc.pattern, func(t *testing.T) {
+			assert.Equal(t, tc.prefix, LiteralPrefix(tc.pattern))
+		})
+	}
+}
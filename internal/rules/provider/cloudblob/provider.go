@@ -31,6 +31,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
 	rule_config "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
@@ -46,12 +47,14 @@ type provider struct {
 	s          *gocron.Scheduler
 	cancel     context.CancelFunc
 	states     sync.Map
+	syncs      map[string]*readiness.SyncState
 	configured bool
 }
 
 func newProvider(
 	conf *config.Configuration,
 	processor rule.SetProcessor,
+	registry readiness.Registry,
 	logger zerolog.Logger,
 ) (*provider, error) {
 	rawConf := conf.Rules.Providers.CloudBlob
@@ -90,6 +93,7 @@ func newProvider(
 		l:          logger,
 		s:          scheduler,
 		cancel:     cancel,
+		syncs:      make(map[string]*readiness.SyncState, len(providerConf.Buckets)),
 		configured: true,
 	}
 
@@ -99,6 +103,10 @@ func newProvider(
 				"missing url for #%d bucket in cloud_blob rule provider configuration", idx)
 		}
 
+		syncState := readiness.NewSyncState("rule_provider:cloud_blob:" + bucket.ID())
+		registry.Register(syncState)
+		prov.syncs[bucket.ID()] = syncState
+
 		if _, err := x.IfThenElseExec(providerConf.WatchInterval != nil && *providerConf.WatchInterval > 0,
 			func() *gocron.Scheduler { return prov.s.Every(*providerConf.WatchInterval) },
 			func() *gocron.Scheduler { return prov.s.Every(1 * time.Second).LimitRunsTo(1) }).
@@ -155,6 +163,8 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 			Str("_endpoint", rsf.ID()).
 			Msg("Failed to fetch rule set")
 
+		p.syncFailed(rsf.ID(), err)
+
 		if errors.Is(err, heimdall.ErrInternal) || errors.Is(err, heimdall.ErrConfiguration) {
 			return err
 		}
@@ -166,16 +176,38 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 	if len(ruleSets) == 0 && len(state) == 0 {
 		p.l.Debug().Str("_endpoint", rsf.ID()).Msg("No updates received")
 
+		if err == nil {
+			p.syncSucceeded(rsf.ID())
+		}
+
 		return nil
 	}
 
+	fetchErr := err
+
 	if err = p.ruleSetsUpdated(ruleSets, state, rsf.ID()); err != nil {
 		p.l.Warn().Err(err).Str("_endpoint", rsf.ID()).Msg("Failed to apply rule set changes")
+
+		p.syncFailed(rsf.ID(), err)
+	} else if fetchErr == nil {
+		p.syncSucceeded(rsf.ID())
 	}
 
 	return nil
 }
 
+func (p *provider) syncSucceeded(id string) {
+	if state, ok := p.syncs[id]; ok {
+		state.Succeeded()
+	}
+}
+
+func (p *provider) syncFailed(id string, err error) {
+	if state, ok := p.syncs[id]; ok {
+		state.Failed(err)
+	}
+}
+
 func (p *provider) ruleSetsUpdated(ruleSets []*rule_config.RuleSet, state BucketState, buketID string) error {
 	// check which were present in the past and are not present now
 	// and which are new
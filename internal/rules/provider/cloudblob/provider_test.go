@@ -34,6 +34,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
 	"github.com/dadrus/heimdall/internal/x"
@@ -140,7 +141,7 @@ buckets:
 			}
 
 			// WHEN
-			prov, err := newProvider(conf, mocks.NewRuleSetProcessorMock(t), log.Logger)
+			prov, err := newProvider(conf, mocks.NewRuleSetProcessorMock(t), readiness.NewRegistry(), log.Logger)
 
 			// THEN
 			tc.assert(t, err, prov)
@@ -542,7 +543,7 @@ rules:
 			}
 
 			logs := &strings.Builder{}
-			prov, err := newProvider(conf, mock, zerolog.New(logs))
+			prov, err := newProvider(conf, mock, readiness.NewRegistry(), zerolog.New(logs))
 			require.NoError(t, err)
 
 			ctx := context.Background()
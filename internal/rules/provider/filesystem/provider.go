@@ -33,6 +33,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
@@ -44,11 +45,17 @@ type Provider struct {
 	p              rule.SetProcessor
 	l              zerolog.Logger
 	states         sync.Map
+	sync           *readiness.SyncState
 	envVarsEnabled bool
 	configured     bool
 }
 
-func NewProvider(conf *config.Configuration, processor rule.SetProcessor, logger zerolog.Logger) (*Provider, error) {
+func NewProvider(
+	conf *config.Configuration,
+	processor rule.SetProcessor,
+	registry readiness.Registry,
+	logger zerolog.Logger,
+) (*Provider, error) {
 	rawConf := conf.Rules.Providers.FileSystem
 
 	if conf.Rules.Providers.FileSystem == nil {
@@ -101,11 +108,15 @@ func NewProvider(conf *config.Configuration, processor rule.SetProcessor, logger
 	logger = logger.With().Str("_provider_type", "file_system").Logger()
 	logger.Info().Msg("Rule provider configured.")
 
+	syncState := readiness.NewSyncState("rule_provider:file_system")
+	registry.Register(syncState)
+
 	return &Provider{
 		src:            absPath,
 		w:              watcher,
 		p:              processor,
 		l:              logger,
+		sync:           syncState,
 		configured:     true,
 		envVarsEnabled: providerConf.EnvVarsEnabled,
 	}, nil
@@ -124,6 +135,8 @@ func (p *Provider) Start(_ context.Context) error {
 		return err
 	}
 
+	p.sync.Succeeded()
+
 	if p.w == nil {
 		p.l.Warn().
 			Msg("Watcher for file_system provider is not configured. Updates to rules will have no effects.")
@@ -170,6 +183,9 @@ func (p *Provider) watchFiles() {
 
 			if err := p.ruleSetsChanged(evt); err != nil {
 				p.l.Warn().Err(err).Str("_src", evt.Name).Msg("Failed to apply rule set changes")
+				p.sync.Failed(err)
+			} else {
+				p.sync.Succeeded()
 			}
 		case err, ok := <-p.w.Errors:
 			if !ok {
@@ -30,6 +30,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
 	"github.com/dadrus/heimdall/internal/x"
@@ -139,7 +140,7 @@ func TestNewProvider(t *testing.T) {
 			// GIVEN
 			conf := &config.Configuration{Rules: config.Rules{Providers: config.RuleProviders{FileSystem: tc.conf}}}
 
-			prov, err := NewProvider(conf, nil, log.Logger)
+			prov, err := NewProvider(conf, nil, readiness.NewRegistry(), log.Logger)
 
 			tc.assert(t, err, prov)
 		})
@@ -167,6 +168,7 @@ func TestProviderLifecycle(t *testing.T) {
 
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), "no such file")
+				assert.False(t, provider.sync.Status(context.Background()).Ready)
 			},
 		},
 		{
@@ -224,6 +226,7 @@ rules:
 				assert.Equal(t, "1", ruleSet.Version)
 				assert.Len(t, ruleSet.Rules, 1)
 				assert.Equal(t, "foo", ruleSet.Rules[0].ID)
+				assert.True(t, provider.sync.Status(context.Background()).Ready)
 			},
 		},
 		{
@@ -486,6 +489,7 @@ rules:
 				p:          processor,
 				l:          log.Logger,
 				w:          watcher,
+				sync:       readiness.NewSyncState("test"),
 				configured: true,
 			}
 
@@ -30,6 +30,7 @@ import (
 	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
@@ -42,6 +43,7 @@ type provider struct {
 	s          *gocron.Scheduler
 	cancel     context.CancelFunc
 	states     sync.Map
+	syncs      map[string]*readiness.SyncState
 	configured bool
 }
 
@@ -49,6 +51,7 @@ func newProvider(
 	conf *config.Configuration,
 	cch cache.Cache,
 	processor rule.SetProcessor,
+	registry readiness.Registry,
 	logger zerolog.Logger,
 ) (*provider, error) {
 	rawConf := conf.Rules.Providers.HTTPEndpoint
@@ -96,10 +99,15 @@ func newProvider(
 		l:          logger,
 		s:          scheduler,
 		cancel:     cancel,
+		syncs:      make(map[string]*readiness.SyncState, len(providerConf.Endpoints)),
 		configured: true,
 	}
 
 	for idx, ep := range providerConf.Endpoints {
+		syncState := readiness.NewSyncState("rule_provider:http_endpoint:" + ep.ID())
+		registry.Register(syncState)
+		prov.syncs[ep.ID()] = syncState
+
 		if _, err := x.IfThenElseExec(providerConf.WatchInterval != nil && *providerConf.WatchInterval > 0,
 			func() *gocron.Scheduler { return prov.s.Every(*providerConf.WatchInterval) },
 			func() *gocron.Scheduler { return prov.s.Every(1 * time.Second).LimitRunsTo(1) },
@@ -146,6 +154,9 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 		Msg("Retrieving rule set")
 
 	ruleSet, err := rsf.FetchRuleSet(ctx)
+	// an empty rule set is a valid answer of the endpoint
+	synced := err == nil || errors.Is(err, config2.ErrEmptyRuleSet)
+
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			p.l.Debug().Msg("Watcher closed")
@@ -157,6 +168,10 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 			Str("_endpoint", rsf.ID()).
 			Msg("Failed to fetch rule set")
 
+		if !synced {
+			p.syncFailed(rsf.ID(), err)
+		}
+
 		if !errors.Is(err, config2.ErrEmptyRuleSet) &&
 			(errors.Is(err, heimdall.ErrInternal) || errors.Is(err, heimdall.ErrConfiguration)) {
 			return err
@@ -174,11 +189,27 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 		p.l.Warn().Err(err).
 			Str("_src", rsf.ID()).
 			Msg("Failed to apply rule set changes")
+
+		p.syncFailed(rsf.ID(), err)
+	} else if synced {
+		p.syncSucceeded(rsf.ID())
 	}
 
 	return nil
 }
 
+func (p *provider) syncSucceeded(id string) {
+	if state, ok := p.syncs[id]; ok {
+		state.Succeeded()
+	}
+}
+
+func (p *provider) syncFailed(id string, err error) {
+	if state, ok := p.syncs[id]; ok {
+		state.Failed(err)
+	}
+}
+
 func (p *provider) ruleSetsUpdated(ruleSet *config2.RuleSet, stateID string) error {
 	var hash []byte
 
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/zerologr"
@@ -33,6 +34,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/provider/kubernetes/api/v1alpha2"
 	"github.com/dadrus/heimdall/internal/rules/rule"
@@ -50,12 +52,15 @@ type provider struct {
 	configured bool
 	wg         sync.WaitGroup
 	ac         string
+	sync       *readiness.SyncState
+	synced     atomic.Bool
 }
 
 func newProvider(
 	conf *config.Configuration,
 	k8sCF ConfigFactory,
 	processor rule.SetProcessor,
+	registry readiness.Registry,
 	logger zerolog.Logger,
 ) (*provider, error) {
 	rawConf := conf.Rules.Providers.Kubernetes
@@ -93,12 +98,16 @@ func newProvider(
 
 	logger.Info().Msg("Rule provider configured.")
 
+	syncState := readiness.NewSyncState("rule_provider:" + ProviderType)
+	registry.Register(syncState)
+
 	return &provider{
 		p:          processor,
 		l:          logger,
 		cl:         client,
 		ac:         x.IfThenElse(len(providerConf.AuthClass) != 0, providerConf.AuthClass, DefaultClass),
 		configured: true,
+		sync:       syncState,
 	}, nil
 }
 
@@ -136,13 +145,23 @@ func (p *provider) Start(_ context.Context) error {
 	// canceled
 	controller := p.newController(ctx, "") //nolint:contextcheck
 
-	p.wg.Add(1)
+	p.wg.Add(2) //nolint:gomnd
 
 	go func() {
 		controller.Run(ctx.Done())
 		p.wg.Done()
 	}()
 
+	go func() {
+		// the provider becomes ready as soon as the initially listed rule sets have been processed
+		if cache.WaitForCacheSync(ctx.Done(), controller.HasSynced) {
+			p.synced.Store(true)
+			p.sync.Succeeded()
+		}
+
+		p.wg.Done()
+	}()
+
 	return nil
 }
 
@@ -199,7 +218,10 @@ func (p *provider) updateRuleSet(_, newObj any) {
 	p.l.Debug().Str("_src", conf.Source).
 		Msgf("Rule set resource version mapped from '%s' to '%s'", rs.APIVersion, conf.Version)
 
-	if err := p.p.OnUpdated(conf); err != nil {
+	err := p.p.OnUpdated(conf)
+	p.syncResult(err)
+
+	if err != nil {
 		p.l.Warn().Err(err).Str("_src", conf.Source).Msg("Failed to apply rule set updates")
 	} else {
 		p.l.Info().Str("_src", conf.Source).Msg("Rule set updated")
@@ -233,7 +255,10 @@ func (p *provider) addRuleSet(obj any) {
 	p.l.Debug().Str("_src", conf.Source).
 		Msgf("Rule set resource version mapped from '%s' to '%s'", rs.APIVersion, conf.Version)
 
-	if err := p.p.OnCreated(conf); err != nil {
+	err := p.p.OnCreated(conf)
+	p.syncResult(err)
+
+	if err != nil {
 		p.l.Warn().Err(err).Str("_src", conf.Source).Msg("Failed creating rule set")
 	} else {
 		p.l.Info().Str("_src", conf.Source).Msg("Rule set created")
@@ -266,13 +291,26 @@ func (p *provider) deleteRuleSet(obj any) {
 	p.l.Debug().Str("_src", conf.Source).
 		Msgf("Rule set resource version mapped from '%s' to '%s'", rs.APIVersion, conf.Version)
 
-	if err := p.p.OnDeleted(conf); err != nil {
+	err := p.p.OnDeleted(conf)
+	p.syncResult(err)
+
+	if err != nil {
 		p.l.Warn().Err(err).Str("_src", conf.Source).Msg("Failed deleting rule set")
 	} else {
 		p.l.Info().Str("_src", conf.Source).Msg("Rule set deleted")
 	}
 }
 
+func (p *provider) syncResult(err error) {
+	switch {
+	case err != nil:
+		p.sync.Failed(err)
+	case p.synced.Load():
+		// before the initial sync, readiness is handled in Start
+		p.sync.Succeeded()
+	}
+}
+
 func (p *provider) mapVersion(_ string) string {
 	// currently the only possible version is v1alpha2, which is mapped to the version "1alpha2" used internally
 	return "1alpha2"
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
 	"github.com/dadrus/heimdall/internal/rules/event"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
@@ -26,6 +30,8 @@ func newRepository(
 			func() rule.Rule { return ruleFactory.DefaultRule() },
 			func() rule.Rule { return nil }),
 		logger: logger,
+		names:  make(map[string]string),
+		index:  newRuleIndex(nil),
 		queue:  queue,
 		quit:   make(chan bool),
 	}
@@ -36,28 +42,117 @@ type repository struct {
 	logger zerolog.Logger
 
 	rules []rule.Rule
+	names map[string]string
+	index *ruleIndex
 	mutex sync.RWMutex
+	// loaded is set as soon as the first rule set has been applied
+	loaded bool
+	// applying is set while a rule set change taken from the queue is being applied
+	applying atomic.Bool
 
 	queue event.RuleSetChangedEventQueue
 	quit  chan bool
 }
 
-func (r *repository) FindRule(requestURL *url.URL) (rule.Rule, error) {
+func (r *repository) FindRule(method string, requestURL *url.URL) (rule.Rule, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	for _, rul := range r.rules {
-		if rul.MatchesURL(requestURL) {
+	var urlMatched rule.Rule
+
+	for _, rul := range r.index.candidates(urlToMatch(requestURL)) {
+		if !rul.MatchesURL(requestURL) {
+			continue
+		}
+
+		if rul.MatchesMethod(method) {
 			return rul, nil
 		}
+
+		if urlMatched == nil {
+			urlMatched = rul
+		}
+	}
+
+	if urlMatched != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrMethodNotAllowed,
+			"rule (id=%s, src=%s) matching %s does not accept %s method",
+			urlMatched.ID(), urlMatched.SrcID(), requestURL.String(), method)
+	}
+
+	if r.dr == nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrNoRuleFound,
+			"no applicable rule found for %s", requestURL.String())
+	}
+
+	if !r.dr.MatchesMethod(method) {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrMethodNotAllowed,
+			"default rule doesn't match %s method", method)
+	}
+
+	return r.dr, nil
+}
+
+func (r *repository) RuleSets() []rule.SetInfo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	sets := make(map[string]*rule.SetInfo, len(r.names))
+
+	for srcID, name := range r.names {
+		sets[srcID] = &rule.SetInfo{Source: srcID, Name: name, Rules: []rule.Info{}}
+	}
+
+	for _, rul := range r.rules {
+		set, ok := sets[rul.SrcID()]
+		if !ok {
+			set = &rule.SetInfo{Source: rul.SrcID(), Rules: []rule.Info{}}
+			sets[rul.SrcID()] = set
+		}
+
+		set.Rules = append(set.Rules, ruleInfo(rul))
 	}
 
-	if r.dr != nil {
-		return r.dr, nil
+	result := make([]rule.SetInfo, 0, len(sets))
+	for _, set := range sets {
+		result = append(result, *set)
 	}
 
-	return nil, errorchain.NewWithMessagef(heimdall.ErrNoRuleFound,
-		"no applicable rule found for %s", requestURL.String())
+	slices.SortFunc(result, func(lhs, rhs rule.SetInfo) int { return strings.Compare(lhs.Source, rhs.Source) })
+
+	return result
+}
+
+func (r *repository) DefaultRule() (rule.Info, bool) {
+	if r.dr == nil {
+		return rule.Info{}, false
+	}
+
+	return ruleInfo(r.dr), true
+}
+
+func (r *repository) Describe(rul rule.Rule) rule.Info { return ruleInfo(rul) }
+
+func (r *repository) Name() string { return "rules" }
+
+// Status reports the repository as ready, as soon as the first rule set has been loaded, or
+// if there is nothing to load, like if only the default rule is configured, or the sources
+// of the rule providers are empty. Whether the rule providers completed their initial
+// synchronization is reported by the providers themselves. Subsequent rule set changes do
+// not affect the readiness.
+func (r *repository) Status(_ context.Context) readiness.Status {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return readiness.Status{
+		Ready: r.loaded || (len(r.queue) == 0 && !r.applying.Load()),
+		Details: map[string]any{
+			"rule_sets":       len(r.names),
+			"rules":           len(r.rules),
+			"default_rule":    r.dr != nil,
+			"pending_changes": len(r.queue),
+		},
+	}
 }
 
 func (r *repository) Start(_ context.Context) error {
@@ -86,14 +181,20 @@ func (r *repository) watchRuleSetChanges() {
 				r.logger.Debug().Msg("Rule set definition queue closed")
 			}
 
+			r.applying.Store(true)
+
 			switch evt.ChangeType {
 			case event.Create:
+				r.setRuleSetName(evt.Source, evt.Name)
 				r.addRuleSet(evt.Source, evt.Rules)
 			case event.Update:
+				r.setRuleSetName(evt.Source, evt.Name)
 				r.updateRuleSet(evt.Source, evt.Rules)
 			case event.Remove:
 				r.deleteRuleSet(evt.Source)
 			}
+
+			r.applying.Store(false)
 		case <-r.quit:
 			r.logger.Info().Msg("Rule definition loader stopped")
 
@@ -102,6 +203,13 @@ func (r *repository) watchRuleSetChanges() {
 	}
 }
 
+func (r *repository) setRuleSetName(srcID, name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.names[srcID] = name
+}
+
 func (r *repository) addRuleSet(srcID string, rules []rule.Rule) {
 	// create rules
 	r.logger.Info().Str("_src", srcID).Msg("Adding rule set")
@@ -109,8 +217,14 @@ func (r *repository) addRuleSet(srcID string, rules []rule.Rule) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	r.logCollisions(srcID, rules)
+
 	// add them
 	r.addRules(rules)
+
+	r.reindex()
+
+	r.loaded = true
 }
 
 func (r *repository) updateRuleSet(srcID string, rules []rule.Rule) {
@@ -177,6 +291,8 @@ func (r *repository) updateRuleSet(srcID string, rules []rule.Rule) {
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
 
+		r.logCollisions(srcID, rules)
+
 		// remove deleted rules
 		r.removeRules(deletedRules)
 
@@ -185,6 +301,10 @@ func (r *repository) updateRuleSet(srcID string, rules []rule.Rule) {
 
 		// add new rules
 		r.addRules(newRules)
+
+		r.reindex()
+
+		r.loaded = true
 	}()
 }
 
@@ -199,6 +319,10 @@ func (r *repository) deleteRuleSet(srcID string) {
 
 	// remove them
 	r.removeRules(applicable)
+
+	delete(r.names, srcID)
+
+	r.reindex()
 }
 
 func (r *repository) addRules(rules []rule.Rule) {
@@ -210,46 +334,29 @@ func (r *repository) addRules(rules []rule.Rule) {
 }
 
 func (r *repository) removeRules(rules []rule.Rule) {
-	// find all indexes for affected rules
-	var idxs []int
-
-	for idx, rul := range r.rules {
-		for _, tbd := range rules {
-			if rul.ID() == tbd.ID() {
-				idxs = append(idxs, idx)
-
-				r.logger.Debug().Str("_src", rul.SrcID()).Str("_id", rul.ID()).Msg("Rule removed")
-			}
+	// the rules are sorted by compareRules, so rules of different rule sets are interleaved.
+	// A stable filter is used to keep the order of the remaining rules.
+	remaining := slices.DeleteFunc(r.rules, func(rul rule.Rule) bool {
+		remove := slices.ContainsFunc(rules, func(tbd rule.Rule) bool { return sameRule(rul, tbd) })
+		if remove {
+			r.logger.Debug().Str("_src", rul.SrcID()).Str("_id", rul.ID()).Msg("Rule removed")
 		}
-	}
 
-	// if all rules should be dropped, just create a new slice
-	if len(idxs) == len(r.rules) {
-		r.rules = nil
-
-		return
-	}
-
-	// move the elements from the end of the rules slice to the found positions
-	// and set the corresponding "emptied" values to nil
-	for i, idx := range idxs {
-		tailIdx := len(r.rules) - (1 + i)
-
-		r.rules[idx] = r.rules[tailIdx]
+		return remove
+	})
 
-		// the below re-slice preserves the capacity of the slice.
-		// this is required to avoid memory leaks
-		r.rules[tailIdx] = nil
+	// set the freed tail to nil to avoid memory leaks, as the re-slice preserves the capacity
+	for idx := len(remaining); idx < len(r.rules); idx++ {
+		r.rules[idx] = nil
 	}
 
-	// re-slice
-	r.rules = r.rules[:len(r.rules)-len(idxs)]
+	r.rules = remaining
 }
 
 func (r *repository) replaceRules(rules []rule.Rule) {
 	for _, updated := range rules {
 		for idx, existing := range r.rules {
-			if existing.ID() == updated.ID() {
+			if sameRule(existing, updated) {
 				r.rules[idx] = updated
 
 				r.logger.Debug().
@@ -262,3 +369,95 @@ func (r *repository) replaceRules(rules []rule.Rule) {
 		}
 	}
 }
+
+// reindex brings the rules into the order defined by compareRules and rebuilds the
+// index. This way the rule used for a request does not depend on the order the
+// rule sets have been loaded in.
+func (r *repository) reindex() {
+	slices.SortFunc(r.rules, compareRules)
+
+	r.index = newRuleIndex(r.rules)
+}
+
+// logCollisions warns about rules from the given rule set, which have the same url
+// pattern and priority as rules from other rule sets and accept at least one
+// common method. For such requests only the rule ordered first is used.
+func (r *repository) logCollisions(srcID string, rules []rule.Rule) {
+	for _, rul := range rules {
+		for _, existing := range r.rules {
+			if existing.SrcID() == srcID || !collide(rul, existing) {
+				continue
+			}
+
+			used, shadowed := rul, existing
+			if compareRules(existing, rul) < 0 {
+				used, shadowed = existing, rul
+			}
+
+			r.logger.Warn().
+				Str("_src", shadowed.SrcID()).
+				Str("_id", shadowed.ID()).
+				Str("_used_src", used.SrcID()).
+				Str("_used_id", used.ID()).
+				Msg("Rule collides with a rule from another rule set and is shadowed by it")
+		}
+	}
+}
+
+// compareRules orders rules by their priority (higher first), the length of the
+// literal prefix of their url pattern (longer, thus more specific, first), and
+// finally by their source and rule ids.
+func compareRules(lhs, rhs rule.Rule) int {
+	if lp, rp := priorityOf(lhs), priorityOf(rhs); lp != rp {
+		return rp - lp
+	}
+
+	if ll, rl := len(indexKey(lhs)), len(indexKey(rhs)); ll != rl {
+		return rl - ll
+	}
+
+	if res := strings.Compare(lhs.SrcID(), rhs.SrcID()); res != 0 {
+		return res
+	}
+
+	return strings.Compare(lhs.ID(), rhs.ID())
+}
+
+// sameRule reports whether both rules have the same identity. Rule ids are only
+// unique within a rule set, so the source of the rule set is part of the identity.
+func sameRule(lhs, rhs rule.Rule) bool {
+	return lhs.SrcID() == rhs.SrcID() && lhs.ID() == rhs.ID()
+}
+
+func ruleInfo(rul rule.Rule) rule.Info {
+	if impl, ok := rul.(*ruleImpl); ok {
+		return impl.info()
+	}
+
+	return rule.Info{ID: rul.ID(), SrcID: rul.SrcID()}
+}
+
+func priorityOf(rul rule.Rule) int {
+	if impl, ok := rul.(*ruleImpl); ok {
+		return impl.priority
+	}
+
+	return 0
+}
+
+func collide(lhs, rhs rule.Rule) bool {
+	left, lok := lhs.(*ruleImpl)
+	right, rok := rhs.(*ruleImpl)
+
+	if !lok || !rok || left.urlPattern != right.urlPattern || left.priority != right.priority {
+		return false
+	}
+
+	for _, method := range left.methods {
+		if right.MatchesMethod(method) {
+			return true
+		}
+	}
+
+	return false
+}
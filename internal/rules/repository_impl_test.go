@@ -18,7 +18,11 @@ package rules
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,11 +32,14 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
 	"github.com/dadrus/heimdall/internal/rules/event"
+	rulemocks "github.com/dadrus/heimdall/internal/rules/mocks"
 	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
 	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
 func TestRepositoryAddAndRemoveRulesFromSameRuleSet(t *testing.T) {
@@ -64,6 +71,7 @@ func TestRepositoryFindRule(t *testing.T) {
 
 	for _, tc := range []struct {
 		uc               string
+		method           string
 		requestURL       *url.URL
 		addRules         func(t *testing.T, repo *repository)
 		configureFactory func(t *testing.T, factory *mocks.FactoryMock)
@@ -71,6 +79,7 @@ func TestRepositoryFindRule(t *testing.T) {
 	}{
 		{
 			uc:         "no matching rule without default rule",
+			method:     http.MethodGet,
 			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
@@ -86,22 +95,45 @@ func TestRepositoryFindRule(t *testing.T) {
 		},
 		{
 			uc:         "no matching rule with default rule",
+			method:     http.MethodGet,
 			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
 
 				factory.EXPECT().HasDefaultRule().Return(true)
-				factory.EXPECT().DefaultRule().Return(&ruleImpl{id: "test", isDefault: true})
+				factory.EXPECT().DefaultRule().Return(&ruleImpl{
+					id: "test", isDefault: true, methods: []string{http.MethodGet},
+				})
 			},
 			assert: func(t *testing.T, err error, rul rule.Rule) {
 				t.Helper()
 
 				require.NoError(t, err)
-				require.Equal(t, &ruleImpl{id: "test", isDefault: true}, rul)
+				require.Equal(t, &ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodGet}}, rul)
+			},
+		},
+		{
+			uc:         "no matching rule with default rule not accepting the method",
+			method:     http.MethodPost,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(true)
+				factory.EXPECT().DefaultRule().Return(&ruleImpl{
+					id: "test", isDefault: true, methods: []string{http.MethodGet},
+				})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrMethodNotAllowed)
 			},
 		},
 		{
 			uc:         "matching rule",
+			method:     http.MethodGet,
 			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
@@ -111,28 +143,12 @@ func TestRepositoryFindRule(t *testing.T) {
 			addRules: func(t *testing.T, repo *repository) {
 				t.Helper()
 
-				repo.rules = append(repo.rules,
-					&ruleImpl{
-						id:    "test1",
-						srcID: "bar",
-						urlMatcher: func() patternmatcher.PatternMatcher {
-							matcher, _ := patternmatcher.NewPatternMatcher("glob",
-								"http://heimdall.test.local/baz")
-
-							return matcher
-						}(),
-					},
-					&ruleImpl{
-						id:    "test2",
-						srcID: "baz",
-						urlMatcher: func() patternmatcher.PatternMatcher {
-							matcher, _ := patternmatcher.NewPatternMatcher("glob",
-								"http://foo.bar/baz")
-
-							return matcher
-						}(),
-					},
-				)
+				repo.addRuleSet("bar", []rule.Rule{
+					newTestRule(t, "test1", "bar", "http://heimdall.test.local/baz", http.MethodGet),
+				})
+				repo.addRuleSet("baz", []rule.Rule{
+					newTestRule(t, "test2", "baz", "http://foo.bar/baz", http.MethodGet),
+				})
 			},
 			assert: func(t *testing.T, err error, rul rule.Rule) {
 				t.Helper()
@@ -146,6 +162,142 @@ func TestRepositoryFindRule(t *testing.T) {
 				require.Equal(t, "baz", impl.srcID)
 			},
 		},
+		{
+			uc:         "rules sharing the url pattern, but accepting different methods",
+			method:     http.MethodPost,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/baz"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(false)
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				repo.addRuleSet("bar", []rule.Rule{
+					newTestRule(t, "test1", "bar", "http://foo.bar/api/<**>", http.MethodGet, http.MethodHead),
+					newTestRule(t, "test2", "bar", "http://foo.bar/api/<**>", http.MethodPost),
+				})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				impl, ok := rul.(*ruleImpl)
+				require.True(t, ok)
+
+				require.Equal(t, "test2", impl.id)
+			},
+		},
+		{
+			uc:         "rules matching the url, but not accepting the method",
+			method:     http.MethodDelete,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/baz"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(true)
+				factory.EXPECT().DefaultRule().Return(&ruleImpl{
+					id: "test", isDefault: true, methods: []string{http.MethodDelete},
+				})
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				repo.addRuleSet("bar", []rule.Rule{
+					newTestRule(t, "test1", "bar", "http://foo.bar/api/<**>", http.MethodGet),
+					newTestRule(t, "test2", "bar", "http://foo.bar/api/<**>", http.MethodPost),
+				})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, heimdall.ErrMethodNotAllowed)
+				assert.Contains(t, err.Error(), "src=bar")
+				assert.Contains(t, err.Error(), "matching http://foo.bar/api/baz does not accept DELETE method")
+			},
+		},
+		{
+			uc:         "rule with higher priority is used regardless of load order",
+			method:     http.MethodGet,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/baz"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(false)
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				specific := newTestRule(t, "test1", "bar", "http://foo.bar/api/baz", http.MethodGet)
+				prioritized := newTestRule(t, "test2", "baz", "http://foo.bar/<**>", http.MethodGet)
+				prioritized.priority = 10
+
+				repo.addRuleSet("bar", []rule.Rule{specific})
+				repo.addRuleSet("baz", []rule.Rule{prioritized})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "test2", rul.ID())
+			},
+		},
+		{
+			uc:         "more specific rule is used regardless of load order",
+			method:     http.MethodGet,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/baz"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(false)
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				repo.addRuleSet("bar", []rule.Rule{
+					newTestRule(t, "test1", "bar", "http://foo.bar/<**>", http.MethodGet),
+				})
+				repo.addRuleSet("baz", []rule.Rule{
+					newTestRule(t, "test2", "baz", "http://foo.bar/api/<**>", http.MethodGet),
+				})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "test2", rul.ID())
+			},
+		},
+		{
+			uc:         "colliding rules are ordered by their source",
+			method:     http.MethodGet,
+			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/baz"},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(false)
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				repo.addRuleSet("foo", []rule.Rule{
+					newTestRule(t, "test1", "foo", "http://foo.bar/api/<**>", http.MethodGet),
+				})
+				repo.addRuleSet("bar", []rule.Rule{
+					newTestRule(t, "test2", "bar", "http://foo.bar/api/<**>", http.MethodGet),
+				})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "test2", rul.ID())
+				assert.Equal(t, "bar", rul.SrcID())
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -161,7 +313,7 @@ func TestRepositoryFindRule(t *testing.T) {
 			addRules(t, repo)
 
 			// WHEN
-			rul, err := repo.FindRule(tc.requestURL)
+			rul, err := repo.FindRule(tc.method, tc.requestURL)
 
 			// THEN
 			tc.assert(t, err, rul)
@@ -169,6 +321,132 @@ func TestRepositoryFindRule(t *testing.T) {
 	}
 }
 
+func TestRepositoryLogsCollidingRules(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	tb := &testsupport.TestingLog{TB: t}
+	logger := zerolog.New(zerolog.TestWriter{T: tb})
+
+	repo := newRepository(nil, &ruleFactory{}, logger)
+
+	repo.addRuleSet("foo", []rule.Rule{
+		newTestRule(t, "test1", "foo", "http://foo.bar/api/<**>", http.MethodGet, http.MethodPost),
+		newTestRule(t, "test2", "foo", "http://foo.bar/<**>", http.MethodGet),
+	})
+
+	// WHEN
+	repo.addRuleSet("bar", []rule.Rule{
+		// same pattern, overlapping methods
+		newTestRule(t, "test1", "bar", "http://foo.bar/api/<**>", http.MethodPost),
+		// same pattern, but different methods
+		newTestRule(t, "test2", "bar", "http://foo.bar/<**>", http.MethodDelete),
+		// different pattern
+		newTestRule(t, "test3", "bar", "http://foo.bar/api/v1/<**>", http.MethodGet),
+	})
+
+	// THEN
+	logs := tb.CollectedLog()
+	assert.Equal(t, 1, strings.Count(logs, "collides with a rule from another rule set"))
+	assert.Contains(t, logs, `"_src":"foo"`)
+	assert.Contains(t, logs, `"_used_src":"bar"`)
+}
+
+func TestRepositoryRuleSetsAndDefaultRuleInspection(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	queue := make(event.RuleSetChangedEventQueue, 10)
+	defer close(queue)
+
+	authenticator := rulemocks.NewSubjectCreatorMock(t)
+	authenticator.EXPECT().HandlerID().Return("anon")
+
+	unifier := rulemocks.NewSubjectHandlerMock(t)
+	unifier.EXPECT().HandlerID().Return("noop")
+
+	errorHandler := rulemocks.NewErrorHandlerMock(t)
+	errorHandler.EXPECT().HandlerID().Return("default")
+
+	factory := mocks.NewFactoryMock(t)
+	factory.EXPECT().HasDefaultRule().Return(true)
+	factory.EXPECT().DefaultRule().Return(&ruleImpl{
+		id:        "default",
+		srcID:     "config",
+		isDefault: true,
+		methods:   []string{http.MethodGet},
+		sc:        compositeSubjectCreator{authenticator},
+		un:        compositeSubjectHandler{&conditionalSubjectHandler{h: unifier, typ: "unifier"}},
+		eh:        compositeErrorHandler{errorHandler},
+	})
+
+	repo := newRepository(queue, factory, log.Logger)
+
+	ctx := context.Background()
+	require.NoError(t, repo.Start(ctx))
+
+	defer repo.Stop(ctx)
+
+	rul := newTestRule(t, "rule:foo", "test1", "http://foo.bar/<**>", http.MethodGet)
+	rul.urlStrategy = "glob"
+	rul.hash = []byte{1, 2}
+
+	// WHEN
+	queue <- event.RuleSetChanged{Source: "test1", Name: "foo", ChangeType: event.Create, Rules: []rule.Rule{rul}}
+	queue <- event.RuleSetChanged{Source: "test2", Name: "bar", ChangeType: event.Create}
+
+	time.Sleep(100 * time.Millisecond)
+
+	sets := repo.RuleSets()
+	defRule, ok := repo.DefaultRule()
+
+	// THEN
+	require.Len(t, sets, 2)
+	assert.Equal(t, rule.SetInfo{
+		Source: "test1",
+		Name:   "foo",
+		Rules: []rule.Info{
+			{
+				ID:      "rule:foo",
+				SrcID:   "test1",
+				Match:   rule.MatchInfo{URL: "http://foo.bar/<**>", Strategy: "glob"},
+				Methods: []string{http.MethodGet},
+				Execute: []rule.MechanismInfo{},
+				OnError: []rule.MechanismInfo{},
+				Hash:    "0102",
+			},
+		},
+	}, sets[0])
+	assert.Equal(t, rule.SetInfo{Source: "test2", Name: "bar", Rules: []rule.Info{}}, sets[1])
+
+	require.True(t, ok)
+	assert.Equal(t, rule.Info{
+		ID:      "default",
+		SrcID:   "config",
+		Methods: []string{http.MethodGet},
+		Execute: []rule.MechanismInfo{
+			{Type: "authenticator", ID: "anon"},
+			{Type: "unifier", ID: "noop"},
+		},
+		OnError: []rule.MechanismInfo{{Type: "error_handler", ID: "default"}},
+	}, defRule)
+}
+
+func newTestRule(t *testing.T, id, srcID, pattern string, methods ...string) *ruleImpl {
+	t.Helper()
+
+	matcher, err := patternmatcher.NewPatternMatcher("glob", pattern)
+	require.NoError(t, err)
+
+	return &ruleImpl{
+		id:         id,
+		srcID:      srcID,
+		urlPattern: pattern,
+		urlMatcher: matcher,
+		methods:    methods,
+	}
+}
+
 func TestRepositoryAddAndRemoveRulesFromDifferentRuleSets(t *testing.T) {
 	t.Parallel()
 
@@ -214,6 +492,98 @@ func TestRepositoryAddAndRemoveRulesFromDifferentRuleSets(t *testing.T) {
 	assert.Len(t, repo.rules, 0)
 }
 
+func TestRepositoryRemoveRulesInterleavedWithOtherRuleSets(t *testing.T) {
+	t.Parallel()
+
+	ruleIDs := func(rules []rule.Rule) []string {
+		ids := make([]string, len(rules))
+		for idx, rul := range rules {
+			ids[idx] = rul.SrcID() + "/" + rul.ID()
+		}
+
+		return ids
+	}
+
+	for _, tc := range []struct {
+		uc       string
+		modify   func(t *testing.T, repo *repository)
+		expected []string
+	}{
+		{
+			uc: "delete rule set",
+			modify: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				repo.deleteRuleSet("a")
+			},
+			expected: []string{"b/2", "b/3"},
+		},
+		{
+			uc: "update rule set",
+			modify: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				repo.updateRuleSet("a", []rule.Rule{newTestRule(t, "1", "a", "http://x/aaaaaaaa/<**>")})
+			},
+			expected: []string{"a/1", "b/2", "b/3"},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			repo := newRepository(nil, &ruleFactory{}, log.Logger)
+
+			repo.addRuleSet("a", []rule.Rule{
+				newTestRule(t, "1", "a", "http://x/aaaaaaaa/<**>"),
+				newTestRule(t, "4", "a", "<**>"),
+			})
+			repo.addRuleSet("b", []rule.Rule{
+				newTestRule(t, "2", "b", "http://x/aaa/<**>"),
+				newTestRule(t, "3", "b", "http://x/<**>"),
+			})
+
+			// the rules of both rule sets are interleaved after sorting
+			require.Equal(t, []string{"a/1", "b/2", "b/3", "a/4"}, ruleIDs(repo.rules))
+
+			// WHEN
+			tc.modify(t, repo)
+
+			// THEN
+			assert.Equal(t, tc.expected, ruleIDs(repo.rules))
+
+			rul, err := repo.FindRule(http.MethodGet, &url.URL{Scheme: "http", Host: "y", Path: "/foo"})
+			require.ErrorIs(t, err, heimdall.ErrNoRuleFound)
+			assert.Nil(t, rul)
+		})
+	}
+}
+
+func TestRepositoryRulesWithSameIDFromDifferentRuleSets(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newRepository(nil, &ruleFactory{}, log.Logger)
+
+	repo.addRuleSet("foo", []rule.Rule{&ruleImpl{id: "1", srcID: "foo", hash: []byte{1}}})
+	repo.addRuleSet("bar", []rule.Rule{&ruleImpl{id: "1", srcID: "bar", hash: []byte{1}}})
+
+	// WHEN
+	repo.updateRuleSet("bar", []rule.Rule{&ruleImpl{id: "1", srcID: "bar", hash: []byte{2}}})
+
+	// THEN
+	require.Len(t, repo.rules, 2)
+	assert.ElementsMatch(t, repo.rules, []rule.Rule{
+		&ruleImpl{id: "1", srcID: "foo", hash: []byte{1}},
+		&ruleImpl{id: "1", srcID: "bar", hash: []byte{2}},
+	})
+
+	// WHEN
+	repo.deleteRuleSet("foo")
+
+	// THEN
+	require.Len(t, repo.rules, 1)
+	assert.Equal(t, &ruleImpl{id: "1", srcID: "bar", hash: []byte{2}}, repo.rules[0])
+}
+
 func TestRepositoryRuleSetLifecycleManagement(t *testing.T) {
 	t.Parallel()
 
@@ -358,3 +728,145 @@ func TestRepositoryRuleSetLifecycleManagement(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkRepositoryFindRule(b *testing.B) {
+	for _, count := range []int{10, 100, 1000, 10000} {
+		rules := make([]rule.Rule, count)
+
+		for i := 0; i < count; i++ {
+			pattern := fmt.Sprintf("https://service-%d.example.com/api/<**>", i)
+			matcher, err := patternmatcher.NewPatternMatcher("glob", pattern)
+			require.NoError(b, err)
+
+			rules[i] = &ruleImpl{
+				id:         strconv.Itoa(i),
+				srcID:      "bench",
+				urlPattern: pattern,
+				urlMatcher: matcher,
+				methods:    []string{http.MethodGet},
+			}
+		}
+
+		repo := newRepository(nil, &ruleFactory{}, log.Logger)
+		repo.addRuleSet("bench", rules)
+
+		// the rule defined last is the worst case for a linear scan
+		requestURL := &url.URL{
+			Scheme: "https",
+			Host:   fmt.Sprintf("service-%d.example.com", count-1),
+			Path:   "/api/v1/foo",
+		}
+
+		b.Run(fmt.Sprintf("rules=%d", count), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindRule(http.MethodGet, requestURL); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestRepositoryStatus(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc        string
+		factory   *ruleFactory
+		configure func(t *testing.T, repo *repository, queue event.RuleSetChangedEventQueue)
+		assert    func(t *testing.T, status readiness.Status)
+	}{
+		{
+			uc:        "only default rule configured",
+			factory:   &ruleFactory{hasDefaultRule: true, defaultRule: &ruleImpl{id: "default"}},
+			configure: func(t *testing.T, _ *repository, _ event.RuleSetChangedEventQueue) { t.Helper() },
+			assert: func(t *testing.T, status readiness.Status) {
+				t.Helper()
+
+				assert.True(t, status.Ready)
+				assert.Equal(t, true, status.Details["default_rule"])
+				assert.Equal(t, 0, status.Details["rules"])
+			},
+		},
+		{
+			uc:      "rule provider with empty source",
+			factory: &ruleFactory{},
+			configure: func(t *testing.T, repo *repository, _ event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.NoError(t, repo.Start(context.Background()))
+				t.Cleanup(func() { _ = repo.Stop(context.Background()) })
+			},
+			assert: func(t *testing.T, status readiness.Status) {
+				t.Helper()
+
+				assert.True(t, status.Ready)
+				assert.Equal(t, 0, status.Details["rule_sets"])
+			},
+		},
+		{
+			uc:      "initial rule set not yet applied",
+			factory: &ruleFactory{},
+			configure: func(t *testing.T, _ *repository, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				queue <- event.RuleSetChanged{Source: "bar", ChangeType: event.Create}
+			},
+			assert: func(t *testing.T, status readiness.Status) {
+				t.Helper()
+
+				assert.False(t, status.Ready)
+				assert.Equal(t, 1, status.Details["pending_changes"])
+			},
+		},
+		{
+			uc:      "rule set changes pending after the first rule set has been loaded",
+			factory: &ruleFactory{},
+			configure: func(t *testing.T, repo *repository, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				repo.addRuleSet("bar", []rule.Rule{&ruleImpl{id: "1", srcID: "bar"}})
+				queue <- event.RuleSetChanged{Source: "baz", ChangeType: event.Create}
+			},
+			assert: func(t *testing.T, status readiness.Status) {
+				t.Helper()
+
+				assert.True(t, status.Ready)
+				assert.Equal(t, 1, status.Details["rules"])
+				assert.Equal(t, 1, status.Details["pending_changes"])
+			},
+		},
+		{
+			uc:      "all rule sets deleted after the first rule set has been loaded",
+			factory: &ruleFactory{},
+			configure: func(t *testing.T, repo *repository, _ event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				repo.addRuleSet("bar", []rule.Rule{&ruleImpl{id: "1", srcID: "bar"}})
+				repo.deleteRuleSet("bar")
+			},
+			assert: func(t *testing.T, status readiness.Status) {
+				t.Helper()
+
+				assert.True(t, status.Ready)
+				assert.Equal(t, 0, status.Details["rules"])
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			queue := make(event.RuleSetChangedEventQueue, 10)
+			repo := newRepository(queue, tc.factory, log.Logger)
+
+			tc.configure(t, repo, queue)
+
+			// WHEN
+			status := repo.Status(context.Background())
+
+			// THEN
+			tc.assert(t, status)
+		})
+	}
+}
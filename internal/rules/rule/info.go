@@ -0,0 +1,53 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rule
+
+//go:generate mockery --name Inspector --structname InspectorMock
+
+// Inspector provides read-only access to the rules currently loaded.
+type Inspector interface {
+	RuleSets() []SetInfo
+	DefaultRule() (Info, bool)
+	Describe(rul Rule) Info
+}
+
+type SetInfo struct {
+	Source string `json:"src"`
+	Name   string `json:"name"`
+	Rules  []Info `json:"rules"`
+}
+
+type Info struct {
+	ID       string          `json:"id"`
+	SrcID    string          `json:"src"`
+	Match    MatchInfo       `json:"match"`
+	Methods  []string        `json:"methods"`
+	Priority int             `json:"priority"`
+	Execute  []MechanismInfo `json:"execute"`
+	OnError  []MechanismInfo `json:"on_error"`
+	Hash     string          `json:"hash,omitempty"`
+}
+
+type MatchInfo struct {
+	URL      string `json:"url,omitempty"`
+	Strategy string `json:"strategy,omitempty"`
+}
+
+type MechanismInfo struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
@@ -22,25 +22,25 @@ func (_m *RepositoryMock) EXPECT() *RepositoryMock_Expecter {
 	return &RepositoryMock_Expecter{mock: &_m.Mock}
 }
 
-// FindRule provides a mock function with given fields: _a0
-func (_m *RepositoryMock) FindRule(_a0 *url.URL) (rule.Rule, error) {
-	ret := _m.Called(_a0)
+// FindRule provides a mock function with given fields: method, requestURL
+func (_m *RepositoryMock) FindRule(method string, requestURL *url.URL) (rule.Rule, error) {
+	ret := _m.Called(method, requestURL)
 
 	var r0 rule.Rule
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*url.URL) (rule.Rule, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(string, *url.URL) (rule.Rule, error)); ok {
+		return rf(method, requestURL)
 	}
-	if rf, ok := ret.Get(0).(func(*url.URL) rule.Rule); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(string, *url.URL) rule.Rule); ok {
+		r0 = rf(method, requestURL)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(rule.Rule)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*url.URL) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(string, *url.URL) error); ok {
+		r1 = rf(method, requestURL)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -54,14 +54,15 @@ type RepositoryMock_FindRule_Call struct {
 }
 
 // FindRule is a helper method to define mock.On call
-//   - _a0 *url.URL
-func (_e *RepositoryMock_Expecter) FindRule(_a0 interface{}) *RepositoryMock_FindRule_Call {
-	return &RepositoryMock_FindRule_Call{Call: _e.mock.On("FindRule", _a0)}
+//   - method string
+//   - requestURL *url.URL
+func (_e *RepositoryMock_Expecter) FindRule(method interface{}, requestURL interface{}) *RepositoryMock_FindRule_Call {
+	return &RepositoryMock_FindRule_Call{Call: _e.mock.On("FindRule", method, requestURL)}
 }
 
-func (_c *RepositoryMock_FindRule_Call) Run(run func(_a0 *url.URL)) *RepositoryMock_FindRule_Call {
+func (_c *RepositoryMock_FindRule_Call) Run(run func(method string, requestURL *url.URL)) *RepositoryMock_FindRule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*url.URL))
+		run(args[0].(string), args[1].(*url.URL))
 	})
 	return _c
 }
@@ -71,7 +72,7 @@ func (_c *RepositoryMock_FindRule_Call) Return(_a0 rule.Rule, _a1 error) *Reposi
 	return _c
 }
 
-func (_c *RepositoryMock_FindRule_Call) RunAndReturn(run func(*url.URL) (rule.Rule, error)) *RepositoryMock_FindRule_Call {
+func (_c *RepositoryMock_FindRule_Call) RunAndReturn(run func(string, *url.URL) (rule.Rule, error)) *RepositoryMock_FindRule_Call {
 	_c.Call.Return(run)
 	return _c
 }
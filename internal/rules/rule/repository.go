@@ -23,5 +23,8 @@ import (
 //go:generate mockery --name Repository --structname RepositoryMock
 
 type Repository interface {
-	FindRule(*url.URL) (Rule, error)
+	// FindRule returns the rule, which matches both, the given request method and URL.
+	// If there are rules matching the URL, but none of these accepts the method,
+	// an error wrapping heimdall.ErrMethodNotAllowed is returned.
+	FindRule(method string, requestURL *url.URL) (Rule, error)
 }
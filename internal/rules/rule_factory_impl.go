@@ -207,14 +207,17 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 	}
 
 	return &ruleImpl{
-		id:         ruleConfig.ID,
-		urlMatcher: matcher,
+		id:          ruleConfig.ID,
+		urlPattern:  ruleConfig.RuleMatcher.URL,
+		urlStrategy: ruleConfig.RuleMatcher.Strategy,
+		urlMatcher:  matcher,
 		// this is weird, but without upstreamURLFactory will not be nil
 		// it will contain a nil pointer to the type of ruleConfig.UpstreamURLFactory
 		// so nil check on upstreamURLFactory will fail
 		upstreamURLFactory: x.IfThenElse[UpstreamURLFactory](ruleConfig.UpstreamURLFactory != nil,
 			ruleConfig.UpstreamURLFactory, nil),
 		methods:   methods,
+		priority:  ruleConfig.Priority,
 		srcID:     srcID,
 		isDefault: false,
 		hash:      hash,
@@ -405,7 +408,7 @@ func createHandler[T subjectHandler](
 		return nil, err
 	}
 
-	return &conditionalSubjectHandler{h: handler, c: condition}, err
+	return &conditionalSubjectHandler{h: handler, c: condition, typ: handlerType}, err
 }
 
 func getConfig(conf any) config.MechanismConfig {
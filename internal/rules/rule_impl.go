@@ -17,12 +17,14 @@
 package rules
 
 import (
+	"encoding/hex"
 	"fmt"
 	"net/url"
 
 	"github.com/rs/zerolog"
 	"golang.org/x/exp/slices"
 
+	"github.com/dadrus/heimdall/internal/accesscontext"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
 	"github.com/dadrus/heimdall/internal/rules/rule"
@@ -37,9 +39,12 @@ type UpstreamURLFactory interface {
 
 type ruleImpl struct {
 	id                 string
+	urlPattern         string
+	urlStrategy        string
 	urlMatcher         patternmatcher.PatternMatcher
 	upstreamURLFactory UpstreamURLFactory
 	methods            []string
+	priority           int
 	srcID              string
 	isDefault          bool
 	hash               []byte
@@ -68,6 +73,8 @@ func (r *ruleImpl) Execute(ctx heimdall.Context) (rule.URIMutator, error) {
 		logger.Debug().Str("_src", r.srcID).Str("_id", r.id).Msg("Executing rule")
 	}
 
+	accesscontext.SetRuleID(ctx.AppContext(), r.id)
+
 	// authenticators
 	sub, err := r.sc.Execute(ctx)
 	if err != nil {
@@ -94,16 +101,62 @@ func (r *ruleImpl) Execute(ctx heimdall.Context) (rule.URIMutator, error) {
 }
 
 func (r *ruleImpl) MatchesURL(requestURL *url.URL) bool {
+	return r.urlMatcher.Match(urlToMatch(requestURL))
+}
+
+func (r *ruleImpl) MatchesMethod(method string) bool { return slices.Contains(r.methods, method) }
+
+func (r *ruleImpl) ID() string { return r.id }
+
+func (r *ruleImpl) SrcID() string { return r.srcID }
+
+func urlToMatch(requestURL *url.URL) string {
 	toBeMatched := url.URL{
 		Scheme: requestURL.Scheme,
 		Opaque: fmt.Sprintf("//%s%s", requestURL.Host, requestURL.Path),
 	}
 
-	return r.urlMatcher.Match(toBeMatched.String())
+	return toBeMatched.String()
 }
 
-func (r *ruleImpl) MatchesMethod(method string) bool { return slices.Contains(r.methods, method) }
+func (r *ruleImpl) info() rule.Info {
+	execute := make([]rule.MechanismInfo, 0, len(r.sc)+len(r.sh)+len(r.un))
+	onError := make([]rule.MechanismInfo, 0, len(r.eh))
 
-func (r *ruleImpl) ID() string { return r.id }
+	for _, sc := range r.sc {
+		execute = append(execute, rule.MechanismInfo{Type: "authenticator", ID: sc.HandlerID()})
+	}
 
-func (r *ruleImpl) SrcID() string { return r.srcID }
+	for _, sh := range r.sh {
+		execute = append(execute, subjectHandlerInfo(sh))
+	}
+
+	for _, un := range r.un {
+		execute = append(execute, subjectHandlerInfo(un))
+	}
+
+	for _, eh := range r.eh {
+		onError = append(onError, rule.MechanismInfo{Type: "error_handler", ID: eh.HandlerID()})
+	}
+
+	return rule.Info{
+		ID:       r.id,
+		SrcID:    r.srcID,
+		Match:    rule.MatchInfo{URL: r.urlPattern, Strategy: r.urlStrategy},
+		Methods:  r.methods,
+		Priority: r.priority,
+		Execute:  execute,
+		OnError:  onError,
+		Hash:     hex.EncodeToString(r.hash),
+	}
+}
+
+func subjectHandlerInfo(handler subjectHandler) rule.MechanismInfo {
+	var typ string
+
+	if conditional, ok := handler.(*conditionalSubjectHandler); ok {
+		typ = conditional.typ
+	}
+
+	return rule.MechanismInfo{Type: typ, ID: handler.HandlerID()}
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/x"
+)
+
+// ruleIndex is a radix tree over the literal prefixes of the URL patterns of the
+// rules (like "https://foo.bar/api/" for "https://foo.bar/api/<**>"). A lookup
+// walks the tree along the URL to match and returns only the rules, whose literal
+// prefix is a prefix of that URL. Only these candidates have to be evaluated by
+// the actual glob or regex matcher. Rules, which patterns start with an expression,
+// e.g. "<http|https>://foo.bar/<**>", end up in the root node and are always candidates.
+type ruleIndex struct {
+	root indexNode
+}
+
+type indexNode struct {
+	prefix   string
+	children []*indexNode
+	entries  []indexEntry
+}
+
+// indexEntry references a rule together with its position in the rule list
+// of the repository. The position is used to preserve the order, the rules
+// would have been evaluated in without an index.
+type indexEntry struct {
+	pos int
+	rul rule.Rule
+}
+
+func newRuleIndex(rules []rule.Rule) *ruleIndex {
+	idx := &ruleIndex{}
+
+	for pos, rul := range rules {
+		idx.root.insert(indexKey(rul), indexEntry{pos: pos, rul: rul})
+	}
+
+	return idx
+}
+
+// candidates returns all rules, which literal prefix is a prefix of the given
+// value, ordered by their position in the rule list of the repository.
+func (idx *ruleIndex) candidates(value string) []rule.Rule {
+	var entries []indexEntry
+
+	node := &idx.root
+	for {
+		entries = append(entries, node.entries...)
+
+		if len(value) == 0 {
+			break
+		}
+
+		child := node.child(value[0])
+		if child == nil || !strings.HasPrefix(value, child.prefix) {
+			break
+		}
+
+		value = value[len(child.prefix):]
+		node = child
+	}
+
+	slices.SortFunc(entries, func(a, b indexEntry) int { return a.pos - b.pos })
+
+	rules := make([]rule.Rule, len(entries))
+	for i, entry := range entries {
+		rules[i] = entry.rul
+	}
+
+	return rules
+}
+
+func (n *indexNode) insert(key string, entry indexEntry) {
+	node := n
+
+	for len(key) != 0 {
+		child := node.child(key[0])
+		if child == nil {
+			node.children = append(node.children, &indexNode{prefix: key, entries: []indexEntry{entry}})
+
+			return
+		}
+
+		common := commonPrefixLength(key, child.prefix)
+		if common < len(child.prefix) {
+			// split the child node at the end of the common prefix
+			split := &indexNode{
+				prefix:   child.prefix[common:],
+				children: child.children,
+				entries:  child.entries,
+			}
+
+			child.prefix = child.prefix[:common]
+			child.children = []*indexNode{split}
+			child.entries = nil
+		}
+
+		key = key[common:]
+		node = child
+	}
+
+	node.entries = append(node.entries, entry)
+}
+
+func (n *indexNode) child(first byte) *indexNode {
+	for _, child := range n.children {
+		if child.prefix[0] == first {
+			return child
+		}
+	}
+
+	return nil
+}
+
+func commonPrefixLength(a, b string) int {
+	length := x.IfThenElse(len(a) < len(b), len(a), len(b))
+
+	for i := 0; i < length; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return length
+}
+
+func indexKey(rul rule.Rule) string {
+	impl, ok := rul.(*ruleImpl)
+	if !ok {
+		return ""
+	}
+
+	return patternmatcher.LiteralPrefix(impl.urlPattern)
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+)
+
+func TestRuleIndexCandidates(t *testing.T) {
+	t.Parallel()
+
+	rules := []rule.Rule{
+		&ruleImpl{id: "1", urlPattern: "http://foo.bar/api/<**>"},
+		&ruleImpl{id: "2", urlPattern: "<http|https>://foo.bar/<**>"},
+		&ruleImpl{id: "3", urlPattern: "http://foo.bar/api/v1/<**>"},
+		&ruleImpl{id: "4", urlPattern: "http://foo.baz/<**>"},
+		&ruleImpl{id: "5", urlPattern: "http://foo.bar/api"},
+		&ruleImpl{id: "6", urlPattern: "http://<*>.bar/<**>"},
+		&mocks.RuleMock{},
+		&ruleImpl{id: "8", urlPattern: "http://foo.bar/api/v1/<**>"},
+	}
+
+	idx := newRuleIndex(rules)
+
+	for _, tc := range []struct {
+		uc       string
+		value    string
+		expected []rule.Rule
+	}{
+		{
+			uc:       "empty value",
+			value:    "",
+			expected: []rule.Rule{rules[1], rules[6]},
+		},
+		{
+			uc:       "no literal prefix matches",
+			value:    "https://foo.bar/api/v1/foo",
+			expected: []rule.Rule{rules[1], rules[6]},
+		},
+		{
+			uc:       "only the common part of two prefixes matches",
+			value:    "http://foo.bax/api",
+			expected: []rule.Rule{rules[1], rules[5], rules[6]},
+		},
+		{
+			uc:       "exact prefix match",
+			value:    "http://foo.bar/api",
+			expected: []rule.Rule{rules[1], rules[4], rules[5], rules[6]},
+		},
+		{
+			uc:    "several prefixes on the path match",
+			value: "http://foo.bar/api/v1/foo",
+			expected: []rule.Rule{
+				rules[0], rules[1], rules[2], rules[4], rules[5], rules[6], rules[7],
+			},
+		},
+		{
+			uc:       "other host",
+			value:    "http://foo.baz/api/v1/foo",
+			expected: []rule.Rule{rules[1], rules[3], rules[5], rules[6]},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			candidates := idx.candidates(tc.value)
+
+			// THEN
+			assert.Equal(t, tc.expected, candidates)
+		})
+	}
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
-var ErrUnsupportedRuleSetVersion = errors.New("unsupported rule set version")
+var (
+	ErrUnsupportedRuleSetVersion = errors.New("unsupported rule set version")
+	ErrDuplicateRuleID           = errors.New("duplicate rule id")
+)
 
 type ruleSetProcessor struct {
 	q event.RuleSetChangedEventQueue
@@ -36,8 +39,16 @@ func (p *ruleSetProcessor) isVersionSupported(version string) bool {
 
 func (p *ruleSetProcessor) loadRules(ruleSet *config.RuleSet) ([]rule.Rule, error) {
 	rules := make([]rule.Rule, len(ruleSet.Rules))
+	known := make(map[string]struct{}, len(ruleSet.Rules))
 
 	for idx, rc := range ruleSet.Rules {
+		if _, ok := known[rc.ID]; ok {
+			return nil, errorchain.NewWithMessagef(ErrDuplicateRuleID,
+				"rule ID=%s is defined multiple times in rule set %s from %s", rc.ID, ruleSet.Name, ruleSet.Source)
+		}
+
+		known[rc.ID] = struct{}{}
+
 		rul, err := p.f.CreateRule(ruleSet.Version, ruleSet.Source, rc)
 		if err != nil {
 			return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed loading rule").CausedBy(err)
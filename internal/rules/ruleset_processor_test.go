@@ -51,6 +51,29 @@ func TestRuleSetProcessorOnCreated(t *testing.T) {
 				assert.Contains(t, err.Error(), "failed loading")
 			},
 		},
+		{
+			uc: "rule set with duplicate rule ids",
+			ruleset: &config.RuleSet{
+				MetaData: config.MetaData{Source: "test"},
+				Version:  config.CurrentRuleSetVersion,
+				Name:     "foobar",
+				Rules:    []config.Rule{{ID: "foo"}, {ID: "bar"}, {ID: "foo"}},
+			},
+			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateRule(config.CurrentRuleSetVersion, mock.Anything, mock.Anything).
+					Return(&mocks.RuleMock{}, nil).Twice()
+			},
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrDuplicateRuleID)
+				assert.Contains(t, err.Error(), "ID=foo")
+				assert.Len(t, queue, 0)
+			},
+		},
 		{
 			uc: "successful",
 			ruleset: &config.RuleSet{
@@ -138,6 +161,29 @@ func TestRuleSetProcessorOnUpdated(t *testing.T) {
 				assert.Contains(t, err.Error(), "failed loading")
 			},
 		},
+		{
+			uc: "rule set with duplicate rule ids",
+			ruleset: &config.RuleSet{
+				MetaData: config.MetaData{Source: "test"},
+				Version:  config.CurrentRuleSetVersion,
+				Name:     "foobar",
+				Rules:    []config.Rule{{ID: "foo"}, {ID: "bar"}, {ID: "foo"}},
+			},
+			configureFactory: func(t *testing.T, mhf *mocks.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateRule(config.CurrentRuleSetVersion, mock.Anything, mock.Anything).
+					Return(&mocks.RuleMock{}, nil).Twice()
+			},
+			assert: func(t *testing.T, err error, queue event.RuleSetChangedEventQueue) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrDuplicateRuleID)
+				assert.Contains(t, err.Error(), "ID=foo")
+				assert.Len(t, queue, 0)
+			},
+		},
 		{
 			uc: "successful",
 			ruleset: &config.RuleSet{
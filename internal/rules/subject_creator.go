@@ -26,4 +26,5 @@ import (
 type subjectCreator interface {
 	Execute(heimdall.Context) (*subject.Subject, error)
 	IsFallbackOnErrorAllowed() bool
+	HandlerID() string
 }
@@ -26,4 +26,5 @@ import (
 type subjectHandler interface {
 	Execute(heimdall.Context, *subject.Subject) error
 	ContinueOnError() bool
+	HandlerID() string
 }
@@ -0,0 +1,231 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var (
+	ErrInvalidSession = errors.New("invalid session")
+	ErrCodec          = errors.New("session codec error")
+)
+
+type sessionClaims struct {
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Codec converts sessions to and from cookie values. Sessions are encoded as JWTs signed with
+// the configured key of the key store and, if encryption is enabled, additionally encrypted
+// to the public key of that entry, resulting in a nested JWT.
+type Codec struct {
+	ks      keystore.KeyStore
+	kse     *keystore.Entry
+	encrypt bool
+}
+
+func NewCodec(ks keystore.KeyStore, keyID string, encrypt bool) (*Codec, error) {
+	var (
+		kse *keystore.Entry
+		err error
+	)
+
+	if len(keyID) == 0 {
+		kse = ks.Entries()[0]
+	} else {
+		kse, err = ks.GetKey(keyID)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(ErrCodec, "failed to retrieve session key").CausedBy(err)
+		}
+	}
+
+	return &Codec{ks: ks, kse: kse, encrypt: encrypt}, nil
+}
+
+// Encode creates the cookie value for the given session. If the session has no ID or issuance time
+// set, these are set as well.
+func (c *Codec) Encode(sess *Session) (string, error) {
+	if len(sess.ID) == 0 {
+		sess.ID = uuid.New().String()
+	}
+
+	if sess.IssuedAt.IsZero() {
+		sess.IssuedAt = time.Now()
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: c.kse.JOSEAlgorithm(), Key: c.kse.PrivateKey},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", c.kse.KeyID))
+	if err != nil {
+		return "", errorchain.NewWithMessage(ErrCodec, "failed to create session signer").CausedBy(err)
+	}
+
+	claims := jwt.Claims{
+		ID:       sess.ID,
+		Subject:  sess.SubjectID,
+		IssuedAt: jwt.NewNumericDate(sess.IssuedAt),
+	}
+
+	if !sess.ExpiresAt.IsZero() {
+		claims.Expiry = jwt.NewNumericDate(sess.ExpiresAt)
+	}
+
+	var raw string
+
+	if c.encrypt {
+		var encrypter jose.Encrypter
+
+		encrypter, err = c.newEncrypter()
+		if err != nil {
+			return "", err
+		}
+
+		raw, err = jwt.SignedAndEncrypted(signer, encrypter).
+			Claims(claims).
+			Claims(sessionClaims{Attributes: sess.Attributes}).
+			CompactSerialize()
+	} else {
+		raw, err = jwt.Signed(signer).
+			Claims(claims).
+			Claims(sessionClaims{Attributes: sess.Attributes}).
+			CompactSerialize()
+	}
+
+	if err != nil {
+		return "", errorchain.NewWithMessage(ErrCodec, "failed to encode session").CausedBy(err)
+	}
+
+	return raw, nil
+}
+
+// Decode verifies the given cookie value and returns the session encoded in it. The signature is
+// verified using the key referenced by the kid header. Expiration is not checked by intention, as
+// it is the responsibility of the caller.
+func (c *Codec) Decode(value string) (*Session, error) {
+	token, err := c.parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(token.Headers) != 1 {
+		return nil, errorchain.NewWithMessage(ErrInvalidSession, "unexpected number of signatures")
+	}
+
+	header := token.Headers[0]
+
+	kse, err := c.ks.GetKey(header.KeyID)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrInvalidSession, "no key for referenced kid").CausedBy(err)
+	}
+
+	if header.Algorithm != string(kse.JOSEAlgorithm()) {
+		return nil, errorchain.NewWithMessagef(ErrInvalidSession, "unexpected algorithm %s", header.Algorithm)
+	}
+
+	var (
+		claims jwt.Claims
+		extra  sessionClaims
+	)
+
+	if err = token.Claims(kse.PrivateKey.Public(), &claims, &extra); err != nil {
+		return nil, errorchain.NewWithMessage(ErrInvalidSession, "signature verification failed").CausedBy(err)
+	}
+
+	if len(claims.ID) == 0 || claims.IssuedAt == nil {
+		return nil, errorchain.NewWithMessage(ErrInvalidSession, "session has no id or issuance time")
+	}
+
+	sess := &Session{
+		ID:         claims.ID,
+		SubjectID:  claims.Subject,
+		Attributes: extra.Attributes,
+		IssuedAt:   claims.IssuedAt.Time(),
+	}
+
+	if claims.Expiry != nil {
+		sess.ExpiresAt = claims.Expiry.Time()
+	}
+
+	return sess, nil
+}
+
+func (c *Codec) parse(value string) (*jwt.JSONWebToken, error) {
+	const jweCompactDots = 4
+
+	if strings.Count(value, ".") != jweCompactDots {
+		if c.encrypt {
+			return nil, errorchain.NewWithMessage(ErrInvalidSession, "session is not encrypted")
+		}
+
+		token, err := jwt.ParseSigned(value)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(ErrInvalidSession, "failed to parse session").CausedBy(err)
+		}
+
+		return token, nil
+	}
+
+	nested, err := jwt.ParseSignedAndEncrypted(value)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrInvalidSession, "failed to parse session").CausedBy(err)
+	}
+
+	entry, err := c.ks.GetKey(nested.Headers[0].KeyID)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrInvalidSession, "no key for referenced kid").CausedBy(err)
+	}
+
+	token, err := nested.Decrypt(entry.PrivateKey)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrInvalidSession, "failed to decrypt session").CausedBy(err)
+	}
+
+	return token, nil
+}
+
+func (c *Codec) newEncrypter() (jose.Encrypter, error) {
+	var alg jose.KeyAlgorithm
+
+	switch c.kse.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		alg = jose.RSA_OAEP_256
+	case *ecdsa.PrivateKey:
+		alg = jose.ECDH_ES_A256KW
+	default:
+		return nil, errorchain.NewWithMessage(ErrCodec, "unsupported key type for session encryption")
+	}
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM,
+		jose.Recipient{Algorithm: alg, Key: c.kse.PrivateKey.Public(), KeyID: c.kse.KeyID},
+		(&jose.EncrypterOptions{}).WithContentType("JWT"))
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrCodec, "failed to create session encrypter").CausedBy(err)
+	}
+
+	return encrypter, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+)
+
+func createKeyStore(t *testing.T) keystore.KeyStore {
+	t.Helper()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(
+		pemx.WithECDSAPrivateKey(ecKey, pemx.WithHeader("X-Key-ID", "ec")),
+		pemx.WithRSAPrivateKey(rsaKey, pemx.WithHeader("X-Key-ID", "rsa")),
+	)
+	require.NoError(t, err)
+
+	ks, err := keystore.NewKeyStoreFromPEMBytes(pemBytes, "")
+	require.NoError(t, err)
+
+	return ks
+}
+
+func TestNewCodec(t *testing.T) {
+	t.Parallel()
+
+	ks := createKeyStore(t)
+
+	for _, tc := range []struct {
+		uc     string
+		keyID  string
+		assert func(t *testing.T, err error, codec *Codec)
+	}{
+		{
+			uc: "without key id",
+			assert: func(t *testing.T, err error, codec *Codec) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "ec", codec.kse.KeyID)
+			},
+		},
+		{
+			uc:    "with existing key id",
+			keyID: "rsa",
+			assert: func(t *testing.T, err error, codec *Codec) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "rsa", codec.kse.KeyID)
+			},
+		},
+		{
+			uc:    "with not existing key id",
+			keyID: "foo",
+			assert: func(t *testing.T, err error, _ *Codec) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrCodec)
+				assert.ErrorIs(t, err, keystore.ErrNoSuchKey)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			codec, err := NewCodec(ks, tc.keyID, false)
+
+			// THEN
+			tc.assert(t, err, codec)
+		})
+	}
+}
+
+func TestCodecEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	ks := createKeyStore(t)
+	issuedAt := time.Now().Add(-1 * time.Minute).Truncate(time.Second)
+	expiresAt := issuedAt.Add(1 * time.Hour)
+
+	for _, tc := range []struct {
+		uc      string
+		keyID   string
+		encrypt bool
+		parts   int
+	}{
+		{uc: "signed with ecdsa key", keyID: "ec", parts: 3},
+		{uc: "signed with rsa key", keyID: "rsa", parts: 3},
+		{uc: "signed and encrypted with ecdsa key", keyID: "ec", encrypt: true, parts: 5},
+		{uc: "signed and encrypted with rsa key", keyID: "rsa", encrypt: true, parts: 5},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			codec, err := NewCodec(ks, tc.keyID, tc.encrypt)
+			require.NoError(t, err)
+
+			sess := &Session{
+				SubjectID:  "foo",
+				Attributes: map[string]any{"bar": "baz"},
+				IssuedAt:   issuedAt,
+				ExpiresAt:  expiresAt,
+			}
+
+			// WHEN
+			value, err := codec.Encode(sess)
+			require.NoError(t, err)
+
+			decoded, err := codec.Decode(value)
+
+			// THEN
+			require.NoError(t, err)
+			assert.NotEmpty(t, sess.ID)
+			assert.Equal(t, sess.ID, decoded.ID)
+			assert.Equal(t, "foo", decoded.SubjectID)
+			assert.Equal(t, map[string]any{"bar": "baz"}, decoded.Attributes)
+			assert.Equal(t, issuedAt, decoded.IssuedAt)
+			assert.Equal(t, expiresAt, decoded.ExpiresAt)
+			assert.Len(t, strings.Split(value, "."), tc.parts)
+		})
+	}
+}
+
+func TestCodecDecode(t *testing.T) {
+	t.Parallel()
+
+	ks := createKeyStore(t)
+	otherKS := createKeyStore(t)
+
+	signingCodec, err := NewCodec(ks, "ec", false)
+	require.NoError(t, err)
+
+	foreignCodec, err := NewCodec(otherKS, "ec", false)
+	require.NoError(t, err)
+
+	foreignEncryptingCodec, err := NewCodec(otherKS, "ec", true)
+	require.NoError(t, err)
+
+	signed, err := signingCodec.Encode(&Session{SubjectID: "foo"})
+	require.NoError(t, err)
+
+	foreignSigned, err := foreignCodec.Encode(&Session{SubjectID: "foo"})
+	require.NoError(t, err)
+
+	foreignEncrypted, err := foreignEncryptingCodec.Encode(&Session{SubjectID: "foo"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc      string
+		encrypt bool
+		value   string
+		assert  func(t *testing.T, err error)
+	}{
+		{
+			uc:    "malformed value",
+			value: "foo.bar.baz",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidSession)
+				assert.Contains(t, err.Error(), "failed to parse")
+			},
+		},
+		{
+			uc:      "signed only value, but encryption is required",
+			encrypt: true,
+			value:   signed,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidSession)
+				assert.Contains(t, err.Error(), "not encrypted")
+			},
+		},
+		{
+			uc:    "value signed with a foreign key",
+			value: foreignSigned,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidSession)
+				assert.Contains(t, err.Error(), "signature verification failed")
+			},
+		},
+		{
+			uc:    "value encrypted with a foreign key",
+			value: foreignEncrypted,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidSession)
+				assert.Contains(t, err.Error(), "failed to decrypt")
+			},
+		},
+		{
+			uc:    "tampered value",
+			value: signed[:len(signed)-4] + "AAAA",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidSession)
+			},
+		},
+		{
+			uc:    "valid value",
+			value: signed,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			codec, err := NewCodec(ks, "ec", tc.encrypt)
+			require.NoError(t, err)
+
+			// WHEN
+			_, err = codec.Decode(tc.value)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"time"
+)
+
+// Session represents a session established by heimdall for a subject.
+type Session struct {
+	// ID uniquely identifies the session. It is used to track the activity and the revocation
+	// state of the session on the server side.
+	ID         string
+	SubjectID  string
+	Attributes map[string]any
+	IssuedAt   time.Time
+	// ExpiresAt is the absolute point in time the session ends at. Zero means no absolute expiry.
+	ExpiresAt time.Time
+}
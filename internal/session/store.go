@@ -0,0 +1,71 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	activityKeyPrefix   = "session-activity:"
+	revocationKeyPrefix = "session-revocation:"
+)
+
+// Store keeps the server side state of sessions, that is their activity and revocation, in
+// the cache. To share that state between multiple heimdall instances, a distributed cache
+// must be used.
+type Store struct {
+	cch cache.Cache
+}
+
+func NewStore(cch cache.Cache) Store {
+	return Store{cch: cch}
+}
+
+// Touch records an activity for the session with the given id. The record expires after the
+// given idle timeout.
+func (s Store) Touch(sid string, idleTimeout time.Duration) {
+	s.cch.Set(key(activityKeyPrefix, sid), sid, idleTimeout)
+}
+
+// IsActive returns true if an activity for the session with the given id has been recorded and
+// the corresponding record did not expire yet.
+func (s Store) IsActive(sid string) bool {
+	return s.cch.Get(key(activityKeyPrefix, sid)) != nil
+}
+
+// Revoke marks the session with the given id as revoked. The given ttl should cover the remaining
+// lifetime of the session.
+func (s Store) Revoke(sid string, ttl time.Duration) {
+	s.cch.Delete(key(activityKeyPrefix, sid))
+	s.cch.Set(key(revocationKeyPrefix, sid), sid, ttl)
+}
+
+func (s Store) IsRevoked(sid string) bool {
+	return s.cch.Get(key(revocationKeyPrefix, sid)) != nil
+}
+
+func key(prefix, sid string) string {
+	digest := sha256.Sum256(stringx.ToBytes(sid))
+
+	return prefix + hex.EncodeToString(digest[:])
+}
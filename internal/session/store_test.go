@@ -0,0 +1,86 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+)
+
+func TestStoreActivity(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var activityKey string
+
+	cch := mocks.NewCacheMock(t)
+	cch.EXPECT().Set(mock.Anything, "foo", 10*time.Second).Run(func(key string, _ any, _ time.Duration) {
+		activityKey = key
+	})
+	cch.EXPECT().Get(mock.Anything).RunAndReturn(func(key string) any {
+		if key == activityKey {
+			return "foo"
+		}
+
+		return nil
+	})
+
+	store := NewStore(cch)
+
+	// WHEN
+	store.Touch("foo", 10*time.Second)
+
+	// THEN
+	assert.True(t, store.IsActive("foo"))
+	assert.False(t, store.IsActive("bar"))
+	assert.False(t, store.IsRevoked("foo"))
+}
+
+func TestStoreRevocation(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var revocationKey string
+
+	cch := mocks.NewCacheMock(t)
+	cch.EXPECT().Delete(mock.Anything)
+	cch.EXPECT().Set(mock.Anything, "foo", 1*time.Hour).Run(func(key string, _ any, _ time.Duration) {
+		revocationKey = key
+	})
+	cch.EXPECT().Get(mock.Anything).RunAndReturn(func(key string) any {
+		if key == revocationKey {
+			return "foo"
+		}
+
+		return nil
+	})
+
+	store := NewStore(cch)
+
+	// WHEN
+	store.Revoke("foo", 1*time.Hour)
+
+	// THEN
+	assert.True(t, store.IsRevoked("foo"))
+	assert.False(t, store.IsRevoked("bar"))
+	assert.False(t, store.IsActive("foo"))
+}
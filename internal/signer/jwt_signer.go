@@ -17,12 +17,17 @@
 package signer
 
 import (
+	"bytes"
+	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/x509"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -34,6 +39,8 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/readiness"
+	"github.com/dadrus/heimdall/internal/watcher"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/pkix"
 	"github.com/dadrus/heimdall/internal/x/stringx"
@@ -67,6 +74,44 @@ func NewJWTSigner(conf *config.Configuration, logger zerolog.Logger) (heimdall.J
 		return nil, err
 	}
 
+	kse, err = selectKey(ks, conf.Signer.KeyID, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info().Str("_key_id", kse.KeyID).Msg("Signer configured")
+
+	signer := &jwtSigner{
+		iss:  conf.Signer.Name,
+		jwk:  kse.JWK(),
+		key:  kse.PrivateKey,
+		cert: leafCertificate(kse),
+		ks:   ks,
+		l:    logger,
+	}
+
+	if conf.Signer.KeyRotation.Enabled {
+		if len(conf.Signer.KeyStore.Path) == 0 {
+			logger.Warn().Msg("Key rotation is enabled, but no key store is configured. Ignoring.")
+
+			return signer, nil
+		}
+
+		signer.rotation, err = newKeyRotation(conf.Signer, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return signer, nil
+}
+
+func selectKey(ks keystore.KeyStore, keyID string, logger zerolog.Logger) (*keystore.Entry, error) {
+	var (
+		kse *keystore.Entry
+		err error
+	)
+
 	logger.Info().Msg("Key store contains following entries")
 
 	for _, entry := range ks.Entries() {
@@ -77,12 +122,12 @@ func NewJWTSigner(conf *config.Configuration, logger zerolog.Logger) (heimdall.J
 			Msg("Entry info")
 	}
 
-	if len(conf.Signer.KeyID) == 0 {
+	if len(keyID) == 0 {
 		logger.Warn().Msg("No key id for signer configured. Taking first entry from the key store")
 
 		kse, err = ks.Entries()[0], nil
 	} else {
-		kse, err = ks.GetKey(conf.Signer.KeyID)
+		kse, err = ks.GetKey(keyID)
 	}
 
 	if err != nil {
@@ -99,24 +144,52 @@ func NewJWTSigner(conf *config.Configuration, logger zerolog.Logger) (heimdall.J
 		}
 	}
 
-	logger.Info().Str("_key_id", kse.KeyID).Msg("Signer configured")
+	return kse, nil
+}
 
-	return &jwtSigner{
-		iss: conf.Signer.Name,
-		jwk: kse.JWK(),
-		key: kse.PrivateKey,
-		ks:  ks,
-	}, nil
+func leafCertificate(kse *keystore.Entry) *x509.Certificate {
+	if len(kse.CertChain) != 0 {
+		return kse.CertChain[0]
+	}
+
+	return nil
 }
 
 type jwtSigner struct {
-	iss string
-	jwk jose.JSONWebKey
-	key crypto.Signer
-	ks  keystore.KeyStore
+	mut sync.RWMutex
+
+	iss  string
+	jwk  jose.JSONWebKey
+	key  crypto.Signer
+	cert *x509.Certificate
+	ks   keystore.KeyStore
+	l    zerolog.Logger
+
+	// set only if key rotation is enabled
+	rotation *keyRotation
+	// the key store loaded on key rotation. Its keys are published, but used for signing only
+	// after the overlap period is over.
+	next *pendingKey
+	// the keys published before the last switch of the signing key. These are still published
+	// until the given point in time to allow verification of JWTs issued before the switch.
+	previous      []jose.JSONWebKey
+	previousUntil time.Time
+}
+
+type pendingKey struct {
+	jwk         jose.JSONWebKey
+	key         crypto.Signer
+	cert        *x509.Certificate
+	ks          keystore.KeyStore
+	activatesAt time.Time
 }
 
 func (s *jwtSigner) Hash() []byte {
+	s.rotateIfDue()
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
 	hash := sha256.New()
 	hash.Write(stringx.ToBytes(s.jwk.KeyID))
 	hash.Write(stringx.ToBytes(s.jwk.Algorithm))
@@ -126,14 +199,20 @@ func (s *jwtSigner) Hash() []byte {
 }
 
 func (s *jwtSigner) Sign(sub string, ttl time.Duration, custClaims map[string]any) (string, error) {
+	s.rotateIfDue()
+
+	s.mut.RLock()
+	jwk, key := s.jwk, s.key
+	s.mut.RUnlock()
+
 	signerOpts := jose.SignerOptions{}
 	signerOpts.
 		WithType("JWT").
-		WithHeader("kid", s.jwk.KeyID).
-		WithHeader("alg", s.jwk.Algorithm)
+		WithHeader("kid", jwk.KeyID).
+		WithHeader("alg", jwk.Algorithm)
 
 	signer, err := jose.NewSigner(
-		jose.SigningKey{Algorithm: jose.SignatureAlgorithm(s.jwk.Algorithm), Key: s.key},
+		jose.SigningKey{Algorithm: jose.SignatureAlgorithm(jwk.Algorithm), Key: key},
 		&signerOpts)
 	if err != nil {
 		return "", errorchain.NewWithMessage(heimdall.ErrInternal, "failed to create JWT signer").CausedBy(err)
@@ -161,12 +240,288 @@ func (s *jwtSigner) Sign(sub string, ttl time.Duration, custClaims map[string]an
 	return rawJwt, nil
 }
 
+// Keys returns the keys from the used key store. During key rotation, the keys from the new
+// key store, as well as the keys from the previously used one are returned.
 func (s *jwtSigner) Keys() []jose.JSONWebKey {
-	keys := make([]jose.JSONWebKey, len(s.ks.Entries()))
+	s.rotateIfDue()
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
 
-	for idx, entry := range s.ks.Entries() {
+	keys := keysOf(s.ks)
+
+	if s.next != nil {
+		keys = appendMissing(keys, keysOf(s.next.ks))
+	}
+
+	if time.Now().Before(s.previousUntil) {
+		keys = appendMissing(keys, s.previous)
+	}
+
+	return keys
+}
+
+func (s *jwtSigner) Name() string { return "signer" }
+
+// Status reports the signer as ready as long as the certificate of the used key, if present, is valid.
+func (s *jwtSigner) Status(_ context.Context) readiness.Status {
+	s.rotateIfDue()
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	status := readiness.Status{
+		Ready:   true,
+		Details: map[string]any{"key_id": s.jwk.KeyID, "algorithm": s.jwk.Algorithm},
+	}
+
+	if s.next != nil {
+		status.Details["next_key_id"] = s.next.jwk.KeyID
+		status.Details["next_key_activation"] = s.next.activatesAt.UTC().Format(time.RFC3339)
+	}
+
+	if s.cert == nil {
+		return status
+	}
+
+	status.Details["not_after"] = s.cert.NotAfter.UTC().Format(time.RFC3339)
+
+	if now := time.Now(); now.Before(s.cert.NotBefore) || now.After(s.cert.NotAfter) {
+		status.Ready = false
+		status.Error = "certificate of the signing key is not valid"
+	}
+
+	return status
+}
+
+func (s *jwtSigner) start(_ context.Context) error {
+	if s.rotation == nil {
+		return nil
+	}
+
+	return s.rotation.start(s.reload)
+}
+
+func (s *jwtSigner) stop(_ context.Context) error {
+	if s.rotation == nil {
+		return nil
+	}
+
+	return s.rotation.stop()
+}
+
+// reload loads the key store anew and schedules the switch to the configured key. If the key
+// did not change, or no overlap period is configured, the new key is used right away. A new key
+// reusing the key id of the current one is refused, as consumers would not be able to tell the
+// keys apart.
+func (s *jwtSigner) reload() error {
+	ks, err := keystore.NewKeyStoreFromPEMFile(s.rotation.path, s.rotation.password)
+	if err != nil {
+		return err
+	}
+
+	kse, err := selectKey(ks, s.rotation.keyID, s.l)
+	if err != nil {
+		return err
+	}
+
+	next := &pendingKey{
+		jwk:         kse.JWK(),
+		key:         kse.PrivateKey,
+		cert:        leafCertificate(kse),
+		ks:          ks,
+		activatesAt: time.Now().Add(s.rotation.overlap),
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if next.jwk.KeyID == s.jwk.KeyID {
+		sameKey, err := sameKeyMaterial(next.jwk, s.jwk)
+		if err != nil {
+			return err
+		}
+
+		if !sameKey {
+			s.l.Warn().
+				Str("_key_id", next.jwk.KeyID).
+				Msg("Key material changed without a change of the key id. Refusing key rotation, keeping the " +
+					"current signing key. Use a new key id for the new key")
+
+			return nil
+		}
+	}
+
+	if next.jwk.KeyID == s.jwk.KeyID || s.rotation.overlap <= 0 {
+		s.l.Info().Str("_key_id", next.jwk.KeyID).Msg("Switching signing key")
+
+		s.next = nil
+		s.activate(next, false)
+
+		return nil
+	}
+
+	s.l.Info().
+		Str("_key_id", next.jwk.KeyID).
+		Time("_activation", next.activatesAt).
+		Msg("Publishing new signing key. It will be used for signing after the overlap period")
+
+	s.next = next
+
+	return nil
+}
+
+func (s *jwtSigner) rotateIfDue() {
+	s.mut.RLock()
+	due := s.next != nil && !time.Now().Before(s.next.activatesAt)
+	s.mut.RUnlock()
+
+	if !due {
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	// might have been done concurrently
+	if s.next == nil || time.Now().Before(s.next.activatesAt) {
+		return
+	}
+
+	s.l.Info().Str("_key_id", s.next.jwk.KeyID).Msg("Switching signing key")
+
+	next := s.next
+	s.next = nil
+	s.activate(next, true)
+}
+
+// activate makes the given key the signing key. If requested, the keys published so far
+// remain published for the overlap period. Not concurrently safe.
+func (s *jwtSigner) activate(next *pendingKey, retainPrevious bool) {
+	if retainPrevious {
+		s.previous = keysOf(s.ks)
+		s.previousUntil = time.Now().Add(s.rotation.overlap)
+	}
+
+	s.jwk = next.jwk
+	s.key = next.key
+	s.cert = next.cert
+	s.ks = next.ks
+}
+
+// sameKeyMaterial compares the thumbprints of the given keys.
+func sameKeyMaterial(key, other jose.JSONWebKey) (bool, error) {
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return false, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to calculate the thumbprint of the new signing key").CausedBy(err)
+	}
+
+	otherThumbprint, err := other.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return false, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to calculate the thumbprint of the current signing key").CausedBy(err)
+	}
+
+	return bytes.Equal(thumbprint, otherThumbprint), nil
+}
+
+func keysOf(ks keystore.KeyStore) []jose.JSONWebKey {
+	keys := make([]jose.JSONWebKey, len(ks.Entries()))
+
+	for idx, entry := range ks.Entries() {
 		keys[idx] = entry.JWK()
 	}
 
 	return keys
 }
+
+func appendMissing(keys []jose.JSONWebKey, others []jose.JSONWebKey) []jose.JSONWebKey {
+	known := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		known[key.KeyID] = true
+	}
+
+	for _, key := range others {
+		if !known[key.KeyID] {
+			keys = append(keys, key)
+			known[key.KeyID] = true
+		}
+	}
+
+	return keys
+}
+
+type keyRotation struct {
+	path     string
+	password string
+	keyID    string
+	overlap  time.Duration
+	w        *watcher.Watcher
+	l        zerolog.Logger
+	hash     []byte
+}
+
+func newKeyRotation(conf config.SignerConfig, logger zerolog.Logger) (*keyRotation, error) {
+	absPath, err := filepath.Abs(conf.KeyStore.Path)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to get the absolute path for the key store").CausedBy(err)
+	}
+
+	hash, err := fileHash(absPath)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to read the key store").CausedBy(err)
+	}
+
+	return &keyRotation{
+		path:     absPath,
+		password: conf.KeyStore.Password,
+		keyID:    conf.KeyID,
+		overlap:  conf.KeyRotation.Overlap,
+		w:        watcher.New(absPath),
+		l:        logger,
+		hash:     hash,
+	}, nil
+}
+
+func (r *keyRotation) start(reload func() error) error {
+	return r.w.Start(r.l, func() error { return r.keyStoreChanged(reload) })
+}
+
+func (r *keyRotation) stop() error { return r.w.Stop() }
+
+func (r *keyRotation) keyStoreChanged(reload func() error) error {
+	hash, err := fileHash(r.path)
+	if err != nil {
+		// the file might be in the middle of being replaced
+		r.l.Debug().Err(err).Msg("Failed to read key store")
+
+		return nil
+	}
+
+	if bytes.Equal(hash, r.hash) {
+		return nil
+	}
+
+	if err = reload(); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to reload key store, keeping the current signing key").CausedBy(err)
+	}
+
+	r.hash = hash
+
+	return nil
+}
+
+func fileHash(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(data)
+
+	return hash[:], nil
+}
@@ -17,6 +17,7 @@
 package signer
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -249,6 +250,39 @@ func TestNewJWTSigner(t *testing.T) {
 				assert.Contains(t, err.Error(), "failed to get information about")
 			},
 		},
+		{
+			uc: "with key rotation enabled",
+			config: config.SignerConfig{
+				Name:        "foo",
+				KeyStore:    config.KeyStore{Path: keyFile.Name(), Password: "bar"},
+				KeyID:       "key2",
+				KeyRotation: config.KeyRotation{Enabled: true, Overlap: 10 * time.Minute},
+			},
+			assert: func(t *testing.T, err error, signer *jwtSigner) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, signer.rotation)
+
+				defer signer.rotation.stop()
+
+				assert.Equal(t, keyFile.Name(), signer.rotation.path)
+				assert.Equal(t, "bar", signer.rotation.password)
+				assert.Equal(t, "key2", signer.rotation.keyID)
+				assert.Equal(t, 10*time.Minute, signer.rotation.overlap)
+				assert.NotEmpty(t, signer.rotation.hash)
+			},
+		},
+		{
+			uc:     "with key rotation enabled, but without key store",
+			config: config.SignerConfig{Name: "foo", KeyRotation: config.KeyRotation{Enabled: true}},
+			assert: func(t *testing.T, err error, signer *jwtSigner) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Nil(t, signer.rotation)
+			},
+		},
 		{
 			uc:     "with certificate, which cannot be used for signature",
 			config: config.SignerConfig{Name: "foo", KeyStore: config.KeyStore{Path: keyFile.Name()}, KeyID: "invalid"},
@@ -432,6 +466,44 @@ func TestJWTSignerHash(t *testing.T) {
 	assert.Equal(t, hash1, hash2)
 }
 
+func TestJWTSignerStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	for _, tc := range []struct {
+		uc    string
+		cert  *x509.Certificate
+		ready bool
+	}{
+		{uc: "without certificate", ready: true},
+		{
+			uc:    "with valid certificate",
+			cert:  &x509.Certificate{NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+			ready: true,
+		},
+		{
+			uc:   "with expired certificate",
+			cert: &x509.Certificate{NotBefore: now.Add(-2 * time.Hour), NotAfter: now.Add(-time.Hour)},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			signer := &jwtSigner{jwk: jose.JSONWebKey{KeyID: "bar", Algorithm: "ES384"}, cert: tc.cert}
+
+			// WHEN
+			status := signer.Status(context.Background())
+
+			// THEN
+			assert.Equal(t, "signer", signer.Name())
+			assert.Equal(t, tc.ready, status.Ready)
+			assert.Equal(t, "bar", status.Details["key_id"])
+			assert.Equal(t, "ES384", status.Details["algorithm"])
+			assert.Equal(t, tc.ready, len(status.Error) == 0)
+		})
+	}
+}
+
 func TestJwtSignerKeys(t *testing.T) {
 	t.Parallel()
 
@@ -470,3 +542,222 @@ func TestJwtSignerKeys(t *testing.T) {
 	assert.Equal(t, "PS256", keys[0].Algorithm)
 	assert.Equal(t, "ES256", keys[1].Algorithm)
 }
+
+func writeKeyStore(t *testing.T, path string, keys map[string]*ecdsa.PrivateKey, order ...string) {
+	t.Helper()
+
+	opts := make([]pemx.EntryOption, len(order))
+	for idx, keyID := range order {
+		opts[idx] = pemx.WithECDSAPrivateKey(keys[keyID], pemx.WithHeader("X-Key-ID", keyID))
+	}
+
+	pemBytes, err := pemx.BuildPEM(opts...)
+	require.NoError(t, err)
+
+	// write and rename to have an atomic replacement of the file
+	tmpFile := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmpFile, pemBytes, 0o600))
+	require.NoError(t, os.Rename(tmpFile, path))
+}
+
+func keyIDsOf(keys []jose.JSONWebKey) []string {
+	ids := make([]string, len(keys))
+	for idx, key := range keys {
+		ids[idx] = key.KeyID
+	}
+
+	return ids
+}
+
+func signingKeyIDOf(t *testing.T, signer heimdall.JWTSigner) string {
+	t.Helper()
+
+	rawJWT, err := signer.Sign("foo", time.Minute, nil)
+	require.NoError(t, err)
+
+	token, err := jwt.ParseSigned(rawJWT)
+	require.NoError(t, err)
+
+	return token.Headers[0].KeyID
+}
+
+func TestJWTSignerKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	keys := make(map[string]*ecdsa.PrivateKey)
+
+	for _, keyID := range []string{"key1", "key2"} {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		keys[keyID] = key
+	}
+
+	for _, tc := range []struct {
+		uc      string
+		overlap time.Duration
+		update  func(t *testing.T, path string)
+		assert  func(t *testing.T, signer *jwtSigner)
+	}{
+		{
+			uc:      "new key is published first and used after the overlap period",
+			overlap: 2 * time.Second,
+			update: func(t *testing.T, path string) {
+				t.Helper()
+
+				writeKeyStore(t, path, keys, "key2", "key1")
+			},
+			assert: func(t *testing.T, signer *jwtSigner) {
+				t.Helper()
+
+				require.Eventually(t, func() bool {
+					return len(signer.Keys()) == 2
+				}, 1*time.Second, 10*time.Millisecond)
+
+				hash := signer.Hash()
+				assert.ElementsMatch(t, []string{"key1", "key2"}, keyIDsOf(signer.Keys()))
+				assert.Equal(t, "key1", signingKeyIDOf(t, signer))
+				assert.Equal(t, "key2", signer.Status(context.Background()).Details["next_key_id"])
+
+				require.Eventually(t, func() bool {
+					return signingKeyIDOf(t, signer) == "key2"
+				}, 3*time.Second, 50*time.Millisecond)
+
+				assert.NotEqual(t, hash, signer.Hash())
+				assert.ElementsMatch(t, []string{"key1", "key2"}, keyIDsOf(signer.Keys()))
+				assert.NotContains(t, signer.Status(context.Background()).Details, "next_key_id")
+			},
+		},
+		{
+			uc:      "previous keys are not published anymore after the overlap period",
+			overlap: 300 * time.Millisecond,
+			update: func(t *testing.T, path string) {
+				t.Helper()
+
+				writeKeyStore(t, path, keys, "key2")
+			},
+			assert: func(t *testing.T, signer *jwtSigner) {
+				t.Helper()
+
+				require.Eventually(t, func() bool {
+					return signingKeyIDOf(t, signer) == "key2"
+				}, 2*time.Second, 10*time.Millisecond)
+
+				require.Eventually(t, func() bool {
+					return len(signer.Keys()) == 1
+				}, 2*time.Second, 10*time.Millisecond)
+
+				assert.Equal(t, []string{"key2"}, keyIDsOf(signer.Keys()))
+			},
+		},
+		{
+			uc: "new key is used right away without overlap period",
+			update: func(t *testing.T, path string) {
+				t.Helper()
+
+				writeKeyStore(t, path, keys, "key2")
+			},
+			assert: func(t *testing.T, signer *jwtSigner) {
+				t.Helper()
+
+				require.Eventually(t, func() bool {
+					return signingKeyIDOf(t, signer) == "key2"
+				}, 1*time.Second, 10*time.Millisecond)
+
+				assert.Equal(t, []string{"key2"}, keyIDsOf(signer.Keys()))
+			},
+		},
+		{
+			uc:      "key store is reloaded right away if the key did not change",
+			overlap: 10 * time.Second,
+			update: func(t *testing.T, path string) {
+				t.Helper()
+
+				writeKeyStore(t, path, keys, "key1", "key2")
+			},
+			assert: func(t *testing.T, signer *jwtSigner) {
+				t.Helper()
+
+				require.Eventually(t, func() bool {
+					return len(signer.Keys()) == 2
+				}, 1*time.Second, 10*time.Millisecond)
+
+				assert.Equal(t, "key1", signingKeyIDOf(t, signer))
+				assert.NotContains(t, signer.Status(context.Background()).Details, "next_key_id")
+			},
+		},
+		{
+			uc:      "new key material with the key id of the current key is refused",
+			overlap: 10 * time.Millisecond,
+			update: func(t *testing.T, path string) {
+				t.Helper()
+
+				writeKeyStore(t, path, map[string]*ecdsa.PrivateKey{"key1": keys["key2"]}, "key1")
+			},
+			assert: func(t *testing.T, signer *jwtSigner) {
+				t.Helper()
+
+				time.Sleep(200 * time.Millisecond)
+
+				published := signer.Keys()
+				require.Len(t, published, 1)
+				assert.Equal(t, "key1", published[0].KeyID)
+				assert.Equal(t, &keys["key1"].PublicKey, published[0].Key)
+
+				signer.mut.RLock()
+				defer signer.mut.RUnlock()
+
+				assert.Equal(t, keys["key1"], signer.key)
+				assert.Nil(t, signer.next)
+			},
+		},
+		{
+			uc:      "current key is kept if new key store cannot be loaded",
+			overlap: 10 * time.Millisecond,
+			update: func(t *testing.T, path string) {
+				t.Helper()
+
+				require.NoError(t, os.WriteFile(path+".tmp", []byte("foobar"), 0o600))
+				require.NoError(t, os.Rename(path+".tmp", path))
+			},
+			assert: func(t *testing.T, signer *jwtSigner) {
+				t.Helper()
+
+				time.Sleep(200 * time.Millisecond)
+
+				assert.Equal(t, "key1", signingKeyIDOf(t, signer))
+				assert.Equal(t, []string{"key1"}, keyIDsOf(signer.Keys()))
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			keyFile := filepath.Join(t.TempDir(), "keys.pem")
+			writeKeyStore(t, keyFile, keys, "key1")
+
+			signer, err := NewJWTSigner(
+				&config.Configuration{Signer: config.SignerConfig{
+					KeyStore:    config.KeyStore{Path: keyFile},
+					KeyRotation: config.KeyRotation{Enabled: true, Overlap: tc.overlap},
+				}},
+				log.Logger,
+			)
+			require.NoError(t, err)
+
+			impl, ok := signer.(*jwtSigner)
+			require.True(t, ok)
+
+			require.NoError(t, impl.start(context.Background()))
+
+			defer impl.stop(context.Background())
+
+			require.Equal(t, "key1", signingKeyIDOf(t, signer))
+
+			// WHEN
+			tc.update(t, keyFile)
+
+			// THEN
+			tc.assert(t, impl)
+		})
+	}
+}
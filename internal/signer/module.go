@@ -16,10 +16,40 @@
 
 package signer
 
-import "go.uber.org/fx"
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/readiness"
+)
 
 // Module is used on app bootstrap.
 // nolint: gochecknoglobals
 var Module = fx.Options(
-	fx.Provide(NewJWTSigner),
+	fx.Provide(
+		fx.Annotate(
+			NewJWTSigner,
+			fx.OnStart(func(ctx context.Context, signer heimdall.JWTSigner) error {
+				if s, ok := signer.(*jwtSigner); ok {
+					return s.start(ctx)
+				}
+
+				return nil
+			}),
+			fx.OnStop(func(ctx context.Context, signer heimdall.JWTSigner) error {
+				if s, ok := signer.(*jwtSigner); ok {
+					return s.stop(ctx)
+				}
+
+				return nil
+			}),
+		),
+	),
+	fx.Invoke(func(registry readiness.Registry, signer heimdall.JWTSigner) {
+		if checker, ok := signer.(readiness.Checker); ok {
+			registry.Register(checker)
+		}
+	}),
 )
@@ -0,0 +1,103 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// Watcher watches the given files and directories and, once started, calls the given
+// reload function in its own goroutine on every change. For files, the enclosing directory is watched,
+// as files might be replaced instead of being written to, e.g. by kubernetes for mounted
+// secrets and config maps. That is why reload functions should be tolerant of events,
+// which do not affect the files they are interested in.
+type Watcher struct {
+	paths []string
+	w     *fsnotify.Watcher
+}
+
+func New(paths ...string) *Watcher {
+	return &Watcher{paths: paths}
+}
+
+func (w *Watcher) Start(logger zerolog.Logger, reload func() error) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to instantiate file watcher").
+			CausedBy(err)
+	}
+
+	for _, path := range w.paths {
+		if fInfo, err := os.Stat(path); err == nil && !fInfo.IsDir() {
+			path = filepath.Dir(path)
+		}
+
+		if err = watcher.Add(path); err != nil {
+			watcher.Close()
+
+			return errorchain.NewWithMessagef(heimdall.ErrInternal, "failed to watch %s", path).
+				CausedBy(err)
+		}
+	}
+
+	w.w = watcher
+
+	go w.watch(logger, reload)
+
+	return nil
+}
+
+func (w *Watcher) Stop() error {
+	if w.w == nil {
+		return nil
+	}
+
+	return w.w.Close()
+}
+
+func (w *Watcher) watch(logger zerolog.Logger, reload func() error) {
+	logger.Debug().Strs("_paths", w.paths).Msg("Watching files for changes")
+
+	for {
+		select {
+		case _, ok := <-w.w.Events:
+			if !ok {
+				logger.Debug().Strs("_paths", w.paths).Msg("File watcher closed")
+
+				return
+			}
+
+			if err := reload(); err != nil {
+				logger.Warn().Err(err).Strs("_paths", w.paths).
+					Msg("Failed to reload changed files. Keeping the current state")
+			}
+		case err, ok := <-w.w.Errors:
+			if !ok {
+				return
+			}
+
+			logger.Warn().Err(err).Strs("_paths", w.paths).Msg("File watcher error received")
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+func TestWatcherStart(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		paths  func(t *testing.T) []string
+		assert func(t *testing.T, err error)
+	}{
+		{
+			uc: "not existing path",
+			paths: func(t *testing.T) []string {
+				t.Helper()
+
+				return []string{filepath.Join(t.TempDir(), "foo", "bar")}
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to watch")
+			},
+		},
+		{
+			uc: "existing file and directory",
+			paths: func(t *testing.T) []string {
+				t.Helper()
+
+				dir := t.TempDir()
+				file := filepath.Join(dir, "foo")
+				require.NoError(t, os.WriteFile(file, []byte("foo"), 0o600))
+
+				return []string{file, dir}
+			},
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			w := New(tc.paths(t)...)
+
+			// WHEN
+			err := w.Start(zerolog.Nop(), func() error { return nil })
+
+			// THEN
+			tc.assert(t, err)
+			require.NoError(t, w.Stop())
+		})
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var calls atomic.Int32
+
+	file := filepath.Join(t.TempDir(), "foo")
+	require.NoError(t, os.WriteFile(file, []byte("foo"), 0o600))
+
+	w := New(file)
+	require.NoError(t, w.Start(zerolog.Nop(), func() error {
+		calls.Add(1)
+
+		return errors.New("test error")
+	}))
+
+	// WHEN
+	require.NoError(t, os.WriteFile(file, []byte("bar"), 0o600))
+
+	// THEN
+	assert.Eventually(t, func() bool { return calls.Load() != 0 }, 2*time.Second, 10*time.Millisecond)
+
+	// WHEN
+	require.NoError(t, w.Stop())
+	time.Sleep(100 * time.Millisecond)
+
+	current := calls.Load()
+
+	require.NoError(t, os.WriteFile(file, []byte("baz"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+
+	// THEN
+	assert.Equal(t, current, calls.Load())
+}
+
+func TestWatcherStopWithoutStart(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, New("foo").Stop())
+}